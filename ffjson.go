@@ -32,12 +32,15 @@ import (
 var outputPathFlag = flag.String("w", "", "Write generate code to this path instead of ${input}_ffjson.go.")
 var goCmdFlag = flag.String("go-cmd", "", "Path to go command; Useful for `goapp` support.")
 var importNameFlag = flag.String("import-name", "", "Override import name in case it cannot be detected.")
-var forceRegenerateFlag = flag.Bool("force-regenerate", false, "Regenerate every input file, without checking modification date.")
+var forceRegenerateFlag = flag.Bool("force-regenerate", false, "Regenerate every input file, even if the hash of its inputs is unchanged.")
+var tagsFlag = flag.String("tags", "", "Comma-separated build tags to compile the package with, as for go build -tags.")
+var staticFlag = flag.Bool("static", false, "Take struct layouts from the type checked source instead of compiling and running an inception program")
+var parallelFlag = flag.Int("j", 0, "Number of packages to generate concurrently; 0 uses the number of CPUs")
 var resetFields = flag.Bool("reset-fields", false, "When unmarshalling reset all fields missing in the JSON")
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "\t%s [options] [input_file]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\t%s [options] input_file|package...\n\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, "%s generates Go code for optimized JSON serialization.\n\n", os.Args[0])
 	flag.PrintDefaults()
 	os.Exit(1)
@@ -49,19 +52,10 @@ func main() {
 	flag.Parse()
 	extra := flag.Args()
 
-	if len(extra) != 1 {
+	if len(extra) == 0 {
 		usage()
 	}
 
-	inputPath := filepath.ToSlash(extra[0])
-
-	var outputPath string
-	if outputPathFlag == nil || *outputPathFlag == "" {
-		outputPath = extRe.ReplaceAllString(inputPath, "${1}_ffjson.go")
-	} else {
-		outputPath = *outputPathFlag
-	}
-
 	var goCmd string
 	if goCmdFlag == nil || *goCmdFlag == "" {
 		goCmd = "go"
@@ -69,17 +63,79 @@ func main() {
 		goCmd = *goCmdFlag
 	}
 
+	// Arguments not naming a .go file are package patterns, like ./...
+	var jobs []generator.Job
+	var patterns []string
+	for _, arg := range extra {
+		if !extRe.MatchString(arg) {
+			patterns = append(patterns, arg)
+			continue
+		}
+
+		inputPath := filepath.ToSlash(arg)
+
+		var outputPath string
+		if outputPathFlag == nil || *outputPathFlag == "" {
+			outputPath = extRe.ReplaceAllString(inputPath, "${1}_ffjson.go")
+		} else {
+			outputPath = *outputPathFlag
+		}
+
+		jobs = append(jobs, generator.Job{InputPath: inputPath, OutputPath: outputPath})
+	}
+
+	if len(patterns) > 0 {
+		pkgJobs, err := generator.PackageJobs(goCmd, *tagsFlag, patterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s:\n\n", err)
+			os.Exit(1)
+		}
+		jobs = append(jobs, pkgJobs...)
+	}
+
+	if outputPathFlag != nil && *outputPathFlag != "" && (len(jobs) > 1 || len(patterns) > 0) {
+		fmt.Fprintf(os.Stderr, "Error: -w can only be used with a single input file\n")
+		os.Exit(1)
+	}
+
+	if *parallelFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -j must not be negative\n")
+		os.Exit(1)
+	}
+
 	var importName string
 	if importNameFlag != nil && *importNameFlag != "" {
 		importName = *importNameFlag
 	}
 
-	err := generator.GenerateFiles(goCmd, inputPath, outputPath, importName, *forceRegenerateFlag, *resetFields)
+	errs := generator.GenerateAll(jobs, *parallelFlag, generator.Options{
+		GoCmd:           goCmd,
+		ImportName:      importName,
+		ForceRegenerate: *forceRegenerateFlag,
+		ResetFields:     *resetFields,
+		Tags:            *tagsFlag,
+		Static:          *staticFlag,
+	})
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s:\n\n", err)
-		os.Exit(1)
+	failed := false
+	for i, err := range errs {
+		if err == generator.ErrNoStructs {
+			continue
+		}
+		if err != nil {
+			if len(jobs) > 1 {
+				fmt.Fprintf(os.Stderr, "Error: %s: %s:\n\n", jobs[i].OutputPath, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s:\n\n", err)
+			}
+			failed = true
+			continue
+		}
+
+		println(jobs[i].OutputPath)
 	}
 
-	println(outputPath)
+	if failed {
+		os.Exit(1)
+	}
 }
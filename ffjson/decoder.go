@@ -18,6 +18,7 @@ package ffjson
  */
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	fflib "github.com/maxproc/ffjson/fflib/v1"
@@ -29,30 +30,82 @@ import (
 // This is a reusable decoder.
 // This should not be used by more than one goroutine at the time.
 type Decoder struct {
-	fs *fflib.FFLexer
+	fs   *fflib.FFLexer
+	opts DecodeOptions
 }
 
+// DecodeOptions configure the generated decoders for a single decoding,
+// see fflib.DecodeOptions. They are passed to the generated
+// UnmarshalJSONOpts methods, or set on a Decoder with SetOptions.
+type DecodeOptions = fflib.DecodeOptions
+
 // NewDecoder returns a reusable Decoder.
 func NewDecoder() *Decoder {
 	return &Decoder{}
 }
 
+// UseNumber makes the decoder store numbers in interface{} values as
+// json.Number instead of float64, like json.Decoder.UseNumber. It applies
+// to the generated decoders and to the encoding/json fallback, but not
+// to UnmarshalJSON methods, which only see the bytes; see
+// fflib.SetUseNumber for those.
+func (d *Decoder) UseNumber() {
+	d.opts.UseNumber = true
+	if d.fs != nil {
+		d.fs.UseNumber = true
+	}
+}
+
+// SetOptions sets the options of the following decodings to opts, or to
+// the defaults if opts is nil, replacing UseNumber. The encoding/json
+// fallback only supports DisallowUnknownFields and UseNumber, and
+// UnmarshalJSON methods, which only see the bytes, none of them.
+func (d *Decoder) SetOptions(opts *DecodeOptions) {
+	d.opts = DecodeOptions{}
+	if opts != nil {
+		d.opts = *opts
+	}
+	if d.fs != nil {
+		d.fs.SetOptions(&d.opts)
+	}
+}
+
+// jsonDecoder sets the options encoding/json supports on dec.
+func (d *Decoder) jsonDecoder(dec *json.Decoder) *json.Decoder {
+	if d.opts.UseNumber {
+		dec.UseNumber()
+	}
+	if d.opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec
+}
+
+// lexer returns the lexer of the decoder, reset to data.
+func (d *Decoder) lexer(data []byte) *fflib.FFLexer {
+	if d.fs == nil {
+		d.fs = fflib.NewFFLexer(data)
+		d.fs.SetOptions(&d.opts)
+	} else {
+		d.fs.Reset(data)
+	}
+	return d.fs
+}
+
 // Decode the data in the supplied data slice.
 func (d *Decoder) Decode(data []byte, v interface{}) error {
 	f, ok := v.(unmarshalFaster)
 	if ok {
-		if d.fs == nil {
-			d.fs = fflib.NewFFLexer(data)
-		} else {
-			d.fs.Reset(data)
-		}
-		return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+		return f.UnmarshalJSONFFLexer(d.lexer(data), fflib.FFParse_map_start)
 	}
 
 	um, ok := v.(json.Unmarshaler)
 	if ok {
 		return um.UnmarshalJSON(data)
 	}
+	if d.opts.UseNumber || d.opts.DisallowUnknownFields {
+		return d.jsonDecoder(json.NewDecoder(bytes.NewReader(data))).Decode(v)
+	}
 	return json.Unmarshal(data, v)
 }
 
@@ -69,8 +122,7 @@ func (d *Decoder) DecodeReader(r io.Reader, v interface{}) error {
 		defer fflib.Pool(data)
 		return d.Decode(data, v)
 	}
-	dec := json.NewDecoder(r)
-	return dec.Decode(v)
+	return d.jsonDecoder(json.NewDecoder(r)).Decode(v)
 }
 
 // DecodeFast will unmarshal the data if fast unmarshal is available.
@@ -83,10 +135,5 @@ func (d *Decoder) DecodeFast(data []byte, v interface{}) error {
 	if !ok {
 		return errors.New("ffjson unmarshal not available for type " + reflect.TypeOf(v).String())
 	}
-	if d.fs == nil {
-		d.fs = fflib.NewFFLexer(data)
-	} else {
-		d.fs.Reset(data)
-	}
-	return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+	return f.UnmarshalJSONFFLexer(d.lexer(data), fflib.FFParse_map_start)
 }
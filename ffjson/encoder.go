@@ -20,6 +20,7 @@ package ffjson
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	fflib "github.com/maxproc/ffjson/fflib/v1"
 	"io"
 	"reflect"
@@ -29,9 +30,10 @@ import (
 // It allows to encode many objects to a single writer.
 // This should not be used by more than one goroutine at the time.
 type Encoder struct {
-	buf fflib.Buffer
-	w   io.Writer
-	enc *json.Encoder
+	out   *fflib.ChunkedWriter
+	w     io.Writer
+	enc   *json.Encoder
+	flush int
 }
 
 // SetEscapeHTML specifies whether problematic HTML characters
@@ -48,7 +50,17 @@ func (enc *Encoder) SetEscapeHTML(on bool) {
 // NewEncoder returns a reusable Encoder.
 // Output will be written to the supplied writer.
 func NewEncoder(w io.Writer) *Encoder {
-	return &Encoder{w: w, enc: json.NewEncoder(w)}
+	return &Encoder{out: fflib.NewChunkedWriter(w, 0), w: w, enc: json.NewEncoder(w)}
+}
+
+// SetFlushSize makes Encode write to the stream whenever more than n
+// bytes are buffered, instead of buffering the whole output of a value.
+// This bounds the memory used for encoding large values, but if encoding
+// fails, part of the value may already have been written.
+// A size of 0, the default, disables this.
+func (e *Encoder) SetFlushSize(n int) {
+	e.out = fflib.NewChunkedWriter(e.w, n)
+	e.flush = n
 }
 
 // Encode the data in the supplied value to the stream
@@ -58,14 +70,13 @@ func NewEncoder(w io.Writer) *Encoder {
 func (e *Encoder) Encode(v interface{}) error {
 	f, ok := v.(marshalerFaster)
 	if ok {
-		e.buf.Reset()
-		err := f.MarshalJSONBuf(&e.buf)
+		e.out.Reset()
+		err := f.MarshalJSONBuf(e.out)
 		if err != nil {
 			return err
 		}
 
-		_, err = io.Copy(e.w, &e.buf)
-		return err
+		return e.out.Flush()
 	}
 
 	return e.enc.Encode(v)
@@ -83,3 +94,63 @@ func (e *Encoder) EncodeFast(v interface{}) error {
 	}
 	return e.Encode(v)
 }
+
+// EncodeArray encodes the elements of the slice or array v as a JSON
+// array, one element at a time, using the ffjson marshal function of
+// the elements if available. Unlike Encode, the output is written
+// whenever more than fflib.StreamChunkSize bytes, or the size set with
+// SetFlushSize, are buffered, so large slices can be written to a
+// connection without holding all of their json in memory. If encoding an
+// element fails, the elements before it may already have been written.
+func (e *Encoder) EncodeArray(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("ffjson: EncodeArray needs a slice or array, not %T", v)
+	}
+
+	chunk := e.flush
+	if chunk <= 0 {
+		chunk = fflib.StreamChunkSize
+	}
+	out := fflib.NewChunkedWriter(e.w, chunk)
+
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		out.WriteString("null")
+		return out.Flush()
+	}
+
+	out.WriteByte('[')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		err := encodeElement(out, rv.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+	out.WriteByte(']')
+	return out.Flush()
+}
+
+// encodeElement writes the element ev of an array to out.
+func encodeElement(out *fflib.ChunkedWriter, ev reflect.Value) error {
+	if ev.CanAddr() {
+		if f, ok := ev.Addr().Interface().(marshalerFaster); ok {
+			return f.MarshalJSONBuf(out)
+		}
+	}
+	elem := ev.Interface()
+	if f, ok := elem.(marshalerFaster); ok {
+		return f.MarshalJSONBuf(out)
+	}
+	b, err := json.Marshal(elem)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	fflib "github.com/maxproc/ffjson/fflib/v1"
 	"reflect"
+	"sync"
 )
 
 type marshalerFaster interface {
@@ -74,6 +75,38 @@ func MarshalFast(v interface{}) ([]byte, error) {
 	return Marshal(v)
 }
 
+// MarshalAppend appends the json of v to dst and returns the extended
+// slice, like Marshal but without allocating the output. The ffjson
+// marshal function encodes into a pooled buffer, which is copied to dst,
+// so reusing a dst with enough capacity makes encoding allocation free.
+// Types without ffjson code fall back to Marshal.
+func MarshalAppend(dst []byte, v interface{}) ([]byte, error) {
+	f, ok := v.(marshalerFaster)
+	if !ok {
+		b, err := Marshal(v)
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, b...), nil
+	}
+
+	buf := appendPool.Get().(*fflib.Buffer)
+	err := f.MarshalJSONBuf(buf)
+	if err == nil {
+		dst = append(dst, buf.Bytes()...)
+	}
+	// Buffers which grew past 64KB are left to the garbage collector,
+	// so a few large values don't keep their memory in the pool.
+	if buf.Len() <= 1<<16 {
+		buf.Reset()
+		appendPool.Put(buf)
+	}
+	return dst, err
+}
+
+// appendPool holds the buffers of MarshalAppend.
+var appendPool = sync.Pool{New: func() interface{} { return new(fflib.Buffer) }}
+
 // Unmarshal will act the same way as json.Unmarshal, except
 // it will choose the ffjson unmarshal function before falling
 // back to using json.Unmarshal.
@@ -0,0 +1,29 @@
+package ffjson
+
+/**
+ *  Copyright 2015 Paul Querna, Klaus Post
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+import (
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+)
+
+// RegisterType registers factory as the constructor of values whose
+// discriminator is name, for interface fields with the polymorphic
+// option of the ffjson tag. See fflib.RegisterType.
+func RegisterType(name string, factory func() interface{}) {
+	fflib.RegisterType(name, factory)
+}
@@ -0,0 +1,87 @@
+package ffjson
+
+/**
+ *  Copyright 2015 Paul Querna, Klaus Post
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+import (
+	"bufio"
+	"io"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+)
+
+// StreamDecoder decodes JSON values from a reader one at a time, reading
+// only as much of the input as each value needs. Unlike
+// Decoder.DecodeReader, the whole input is never held in memory, so
+// streams of values and arrays of any length can be decoded.
+// This should not be used by more than one goroutine at the time.
+type StreamDecoder struct {
+	r   *bufio.Reader
+	arr *fflib.ArrayReader
+	buf fflib.Buffer
+	dec Decoder
+}
+
+// NewStreamDecoder returns a StreamDecoder reading from r. It buffers its
+// reads, so it may read past the last value decoded.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: bufio.NewReader(r)}
+}
+
+// UseNumber makes the decoder store numbers in interface{} values as
+// json.Number instead of float64, see Decoder.UseNumber.
+func (d *StreamDecoder) UseNumber() {
+	d.dec.UseNumber()
+}
+
+// SetOptions sets the options of the following decodings, see
+// Decoder.SetOptions.
+func (d *StreamDecoder) SetOptions(opts *DecodeOptions) {
+	d.dec.SetOptions(opts)
+}
+
+// Decode reads the next JSON value of the stream and stores it in v. It
+// returns io.EOF at the end of the input.
+func (d *StreamDecoder) Decode(v interface{}) error {
+	d.arr = nil
+	d.buf.Reset()
+	err := fflib.ReadValue(d.r, &d.buf)
+	if err != nil {
+		return err
+	}
+	return d.dec.Decode(d.buf.Bytes(), v)
+}
+
+// DecodeElement reads the next element of the array at the current
+// position of the stream and stores it in v, so only one element is in
+// memory at a time. It returns io.EOF after the last element; the next
+// call of Decode, or of DecodeElement for the elements of a following
+// array, reads the value after the array.
+func (d *StreamDecoder) DecodeElement(v interface{}) error {
+	if d.arr == nil {
+		d.arr = fflib.NewArrayReader(d.r)
+	}
+	d.buf.Reset()
+	err := d.arr.Next(&d.buf)
+	if err == io.EOF {
+		d.arr = nil
+	}
+	if err != nil {
+		return err
+	}
+	return d.dec.Decode(d.buf.Bytes(), v)
+}
@@ -0,0 +1,360 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// BinaryWriter writes values in a binary format, like MessagePack or
+// CBOR. The generated MarshalBinaryFF methods write to it, so the same
+// code serves every format.
+type BinaryWriter interface {
+	WriteNil()
+	WriteBool(v bool)
+	WriteInt(v int64)
+	WriteUint(v uint64)
+	WriteFloat32(v float32)
+	WriteFloat64(v float64)
+	WriteString(v string)
+	WriteBytes(v []byte)
+	WriteTime(v time.Time)
+	// WriteArrayHeader starts an array of n values, and WriteMapHeader
+	// a map of n keys, each followed by its value.
+	WriteArrayHeader(n int)
+	WriteMapHeader(n int)
+}
+
+// BinaryReader reads the values written by a BinaryWriter, for the
+// generated UnmarshalBinaryFF methods.
+type BinaryReader interface {
+	// Kind returns the kind of the next value, without reading it.
+	Kind() (BinaryKind, error)
+	// ReadNil reads the next value if it is nil, and reports whether
+	// it was.
+	ReadNil() bool
+	ReadBool() (bool, error)
+	// ReadInt and ReadUint read integers of any size and sign that
+	// fit, and ReadFloat also reads integers.
+	ReadInt() (int64, error)
+	ReadUint() (uint64, error)
+	ReadFloat() (float64, error)
+	ReadString() (string, error)
+	// ReadKey reads a string like ReadString, returning bytes of the
+	// input which are only valid until the next read.
+	ReadKey() ([]byte, error)
+	ReadBytes() ([]byte, error)
+	ReadTime() (time.Time, error)
+	// ReadArrayHeader and ReadMapHeader return the number of values of
+	// an array, and of keys of a map.
+	ReadArrayHeader() (int, error)
+	ReadMapHeader() (int, error)
+	// Skip reads over the next value, including the values it contains.
+	Skip() error
+	// Errorf returns an error at the position of the reader.
+	Errorf(format string, args ...interface{}) error
+	// Finish returns an error if there is input left.
+	Finish() error
+}
+
+// BinaryKind is the kind of a value of a binary format.
+type BinaryKind int
+
+const (
+	BinaryNil BinaryKind = iota
+	BinaryBool
+	// BinaryInt is a negative integer, or one of a signed type in
+	// formats which have them, and BinaryUint other integers.
+	BinaryInt
+	BinaryUint
+	BinaryFloat
+	BinaryString
+	BinaryBytes
+	BinaryArray
+	BinaryMap
+	BinaryTime
+	// BinaryOther are the values of extensions of the format.
+	BinaryOther
+)
+
+// BinaryMaxDepth is the maximum nesting of arrays and maps read by
+// ReadBinaryValue.
+const BinaryMaxDepth = 10000
+
+// BinaryMarshaler and BinaryUnmarshaler are implemented by the structs
+// with generated binary code.
+type BinaryMarshaler interface {
+	MarshalBinaryFF(w BinaryWriter) error
+}
+
+type BinaryUnmarshaler interface {
+	UnmarshalBinaryFF(r BinaryReader) error
+}
+
+// WriteBinaryValue writes v, the value of an interface{} field, to w.
+// Like encoding/json, it supports nil, booleans, numbers, strings,
+// []interface{} and map[string]interface{}, and also []byte, time.Time
+// and the structs with generated binary code.
+func WriteBinaryValue(w BinaryWriter, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		w.WriteNil()
+	case bool:
+		w.WriteBool(t)
+	case int:
+		w.WriteInt(int64(t))
+	case int8:
+		w.WriteInt(int64(t))
+	case int16:
+		w.WriteInt(int64(t))
+	case int32:
+		w.WriteInt(int64(t))
+	case int64:
+		w.WriteInt(t)
+	case uint:
+		w.WriteUint(uint64(t))
+	case uint8:
+		w.WriteUint(uint64(t))
+	case uint16:
+		w.WriteUint(uint64(t))
+	case uint32:
+		w.WriteUint(uint64(t))
+	case uint64:
+		w.WriteUint(t)
+	case float32:
+		w.WriteFloat32(t)
+	case float64:
+		w.WriteFloat64(t)
+	case string:
+		w.WriteString(t)
+	case []byte:
+		w.WriteBytes(t)
+	case time.Time:
+		w.WriteTime(t)
+	case []interface{}:
+		w.WriteArrayHeader(len(t))
+		for _, e := range t {
+			err := WriteBinaryValue(w, e)
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		w.WriteMapHeader(len(t))
+		for k, e := range t {
+			w.WriteString(k)
+			err := WriteBinaryValue(w, e)
+			if err != nil {
+				return err
+			}
+		}
+	case BinaryMarshaler:
+		return t.MarshalBinaryFF(w)
+	default:
+		return &UnsupportedBinaryTypeError{Value: v}
+	}
+	return nil
+}
+
+// UnsupportedBinaryTypeError is returned by WriteBinaryValue for values
+// it can't write.
+type UnsupportedBinaryTypeError struct {
+	Value interface{}
+}
+
+func (e *UnsupportedBinaryTypeError) Error() string {
+	return fmt.Sprintf("ffjson: unsupported type %T in a binary format", e.Value)
+}
+
+// ReadBinaryValue reads the next value of r into an interface{}: nil,
+// a bool, an int64, a uint64 for integers above math.MaxInt64, a
+// float64, a string, a []byte, a time.Time, a []interface{} or a
+// map[string]interface{}, whose integer keys are converted to strings.
+func ReadBinaryValue(r BinaryReader) (interface{}, error) {
+	return readBinaryValue(r, 0)
+}
+
+func readBinaryValue(r BinaryReader, depth int) (interface{}, error) {
+	kind, err := r.Kind()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case BinaryNil:
+		r.ReadNil()
+		return nil, nil
+	case BinaryBool:
+		return r.ReadBool()
+	case BinaryInt:
+		return r.ReadInt()
+	case BinaryUint:
+		v, err := r.ReadUint()
+		if err != nil || v > math.MaxInt64 {
+			return v, err
+		}
+		return int64(v), nil
+	case BinaryFloat:
+		return r.ReadFloat()
+	case BinaryString:
+		return r.ReadString()
+	case BinaryBytes:
+		return r.ReadBytes()
+	case BinaryTime:
+		return r.ReadTime()
+	case BinaryArray, BinaryMap:
+		if depth >= BinaryMaxDepth {
+			return nil, r.Errorf("exceeded max depth")
+		}
+	default:
+		return nil, r.Errorf("unsupported value")
+	}
+
+	if kind == BinaryArray {
+		n, err := r.ReadArrayHeader()
+		if err != nil {
+			return nil, err
+		}
+		v := make([]interface{}, n)
+		for i := range v {
+			v[i], err = readBinaryValue(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	}
+
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return nil, err
+	}
+	v := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readBinaryKey(r)
+		if err != nil {
+			return nil, err
+		}
+		v[k], err = readBinaryValue(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// readBinaryKey reads a key of a map, converting integers to strings as
+// they are in JSON objects.
+func readBinaryKey(r BinaryReader) (string, error) {
+	kind, err := r.Kind()
+	if err != nil {
+		return "", err
+	}
+	switch kind {
+	case BinaryInt:
+		v, err := r.ReadInt()
+		return strconv.FormatInt(v, 10), err
+	case BinaryUint:
+		v, err := r.ReadUint()
+		return strconv.FormatUint(v, 10), err
+	}
+	return r.ReadString()
+}
+
+// binaryInput is the input of a BinaryReader, with the methods common
+// to the formats.
+type binaryInput struct {
+	format string
+	data   []byte
+	off    int
+}
+
+// Errorf returns an error at the offset of the input.
+func (in *binaryInput) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s at offset %d", in.format, fmt.Sprintf(format, args...), in.off)
+}
+
+// Finish returns an error if there is input left.
+func (in *binaryInput) Finish() error {
+	if in.off < len(in.data) {
+		return in.Errorf("unexpected data after the top-level value")
+	}
+	return nil
+}
+
+func (in *binaryInput) eof() error {
+	return in.Errorf("unexpected end of input")
+}
+
+// peek returns the next byte without reading it.
+func (in *binaryInput) peek() (byte, error) {
+	if in.off >= len(in.data) {
+		return 0, in.eof()
+	}
+	return in.data[in.off], nil
+}
+
+func (in *binaryInput) byte() (byte, error) {
+	b, err := in.peek()
+	if err == nil {
+		in.off++
+	}
+	return b, err
+}
+
+// next reads n bytes.
+func (in *binaryInput) next(n uint64) ([]byte, error) {
+	if n > uint64(len(in.data)-in.off) {
+		return nil, in.eof()
+	}
+	b := in.data[in.off : in.off+int(n)]
+	in.off += int(n)
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of size bytes.
+func (in *binaryInput) uint(size int) (uint64, error) {
+	b, err := in.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// count checks that n values of at least size bytes each fit in the
+// input left, so lengths can't allocate more than the input warrants.
+func (in *binaryInput) count(n uint64, size int) (int, error) {
+	if n > uint64(len(in.data)-in.off)/uint64(size) {
+		return 0, in.Errorf("length %d exceeds the input", n)
+	}
+	return int(n), nil
+}
+
+// appendUint appends v in size big endian bytes.
+func appendUint(buf []byte, v uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(v>>(8*uint(i))))
+	}
+	return buf
+}
@@ -0,0 +1,107 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBinaryValue(t *testing.T) {
+	value := map[string]interface{}{
+		"list": []interface{}{int64(1), int64(-2), uint64(1 << 63), 1.5, "x", true, nil},
+		"bin":  []byte{1, 2},
+		"time": time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+		"map":  map[string]interface{}{},
+	}
+
+	formats := []struct {
+		name   string
+		write  func(v interface{}) ([]byte, error)
+		reader func(data []byte) BinaryReader
+	}{
+		{"msgpack", func(v interface{}) ([]byte, error) {
+			w := NewMsgpackWriter(nil)
+			err := WriteBinaryValue(w, v)
+			return w.Bytes(), err
+		}, func(data []byte) BinaryReader { return NewMsgpackReader(data) }},
+		{"cbor", func(v interface{}) ([]byte, error) {
+			w := NewCBORWriter(nil)
+			err := WriteBinaryValue(w, v)
+			return w.Bytes(), err
+		}, func(data []byte) BinaryReader { return NewCBORReader(data) }},
+	}
+
+	for _, f := range formats {
+		data, err := f.write(value)
+		if err != nil {
+			t.Fatalf("%s: WriteBinaryValue: %v", f.name, err)
+		}
+		r := f.reader(data)
+		out, err := ReadBinaryValue(r)
+		if err != nil {
+			t.Fatalf("%s: ReadBinaryValue: %v", f.name, err)
+		}
+		if !reflect.DeepEqual(out, value) {
+			t.Errorf("%s:\nExpected: %#v\nGot: %#v", f.name, value, out)
+		}
+		if err := r.Finish(); err != nil {
+			t.Errorf("%s: Finish: %v", f.name, err)
+		}
+
+		// Small integers of any type are read as int64.
+		data, _ = f.write([]interface{}{uint8(1), int16(-1), float32(0.5)})
+		out, err = ReadBinaryValue(f.reader(data))
+		expected := []interface{}{int64(1), int64(-1), 0.5}
+		if err != nil || !reflect.DeepEqual(out, expected) {
+			t.Errorf("%s: got %#v, %v, expected %#v", f.name, out, err, expected)
+		}
+
+		_, err = f.write(struct{}{})
+		if err == nil {
+			t.Errorf("%s: expected an error for an unsupported type", f.name)
+		}
+	}
+}
+
+func TestBinaryValueIntegerKeys(t *testing.T) {
+	w := NewCBORWriter(nil)
+	w.WriteMapHeader(2)
+	w.WriteInt(-1)
+	w.WriteBool(true)
+	w.WriteUint(7)
+	w.WriteNil()
+	out, err := ReadBinaryValue(NewCBORReader(w.Bytes()))
+	expected := map[string]interface{}{"-1": true, "7": nil}
+	if err != nil || !reflect.DeepEqual(out, expected) {
+		t.Errorf("got %#v, %v, expected %#v", out, err, expected)
+	}
+}
+
+func TestBinaryValueDepth(t *testing.T) {
+	w := NewMsgpackWriter(nil)
+	for i := 0; i <= BinaryMaxDepth; i++ {
+		w.WriteArrayHeader(1)
+	}
+	w.WriteNil()
+	_, err := ReadBinaryValue(NewMsgpackReader(w.Bytes()))
+	if err == nil {
+		t.Errorf("expected an error for %d nested arrays", BinaryMaxDepth+1)
+	}
+}
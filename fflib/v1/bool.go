@@ -0,0 +1,44 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+)
+
+// ParseBool parses the contents of a JSON boolean or string token.
+// With fold set, "true" and "false" are matched case-insensitively.
+func ParseBool(b []byte, fold bool) (value bool, ok bool) {
+	if fold {
+		switch {
+		case bytes.EqualFold(b, []byte("true")):
+			return true, true
+		case bytes.EqualFold(b, []byte("false")):
+			return false, true
+		}
+		return false, false
+	}
+
+	switch string(b) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	return false, false
+}
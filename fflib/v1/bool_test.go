@@ -0,0 +1,45 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		in    string
+		fold  bool
+		value bool
+		ok    bool
+	}{
+		{"true", false, true, true},
+		{"false", false, false, true},
+		{"True", false, false, false},
+		{"TRUE", true, true, true},
+		{"fAlSe", true, false, true},
+		{"yes", true, false, false},
+		{"", true, false, false},
+	}
+	for _, test := range tests {
+		value, ok := ParseBool([]byte(test.in), test.fold)
+		if value != test.value || ok != test.ok {
+			t.Errorf("ParseBool(%q, %t) = %t, %t, expected %t, %t", test.in, test.fold, value, ok, test.value, test.ok)
+		}
+	}
+}
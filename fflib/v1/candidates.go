@@ -0,0 +1,114 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type unmarshalFaster interface {
+	UnmarshalJSONFFLexer(l *FFLexer, state FFParseState) error
+}
+
+// UnmarshalCandidates decodes data into the first of candidates that
+// accepts it without an error, and returns the index of that candidate.
+// Candidates are pointers, and are tried in order using their generated
+// decoder, their UnmarshalJSON method, or encoding/json, whichever is
+// available first. A candidate decoding with a generated decoder or
+// encoding/json only accepts data if it knows all of its keys and
+// consumes all of it, so a struct doesn't take the objects of another
+// one. If no candidate accepts data, the returned error lists the error
+// of each candidate. opts, which may be nil, are the options of the
+// decoder of the value, usually those of its lexer.
+func UnmarshalCandidates(data []byte, opts *DecodeOptions, candidates ...interface{}) (int, error) {
+	errs := make([]string, 0, len(candidates))
+	for i, c := range candidates {
+		err := unmarshalCandidate(data, opts, c)
+		if err == nil {
+			return i, nil
+		}
+		errs = append(errs, fmt.Sprintf("%T: %v", c, err))
+	}
+	return -1, fmt.Errorf("ffjson: no candidate type accepted the value: %s", strings.Join(errs, "; "))
+}
+
+// unmarshalInto decodes data into v with its generated decoder, its
+// UnmarshalJSON method, or encoding/json, with the options opts.
+func unmarshalInto(data []byte, opts *DecodeOptions, v interface{}) error {
+	switch u := v.(type) {
+	case unmarshalFaster:
+		fs := NewFFLexer(data)
+		fs.SetOptions(opts)
+		return u.UnmarshalJSONFFLexer(fs, FFParse_map_start)
+	case json.Unmarshaler:
+		return u.UnmarshalJSON(data)
+	}
+	return newStdDecoder(data, opts).Decode(v)
+}
+
+// newStdDecoder returns an encoding/json decoder of data with the
+// options opts it supports, and the global switches.
+func newStdDecoder(data []byte, opts *DecodeOptions) *json.Decoder {
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if opts.DisallowUnknownFields || DisallowUnknownFields() {
+		dec.DisallowUnknownFields()
+	}
+	if opts.UseNumber || UseNumber() {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+// unmarshalCandidate decodes data into c like unmarshalInto, but fails
+// on unknown keys and on data left after the value. UnmarshalJSON methods
+// are trusted to check both themselves.
+func unmarshalCandidate(data []byte, opts *DecodeOptions, c interface{}) error {
+	switch u := c.(type) {
+	case unmarshalFaster:
+		fs := NewFFLexer(data)
+		fs.SetOptions(opts)
+		fs.DisallowUnknownFields = true
+		err := u.UnmarshalJSONFFLexer(fs, FFParse_map_start)
+		if err != nil {
+			return err
+		}
+		if tok := fs.Scan(); tok != FFTok_eof {
+			return fmt.Errorf("ffjson: unexpected token after the value: %v", tok)
+		}
+		return nil
+	case json.Unmarshaler:
+		return u.UnmarshalJSON(data)
+	}
+	dec := newStdDecoder(data, opts)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(c)
+	if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("ffjson: unexpected data after the value")
+	}
+	return nil
+}
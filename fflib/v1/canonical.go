@@ -0,0 +1,301 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonicalize re-encodes a JSON document according to the
+// RFC 8785 JSON Canonicalization Scheme (JCS):
+//
+//   - object members are sorted by the UTF-16 code units of their names,
+//   - numbers are treated as IEEE-754 doubles and serialized using the
+//     ECMAScript Number.prototype.toString algorithm,
+//   - strings use the minimal escaping mandated by the RFC,
+//   - all insignificant whitespace is removed.
+//
+// Since JCS numbers are doubles, integers outside of +/-2^53 lose
+// precision. Duplicate object names are rejected.
+func Canonicalize(input []byte) ([]byte, error) {
+	// The lexer needs a delimiter after a number, so a trailing space is
+	// appended for documents consisting of a bare number.
+	fs := NewFFLexer(append(input[:len(input):len(input)], ' '))
+	buf := Buffer{}
+
+	err := canonicalValue(fs, fs.Scan(), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	tok := fs.Scan()
+	if tok != FFTok_eof {
+		return nil, fs.WrapErr(fmt.Errorf("ffjson: unexpected token after canonical value: %v", tok))
+	}
+	return buf.Bytes(), nil
+}
+
+func lexerTokError(fs *FFLexer) error {
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err := fs.Error.ToError()
+	if err == nil {
+		err = errors.New("ffjson: unexpected EOF")
+	}
+	return fs.WrapErr(err)
+}
+
+type canonicalMember struct {
+	key   []uint16
+	name  string
+	value []byte
+}
+
+type canonicalMembers []canonicalMember
+
+func (m canonicalMembers) Len() int      { return len(m) }
+func (m canonicalMembers) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m canonicalMembers) Less(i, j int) bool {
+	a, b := m[i].key, m[j].key
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func canonicalValue(fs *FFLexer, tok FFTok, buf *Buffer) error {
+	switch tok {
+	case FFTok_null, FFTok_bool:
+		buf.Write(fs.Output.Bytes())
+	case FFTok_integer, FFTok_double:
+		f, err := ParseFloat(fs.Output.Bytes(), 64)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		err = AppendFloatCanonical(buf, f)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+	case FFTok_string:
+		WriteJsonCanonical(buf, fs.Output.Bytes())
+	case FFTok_left_brace:
+		return canonicalArray(fs, buf)
+	case FFTok_left_bracket:
+		return canonicalObject(fs, buf)
+	case FFTok_error, FFTok_eof:
+		return lexerTokError(fs)
+	default:
+		return fs.WrapErr(fmt.Errorf("ffjson: unexpected token: %v", tok))
+	}
+	return nil
+}
+
+func canonicalArray(fs *FFLexer, buf *Buffer) error {
+	buf.WriteByte('[')
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		buf.WriteByte(']')
+		return nil
+	}
+	for {
+		err := canonicalValue(fs, tok, buf)
+		if err != nil {
+			return err
+		}
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_comma:
+			buf.WriteByte(',')
+			tok = fs.Scan()
+		case FFTok_right_brace:
+			buf.WriteByte(']')
+			return nil
+		case FFTok_error, FFTok_eof:
+			return lexerTokError(fs)
+		default:
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of array, but got token: %v", tok))
+		}
+	}
+}
+
+func canonicalObject(fs *FFLexer, buf *Buffer) error {
+	members := canonicalMembers{}
+	seen := make(map[string]bool)
+
+	tok := fs.Scan()
+	if tok != FFTok_right_bracket {
+		for {
+			if tok == FFTok_error || tok == FFTok_eof {
+				return lexerTokError(fs)
+			}
+			if tok != FFTok_string {
+				return fs.WrapErr(fmt.Errorf("ffjson: wanted key token, but got token: %v", tok))
+			}
+			name := fs.Output.String()
+			if seen[name] {
+				return fs.WrapErr(fmt.Errorf("ffjson: duplicate object key %q", name))
+			}
+			seen[name] = true
+
+			tok = fs.Scan()
+			if tok != FFTok_colon {
+				return fs.WrapErr(fmt.Errorf("ffjson: wanted colon token, but got token: %v", tok))
+			}
+
+			value := Buffer{}
+			err := canonicalValue(fs, fs.Scan(), &value)
+			if err != nil {
+				return err
+			}
+			members = append(members, canonicalMember{
+				key:   utf16.Encode([]rune(name)),
+				name:  name,
+				value: value.Bytes(),
+			})
+
+			tok = fs.Scan()
+			if tok == FFTok_right_bracket {
+				break
+			}
+			if tok != FFTok_comma {
+				if tok == FFTok_error || tok == FFTok_eof {
+					return lexerTokError(fs)
+				}
+				return fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of object, but got token: %v", tok))
+			}
+			tok = fs.Scan()
+		}
+	}
+
+	sort.Sort(members)
+
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		WriteJsonCanonical(buf, []byte(m.name))
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// AppendFloatCanonical writes f using the ECMAScript Number serialization
+// required by RFC 8785. NaN and Infinity are not representable and
+// result in an error.
+func AppendFloatCanonical(buf EncodingBuffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("ffjson: unsupported value in canonical json: %s", strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	// This also takes care of negative zero.
+	if f == 0 {
+		buf.WriteByte('0')
+		return nil
+	}
+	if f < 0 {
+		buf.WriteByte('-')
+		f = -f
+	}
+
+	format := byte('e')
+	if f < 1e21 && f >= 1e-6 {
+		format = 'f'
+	}
+
+	var scratch [32]byte
+	b := strconv.AppendFloat(scratch[:0], f, format, -1, 64)
+	if format == 'e' {
+		// Go writes "1e+09" where ECMAScript writes "1e+9".
+		for i := 0; i < len(b); i++ {
+			if b[i] == 'e' && i+3 < len(b) && b[i+2] == '0' {
+				b = append(b[:i+2], b[i+3:]...)
+				break
+			}
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// WriteJsonCanonical writes s as a JSON string with the minimal escaping
+// required by RFC 8785: only '"', '\\' and control characters are escaped,
+// using the short forms where JSON defines them.
+func WriteJsonCanonical(buf JsonStringWriter, s []byte) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b >= utf8.RuneSelf {
+			c, size := utf8.DecodeRune(s[i:])
+			if c == utf8.RuneError && size == 1 {
+				if start < i {
+					buf.Write(s[start:i])
+				}
+				buf.WriteString("\ufffd")
+				i += size
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		if b >= 0x20 && b != '"' && b != '\\' {
+			i++
+			continue
+		}
+		if start < i {
+			buf.Write(s[start:i])
+		}
+		switch b {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(b)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[b>>4])
+			buf.WriteByte(hex[b&0xF])
+		}
+		i++
+		start = i
+	}
+	if start < len(s) {
+		buf.Write(s[start:])
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,126 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"testing"
+)
+
+// Test vectors from RFC 8785, Appendix B.
+var canonicalNumbers = []struct {
+	bits     uint64
+	expected string
+}{
+	{0x0000000000000000, "0"},
+	{0x8000000000000000, "0"},
+	{0x0000000000000001, "5e-324"},
+	{0x8000000000000001, "-5e-324"},
+	{0x7fefffffffffffff, "1.7976931348623157e+308"},
+	{0xffefffffffffffff, "-1.7976931348623157e+308"},
+	{0x4340000000000000, "9007199254740992"},
+	{0xc340000000000000, "-9007199254740992"},
+	{0x4430000000000000, "295147905179352830000"},
+	{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+	{0x44b52d02c7e14af6, "1e+23"},
+	{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+	{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+	{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+	{0x444b1ae4d6e2ef50, "1e+21"},
+	{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+	{0x3eb0c6f7a0b5ed8d, "0.000001"},
+	{0x41b3de4355555553, "333333333.3333332"},
+	{0x41b3de4355555554, "333333333.33333325"},
+	{0x41b3de4355555555, "333333333.3333333"},
+	{0x41b3de4355555556, "333333333.3333334"},
+	{0x41b3de4355555557, "333333333.33333343"},
+	{0xbecbf647612f3696, "-0.0000033333333333333333"},
+	{0x43143ff3c1cb0959, "1424953923781206.2"},
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	for _, tc := range canonicalNumbers {
+		var buf Buffer
+		err := AppendFloatCanonical(&buf, math.Float64frombits(tc.bits))
+		if err != nil {
+			t.Fatalf("%016x: unexpected error: %v", tc.bits, err)
+		}
+		if buf.String() != tc.expected {
+			t.Fatalf("%016x: Expected: %v\nGot: %v", tc.bits, tc.expected, buf.String())
+		}
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000} {
+		var buf Buffer
+		err := AppendFloatCanonical(&buf, math.Float64frombits(bits))
+		if err == nil {
+			t.Fatalf("%016x: expected error, got %v", bits, buf.String())
+		}
+	}
+}
+
+func tCanonical(t *testing.T, input string, expected string) {
+	out, err := Canonicalize([]byte(input))
+	if err != nil {
+		t.Fatalf("Canonicalize(%s): unexpected error: %v", input, err)
+	}
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	// RFC 8785, Section 3.2.2.
+	tCanonical(t, `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`)
+
+	// RFC 8785, Section 3.2.3.
+	tCanonical(t, `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\","+
+		"\"€\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}")
+
+	tCanonical(t, `{"b":{"z":[], "a":{}},"a":"<&>"}`, `{"a":"<&>","b":{"a":{},"z":[]}}`)
+	tCanonical(t, ` 12 `, `12`)
+	tCanonical(t, `-1.50`, `-1.5`)
+}
+
+func TestCanonicalizeInvalid(t *testing.T) {
+	for _, input := range []string{
+		`{"a":1,"a":2}`,
+		`{"a":1,}`,
+		`[1 2]`,
+		`{"a"}`,
+		`[1]]`,
+		``,
+	} {
+		_, err := Canonicalize([]byte(input))
+		if err == nil {
+			t.Fatalf("Canonicalize(%s): expected error", input)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"time"
+)
+
+// The major types of CBOR.
+const (
+	cborUint = iota << 5
+	cborNegint
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// CBORWriter is a BinaryWriter of CBOR, writing definite lengths and
+// the shortest form of integers and lengths, as in the preferred
+// serialization of RFC 8949.
+type CBORWriter struct {
+	buf []byte
+}
+
+// NewCBORWriter returns a CBORWriter appending to buf[:0].
+func NewCBORWriter(buf []byte) *CBORWriter {
+	return &CBORWriter{buf: buf[:0]}
+}
+
+// Bytes returns the CBOR written.
+func (w *CBORWriter) Bytes() []byte {
+	return w.buf
+}
+
+// head writes the head of a value of major type major and argument v.
+func (w *CBORWriter) head(major byte, v uint64) {
+	switch {
+	case v < 24:
+		w.buf = append(w.buf, major|byte(v))
+	case v <= math.MaxUint8:
+		w.buf = append(w.buf, major|24, byte(v))
+	case v <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, major|25), v, 2)
+	case v <= math.MaxUint32:
+		w.buf = appendUint(append(w.buf, major|26), v, 4)
+	default:
+		w.buf = appendUint(append(w.buf, major|27), v, 8)
+	}
+}
+
+func (w *CBORWriter) WriteNil() {
+	w.buf = append(w.buf, 0xf6)
+}
+
+func (w *CBORWriter) WriteBool(v bool) {
+	if v {
+		w.buf = append(w.buf, 0xf5)
+	} else {
+		w.buf = append(w.buf, 0xf4)
+	}
+}
+
+func (w *CBORWriter) WriteInt(v int64) {
+	if v < 0 {
+		w.head(cborNegint, uint64(-1-v))
+	} else {
+		w.head(cborUint, uint64(v))
+	}
+}
+
+func (w *CBORWriter) WriteUint(v uint64) {
+	w.head(cborUint, v)
+}
+
+func (w *CBORWriter) WriteFloat32(v float32) {
+	w.buf = appendUint(append(w.buf, 0xfa), uint64(math.Float32bits(v)), 4)
+}
+
+func (w *CBORWriter) WriteFloat64(v float64) {
+	w.buf = appendUint(append(w.buf, 0xfb), math.Float64bits(v), 8)
+}
+
+func (w *CBORWriter) WriteString(v string) {
+	w.head(cborText, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *CBORWriter) WriteBytes(v []byte) {
+	w.head(cborBytes, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// WriteTime writes v as a standard date/time string, tag 0, which
+// keeps its offset from UTC.
+func (w *CBORWriter) WriteTime(v time.Time) {
+	w.buf = append(w.buf, cborTag|0)
+	w.WriteString(v.Format(time.RFC3339Nano))
+}
+
+func (w *CBORWriter) WriteArrayHeader(n int) {
+	w.head(cborArray, uint64(n))
+}
+
+func (w *CBORWriter) WriteMapHeader(n int) {
+	w.head(cborMap, uint64(n))
+}
+
+// CBORReader is a BinaryReader of CBOR. Items of indefinite length
+// aren't supported.
+type CBORReader struct {
+	binaryInput
+}
+
+// NewCBORReader returns a CBORReader of data.
+func NewCBORReader(data []byte) *CBORReader {
+	return &CBORReader{binaryInput{format: "cbor", data: data}}
+}
+
+// head reads the head of a value, returning its major type and its
+// argument. Simple values and floats are returned with their additional
+// information as argument, without reading what follows.
+func (r *CBORReader) head() (byte, uint64, error) {
+	b, err := r.byte()
+	if err != nil {
+		return 0, 0, err
+	}
+	major, info := b&0xe0, b&0x1f
+	switch {
+	case major == cborSimple && info < 28:
+		return major, uint64(info), nil
+	case info < 24:
+		return major, uint64(info), nil
+	case info <= 27:
+		v, err := r.uint(1 << (info - 24))
+		return major, v, err
+	case info == 31:
+		r.off--
+		return 0, 0, r.Errorf("indefinite length items aren't supported")
+	}
+	r.off--
+	return 0, 0, r.Errorf("invalid additional information %d", info)
+}
+
+// expect reads the head of a value of major type major, and resets the
+// reader to the value if it has another type.
+func (r *CBORReader) expect(major byte, what string) (uint64, error) {
+	start := r.off
+	m, v, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		r.off = start
+		return 0, r.Errorf("expected %s", what)
+	}
+	return v, nil
+}
+
+func (r *CBORReader) Kind() (BinaryKind, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	switch b & 0xe0 {
+	case cborUint:
+		return BinaryUint, nil
+	case cborNegint:
+		return BinaryInt, nil
+	case cborBytes:
+		return BinaryBytes, nil
+	case cborText:
+		return BinaryString, nil
+	case cborArray:
+		return BinaryArray, nil
+	case cborMap:
+		return BinaryMap, nil
+	case cborTag:
+		if b == cborTag|0 || b == cborTag|1 {
+			return BinaryTime, nil
+		}
+		return BinaryOther, nil
+	}
+	switch b {
+	case 0xf4, 0xf5:
+		return BinaryBool, nil
+	case 0xf6, 0xf7:
+		return BinaryNil, nil
+	case 0xf9, 0xfa, 0xfb:
+		return BinaryFloat, nil
+	}
+	return BinaryOther, nil
+}
+
+// ReadNil also reads undefined.
+func (r *CBORReader) ReadNil() bool {
+	if r.off < len(r.data) && (r.data[r.off] == 0xf6 || r.data[r.off] == 0xf7) {
+		r.off++
+		return true
+	}
+	return false
+}
+
+func (r *CBORReader) ReadBool() (bool, error) {
+	b, err := r.peek()
+	if err != nil {
+		return false, err
+	}
+	if b != 0xf4 && b != 0xf5 {
+		return false, r.Errorf("expected a boolean")
+	}
+	r.off++
+	return b == 0xf5, nil
+}
+
+// integer reads an integer as its major type and argument.
+func (r *CBORReader) integer() (byte, uint64, error) {
+	start := r.off
+	major, v, err := r.head()
+	if err != nil {
+		return 0, 0, err
+	}
+	if major != cborUint && major != cborNegint {
+		r.off = start
+		return 0, 0, r.Errorf("expected an integer")
+	}
+	return major, v, nil
+}
+
+func (r *CBORReader) ReadInt() (int64, error) {
+	start := r.off
+	major, v, err := r.integer()
+	if err != nil {
+		return 0, err
+	}
+	if v > math.MaxInt64 {
+		r.off = start
+		if major == cborNegint {
+			return 0, r.Errorf("integer -1-%d overflows int64", v)
+		}
+		return 0, r.Errorf("integer %d overflows int64", v)
+	}
+	if major == cborNegint {
+		return -1 - int64(v), nil
+	}
+	return int64(v), nil
+}
+
+func (r *CBORReader) ReadUint() (uint64, error) {
+	start := r.off
+	major, v, err := r.integer()
+	if err != nil {
+		return 0, err
+	}
+	if major == cborNegint {
+		r.off = start
+		return 0, r.Errorf("negative integer for an unsigned integer")
+	}
+	return v, nil
+}
+
+func (r *CBORReader) ReadFloat() (float64, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 0xf9:
+		r.off++
+		v, err := r.uint(2)
+		return halfFloat(uint16(v)), err
+	case 0xfa:
+		r.off++
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xfb:
+		r.off++
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	}
+	major, v, err := r.integer()
+	if major == cborNegint {
+		return -1 - float64(v), err
+	}
+	return float64(v), err
+}
+
+// halfFloat converts the bits of an IEEE 754 half precision float.
+func halfFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
+
+func (r *CBORReader) ReadKey() ([]byte, error) {
+	n, err := r.expect(cborText, "a string")
+	if err != nil {
+		return nil, err
+	}
+	return r.next(n)
+}
+
+func (r *CBORReader) ReadString() (string, error) {
+	b, err := r.ReadKey()
+	return string(b), err
+}
+
+func (r *CBORReader) ReadBytes() ([]byte, error) {
+	n, err := r.expect(cborBytes, "a byte string")
+	if err != nil {
+		return nil, err
+	}
+	v, err := r.next(n)
+	return append([]byte(nil), v...), err
+}
+
+// ReadTime reads a date/time string, tag 0, or an epoch based date/time,
+// tag 1, in UTC.
+func (r *CBORReader) ReadTime() (time.Time, error) {
+	start := r.off
+	tag, err := r.expect(cborTag, "a time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch tag {
+	case 0:
+		s, err := r.ReadString()
+		if err != nil {
+			return time.Time{}, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			r.off = start
+			return time.Time{}, r.Errorf("invalid time %q", s)
+		}
+		return t, nil
+	case 1:
+		kind, err := r.Kind()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if kind == BinaryFloat {
+			f, err := r.ReadFloat()
+			if err != nil {
+				return time.Time{}, err
+			}
+			sec := math.Floor(f)
+			return time.Unix(int64(sec), int64((f-sec)*1e9)).UTC(), nil
+		}
+		sec, err := r.ReadInt()
+		return time.Unix(sec, 0).UTC(), err
+	}
+	r.off = start
+	return time.Time{}, r.Errorf("expected a time, got tag %d", tag)
+}
+
+func (r *CBORReader) ReadArrayHeader() (int, error) {
+	n, err := r.expect(cborArray, "an array")
+	if err != nil {
+		return 0, err
+	}
+	return r.count(n, 1)
+}
+
+func (r *CBORReader) ReadMapHeader() (int, error) {
+	n, err := r.expect(cborMap, "a map")
+	if err != nil {
+		return 0, err
+	}
+	return r.count(n, 2)
+}
+
+func (r *CBORReader) Skip() error {
+	// The values left to skip, which arrays, maps and tags add to.
+	for left := 1; left > 0; left-- {
+		major, v, err := r.head()
+		if err != nil {
+			return err
+		}
+		switch major {
+		case cborBytes, cborText:
+			_, err = r.next(v)
+		case cborArray:
+			var n int
+			n, err = r.count(v, 1)
+			left += n
+		case cborMap:
+			var n int
+			n, err = r.count(v, 2)
+			left += 2 * n
+		case cborTag:
+			left++
+		case cborSimple:
+			switch v {
+			case 24:
+				_, err = r.next(1)
+			case 25, 26, 27:
+				_, err = r.next(1 << (v - 24))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	enchex "encoding/hex"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCBORWriter(t *testing.T) {
+	// The examples of appendix A of RFC 8949.
+	tests := []struct {
+		write    func(w *CBORWriter)
+		expected string
+	}{
+		{func(w *CBORWriter) { w.WriteInt(0) }, "00"},
+		{func(w *CBORWriter) { w.WriteInt(23) }, "17"},
+		{func(w *CBORWriter) { w.WriteInt(24) }, "1818"},
+		{func(w *CBORWriter) { w.WriteInt(1000) }, "1903e8"},
+		{func(w *CBORWriter) { w.WriteInt(1000000) }, "1a000f4240"},
+		{func(w *CBORWriter) { w.WriteInt(1000000000000) }, "1b000000e8d4a51000"},
+		{func(w *CBORWriter) { w.WriteUint(math.MaxUint64) }, "1bffffffffffffffff"},
+		{func(w *CBORWriter) { w.WriteInt(-1) }, "20"},
+		{func(w *CBORWriter) { w.WriteInt(-100) }, "3863"},
+		{func(w *CBORWriter) { w.WriteInt(-1000) }, "3903e7"},
+		{func(w *CBORWriter) { w.WriteInt(math.MinInt64) }, "3b7fffffffffffffff"},
+		{func(w *CBORWriter) { w.WriteFloat64(1.1) }, "fb3ff199999999999a"},
+		{func(w *CBORWriter) { w.WriteFloat32(100000) }, "fa47c35000"},
+		{func(w *CBORWriter) { w.WriteBool(false) }, "f4"},
+		{func(w *CBORWriter) { w.WriteBool(true) }, "f5"},
+		{func(w *CBORWriter) { w.WriteNil() }, "f6"},
+		{func(w *CBORWriter) { w.WriteTime(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)) }, "c074323031332d30332d32315432303a30343a30305a"},
+		{func(w *CBORWriter) { w.WriteBytes([]byte{1, 2, 3, 4}) }, "4401020304"},
+		{func(w *CBORWriter) { w.WriteString("IETF") }, "6449455446"},
+		{func(w *CBORWriter) { w.WriteString("\u00fc") }, "62c3bc"},
+		{func(w *CBORWriter) { w.WriteArrayHeader(3) }, "83"},
+		{func(w *CBORWriter) { w.WriteArrayHeader(25) }, "9819"},
+		{func(w *CBORWriter) { w.WriteMapHeader(0) }, "a0"},
+		{func(w *CBORWriter) { w.WriteMapHeader(2) }, "a2"},
+	}
+
+	for _, test := range tests {
+		w := NewCBORWriter(nil)
+		test.write(w)
+		if out := enchex.EncodeToString(w.Bytes()); out != test.expected {
+			t.Errorf("Expected: %s\nGot: %s", test.expected, out)
+		}
+	}
+}
+
+func TestCBORReadFloat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"f93c00", 1},
+		{"f97bff", 65504},
+		{"f90001", 5.960464477539063e-8},
+		{"f9c400", -4},
+		{"f97c00", math.Inf(1)},
+		{"fa47c35000", 100000},
+		{"fb3ff199999999999a", 1.1},
+		{"3863", -100},
+		{"1903e8", 1000},
+	}
+
+	for _, test := range tests {
+		v, err := NewCBORReader(mustHex(t, test.input)).ReadFloat()
+		if err != nil || v != test.expected {
+			t.Errorf("%s: got %v, %v, expected %v", test.input, v, err, test.expected)
+		}
+	}
+}
+
+func TestCBORReadTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"c074323031332d30332d32315432303a30343a30305a", time.Unix(1363896240, 0)},
+		{"c11a514b67b0", time.Unix(1363896240, 0)},
+		{"c1fb41d452d9ec200000", time.Unix(1363896240, 5e8)},
+	}
+
+	for _, test := range tests {
+		v, err := NewCBORReader(mustHex(t, test.input)).ReadTime()
+		if err != nil || !v.Equal(test.expected) {
+			t.Errorf("%s: got %v, %v, expected %v", test.input, v, err, test.expected)
+		}
+	}
+}
+
+func TestCBORReader(t *testing.T) {
+	r := NewCBORReader(mustHex(t, "3b7fffffffffffffff1bffffffffffffffff6449455446f7"))
+	if v, err := r.ReadInt(); v != math.MinInt64 || err != nil {
+		t.Errorf("ReadInt: %v, %v", v, err)
+	}
+	if v, err := r.ReadUint(); v != math.MaxUint64 || err != nil {
+		t.Errorf("ReadUint: %v, %v", v, err)
+	}
+	if v, err := r.ReadKey(); string(v) != "IETF" || err != nil {
+		t.Errorf("ReadKey: %q, %v", v, err)
+	}
+	// undefined is read as nil.
+	if !r.ReadNil() {
+		t.Errorf("ReadNil: false")
+	}
+	if err := r.Finish(); err != nil {
+		t.Errorf("Finish: %v", err)
+	}
+}
+
+func TestCBORSkip(t *testing.T) {
+	// {"a": [1, -1, h'01', 1(0), 1.5, simple(255), null], "b": {}}, then true
+	r := NewCBORReader(mustHex(t, "a2616187"+"01"+"20"+"4101"+"c100"+"f93e00"+"f8ff"+"f6"+"6162a0"+"f5"))
+	if err := r.Skip(); err != nil {
+		t.Fatalf("Skip: %v", err)
+	}
+	if v, err := r.ReadBool(); !v || err != nil {
+		t.Errorf("ReadBool after Skip: %v, %v", v, err)
+	}
+}
+
+func TestCBORErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		read  func(r *CBORReader) error
+	}{
+		{"", func(r *CBORReader) error { _, err := r.ReadInt(); return err }},
+		{"1b00", func(r *CBORReader) error { _, err := r.ReadInt(); return err }},
+		{"1bffffffffffffffff", func(r *CBORReader) error { _, err := r.ReadInt(); return err }},
+		{"3bffffffffffffffff", func(r *CBORReader) error { _, err := r.ReadInt(); return err }},
+		{"20", func(r *CBORReader) error { _, err := r.ReadUint(); return err }},
+		{"6161", func(r *CBORReader) error { _, err := r.ReadInt(); return err }},
+		{"4161", func(r *CBORReader) error { _, err := r.ReadString(); return err }},
+		{"626161", func(r *CBORReader) error { _, err := r.ReadBytes(); return err }},
+		{"9f01ff", func(r *CBORReader) error { _, err := r.ReadArrayHeader(); return err }},
+		{"9affffffff", func(r *CBORReader) error { _, err := r.ReadArrayHeader(); return err }},
+		{"a2", func(r *CBORReader) error { _, err := r.ReadMapHeader(); return err }},
+		{"c26161", func(r *CBORReader) error { _, err := r.ReadTime(); return err }},
+		{"c06161", func(r *CBORReader) error { _, err := r.ReadTime(); return err }},
+		{"1c", func(r *CBORReader) error { return r.Skip() }},
+		{"8201", func(r *CBORReader) error { return r.Skip() }},
+		{"c1", func(r *CBORReader) error { return r.Skip() }},
+		{"0101", func(r *CBORReader) error { r.Skip(); return r.Finish() }},
+	}
+
+	for _, test := range tests {
+		err := test.read(NewCBORReader(mustHex(t, test.input)))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DateFormat is the layout of the date part of times written with
+	// ffjson:"split=...".
+	DateFormat = "2006-01-02"
+	// TimeFormat is the layout of the time part, with the fractional
+	// seconds only when they aren't zero.
+	TimeFormat = "15:04:05.999999999Z07:00"
+)
+
+// JoinDateTime returns the time with the date part date and the time
+// part clock, either of which may be nil when it was missing. A missing
+// time part is midnight, and a time part without a zone is UTC.
+func JoinDateTime(date, clock *string) (time.Time, error) {
+	if date == nil {
+		return time.Time{}, errors.New("ffjson: time part without a date part")
+	}
+	d, err := time.Parse(DateFormat, *date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ffjson: invalid date %q, wanted YYYY-MM-DD", *date)
+	}
+	if clock == nil {
+		return d, nil
+	}
+
+	// Parse accepts fractional seconds after the seconds, even if the
+	// layout doesn't have them.
+	for _, layout := range []string{"15:04:05Z07:00", "15:04:05"} {
+		t, err := time.Parse(DateFormat+"T"+layout, *date+"T"+*clock)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("ffjson: invalid time %q, wanted hh:mm:ss with optional fractional seconds and zone", *clock)
+}
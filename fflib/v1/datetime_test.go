@@ -0,0 +1,71 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJoinDateTime(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		date     *string
+		clock    *string
+		expected string
+	}{
+		{str("2024-02-29"), nil, "2024-02-29T00:00:00Z"},
+		{str("2024-02-29"), str("13:04:05"), "2024-02-29T13:04:05Z"},
+		{str("2024-02-29"), str("13:04:05.25Z"), "2024-02-29T13:04:05.25Z"},
+		{str("2024-02-29"), str("13:04:05+02:00"), "2024-02-29T13:04:05+02:00"},
+		{str("2024-02-29"), str("13:04:05.000001-07:30"), "2024-02-29T13:04:05.000001-07:30"},
+	}
+
+	for _, test := range tests {
+		out, err := JoinDateTime(test.date, test.clock)
+		if err != nil {
+			t.Errorf("JoinDateTime(%v, %v): %v", test.date, test.clock, err)
+			continue
+		}
+		if got := out.Format(time.RFC3339Nano); got != test.expected {
+			t.Errorf("JoinDateTime(%v, %v)\nExpected: %s\nGot: %s", test.date, test.clock, test.expected, got)
+		}
+	}
+}
+
+func TestJoinDateTimeInvalid(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		date  *string
+		clock *string
+	}{
+		{nil, str("13:04:05")},
+		{str("2024-02-30"), nil},
+		{str("2024-2-3"), nil},
+		{str("2024-02-29"), str("13:04")},
+		{str("2024-02-29"), str("25:00:00")},
+		{str("2024-02-29"), str("13:04:05 UTC")},
+	}
+
+	for _, test := range tests {
+		_, err := JoinDateTime(test.date, test.clock)
+		if err == nil {
+			t.Errorf("JoinDateTime(%v, %v): expected an error", test.date, test.clock)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Envelope describes a fixed JSON object wrapped around the JSON of a type.
+// Key is the member holding the payload, and Fields are constant sibling
+// members, as canonical JSON values (see Canonicalize).
+type Envelope struct {
+	Key    string
+	Fields map[string][]byte
+}
+
+// Unmarshal reads an envelope object from fs, validating that all constant
+// fields are present with their expected value. The payload function is
+// called with the lexer positioned just after the opening brace of the
+// payload object. Unknown members are skipped, and a null payload leaves
+// the destination untouched.
+func (e *Envelope) Unmarshal(fs *FFLexer, state FFParseState, payload func() error) error {
+	var tok FFTok
+	if state == FFParse_map_start {
+		tok = fs.Scan()
+		if tok != FFTok_left_bracket {
+			return envelopeTokError(fs, tok, FFTok_left_bracket)
+		}
+	}
+
+	seen := make(map[string]bool, len(e.Fields)+1)
+	tok = fs.Scan()
+	for tok != FFTok_right_bracket {
+		if tok != FFTok_string {
+			return envelopeTokError(fs, tok, FFTok_string)
+		}
+		key := fs.Output.String()
+
+		tok = fs.Scan()
+		if tok != FFTok_colon {
+			return envelopeTokError(fs, tok, FFTok_colon)
+		}
+
+		tok = fs.Scan()
+		if tok == FFTok_error || tok == FFTok_eof {
+			return envelopeTokError(fs, tok, FFTok_left_bracket)
+		}
+
+		if key == e.Key {
+			seen[key] = true
+			if tok == FFTok_left_bracket {
+				err := payload()
+				if err != nil {
+					return err
+				}
+			} else if tok != FFTok_null {
+				return envelopeTokError(fs, tok, FFTok_left_bracket)
+			}
+		} else if want, ok := e.Fields[key]; ok {
+			seen[key] = true
+			v, err := fs.CaptureField(tok)
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+			got, err := Canonicalize(v)
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+			if string(got) != string(want) {
+				return fs.WrapErr(fmt.Errorf("ffjson: envelope field %q must be %s, but got %s", key, want, got))
+			}
+		} else {
+			err := fs.SkipField(tok)
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+		}
+
+		tok = fs.Scan()
+		if tok == FFTok_comma {
+			tok = fs.Scan()
+			if tok != FFTok_string {
+				return envelopeTokError(fs, tok, FFTok_string)
+			}
+		} else if tok != FFTok_right_bracket {
+			return envelopeTokError(fs, tok, FFTok_comma)
+		}
+	}
+
+	if len(seen) != len(e.Fields)+1 {
+		missing := []string{}
+		if !seen[e.Key] {
+			missing = append(missing, e.Key)
+		}
+		for k := range e.Fields {
+			if !seen[k] {
+				missing = append(missing, k)
+			}
+		}
+		sort.Strings(missing)
+		return fs.WrapErr(fmt.Errorf("ffjson: envelope is missing fields: %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// Unwrap reads a root object from fs that may be wrapped in an object
+// whose only member is e.Key, holding an object, as sent by producers
+// naming the type of the payload. The key is compared exactly. decode is
+// called with a lexer positioned just after the opening brace of the
+// inner object if it is wrapped, and of the object itself otherwise. Any
+// other object, including one with e.Key and other members, isn't
+// unwrapped. e.Fields isn't used. Objects that aren't at the root, with
+// state FFParse_want_key, are passed to decode as they are.
+func (e *Envelope) Unwrap(fs *FFLexer, state FFParseState, decode func(fs *FFLexer) error) error {
+	if state != FFParse_map_start {
+		return decode(fs)
+	}
+	tok := fs.Scan()
+	if tok != FFTok_left_bracket {
+		return envelopeTokError(fs, tok, FFTok_left_bracket)
+	}
+	// The object is captured so it can be decoded as it is if it turns
+	// out not to be wrapped.
+	v, err := fs.CaptureField(tok)
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	obj := append([]byte(nil), v...)
+
+	inner := e.wrapped(obj)
+	if inner == nil {
+		inner = obj
+	}
+	ifs := NewFFLexer(inner)
+	ifs.SetOptions(fs.Options())
+	ifs.Scan()
+	return decode(ifs)
+}
+
+// wrapped returns the inner object of obj if its only member is e.Key
+// and holds an object, or nil.
+func (e *Envelope) wrapped(obj []byte) []byte {
+	fs := NewFFLexer(obj)
+	fs.Scan()
+	if fs.Scan() != FFTok_string || fs.Output.String() != e.Key || fs.Scan() != FFTok_colon {
+		return nil
+	}
+	if fs.Scan() != FFTok_left_bracket {
+		return nil
+	}
+	v, err := fs.CaptureField(FFTok_left_bracket)
+	if err != nil {
+		return nil
+	}
+	inner := append([]byte(nil), v...)
+	if fs.Scan() != FFTok_right_bracket {
+		return nil
+	}
+	return inner
+}
+
+func envelopeTokError(fs *FFLexer, tok FFTok, wanted FFTok) error {
+	if tok == FFTok_error {
+		return lexerTokError(fs)
+	}
+	if tok == FFTok_eof {
+		return fs.WrapErr(errors.New("ffjson: unexpected EOF"))
+	}
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v", wanted, tok))
+}
@@ -0,0 +1,50 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EpochCount returns the number of units from epoch, in Unix seconds, to
+// t, where perSecond units make a second. Fractions of a unit are
+// rounded down.
+func EpochCount(t time.Time, epoch int64, perSecond int64) (int64, error) {
+	secs := t.Unix() - epoch
+	if secs > math.MaxInt64/perSecond-1 || secs < math.MinInt64/perSecond {
+		return 0, fmt.Errorf("ffjson: %v is out of range of the epoch", t)
+	}
+	return secs*perSecond + int64(t.Nanosecond())/(1e9/perSecond), nil
+}
+
+// EpochTime returns the UTC time n units after epoch, in Unix seconds,
+// where perSecond units make a second.
+func EpochTime(n int64, epoch int64, perSecond int64) (time.Time, error) {
+	secs := n / perSecond
+	rem := n % perSecond
+	if rem < 0 {
+		rem += perSecond
+		secs--
+	}
+	if (epoch > 0 && secs > math.MaxInt64-epoch) || (epoch < 0 && secs < math.MinInt64-epoch) {
+		return time.Time{}, fmt.Errorf("ffjson: %d is out of range of the epoch", n)
+	}
+	return time.Unix(epoch+secs, rem*(1e9/perSecond)).UTC(), nil
+}
@@ -0,0 +1,76 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEpochCount(t *testing.T) {
+	y2k := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	tests := []struct {
+		t         time.Time
+		epoch     int64
+		perSecond int64
+		expected  int64
+	}{
+		{time.Date(2000, 1, 1, 0, 1, 0, 0, time.UTC), y2k, 1, 60},
+		{time.Date(2000, 1, 1, 0, 0, 1, 500000000, time.UTC), y2k, 1000, 1500},
+		{time.Date(1999, 12, 31, 23, 59, 59, 999999999, time.UTC), y2k, 1, -1},
+		{time.Date(1999, 12, 31, 23, 59, 59, 999999999, time.UTC), y2k, 1e6, -1},
+		{time.Date(1970, 1, 1, 0, 0, 0, 1, time.FixedZone("", 3600)), 0, 1e9, -3600e9 + 1},
+		{time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), 1e6, 0},
+	}
+
+	for _, test := range tests {
+		n, err := EpochCount(test.t, test.epoch, test.perSecond)
+		if err != nil {
+			t.Errorf("EpochCount(%v, %d, %d): %v", test.t, test.epoch, test.perSecond, err)
+			continue
+		}
+		if n != test.expected {
+			t.Errorf("EpochCount(%v, %d, %d): expected %d, got %d", test.t, test.epoch, test.perSecond, test.expected, n)
+		}
+
+		back, err := EpochTime(n, test.epoch, test.perSecond)
+		if err != nil {
+			t.Errorf("EpochTime(%d, %d, %d): %v", n, test.epoch, test.perSecond, err)
+			continue
+		}
+		if back.Location() != time.UTC || back.After(test.t) || test.t.Sub(back) >= time.Second/time.Duration(test.perSecond) {
+			t.Errorf("EpochTime(%d, %d, %d): got %v, expected %v rounded down", n, test.epoch, test.perSecond, back, test.t)
+		}
+	}
+}
+
+func TestEpochRange(t *testing.T) {
+	_, err := EpochCount(time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), 0, 1e9)
+	if err == nil {
+		t.Errorf("EpochCount: expected an error for a time out of range of nanoseconds")
+	}
+	_, err = EpochTime(math.MaxInt64, 1, 1)
+	if err == nil {
+		t.Errorf("EpochTime: expected an error for a count out of range")
+	}
+	_, err = EpochTime(math.MinInt64, -1, 1)
+	if err == nil {
+		t.Errorf("EpochTime: expected an error for a count out of range")
+	}
+}
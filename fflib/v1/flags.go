@@ -0,0 +1,94 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// WriteFlags writes the names of the bits set in v as a JSON array, in
+// the order of the bits. names[i] is the name of bit i, an empty name
+// leaves the bit unnamed. Set bits without a name result in an error,
+// unless ignoreUnknown is set.
+func WriteFlags(buf EncodingBuffer, v uint64, names []string, ignoreUnknown bool) error {
+	buf.WriteByte('[')
+	first := true
+	for i := uint(0); v != 0 && i < 64; i++ {
+		if v&(1<<i) == 0 {
+			continue
+		}
+		v &^= 1 << i
+		if int(i) >= len(names) || names[i] == "" {
+			if ignoreUnknown {
+				continue
+			}
+			return fmt.Errorf("ffjson: flag bit %d has no name", i)
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		WriteJsonString(buf, names[i])
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// ParseFlags decodes a JSON array of flag names into a bitmask, after its
+// opening bracket has been read by fs. Unknown names result in an error,
+// unless ignoreUnknown is set.
+func ParseFlags(fs *FFLexer, names []string, ignoreUnknown bool) (uint64, error) {
+	var v uint64
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		return 0, nil
+	}
+	for {
+		if tok == FFTok_error {
+			return 0, lexerTokError(fs)
+		}
+		if tok != FFTok_string {
+			return 0, fs.WrapErr(fmt.Errorf("ffjson: wanted flag name, but got token: %v", tok))
+		}
+
+		name := fs.Output.Bytes()
+		found := false
+		for i, n := range names {
+			if n != "" && n == string(name) {
+				v |= 1 << uint(i)
+				found = true
+				break
+			}
+		}
+		if !found && !ignoreUnknown {
+			return 0, fs.WrapErr(fmt.Errorf("ffjson: unknown flag %q", name))
+		}
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_comma:
+			tok = fs.Scan()
+		case FFTok_right_brace:
+			return v, nil
+		case FFTok_error, FFTok_eof:
+			return 0, lexerTokError(fs)
+		default:
+			return 0, fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of array, but got token: %v", tok))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+var flagNames = []string{"read", "write", "", "exec"}
+
+func TestWriteFlags(t *testing.T) {
+	for v, expected := range map[uint64]string{
+		0:   `[]`,
+		1:   `["read"]`,
+		0xb: `["read","write","exec"]`,
+		0x9: `["read","exec"]`,
+	} {
+		var buf Buffer
+		err := WriteFlags(&buf, v, flagNames, false)
+		if err != nil {
+			t.Fatalf("WriteFlags(%x): %v", v, err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("WriteFlags(%x): Expected: %v\nGot: %v", v, expected, buf.String())
+		}
+	}
+
+	for _, v := range []uint64{0x4, 0x10, 1 << 63} {
+		var buf Buffer
+		err := WriteFlags(&buf, v|1, flagNames, false)
+		if err == nil {
+			t.Fatalf("WriteFlags(%x): expected error", v)
+		}
+		buf.Reset()
+		err = WriteFlags(&buf, v|1, flagNames, true)
+		if err != nil || buf.String() != `["read"]` {
+			t.Fatalf("WriteFlags(%x) ignoring unknown: %v %v", v, err, buf.String())
+		}
+	}
+}
+
+func tParseFlags(t *testing.T, input string, ignoreUnknown bool) (uint64, error) {
+	fs := NewFFLexer([]byte(input))
+	tok := fs.Scan()
+	if tok != FFTok_left_brace {
+		t.Fatalf("ParseFlags(%s): unexpected token %v", input, tok)
+	}
+	return ParseFlags(fs, flagNames, ignoreUnknown)
+}
+
+func TestParseFlags(t *testing.T) {
+	for input, expected := range map[string]uint64{
+		`[]`:                         0,
+		`["read"]`:                   1,
+		`[ "exec" , "read" ]`:        0x9,
+		`["read","read","write"]`:    0x3,
+		`["write","exec","unknown"]`: 0xa,
+	} {
+		v, err := tParseFlags(t, input, true)
+		if err != nil {
+			t.Fatalf("ParseFlags(%s): %v", input, err)
+		}
+		if v != expected {
+			t.Fatalf("ParseFlags(%s): Expected: %x\nGot: %x", input, expected, v)
+		}
+	}
+
+	for _, input := range []string{`["unknown"]`, `[""]`, `["read",]`, `["read" "exec"]`, `[1]`, `["read"`} {
+		_, err := tParseFlags(t, input, false)
+		if err == nil {
+			t.Fatalf("ParseFlags(%s): expected error", input)
+		}
+	}
+}
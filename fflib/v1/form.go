@@ -0,0 +1,32 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"strconv"
+)
+
+// ParseFormBool parses a boolean form value. In addition to the values
+// accepted by strconv.ParseBool, "on" is true, which is what browsers send
+// for a checked checkbox without a value attribute.
+func ParseFormBool(s string) (bool, error) {
+	if s == "on" {
+		return true, nil
+	}
+	return strconv.ParseBool(s)
+}
@@ -0,0 +1,48 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"sync/atomic"
+)
+
+// Gate selects between the ffjson encoders and encoding/json. The zero
+// Gate selects ffjson. It can be switched while encoding from other
+// goroutines.
+type Gate struct {
+	stdlib int32
+}
+
+// SetStdlib selects encoding/json if stdlib is set, ffjson otherwise.
+func (g *Gate) SetStdlib(stdlib bool) {
+	var v int32
+	if stdlib {
+		v = 1
+	}
+	atomic.StoreInt32(&g.stdlib, v)
+}
+
+// Stdlib returns whether encoding/json is selected.
+func (g *Gate) Stdlib() bool {
+	return atomic.LoadInt32(&g.stdlib) != 0
+}
+
+// MarshalGate is checked by the MarshalJSON methods generated with
+// -gate, which call json.Marshal instead of the ffjson encoder while it
+// selects encoding/json.
+var MarshalGate Gate
@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	var g Gate
+	if g.Stdlib() {
+		t.Fatalf("the zero Gate selects encoding/json")
+	}
+	g.SetStdlib(true)
+	if !g.Stdlib() {
+		t.Fatalf("SetStdlib(true) didn't select encoding/json")
+	}
+	g.SetStdlib(false)
+	if g.Stdlib() {
+		t.Fatalf("SetStdlib(false) didn't select ffjson")
+	}
+}
@@ -0,0 +1,106 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// WriteGroupedInt writes the base 10 form of v to buf, with sep between
+// groups of three digits, as in 1,234,567.
+func WriteGroupedInt(buf EncodingBuffer, v int64, sep byte) {
+	var b [maxIntLen]byte
+	writeGrouped(buf, strconv.AppendInt(b[:0], v, 10), sep, '.')
+}
+
+// WriteGroupedUint writes v to buf like WriteGroupedInt.
+func WriteGroupedUint(buf EncodingBuffer, v uint64, sep byte) {
+	var b [maxIntLen]byte
+	writeGrouped(buf, strconv.AppendUint(b[:0], v, 10), sep, '.')
+}
+
+// WriteGroupedFloat writes f, which is a float32 if bitSize is 32, to buf
+// without an exponent, with sep between the groups of three digits of its
+// integer part and point before its fraction, as in 1,234.5.
+func WriteGroupedFloat(buf EncodingBuffer, f float64, bitSize int, sep byte, point byte) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("ffjson: can't write %v with grouped digits", f)
+	}
+	var b [maxFloatLen]byte
+	writeGrouped(buf, strconv.AppendFloat(b[:0], f, 'f', -1, bitSize), sep, point)
+	return nil
+}
+
+// writeGrouped writes the number num, formatted by strconv, grouping the
+// digits of its integer part.
+func writeGrouped(buf EncodingBuffer, num []byte, sep byte, point byte) {
+	if num[0] == '-' {
+		buf.WriteByte('-')
+		num = num[1:]
+	}
+	digits := len(num)
+	for i, c := range num {
+		if c == '.' {
+			digits = i
+			break
+		}
+	}
+	for i, c := range num[:digits] {
+		if i != 0 && (digits-i)%3 == 0 {
+			buf.WriteByte(sep)
+		}
+		buf.WriteByte(c)
+	}
+	if digits < len(num) {
+		buf.WriteByte(point)
+		buf.Write(num[digits+1:])
+	}
+}
+
+// Ungroup returns the number s, written with sep between groups of
+// digits and point before its fraction, in the form parsed by ParseInt
+// and ParseFloat. Separators are only accepted between digits of the
+// integer part, and their positions aren't otherwise checked.
+func Ungroup(s []byte, sep byte, point byte) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	fraction := false
+	for i, c := range s {
+		switch {
+		case c == sep:
+			if fraction || i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1]) {
+				return nil, errors.New("ffjson: misplaced digit separator in " + strconv.Quote(string(s)))
+			}
+		case c == point:
+			fraction = true
+			out = append(out, '.')
+		case c == '.':
+			// A '.' that is neither the separator nor the point.
+			return nil, errors.New("ffjson: invalid grouped number " + strconv.Quote(string(s)))
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,91 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWriteGrouped(t *testing.T) {
+	tests := []struct {
+		write    func(buf *Buffer)
+		expected string
+	}{
+		{func(buf *Buffer) { WriteGroupedInt(buf, 0, ',') }, "0"},
+		{func(buf *Buffer) { WriteGroupedInt(buf, 999, ',') }, "999"},
+		{func(buf *Buffer) { WriteGroupedInt(buf, 1234567, ',') }, "1,234,567"},
+		{func(buf *Buffer) { WriteGroupedInt(buf, -123456, '.') }, "-123.456"},
+		{func(buf *Buffer) { WriteGroupedInt(buf, math.MinInt64, ',') }, "-9,223,372,036,854,775,808"},
+		{func(buf *Buffer) { WriteGroupedUint(buf, math.MaxUint64, ' ') }, "18 446 744 073 709 551 615"},
+		{func(buf *Buffer) { WriteGroupedFloat(buf, 1234.5, 64, ',', '.') }, "1,234.5"},
+		{func(buf *Buffer) { WriteGroupedFloat(buf, -1234567.25, 64, '.', ',') }, "-1.234.567,25"},
+		{func(buf *Buffer) { WriteGroupedFloat(buf, 1e21, 64, ',', '.') }, "1,000,000,000,000,000,000,000"},
+		{func(buf *Buffer) { WriteGroupedFloat(buf, 0.125, 32, ',', '.') }, "0.125"},
+	}
+
+	for i, test := range tests {
+		var buf Buffer
+		test.write(&buf)
+		if buf.String() != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, buf.String())
+		}
+	}
+
+	var buf Buffer
+	if err := WriteGroupedFloat(&buf, math.NaN(), 64, ',', '.'); err == nil {
+		t.Errorf("WriteGroupedFloat(NaN): expected an error")
+	}
+}
+
+func TestUngroup(t *testing.T) {
+	tests := []struct {
+		in       string
+		sep      byte
+		point    byte
+		expected string
+	}{
+		{"1,234,567", ',', '.', "1234567"},
+		{"-1.234,5", '.', ',', "-1234.5"},
+		{"12 34", ' ', '.', "1234"},
+		{"1234", ',', '.', "1234"},
+		{"1,234.000,1", ',', '.', ""},
+		{",123", ',', '.', ""},
+		{"123,", ',', '.', ""},
+		{"1,,234", ',', '.', ""},
+		{"-,123", ',', '.', ""},
+		{"1.234.5", ' ', ',', ""},
+	}
+
+	for _, test := range tests {
+		out, err := Ungroup([]byte(test.in), test.sep, test.point)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("Ungroup(%q): expected an error, got %q", test.in, out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Ungroup(%q): %v", test.in, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("Ungroup(%q): expected %q, got %q", test.in, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Error codes defined by the JSON-RPC 2.0 specification.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+)
+
+// JSONRPCResult returns result wrapped in a JSON-RPC 2.0 response, as in
+// {"jsonrpc":"2.0","result":<result>,"id":<id>}. result must be valid JSON;
+// see WriteJSONRPCID for the values id may have.
+func JSONRPCResult(id interface{}, result []byte) ([]byte, error) {
+	var buf Buffer
+	buf.WriteString(`{"jsonrpc":"2.0","result":`)
+	buf.Write(result)
+	buf.WriteString(`,"id":`)
+	err := WriteJSONRPCID(&buf, id)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// JSONRPCError returns a JSON-RPC 2.0 error response, as in
+// {"jsonrpc":"2.0","error":{"code":<code>,"message":<message>,"data":<data>},"id":<id>}.
+// data is left out when it is nil, and must be valid JSON otherwise. The
+// id of requests whose id couldn't be read is nil, written as null.
+func JSONRPCError(id interface{}, code int, message string, data []byte) ([]byte, error) {
+	var buf Buffer
+	buf.WriteString(`{"jsonrpc":"2.0","error":{"code":`)
+	buf.WriteString(strconv.Itoa(code))
+	buf.WriteString(`,"message":`)
+	WriteJsonString(&buf, message)
+	if data != nil {
+		buf.WriteString(`,"data":`)
+		buf.Write(data)
+	}
+	buf.WriteString(`},"id":`)
+	err := WriteJSONRPCID(&buf, id)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// WriteJSONRPCID writes the id of a JSON-RPC 2.0 response, which is the id
+// of the request it answers: a string, a number or null.
+//
+//   - nil is written as null,
+//   - strings are written as JSON strings,
+//   - integers are written as they are,
+//   - float64 values are written as in canonical json (see
+//     Canonicalize), so a float without a fractional part is written as
+//     an integer, and an integer id decoded into an interface{} by
+//     encoding/json is written back as it was sent,
+//   - json.Number and json.RawMessage are written as they are, after
+//     checking they hold a number, or for json.RawMessage also a string or
+//     null.
+//
+// Other types, NaN and infinities return an error.
+func WriteJSONRPCID(buf EncodingBuffer, id interface{}) error {
+	switch v := id.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		WriteJsonString(buf, v)
+	case int:
+		FormatBits2(buf, uint64(v), 10, v < 0)
+	case int8:
+		FormatBits2(buf, uint64(v), 10, v < 0)
+	case int16:
+		FormatBits2(buf, uint64(v), 10, v < 0)
+	case int32:
+		FormatBits2(buf, uint64(v), 10, v < 0)
+	case int64:
+		FormatBits2(buf, uint64(v), 10, v < 0)
+	case uint:
+		FormatBits2(buf, uint64(v), 10, false)
+	case uint8:
+		FormatBits2(buf, uint64(v), 10, false)
+	case uint16:
+		FormatBits2(buf, uint64(v), 10, false)
+	case uint32:
+		FormatBits2(buf, uint64(v), 10, false)
+	case uint64:
+		FormatBits2(buf, v, 10, false)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("ffjson: invalid JSON-RPC id %v", v)
+		}
+		return AppendFloatCanonical(buf, v)
+	case json.Number:
+		return writeJSONRPCRawID(buf, []byte(v), false)
+	case json.RawMessage:
+		return writeJSONRPCRawID(buf, v, true)
+	default:
+		return fmt.Errorf("ffjson: JSON-RPC id must be a string, a number or nil, not %T", id)
+	}
+	return nil
+}
+
+// writeJSONRPCRawID writes an id given as JSON, which must be a number,
+// or also a string or null if any is set.
+func writeJSONRPCRawID(buf EncodingBuffer, id []byte, any bool) error {
+	id = bytes.TrimSpace(id)
+	// The lexer needs a delimiter after a number, as in Canonicalize.
+	fs := NewFFLexer(append(id[:len(id):len(id)], ' '))
+	tok := fs.Scan()
+	switch {
+	case tok == FFTok_integer || tok == FFTok_double:
+	case any && (tok == FFTok_string || tok == FFTok_null):
+	default:
+		return fmt.Errorf("ffjson: JSON-RPC id must be a string, a number or null, not %q", id)
+	}
+	if fs.Scan() != FFTok_eof {
+		return fmt.Errorf("ffjson: JSON-RPC id must be a string, a number or null, not %q", id)
+	}
+	buf.Write(id)
+	return nil
+}
@@ -0,0 +1,100 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestJSONRPCResult(t *testing.T) {
+	tests := []struct {
+		id       interface{}
+		expected string
+	}{
+		{nil, `{"jsonrpc":"2.0","result":{"a":1},"id":null}`},
+		{"req-1", `{"jsonrpc":"2.0","result":{"a":1},"id":"req-1"}`},
+		{`a"b`, `{"jsonrpc":"2.0","result":{"a":1},"id":"a\"b"}`},
+		{7, `{"jsonrpc":"2.0","result":{"a":1},"id":7}`},
+		{int64(-12), `{"jsonrpc":"2.0","result":{"a":1},"id":-12}`},
+		{uint64(math.MaxUint64), `{"jsonrpc":"2.0","result":{"a":1},"id":18446744073709551615}`},
+		{float64(12), `{"jsonrpc":"2.0","result":{"a":1},"id":12}`},
+		{float64(12345678), `{"jsonrpc":"2.0","result":{"a":1},"id":12345678}`},
+		{1.5, `{"jsonrpc":"2.0","result":{"a":1},"id":1.5}`},
+		{json.Number("1.0"), `{"jsonrpc":"2.0","result":{"a":1},"id":1.0}`},
+		{json.RawMessage(` "x" `), `{"jsonrpc":"2.0","result":{"a":1},"id":"x"}`},
+		{json.RawMessage(`null`), `{"jsonrpc":"2.0","result":{"a":1},"id":null}`},
+		{json.RawMessage(`-3`), `{"jsonrpc":"2.0","result":{"a":1},"id":-3}`},
+	}
+
+	for _, test := range tests {
+		out, err := JSONRPCResult(test.id, []byte(`{"a":1}`))
+		if err != nil {
+			t.Errorf("JSONRPCResult(%#v): %v", test.id, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("JSONRPCResult(%#v)\nExpected: %s\nGot: %s", test.id, test.expected, out)
+		}
+	}
+}
+
+func TestJSONRPCError(t *testing.T) {
+	out, err := JSONRPCError(1, JSONRPCInvalidParams, "bad <params>", []byte(`["x"]`))
+	if err != nil {
+		t.Fatalf("JSONRPCError: %v", err)
+	}
+	expected := `{"jsonrpc":"2.0","error":{"code":-32602,"message":"bad \u003cparams\u003e","data":["x"]},"id":1}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	out, err = JSONRPCError(nil, JSONRPCParseError, "parse error", nil)
+	if err != nil {
+		t.Fatalf("JSONRPCError: %v", err)
+	}
+	expected = `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+}
+
+func TestJSONRPCInvalidID(t *testing.T) {
+	for _, id := range []interface{}{
+		true,
+		float32(1),
+		math.NaN(),
+		math.Inf(1),
+		[]int{1},
+		json.Number("x"),
+		json.Number(`"1"`),
+		json.RawMessage(`{}`),
+		json.RawMessage(`1 2`),
+		json.RawMessage(``),
+	} {
+		_, err := JSONRPCResult(id, []byte(`{}`))
+		if err == nil {
+			t.Errorf("JSONRPCResult(%#v): expected an error", id)
+		}
+		_, err = JSONRPCError(id, JSONRPCInternalError, "", nil)
+		if err == nil {
+			t.Errorf("JSONRPCError(%#v): expected an error", id)
+		}
+	}
+}
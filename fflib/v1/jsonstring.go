@@ -39,7 +39,17 @@ type JsonStringWriter interface {
 }
 
 func WriteJsonString(buf JsonStringWriter, s string) {
-	WriteJson(buf, []byte(s))
+	// Strings without characters to escape, the common case, are written
+	// as they are, as converting them to a []byte allocates.
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b >= utf8.RuneSelf || !lt[b] {
+			WriteJson(buf, []byte(s))
+			return
+		}
+	}
+	buf.WriteByte('"')
+	buf.WriteString(s)
+	buf.WriteByte('"')
 }
 
 /**
@@ -47,6 +57,12 @@ func WriteJsonString(buf JsonStringWriter, s string) {
  */
 func WriteJson(buf JsonStringWriter, s []byte) {
 	buf.WriteByte('"')
+	writeJsonChars(buf, s)
+	buf.WriteByte('"')
+}
+
+// writeJsonChars writes s escaped as in a JSON string, without quotes.
+func writeJsonChars(buf JsonStringWriter, s []byte) {
 	start := 0
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
@@ -119,7 +135,6 @@ func WriteJson(buf JsonStringWriter, s []byte) {
 	if start < len(s) {
 		buf.Write(s[start:])
 	}
-	buf.WriteByte('"')
 }
 
 // UnquoteBytes will decode []byte containing json string to go string
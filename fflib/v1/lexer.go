@@ -101,6 +101,18 @@ type FFLexer struct {
 	Token    FFTok
 	Error    FFErr
 	BigError error
+	// UseNumber makes the generated decoders store numbers in interface{}
+	// values as json.Number instead of float64. Reset keeps it.
+	UseNumber bool
+	// DisallowUnknownFields, ExactCase, MaxDepth and MaxStringLength are
+	// the DecodeOptions of the same name, see SetOptions. Reset keeps
+	// them.
+	DisallowUnknownFields bool
+	ExactCase             bool
+	MaxDepth              int
+	MaxStringLength       int
+	// depth is the number of objects and arrays open.
+	depth int
 	// TODO: convert all of this to an interface
 	lastCurrentChar int
 	captureAll      bool
@@ -123,7 +135,10 @@ type LexerError struct {
 	offset int
 	line   int
 	char   int
+	path   string
 	err    error
+	// src is the reader of the lexer which located the error.
+	src *ffReader
 }
 
 // Reset the Lexer and add new input.
@@ -133,24 +148,74 @@ func (ffl *FFLexer) Reset(input []byte) {
 	ffl.BigError = nil
 	ffl.reader.Reset(input)
 	ffl.lastCurrentChar = 0
+	ffl.depth = 0
 	ffl.Output.Reset()
 }
 
 func (le *LexerError) Error() string {
+	if le.path != "" {
+		return fmt.Sprintf(`ffjson error: (%T)%s offset=%d line=%d char=%d path=%s`,
+			le.err, le.err.Error(),
+			le.offset, le.line, le.char, le.path)
+	}
 	return fmt.Sprintf(`ffjson error: (%T)%s offset=%d line=%d char=%d`,
 		le.err, le.err.Error(),
 		le.offset, le.line, le.char)
 }
 
+// Offset returns the byte offset in the input at which the error occurred.
+func (le *LexerError) Offset() int {
+	return le.offset
+}
+
+// Line returns the 1-based line of the error.
+func (le *LexerError) Line() int {
+	return le.line
+}
+
+// Column returns the 1-based column of the error in the line, counting
+// UTF-8 characters.
+func (le *LexerError) Column() int {
+	return le.char
+}
+
+// Path returns the path of the value being decoded when the error
+// occurred, like "items[3].owner.id": object members are joined with dots
+// and array elements are indexed from 0. It is empty for errors outside of
+// any object or array.
+func (le *LexerError) Path() string {
+	return le.path
+}
+
+// Unwrap returns the underlying error.
+func (le *LexerError) Unwrap() error {
+	return le.err
+}
+
+// WrapErr returns err located at the current position of the lexer.
+// Errors already located by the lexer are returned as they are. Errors
+// located by another lexer, decoding a value captured from this one, keep
+// their error and get the position of the value, with their path
+// appended to the path of the value.
 func (ffl *FFLexer) WrapErr(err error) error {
+	inner, ok := err.(*LexerError)
+	if ok && inner.src == ffl.reader {
+		return err
+	}
 	line, char := ffl.reader.PosWithLine()
-	// TOOD: calcualte lines/characters based on offset
-	return &LexerError{
+	le := &LexerError{
 		offset: ffl.reader.Pos(),
 		line:   line,
 		char:   char,
+		path:   ffl.reader.Path(),
 		err:    err,
+		src:    ffl.reader,
+	}
+	if ok {
+		le.path = joinPath(le.path, inner.path)
+		le.err = inner.err
 	}
+	return le
 }
 
 func (ffl *FFLexer) scanReadByte() (byte, error) {
@@ -266,6 +331,10 @@ func (ffl *FFLexer) lexString() FFTok {
 			ffl.BigError = err
 			return FFTok_error
 		}
+		if ffl.MaxStringLength > 0 && ffl.buf.Len() > ffl.MaxStringLength {
+			ffl.BigError = ErrMaxStringLength
+			return FFTok_error
+		}
 
 		WriteJson(ffl.Output, ffl.buf.Bytes())
 
@@ -277,11 +346,26 @@ func (ffl *FFLexer) lexString() FFTok {
 			ffl.BigError = err
 			return FFTok_error
 		}
+		if ffl.MaxStringLength > 0 && ffl.Output.Len() > ffl.MaxStringLength {
+			ffl.BigError = ErrMaxStringLength
+			return FFTok_error
+		}
 
 		return FFTok_string
 	}
 }
 
+// open counts an object or array starting, failing if it is nested
+// deeper than MaxDepth.
+func (ffl *FFLexer) open(tok FFTok) FFTok {
+	ffl.depth++
+	if ffl.MaxDepth > 0 && ffl.depth > ffl.MaxDepth {
+		ffl.BigError = ErrMaxDepth
+		return FFTok_error
+	}
+	return tok
+}
+
 func (ffl *FFLexer) lexNumber() FFTok {
 	var numRead int = 0
 	tok := FFTok_integer
@@ -406,25 +490,27 @@ func (ffl *FFLexer) Scan() FFTok {
 
 		switch c {
 		case '{':
-			tok = FFTok_left_bracket
+			tok = ffl.open(FFTok_left_bracket)
 			if ffl.captureAll {
 				ffl.Output.WriteByte('{')
 			}
 			goto lexed
 		case '}':
 			tok = FFTok_right_bracket
+			ffl.depth--
 			if ffl.captureAll {
 				ffl.Output.WriteByte('}')
 			}
 			goto lexed
 		case '[':
-			tok = FFTok_left_brace
+			tok = ffl.open(FFTok_left_brace)
 			if ffl.captureAll {
 				ffl.Output.WriteByte('[')
 			}
 			goto lexed
 		case ']':
 			tok = FFTok_right_brace
+			ffl.depth--
 			if ffl.captureAll {
 				ffl.Output.WriteByte(']')
 			}
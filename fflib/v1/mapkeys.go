@@ -0,0 +1,56 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+var sortMapKeys int32
+
+// SetSortMapKeys makes the generated encoders of all structs write the
+// keys of maps with string keys in sorted order if on is set, as the
+// encoders of structs with ffjson: sortedmaps always do. It can be
+// switched while encoding from other goroutines.
+func SetSortMapKeys(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&sortMapKeys, v)
+}
+
+// SortMapKeys returns whether the generated encoders sort the keys of
+// maps.
+func SortMapKeys() bool {
+	return atomic.LoadInt32(&sortMapKeys) != 0
+}
+
+// SortedKeys returns the indexes of keys in the order of the keys, the
+// order in which encoding/json writes maps.
+func SortedKeys(keys []string) []int {
+	out := make([]int, len(keys))
+	for i := range out {
+		out[i] = i
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return keys[out[i]] < keys[out[j]]
+	})
+	return out
+}
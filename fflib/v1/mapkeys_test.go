@@ -0,0 +1,56 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortedKeys(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want []int
+	}{
+		{nil, []int{}},
+		{[]string{"a"}, []int{0}},
+		// Like encoding/json, integer keys sort as text.
+		{[]string{"9", "10", "-1", "2"}, []int{2, 1, 3, 0}},
+		{[]string{"b", "a", "c"}, []int{1, 0, 2}},
+	}
+	for _, test := range tests {
+		got := SortedKeys(test.keys)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SortedKeys(%q) = %v, want %v", test.keys, got, test.want)
+		}
+	}
+}
+
+func TestSetSortMapKeys(t *testing.T) {
+	if SortMapKeys() {
+		t.Fatal("SortMapKeys is on by default")
+	}
+	SetSortMapKeys(true)
+	if !SortMapKeys() {
+		t.Error("SortMapKeys = false after SetSortMapKeys(true)")
+	}
+	SetSortMapKeys(false)
+	if SortMapKeys() {
+		t.Error("SortMapKeys = true after SetSortMapKeys(false)")
+	}
+}
@@ -0,0 +1,515 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"time"
+)
+
+// MsgpackWriter is a BinaryWriter of MessagePack, writing each value in
+// its shortest form.
+type MsgpackWriter struct {
+	buf []byte
+}
+
+// NewMsgpackWriter returns a MsgpackWriter appending to buf[:0].
+func NewMsgpackWriter(buf []byte) *MsgpackWriter {
+	return &MsgpackWriter{buf: buf[:0]}
+}
+
+// Bytes returns the MessagePack written.
+func (w *MsgpackWriter) Bytes() []byte {
+	return w.buf
+}
+
+func (w *MsgpackWriter) WriteNil() {
+	w.buf = append(w.buf, 0xc0)
+}
+
+func (w *MsgpackWriter) WriteBool(v bool) {
+	if v {
+		w.buf = append(w.buf, 0xc3)
+	} else {
+		w.buf = append(w.buf, 0xc2)
+	}
+}
+
+func (w *MsgpackWriter) WriteInt(v int64) {
+	switch {
+	case v >= 0:
+		w.WriteUint(uint64(v))
+	case v >= -32:
+		w.buf = append(w.buf, byte(v))
+	case v >= math.MinInt8:
+		w.buf = append(w.buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		w.buf = appendUint(append(w.buf, 0xd1), uint64(v), 2)
+	case v >= math.MinInt32:
+		w.buf = appendUint(append(w.buf, 0xd2), uint64(v), 4)
+	default:
+		w.buf = appendUint(append(w.buf, 0xd3), uint64(v), 8)
+	}
+}
+
+func (w *MsgpackWriter) WriteUint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		w.buf = append(w.buf, byte(v))
+	case v <= math.MaxUint8:
+		w.buf = append(w.buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, 0xcd), v, 2)
+	case v <= math.MaxUint32:
+		w.buf = appendUint(append(w.buf, 0xce), v, 4)
+	default:
+		w.buf = appendUint(append(w.buf, 0xcf), v, 8)
+	}
+}
+
+func (w *MsgpackWriter) WriteFloat32(v float32) {
+	w.buf = appendUint(append(w.buf, 0xca), uint64(math.Float32bits(v)), 4)
+}
+
+func (w *MsgpackWriter) WriteFloat64(v float64) {
+	w.buf = appendUint(append(w.buf, 0xcb), math.Float64bits(v), 8)
+}
+
+func (w *MsgpackWriter) WriteString(v string) {
+	n := uint64(len(v))
+	switch {
+	case n < 32:
+		w.buf = append(w.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		w.buf = append(w.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, 0xda), n, 2)
+	default:
+		w.buf = appendUint(append(w.buf, 0xdb), n, 4)
+	}
+	w.buf = append(w.buf, v...)
+}
+
+func (w *MsgpackWriter) WriteBytes(v []byte) {
+	n := uint64(len(v))
+	switch {
+	case n <= math.MaxUint8:
+		w.buf = append(w.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, 0xc5), n, 2)
+	default:
+		w.buf = appendUint(append(w.buf, 0xc6), n, 4)
+	}
+	w.buf = append(w.buf, v...)
+}
+
+// WriteTime writes v with the timestamp extension type -1, in the
+// shortest of its three forms. The location of v is lost.
+func (w *MsgpackWriter) WriteTime(v time.Time) {
+	sec, nsec := v.Unix(), uint64(v.Nanosecond())
+	if sec>>34 == 0 {
+		data := nsec<<34 | uint64(sec)
+		if data>>32 == 0 {
+			w.buf = appendUint(append(w.buf, 0xd6, 0xff), data, 4)
+		} else {
+			w.buf = appendUint(append(w.buf, 0xd7, 0xff), data, 8)
+		}
+		return
+	}
+	w.buf = appendUint(append(w.buf, 0xc7, 12, 0xff), nsec, 4)
+	w.buf = appendUint(w.buf, uint64(sec), 8)
+}
+
+func (w *MsgpackWriter) WriteArrayHeader(n int) {
+	switch {
+	case n < 16:
+		w.buf = append(w.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, 0xdc), uint64(n), 2)
+	default:
+		w.buf = appendUint(append(w.buf, 0xdd), uint64(n), 4)
+	}
+}
+
+func (w *MsgpackWriter) WriteMapHeader(n int) {
+	switch {
+	case n < 16:
+		w.buf = append(w.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		w.buf = appendUint(append(w.buf, 0xde), uint64(n), 2)
+	default:
+		w.buf = appendUint(append(w.buf, 0xdf), uint64(n), 4)
+	}
+}
+
+// MsgpackReader is a BinaryReader of MessagePack.
+type MsgpackReader struct {
+	binaryInput
+}
+
+// NewMsgpackReader returns a MsgpackReader of data.
+func NewMsgpackReader(data []byte) *MsgpackReader {
+	return &MsgpackReader{binaryInput{format: "msgpack", data: data}}
+}
+
+func (r *MsgpackReader) Kind() (BinaryKind, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b <= 0x7f, b >= 0xcc && b <= 0xcf:
+		return BinaryUint, nil
+	case b >= 0xe0, b >= 0xd0 && b <= 0xd3:
+		return BinaryInt, nil
+	case b <= 0x8f, b == 0xde, b == 0xdf:
+		return BinaryMap, nil
+	case b <= 0x9f, b == 0xdc, b == 0xdd:
+		return BinaryArray, nil
+	case b <= 0xbf, b >= 0xd9 && b <= 0xdb:
+		return BinaryString, nil
+	case b == 0xc0:
+		return BinaryNil, nil
+	case b == 0xc2, b == 0xc3:
+		return BinaryBool, nil
+	case b >= 0xc4 && b <= 0xc6:
+		return BinaryBytes, nil
+	case b == 0xca, b == 0xcb:
+		return BinaryFloat, nil
+	case b == 0xc1:
+		return 0, r.Errorf("invalid byte 0xc1")
+	}
+	start := r.off
+	typ, _, err := r.extHeader()
+	r.off = start
+	if err != nil {
+		return 0, err
+	}
+	if typ == -1 {
+		return BinaryTime, nil
+	}
+	return BinaryOther, nil
+}
+
+func (r *MsgpackReader) ReadNil() bool {
+	if r.off < len(r.data) && r.data[r.off] == 0xc0 {
+		r.off++
+		return true
+	}
+	return false
+}
+
+func (r *MsgpackReader) ReadBool() (bool, error) {
+	b, err := r.peek()
+	if err != nil {
+		return false, err
+	}
+	if b != 0xc2 && b != 0xc3 {
+		return false, r.Errorf("expected a boolean")
+	}
+	r.off++
+	return b == 0xc3, nil
+}
+
+// integer reads an integer as i, or as u when it has an unsigned format.
+func (r *MsgpackReader) integer() (i int64, u uint64, unsigned bool, err error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	switch {
+	case b <= 0x7f:
+		r.off++
+		return 0, uint64(b), true, nil
+	case b >= 0xe0:
+		r.off++
+		return int64(int8(b)), 0, false, nil
+	case b >= 0xcc && b <= 0xcf:
+		r.off++
+		u, err = r.uint(1 << (b - 0xcc))
+		return 0, u, true, err
+	case b >= 0xd0 && b <= 0xd3:
+		r.off++
+		size := 1 << (b - 0xd0)
+		u, err = r.uint(size)
+		// Sign extend.
+		shift := uint(64 - 8*size)
+		return int64(u<<shift) >> shift, 0, false, err
+	}
+	return 0, 0, false, r.Errorf("expected an integer")
+}
+
+func (r *MsgpackReader) ReadInt() (int64, error) {
+	start := r.off
+	i, u, unsigned, err := r.integer()
+	if err != nil || !unsigned {
+		return i, err
+	}
+	if u > math.MaxInt64 {
+		r.off = start
+		return 0, r.Errorf("integer %d overflows int64", u)
+	}
+	return int64(u), nil
+}
+
+func (r *MsgpackReader) ReadUint() (uint64, error) {
+	start := r.off
+	i, u, unsigned, err := r.integer()
+	if err != nil || unsigned {
+		return u, err
+	}
+	if i < 0 {
+		r.off = start
+		return 0, r.Errorf("negative integer %d for an unsigned integer", i)
+	}
+	return uint64(i), nil
+}
+
+func (r *MsgpackReader) ReadFloat() (float64, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 0xca:
+		r.off++
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		r.off++
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	}
+	i, u, unsigned, err := r.integer()
+	if unsigned {
+		return float64(u), err
+	}
+	return float64(i), err
+}
+
+func (r *MsgpackReader) ReadKey() ([]byte, error) {
+	b, err := r.peek()
+	if err != nil {
+		return nil, err
+	}
+	var n uint64
+	switch {
+	case b >= 0xa0 && b <= 0xbf:
+		r.off++
+		n = uint64(b & 0x1f)
+	case b >= 0xd9 && b <= 0xdb:
+		r.off++
+		n, err = r.uint(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, r.Errorf("expected a string")
+	}
+	return r.next(n)
+}
+
+func (r *MsgpackReader) ReadString() (string, error) {
+	b, err := r.ReadKey()
+	return string(b), err
+}
+
+func (r *MsgpackReader) ReadBytes() ([]byte, error) {
+	b, err := r.peek()
+	if err != nil {
+		return nil, err
+	}
+	if b < 0xc4 || b > 0xc6 {
+		// Strings were also used for binary data before bin was added.
+		s, err := r.ReadKey()
+		if err != nil {
+			return nil, r.Errorf("expected binary data")
+		}
+		return append([]byte(nil), s...), nil
+	}
+	r.off++
+	n, err := r.uint(1 << (b - 0xc4))
+	if err != nil {
+		return nil, err
+	}
+	v, err := r.next(n)
+	return append([]byte(nil), v...), err
+}
+
+// extHeader reads the header of an extension, returning its type and
+// the size of its data.
+func (r *MsgpackReader) extHeader() (int8, uint64, error) {
+	b, err := r.byte()
+	if err != nil {
+		return 0, 0, err
+	}
+	var size uint64
+	switch {
+	case b >= 0xd4 && b <= 0xd8:
+		size = 1 << (b - 0xd4)
+	case b >= 0xc7 && b <= 0xc9:
+		size, err = r.uint(1 << (b - 0xc7))
+		if err != nil {
+			return 0, 0, err
+		}
+	default:
+		r.off--
+		return 0, 0, r.Errorf("expected an extension")
+	}
+	typ, err := r.byte()
+	return int8(typ), size, err
+}
+
+// ReadTime reads a timestamp, in UTC.
+func (r *MsgpackReader) ReadTime() (time.Time, error) {
+	start := r.off
+	typ, size, err := r.extHeader()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if typ != -1 {
+		r.off = start
+		return time.Time{}, r.Errorf("expected a timestamp")
+	}
+	var sec int64
+	var nsec uint64
+	switch size {
+	case 4:
+		v, err := r.uint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec = int64(v)
+	case 8:
+		v, err := r.uint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		nsec, sec = v>>34, int64(v&(1<<34-1))
+	case 12:
+		nsec, err = r.uint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		v, err := r.uint(8)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec = int64(v)
+	default:
+		r.off = start
+		return time.Time{}, r.Errorf("invalid timestamp of %d bytes", size)
+	}
+	if nsec >= 1e9 {
+		r.off = start
+		return time.Time{}, r.Errorf("invalid timestamp nanoseconds %d", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), nil
+}
+
+func (r *MsgpackReader) ReadArrayHeader() (int, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch {
+	case b >= 0x90 && b <= 0x9f:
+		r.off++
+		n = uint64(b & 0x0f)
+	case b == 0xdc, b == 0xdd:
+		r.off++
+		n, err = r.uint(2 << (b - 0xdc))
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, r.Errorf("expected an array")
+	}
+	return r.count(n, 1)
+}
+
+func (r *MsgpackReader) ReadMapHeader() (int, error) {
+	b, err := r.peek()
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch {
+	case b >= 0x80 && b <= 0x8f:
+		r.off++
+		n = uint64(b & 0x0f)
+	case b == 0xde, b == 0xdf:
+		r.off++
+		n, err = r.uint(2 << (b - 0xde))
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, r.Errorf("expected a map")
+	}
+	return r.count(n, 2)
+}
+
+func (r *MsgpackReader) Skip() error {
+	// The values left to skip, which arrays and maps add to.
+	for left := 1; left > 0; left-- {
+		kind, err := r.Kind()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case BinaryArray:
+			n, err := r.ReadArrayHeader()
+			if err != nil {
+				return err
+			}
+			left += n
+		case BinaryMap:
+			n, err := r.ReadMapHeader()
+			if err != nil {
+				return err
+			}
+			left += 2 * n
+		case BinaryNil, BinaryBool:
+			r.off++
+		case BinaryInt, BinaryUint, BinaryFloat:
+			_, err = r.ReadFloat()
+		case BinaryString:
+			_, err = r.ReadKey()
+		case BinaryBytes:
+			b := r.data[r.off]
+			r.off++
+			var n uint64
+			n, err = r.uint(1 << (b - 0xc4))
+			if err == nil {
+				_, err = r.next(n)
+			}
+		default:
+			var size uint64
+			_, size, err = r.extHeader()
+			if err == nil {
+				_, err = r.next(size)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
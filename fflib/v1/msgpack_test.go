@@ -0,0 +1,150 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	enchex "encoding/hex"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpackWriter(t *testing.T) {
+	tests := []struct {
+		write    func(w *MsgpackWriter)
+		expected string
+	}{
+		{func(w *MsgpackWriter) { w.WriteNil() }, "c0"},
+		{func(w *MsgpackWriter) { w.WriteBool(false) }, "c2"},
+		{func(w *MsgpackWriter) { w.WriteBool(true) }, "c3"},
+		{func(w *MsgpackWriter) { w.WriteInt(0) }, "00"},
+		{func(w *MsgpackWriter) { w.WriteInt(127) }, "7f"},
+		{func(w *MsgpackWriter) { w.WriteInt(128) }, "cc80"},
+		{func(w *MsgpackWriter) { w.WriteInt(256) }, "cd0100"},
+		{func(w *MsgpackWriter) { w.WriteInt(65536) }, "ce00010000"},
+		{func(w *MsgpackWriter) { w.WriteInt(1 << 32) }, "cf0000000100000000"},
+		{func(w *MsgpackWriter) { w.WriteInt(-1) }, "ff"},
+		{func(w *MsgpackWriter) { w.WriteInt(-32) }, "e0"},
+		{func(w *MsgpackWriter) { w.WriteInt(-33) }, "d0df"},
+		{func(w *MsgpackWriter) { w.WriteInt(-129) }, "d1ff7f"},
+		{func(w *MsgpackWriter) { w.WriteInt(-32769) }, "d2ffff7fff"},
+		{func(w *MsgpackWriter) { w.WriteInt(math.MinInt64) }, "d38000000000000000"},
+		{func(w *MsgpackWriter) { w.WriteUint(math.MaxUint64) }, "cfffffffffffffffff"},
+		{func(w *MsgpackWriter) { w.WriteFloat32(1.5) }, "ca3fc00000"},
+		{func(w *MsgpackWriter) { w.WriteFloat64(1.5) }, "cb3ff8000000000000"},
+		{func(w *MsgpackWriter) { w.WriteString("") }, "a0"},
+		{func(w *MsgpackWriter) { w.WriteString("a") }, "a161"},
+		{func(w *MsgpackWriter) { w.WriteString(strings.Repeat("a", 32)) }, "d920" + strings.Repeat("61", 32)},
+		{func(w *MsgpackWriter) { w.WriteBytes([]byte{1, 2}) }, "c4020102"},
+		{func(w *MsgpackWriter) { w.WriteTime(time.Unix(1, 0)) }, "d6ff00000001"},
+		{func(w *MsgpackWriter) { w.WriteTime(time.Unix(1, 1)) }, "d7ff0000000400000001"},
+		{func(w *MsgpackWriter) { w.WriteTime(time.Unix(1<<34, 5)) }, "c70cff000000050000000400000000"},
+		{func(w *MsgpackWriter) { w.WriteTime(time.Unix(-1, 0)) }, "c70cff00000000ffffffffffffffff"},
+		{func(w *MsgpackWriter) { w.WriteArrayHeader(2) }, "92"},
+		{func(w *MsgpackWriter) { w.WriteArrayHeader(16) }, "dc0010"},
+		{func(w *MsgpackWriter) { w.WriteArrayHeader(1 << 16) }, "dd00010000"},
+		{func(w *MsgpackWriter) { w.WriteMapHeader(1) }, "81"},
+		{func(w *MsgpackWriter) { w.WriteMapHeader(16) }, "de0010"},
+	}
+
+	for _, test := range tests {
+		w := NewMsgpackWriter(nil)
+		test.write(w)
+		if out := enchex.EncodeToString(w.Bytes()); out != test.expected {
+			t.Errorf("Expected: %s\nGot: %s", test.expected, out)
+		}
+	}
+}
+
+func TestMsgpackReader(t *testing.T) {
+	r := NewMsgpackReader(mustHex(t, "cc80d0dfcb3ff8000000000000ca3fc00000d7ff0000000400000001"))
+	if v, err := r.ReadUint(); v != 128 || err != nil {
+		t.Errorf("ReadUint: %v, %v", v, err)
+	}
+	if v, err := r.ReadInt(); v != -33 || err != nil {
+		t.Errorf("ReadInt: %v, %v", v, err)
+	}
+	if v, err := r.ReadFloat(); v != 1.5 || err != nil {
+		t.Errorf("ReadFloat: %v, %v", v, err)
+	}
+	if v, err := r.ReadFloat(); v != 1.5 || err != nil {
+		t.Errorf("ReadFloat of a float32: %v, %v", v, err)
+	}
+	if v, err := r.ReadTime(); !v.Equal(time.Unix(1, 1)) || err != nil {
+		t.Errorf("ReadTime: %v, %v", v, err)
+	}
+	if err := r.Finish(); err != nil {
+		t.Errorf("Finish: %v", err)
+	}
+
+	// Strings are read as binary data, as older encoders wrote them.
+	r = NewMsgpackReader(mustHex(t, "a26162"))
+	if v, err := r.ReadBytes(); string(v) != "ab" || err != nil {
+		t.Errorf("ReadBytes: %q, %v", v, err)
+	}
+}
+
+func TestMsgpackSkip(t *testing.T) {
+	// {"a": [1, "x", {"b": nil}, bin, timestamp, ext]}, then true
+	r := NewMsgpackReader(mustHex(t, "81a16196"+"01"+"a178"+"81a162c0"+"c40101"+"d6ff00000001"+"d50a0102"+"c3"))
+	if err := r.Skip(); err != nil {
+		t.Fatalf("Skip: %v", err)
+	}
+	if v, err := r.ReadBool(); !v || err != nil {
+		t.Errorf("ReadBool after Skip: %v, %v", v, err)
+	}
+}
+
+func TestMsgpackErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		read  func(r *MsgpackReader) error
+	}{
+		{"", func(r *MsgpackReader) error { _, err := r.ReadInt(); return err }},
+		{"cd01", func(r *MsgpackReader) error { _, err := r.ReadInt(); return err }},
+		{"a161", func(r *MsgpackReader) error { _, err := r.ReadInt(); return err }},
+		{"cfffffffffffffffff", func(r *MsgpackReader) error { _, err := r.ReadInt(); return err }},
+		{"ff", func(r *MsgpackReader) error { _, err := r.ReadUint(); return err }},
+		{"a36162", func(r *MsgpackReader) error { _, err := r.ReadString(); return err }},
+		{"01", func(r *MsgpackReader) error { _, err := r.ReadBool(); return err }},
+		{"ddffffffff", func(r *MsgpackReader) error { _, err := r.ReadArrayHeader(); return err }},
+		{"df0000000201", func(r *MsgpackReader) error { _, err := r.ReadMapHeader(); return err }},
+		{"d50a0102", func(r *MsgpackReader) error { _, err := r.ReadTime(); return err }},
+		{"d7ffffffffff00000000", func(r *MsgpackReader) error { _, err := r.ReadTime(); return err }},
+		{"9201", func(r *MsgpackReader) error { return r.Skip() }},
+		{"c1", func(r *MsgpackReader) error { return r.Skip() }},
+		{"0101", func(r *MsgpackReader) error { r.Skip(); return r.Finish() }},
+	}
+
+	for _, test := range tests {
+		err := test.read(NewMsgpackReader(mustHex(t, test.input)))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+		}
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := enchex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
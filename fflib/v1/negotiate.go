@@ -0,0 +1,55 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"strings"
+)
+
+// UnsupportedMediaTypeError is returned by the Marshal methods generated
+// with -negotiate=error for content types they can't write.
+type UnsupportedMediaTypeError struct {
+	ContentType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return "ffjson: unsupported content type " + e.ContentType
+}
+
+// MediaType returns the media type of a Content-Type header, or of a
+// single media range of an Accept header: lower-cased, without its
+// parameters and spaces, as in "application/json" for
+// "Application/JSON; charset=utf-8".
+func MediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// IsJSONMediaType returns whether the media type mt, as returned by
+// MediaType, is written as json: application/json, types with the +json
+// suffix like application/problem+json, and the empty type and the
+// wildcards */* and application/*, which accept json.
+func IsJSONMediaType(mt string) bool {
+	switch mt {
+	case "", "*/*", "application/*", "application/json":
+		return true
+	}
+	return strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json")
+}
@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestMediaType(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+		json     bool
+	}{
+		{"application/json", "application/json", true},
+		{"Application/JSON; charset=utf-8", "application/json", true},
+		{" application/problem+json ", "application/problem+json", true},
+		{"application/vnd.api+json;q=0.9", "application/vnd.api+json", true},
+		{"*/*", "*/*", true},
+		{"application/*", "application/*", true},
+		{"", "", true},
+		{"text/event-stream", "text/event-stream", false},
+		{"text/json", "text/json", false},
+		{"application/yaml", "application/yaml", false},
+		{"text/+json", "text/+json", false},
+	}
+
+	for _, test := range tests {
+		mt := MediaType(test.in)
+		if mt != test.expected {
+			t.Errorf("MediaType(%q): expected %q, got %q", test.in, test.expected, mt)
+		}
+		if IsJSONMediaType(mt) != test.json {
+			t.Errorf("IsJSONMediaType(%q): expected %v", mt, test.json)
+		}
+	}
+}
+
+func TestUnsupportedMediaTypeError(t *testing.T) {
+	err := error(&UnsupportedMediaTypeError{ContentType: "text/csv"})
+	if err.Error() != "ffjson: unsupported content type text/csv" {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// The longest numbers written by the functions below: 20 bytes for
+// math.MinInt64, and 24 bytes for -1.7976931348623157e+308.
+const (
+	maxIntLen   = 20
+	maxFloatLen = 24
+)
+
+// WriteInt writes the base 10 form of v to buf. When buf is a *Buffer the
+// number is formatted directly into its storage, without any temporary
+// allocation.
+func WriteInt(buf EncodingBuffer, v int64) {
+	if b, ok := buf.(*Buffer); ok {
+		b.AppendInt(v)
+		return
+	}
+	FormatBits2(buf, uint64(v), 10, v < 0)
+}
+
+// WriteUint writes the base 10 form of v to buf, like WriteInt.
+func WriteUint(buf EncodingBuffer, v uint64) {
+	if b, ok := buf.(*Buffer); ok {
+		b.AppendUint(v)
+		return
+	}
+	FormatBits2(buf, v, 10, false)
+}
+
+// WriteFloat writes the shortest 'g' form of f, which is a float32 if
+// bitSize is 32, to buf, like WriteInt.
+func WriteFloat(buf EncodingBuffer, f float64, bitSize int) {
+	if b, ok := buf.(*Buffer); ok {
+		b.AppendFloat(f, bitSize)
+		return
+	}
+	AppendFloat(buf, f, 'g', -1, bitSize)
+}
+
+// WriteFiniteFloat writes f like WriteFloat, but NaN and infinities,
+// which have no JSON form, are not written and return a
+// *json.UnsupportedValueError, as encoding/json does.
+func WriteFiniteFloat(buf EncodingBuffer, f float64, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		v := reflect.ValueOf(f)
+		if bitSize == 32 {
+			v = reflect.ValueOf(float32(f))
+		}
+		return &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, bitSize)}
+	}
+	WriteFloat(buf, f, bitSize)
+	return nil
+}
+
+// WriteStdFloat writes f in the form encoding/json writes floats, which
+// is the 'f' form, or the 'e' form without a leading zero in the
+// exponent for magnitudes below 1e-6 or from 1e21 on. Like
+// WriteFiniteFloat, NaN and infinities return a
+// *json.UnsupportedValueError.
+func WriteStdFloat(buf EncodingBuffer, f float64, bitSize int) error {
+	if bitSize == 32 {
+		f = float64(float32(f))
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return WriteFiniteFloat(buf, f, bitSize)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	var scratch [maxFloatLen + 8]byte
+	b := strconv.AppendFloat(scratch[:0], f, format, -1, bitSize)
+	if format == 'e' {
+		// Go writes "1e-07" where encoding/json writes "1e-7".
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// WriteFloatOrNull writes f like WriteFloat, or null if f is NaN or an
+// infinity.
+func WriteFloatOrNull(buf EncodingBuffer, f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf.WriteString("null")
+		return
+	}
+	WriteFloat(buf, f, bitSize)
+}
+
+// WriteNumber writes n to buf as it is, or 0 if n is empty, as
+// encoding/json does. A n that isn't a valid JSON number is not written
+// and returns an error.
+func WriteNumber(buf EncodingBuffer, n json.Number) error {
+	if n == "" {
+		buf.WriteString("0")
+		return nil
+	}
+	if !IsValidNumber(string(n)) {
+		return fmt.Errorf("json: invalid number literal %q", string(n))
+	}
+	buf.WriteString(string(n))
+	return nil
+}
+
+// IsValidNumber returns whether s is a valid JSON number literal.
+func IsValidNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	if s[0] == '-' {
+		s = s[1:]
+		if s == "" {
+			return false
+		}
+	}
+
+	// Digits, without leading zeros.
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = s[1:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	default:
+		return false
+	}
+
+	// . followed by 1 or more digits.
+	if len(s) >= 2 && s[0] == '.' && '0' <= s[1] && s[1] <= '9' {
+		s = s[2:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	// e or E followed by an optional - or + and 1 or more digits.
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+			if s == "" {
+				return false
+			}
+		}
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+
+	return s == ""
+}
+
+// AppendInt appends the base 10 form of v to the buffer. The buffer is
+// grown first, so strconv can format into the spare capacity in place.
+func (b *Buffer) AppendInt(v int64) {
+	m := b.grow(maxIntLen)
+	b.buf = strconv.AppendInt(b.buf[:m], v, 10)
+}
+
+// AppendUint appends the base 10 form of v to the buffer.
+func (b *Buffer) AppendUint(v uint64) {
+	m := b.grow(maxIntLen)
+	b.buf = strconv.AppendUint(b.buf[:m], v, 10)
+}
+
+// AppendFloat appends the shortest 'g' form of f to the buffer. bitSize
+// is 32 for float32 values and 64 for float64 values.
+func (b *Buffer) AppendFloat(f float64, bitSize int) {
+	m := b.grow(maxFloatLen)
+	b.buf = strconv.AppendFloat(b.buf[:m], f, 'g', -1, bitSize)
+}
@@ -0,0 +1,215 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// plainBuffer hides the *Buffer fast path.
+type plainBuffer struct {
+	*Buffer
+}
+
+func TestWriteInt(t *testing.T) {
+	values := []int64{0, 1, -1, 9, 10, 11, -10, 99, 100, math.MaxInt64, math.MinInt64, math.MaxInt32, math.MinInt32}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		values = append(values, r.Int63()>>uint(r.Intn(63))*int64(1-2*r.Intn(2)))
+	}
+
+	for _, v := range values {
+		var buf Buffer
+		WriteInt(&buf, v)
+		WriteInt(plainBuffer{&buf}, v)
+		expected := strconv.FormatInt(v, 10)
+		if buf.String() != expected+expected {
+			t.Fatalf("Expected: %v%v\nGot: %v", expected, expected, buf.String())
+		}
+
+		if v >= 0 {
+			buf.Reset()
+			WriteUint(&buf, uint64(v))
+			WriteUint(plainBuffer{&buf}, uint64(v))
+			if buf.String() != expected+expected {
+				t.Fatalf("Expected: %v%v\nGot: %v", expected, expected, buf.String())
+			}
+		}
+	}
+
+	var buf Buffer
+	WriteUint(&buf, math.MaxUint64)
+	if buf.String() != "18446744073709551615" {
+		t.Fatalf("Expected: 18446744073709551615\nGot: %v", buf.String())
+	}
+}
+
+func TestWriteFloat(t *testing.T) {
+	values := []float64{0, math.Copysign(0, -1), 1, -1, 0.1, 1e20, 1e21, 1e-6, 1e-7, 123456789.123,
+		math.MaxFloat64, -math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat32,
+		math.Inf(1), math.Inf(-1), math.NaN()}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		values = append(values, math.Float64frombits(r.Uint64()), r.NormFloat64()*1e6)
+	}
+
+	for _, v := range values {
+		for _, bitSize := range []int{32, 64} {
+			var buf Buffer
+			WriteFloat(&buf, v, bitSize)
+			WriteFloat(plainBuffer{&buf}, v, bitSize)
+			expected := strconv.FormatFloat(v, 'g', -1, bitSize)
+			if buf.String() != expected+expected {
+				t.Fatalf("%v/%d: Expected: %v%v\nGot: %v", v, bitSize, expected, expected, buf.String())
+			}
+		}
+	}
+}
+
+func TestWriteFiniteFloat(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		for _, bitSize := range []int{32, 64} {
+			var buf Buffer
+			err := WriteFiniteFloat(&buf, v, bitSize)
+			uerr, ok := err.(*json.UnsupportedValueError)
+			if !ok {
+				t.Fatalf("%v/%d: expected a *json.UnsupportedValueError, got %v", v, bitSize, err)
+			}
+			if uerr.Str != strconv.FormatFloat(v, 'g', -1, bitSize) {
+				t.Fatalf("%v/%d: unexpected Str %q", v, bitSize, uerr.Str)
+			}
+			_, expected := json.Marshal(v)
+			if bitSize == 32 {
+				_, expected = json.Marshal(float32(v))
+			}
+			if err.Error() != expected.Error() {
+				t.Fatalf("%v/%d: Expected: %v\nGot: %v", v, bitSize, expected, err)
+			}
+			if buf.Len() != 0 {
+				t.Fatalf("%v/%d: expected nothing written, got %s", v, bitSize, buf.String())
+			}
+
+			WriteFloatOrNull(&buf, v, bitSize)
+			if buf.String() != "null" {
+				t.Fatalf("%v/%d: Expected: null\nGot: %v", v, bitSize, buf.String())
+			}
+		}
+	}
+
+	var buf Buffer
+	if err := WriteFiniteFloat(&buf, -0.5, 64); err != nil {
+		t.Fatal(err)
+	}
+	WriteFloatOrNull(&buf, 1e21, 32)
+	if buf.String() != "-0.51e+21" {
+		t.Fatalf("Expected: -0.51e+21\nGot: %v", buf.String())
+	}
+}
+
+func TestWriteStdFloat(t *testing.T) {
+	values := []float64{0, math.Copysign(0, -1), 1, -1, 0.1, 1e20, 1e21, 1e-6, 1e-7, 123456789.123,
+		math.MaxFloat64, -math.MaxFloat64, math.SmallestNonzeroFloat64, math.MaxFloat32, math.SmallestNonzeroFloat32}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		v := math.Float64frombits(r.Uint64())
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, v)
+		}
+		values = append(values, r.NormFloat64()*1e6, r.NormFloat64()*1e-6)
+	}
+
+	for _, v := range values {
+		for _, bitSize := range []int{32, 64} {
+			var buf Buffer
+			err := WriteStdFloat(&buf, v, bitSize)
+			expected, expectedErr := json.Marshal(v)
+			if bitSize == 32 {
+				expected, expectedErr = json.Marshal(float32(v))
+			}
+			if (err != nil) != (expectedErr != nil) {
+				t.Fatalf("%v/%d: Expected error %v\nGot: %v", v, bitSize, expectedErr, err)
+			}
+			if buf.String() != string(expected) {
+				t.Fatalf("%v/%d: Expected: %s\nGot: %v", v, bitSize, expected, buf.String())
+			}
+		}
+	}
+
+	var buf Buffer
+	err := WriteStdFloat(&buf, math.NaN(), 64)
+	if _, ok := err.(*json.UnsupportedValueError); !ok || buf.Len() != 0 {
+		t.Fatalf("Expected a *json.UnsupportedValueError and nothing written, got %v and %q", err, buf.String())
+	}
+}
+
+func TestWriteNumberAllocs(t *testing.T) {
+	var buf Buffer
+	buf.Grow(4096)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		for i := 0; i < 10; i++ {
+			WriteInt(&buf, -123456789012)
+			WriteUint(&buf, math.MaxUint64)
+			WriteFloat(&buf, -1.7976931348623157e+308, 64)
+			WriteFloat(&buf, 3.14, 32)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func TestWriteNumber(t *testing.T) {
+	valid := []string{"0", "-0", "1", "-1", "12345678901234567890123", "3.14", "-0.5", "1e10", "1E+2", "2.5e-3"}
+	for _, s := range valid {
+		var buf Buffer
+		err := WriteNumber(&buf, json.Number(s))
+		if err != nil || buf.String() != s {
+			t.Errorf("WriteNumber(%q) = %q, %v", s, buf.String(), err)
+		}
+		if !IsValidNumber(s) {
+			t.Errorf("IsValidNumber(%q) = false", s)
+		}
+	}
+
+	var buf Buffer
+	err := WriteNumber(&buf, "")
+	if err != nil || buf.String() != "0" {
+		t.Errorf("WriteNumber(\"\") = %q, %v, want 0", buf.String(), err)
+	}
+
+	invalid := []string{"-", "01", "+1", ".5", "1.", "1e", "1e+", "0x10", "NaN", "1 ", " 1", "abc", "1.5.5"}
+	for _, s := range invalid {
+		var buf Buffer
+		err := WriteNumber(&buf, json.Number(s))
+		if err == nil || buf.Len() != 0 {
+			t.Errorf("WriteNumber(%q) = %q, %v, want error", s, buf.String(), err)
+		}
+		if IsValidNumber(s) {
+			t.Errorf("IsValidNumber(%q) = true", s)
+		}
+		// encoding/json agrees.
+		if _, err := json.Marshal(json.Number(s)); err == nil {
+			t.Errorf("json.Marshal(%q) succeeded", s)
+		}
+	}
+}
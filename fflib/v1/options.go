@@ -0,0 +1,79 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+)
+
+// ErrMaxDepth is the error of decoding objects and arrays nested deeper
+// than DecodeOptions.MaxDepth.
+var ErrMaxDepth = errors.New("ffjson: exceeded max nesting depth")
+
+// ErrMaxStringLength is the error of decoding a string longer than
+// DecodeOptions.MaxStringLength.
+var ErrMaxStringLength = errors.New("ffjson: exceeded max string length")
+
+// DecodeOptions configure the generated decoders for a single decoding,
+// as set on the lexer with SetOptions, so the same types can be decoded
+// with different policies, like stricter limits for untrusted input. They
+// apply in addition to the directives and flags of the structs and to
+// global switches like SetDisallowUnknownFields: options can make
+// decoding stricter, but not looser. The zero value changes nothing.
+type DecodeOptions struct {
+	// DisallowUnknownFields fails decoding on keys not matching a field,
+	// as ffjson: strict does.
+	DisallowUnknownFields bool
+	// ExactCase only matches keys spelled exactly as the json names of
+	// fields, as ffjson: exactcase does, instead of ignoring case.
+	ExactCase bool
+	// MaxDepth fails decoding objects and arrays nested deeper than it,
+	// counting the outermost one as 1. 0 is no limit.
+	MaxDepth int
+	// MaxStringLength fails decoding strings, keys included, longer than
+	// it in bytes, once unescaped. 0 is no limit.
+	MaxStringLength int
+	// UseNumber stores numbers in interface{} values as json.Number
+	// instead of float64, as -use-number does.
+	UseNumber bool
+}
+
+// SetOptions sets the options of the decoders using the lexer to opts,
+// or to the defaults if opts is nil. Reset keeps them.
+func (ffl *FFLexer) SetOptions(opts *DecodeOptions) {
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+	ffl.DisallowUnknownFields = opts.DisallowUnknownFields
+	ffl.ExactCase = opts.ExactCase
+	ffl.MaxDepth = opts.MaxDepth
+	ffl.MaxStringLength = opts.MaxStringLength
+	ffl.UseNumber = opts.UseNumber
+}
+
+// Options returns the options set with SetOptions, for lexers decoding
+// values captured from this one.
+func (ffl *FFLexer) Options() *DecodeOptions {
+	return &DecodeOptions{
+		DisallowUnknownFields: ffl.DisallowUnknownFields,
+		ExactCase:             ffl.ExactCase,
+		MaxDepth:              ffl.MaxDepth,
+		MaxStringLength:       ffl.MaxStringLength,
+		UseNumber:             ffl.UseNumber,
+	}
+}
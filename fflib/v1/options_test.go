@@ -0,0 +1,107 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+// scanErr scans input with opts until the end, returning the error the
+// lexer stopped with.
+func scanErr(input string, opts *DecodeOptions) error {
+	ffl := NewFFLexer([]byte(input))
+	ffl.SetOptions(opts)
+	toks := scanAll(ffl)
+	if toks[len(toks)-1] == FFTok_error {
+		return ffl.BigError
+	}
+	return nil
+}
+
+func TestMaxDepth(t *testing.T) {
+	opts := &DecodeOptions{MaxDepth: 2}
+	for input, want := range map[string]error{
+		`{"a":[1,2]}`:         nil,
+		`[[],[],{}]`:          nil,
+		`{"a":[{}]}`:          ErrMaxDepth,
+		`[[[1]]]`:             ErrMaxDepth,
+		`"[[[[" `:             nil,
+		`{"a":{"b":1},"c":2}`: nil,
+	} {
+		if err := scanErr(input, opts); err != want {
+			t.Errorf("%s: got %v, expected %v", input, err, want)
+		}
+	}
+	if err := scanErr(`[[[[[1]]]]]`, nil); err != nil {
+		t.Errorf("no limit: %v", err)
+	}
+}
+
+func TestMaxDepthCapture(t *testing.T) {
+	ffl := NewFFLexer([]byte(`{"a":[[[1]]]}`))
+	ffl.SetOptions(&DecodeOptions{MaxDepth: 3})
+	scanToTok(ffl, FFTok_colon)
+	tok := ffl.Scan()
+	_, err := ffl.CaptureField(tok)
+	if err != ErrMaxDepth {
+		t.Fatalf("got %v, expected %v", err, ErrMaxDepth)
+	}
+}
+
+func TestMaxStringLength(t *testing.T) {
+	opts := &DecodeOptions{MaxStringLength: 4}
+	for input, want := range map[string]error{
+		`"abcd"`:           nil,
+		`"abcde"`:          ErrMaxStringLength,
+		`"éé"`:             nil,
+		`{"abcde":1}`:      ErrMaxStringLength,
+		`["ab","abcdefg"]`: ErrMaxStringLength,
+		`[12345678]`:       nil,
+	} {
+		if err := scanErr(input, opts); err != want {
+			t.Errorf("%s: got %v, expected %v", input, err, want)
+		}
+	}
+
+	// Captured strings are checked before being quoted again.
+	ffl := NewFFLexer([]byte(`["abcd","abcde"]`))
+	ffl.SetOptions(opts)
+	_, err := ffl.CaptureField(ffl.Scan())
+	if err != ErrMaxStringLength {
+		t.Fatalf("capture: got %v, expected %v", err, ErrMaxStringLength)
+	}
+}
+
+func TestOptionsReset(t *testing.T) {
+	opts := &DecodeOptions{DisallowUnknownFields: true, ExactCase: true, MaxDepth: 1, MaxStringLength: 2, UseNumber: true}
+	ffl := NewFFLexer([]byte(`[[`))
+	ffl.SetOptions(opts)
+	scanAll(ffl)
+	ffl.Reset([]byte(`[1]`))
+	if got := *ffl.Options(); got != *opts {
+		t.Fatalf("Reset changed the options to %+v", got)
+	}
+	if toks := scanAll(ffl); toks[len(toks)-1] != FFTok_eof {
+		t.Fatalf("depth not reset: %v", toks)
+	}
+
+	ffl.SetOptions(nil)
+	if got := *ffl.Options(); got != (DecodeOptions{}) {
+		t.Fatalf("SetOptions(nil) left %+v", got)
+	}
+}
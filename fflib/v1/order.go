@@ -0,0 +1,42 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// KeyOrder returns the order in which to write the fields named names,
+// given the json names of the fields in the order they were decoded:
+// first the decoded fields, each once, then the other fields in the order
+// of names. Names in order that aren't in names are ignored.
+func KeyOrder(order []string, names []string) []int {
+	written := make([]bool, len(names))
+	out := make([]int, 0, len(names))
+	for _, name := range order {
+		for i, n := range names {
+			if n == name && !written[i] {
+				written[i] = true
+				out = append(out, i)
+				break
+			}
+		}
+	}
+	for i := range names {
+		if !written[i] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
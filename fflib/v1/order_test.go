@@ -0,0 +1,43 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyOrder(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	tests := []struct {
+		order    []string
+		expected []int
+	}{
+		{nil, []int{0, 1, 2}},
+		{[]string{"c", "a", "b"}, []int{2, 0, 1}},
+		{[]string{"b"}, []int{1, 0, 2}},
+		{[]string{"c", "c", "x", "a"}, []int{2, 0, 1}},
+	}
+
+	for _, test := range tests {
+		out := KeyOrder(test.order, names)
+		if !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("KeyOrder(%v)\nExpected: %v\nGot: %v", test.order, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// patchValue is a parsed JSON value. Scalars keep their encoded form,
+// objects keep the order of their members.
+type patchValue struct {
+	tok    FFTok
+	scalar []byte
+	names  []string
+	// elems are the members of an object or the elements of an array.
+	elems []*patchValue
+}
+
+// JSONPatch returns a JSON Patch (RFC 6902) transforming the JSON
+// document base into target:
+//
+//   - members of objects only in base are removed, and members only in
+//     target are added,
+//   - arrays are compared element by element: common elements are
+//     patched in place, then the additional elements of target are
+//     appended with "/-", or the extra elements of base are removed from
+//     the last one,
+//   - other values that differ, including values of different types, are
+//     replaced.
+//
+// Equal documents result in an empty patch, "[]".
+func JSONPatch(base, target []byte) ([]byte, error) {
+	from, err := parsePatchDocument(base)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parsePatchDocument(target)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &patchWriter{}
+	p.buf.WriteByte('[')
+	p.diff(from, to)
+	p.buf.WriteByte(']')
+	return p.buf.Bytes(), nil
+}
+
+func parsePatchDocument(input []byte) (*patchValue, error) {
+	// The lexer needs a delimiter after a number, as in Canonicalize.
+	fs := NewFFLexer(append(input[:len(input):len(input)], ' '))
+	v, err := parsePatchValue(fs, fs.Scan())
+	if err != nil {
+		return nil, err
+	}
+	tok := fs.Scan()
+	if tok != FFTok_eof {
+		return nil, fs.WrapErr(fmt.Errorf("ffjson: unexpected token after patch value: %v", tok))
+	}
+	return v, nil
+}
+
+func parsePatchValue(fs *FFLexer, tok FFTok) (*patchValue, error) {
+	v := &patchValue{tok: tok}
+	switch tok {
+	case FFTok_null, FFTok_bool, FFTok_integer, FFTok_double:
+		v.scalar = append([]byte(nil), fs.Output.Bytes()...)
+	case FFTok_string:
+		var buf Buffer
+		WriteJson(&buf, fs.Output.Bytes())
+		v.scalar = buf.Bytes()
+	case FFTok_left_brace:
+		return v, parsePatchArray(fs, v)
+	case FFTok_left_bracket:
+		return v, parsePatchObject(fs, v)
+	case FFTok_error, FFTok_eof:
+		return nil, lexerTokError(fs)
+	default:
+		return nil, fs.WrapErr(fmt.Errorf("ffjson: unexpected token: %v", tok))
+	}
+	return v, nil
+}
+
+func parsePatchArray(fs *FFLexer, v *patchValue) error {
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		return nil
+	}
+	for {
+		elem, err := parsePatchValue(fs, tok)
+		if err != nil {
+			return err
+		}
+		v.elems = append(v.elems, elem)
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_comma:
+			tok = fs.Scan()
+		case FFTok_right_brace:
+			return nil
+		case FFTok_error, FFTok_eof:
+			return lexerTokError(fs)
+		default:
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of array, but got token: %v", tok))
+		}
+	}
+}
+
+func parsePatchObject(fs *FFLexer, v *patchValue) error {
+	tok := fs.Scan()
+	if tok == FFTok_right_bracket {
+		return nil
+	}
+	for {
+		if tok == FFTok_error || tok == FFTok_eof {
+			return lexerTokError(fs)
+		}
+		if tok != FFTok_string {
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted key token, but got token: %v", tok))
+		}
+		name := fs.Output.String()
+		if v.member(name) != nil {
+			return fs.WrapErr(fmt.Errorf("ffjson: duplicate object key %q", name))
+		}
+
+		tok = fs.Scan()
+		if tok != FFTok_colon {
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted colon token, but got token: %v", tok))
+		}
+
+		elem, err := parsePatchValue(fs, fs.Scan())
+		if err != nil {
+			return err
+		}
+		v.names = append(v.names, name)
+		v.elems = append(v.elems, elem)
+
+		tok = fs.Scan()
+		if tok == FFTok_right_bracket {
+			return nil
+		}
+		if tok != FFTok_comma {
+			if tok == FFTok_error || tok == FFTok_eof {
+				return lexerTokError(fs)
+			}
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of object, but got token: %v", tok))
+		}
+		tok = fs.Scan()
+	}
+}
+
+// member returns the member name of an object, or nil.
+func (v *patchValue) member(name string) *patchValue {
+	for i, n := range v.names {
+		if n == name {
+			return v.elems[i]
+		}
+	}
+	return nil
+}
+
+func (v *patchValue) write(buf *Buffer) {
+	switch v.tok {
+	case FFTok_left_brace:
+		buf.WriteByte('[')
+		for i, elem := range v.elems {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			elem.write(buf)
+		}
+		buf.WriteByte(']')
+	case FFTok_left_bracket:
+		buf.WriteByte('{')
+		for i, elem := range v.elems {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			WriteJsonString(buf, v.names[i])
+			buf.WriteByte(':')
+			elem.write(buf)
+		}
+		buf.WriteByte('}')
+	default:
+		buf.Write(v.scalar)
+	}
+}
+
+// patchWriter writes the operations of a JSON Patch, tracking the JSON
+// Pointer of the current location.
+type patchWriter struct {
+	buf  Buffer
+	path []string
+	n    int
+}
+
+func (p *patchWriter) op(op string, path string, value *patchValue) {
+	if p.n != 0 {
+		p.buf.WriteByte(',')
+	}
+	p.n++
+	p.buf.WriteString(`{"op":"` + op + `","path":`)
+	WriteJsonString(&p.buf, p.pointer()+path)
+	if value != nil {
+		p.buf.WriteString(`,"value":`)
+		value.write(&p.buf)
+	}
+	p.buf.WriteByte('}')
+}
+
+func (p *patchWriter) pointer() string {
+	var buf bytes.Buffer
+	for _, token := range p.path {
+		buf.WriteByte('/')
+		buf.WriteString(token)
+	}
+	return buf.String()
+}
+
+func (p *patchWriter) diff(from, to *patchValue) {
+	switch {
+	case from.tok == FFTok_left_bracket && to.tok == FFTok_left_bracket:
+		p.diffObject(from, to)
+	case from.tok == FFTok_left_brace && to.tok == FFTok_left_brace:
+		p.diffArray(from, to)
+	case from.tok != to.tok || !bytes.Equal(from.scalar, to.scalar):
+		p.op("replace", "", to)
+	}
+}
+
+func (p *patchWriter) diffObject(from, to *patchValue) {
+	for _, name := range from.names {
+		if to.member(name) == nil {
+			p.op("remove", "/"+refTokenEscaper.Replace(name), nil)
+		}
+	}
+	for i, name := range to.names {
+		token := refTokenEscaper.Replace(name)
+		base := from.member(name)
+		if base == nil {
+			p.op("add", "/"+token, to.elems[i])
+			continue
+		}
+		p.path = append(p.path, token)
+		p.diff(base, to.elems[i])
+		p.path = p.path[:len(p.path)-1]
+	}
+}
+
+func (p *patchWriter) diffArray(from, to *patchValue) {
+	common := len(from.elems)
+	if len(to.elems) < common {
+		common = len(to.elems)
+	}
+	for i := 0; i < common; i++ {
+		p.path = append(p.path, strconv.Itoa(i))
+		p.diff(from.elems[i], to.elems[i])
+		p.path = p.path[:len(p.path)-1]
+	}
+	for i := common; i < len(to.elems); i++ {
+		p.op("add", "/-", to.elems[i])
+	}
+	// Removing from the end keeps the indexes of the elements before.
+	for i := len(from.elems) - 1; i >= common; i-- {
+		p.op("remove", "/"+strconv.Itoa(i), nil)
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		base     string
+		target   string
+		expected string
+	}{
+		{`{"a":1,"b":"x"}`, `{"a":1,"b":"x"}`, `[]`},
+		{`{"a":1}`, `{"a":2}`, `[{"op":"replace","path":"/a","value":2}]`},
+		{`{"a":1,"b":2}`, `{"b":2,"c":[true]}`,
+			`[{"op":"remove","path":"/a"},{"op":"add","path":"/c","value":[true]}]`},
+		{`{"o":{"x":null}}`, `{"o":{"x":"<"}}`, `[{"op":"replace","path":"/o/x","value":"\u003c"}]`},
+		{`{"a/b~":1}`, `{"a/b~":{}}`, `[{"op":"replace","path":"/a~1b~0","value":{}}]`},
+		{`[1,2,3]`, `[1,5]`, `[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/2"}]`},
+		{`[1,2,3,4]`, `[1]`, `[{"op":"remove","path":"/3"},{"op":"remove","path":"/2"},{"op":"remove","path":"/1"}]`},
+		{`{"l":[{"n":"a"}]}`, `{"l":[{"n":"b"},{"n":"c"}]}`,
+			`[{"op":"replace","path":"/l/0/n","value":"b"},{"op":"add","path":"/l/-","value":{"n":"c"}}]`},
+		{`null`, `{"a":1}`, `[{"op":"replace","path":"","value":{"a":1}}]`},
+		{`1`, `1.0`, `[{"op":"replace","path":"","value":1.0}]`},
+	}
+
+	for _, test := range tests {
+		out, err := JSONPatch([]byte(test.base), []byte(test.target))
+		if err != nil {
+			t.Errorf("JSONPatch(%s, %s): %v", test.base, test.target, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("JSONPatch(%s, %s)\nExpected: %s\nGot: %s", test.base, test.target, test.expected, out)
+		}
+	}
+}
+
+func TestJSONPatchInvalid(t *testing.T) {
+	for _, in := range []string{`{"a":1`, `{"a":1,"a":2}`, `[1] 2`, ``} {
+		_, err := JSONPatch([]byte(in), []byte(`{}`))
+		if err == nil {
+			t.Errorf("JSONPatch(%s): expected an error", in)
+		}
+	}
+}
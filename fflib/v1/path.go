@@ -0,0 +1,131 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// pathFrame is an object or array open at the end of the input of
+// valuePath.
+type pathFrame struct {
+	object bool
+	key    string
+	hasKey bool
+	index  int
+	// inElem is set once the element at index of an array started.
+	inElem bool
+}
+
+// valuePath returns the path of the value being read at the end of s, a
+// prefix of a json document, like "items[3].owner.id". Object members
+// are joined with dots and array elements are indexed. Within an object
+// the path is the member whose key was read last, and within an array the
+// element which started last. It is only used to
+// locate errors, so s is assumed to be valid json up to its end.
+func valuePath(s []byte) string {
+	var stack []pathFrame
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case ' ', '\t', '\r', '\n', ',', ':', '}', ']':
+		default:
+			if len(stack) > 0 && !stack[len(stack)-1].object {
+				stack[len(stack)-1].inElem = true
+			}
+		}
+		switch c {
+		case '{':
+			stack = append(stack, pathFrame{object: true})
+		case '[':
+			stack = append(stack, pathFrame{})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) > 0 {
+				top := &stack[len(stack)-1]
+				if top.object {
+					top.hasKey = false
+				} else {
+					top.index++
+					top.inElem = false
+				}
+			}
+		case '"':
+			end := stringEnd(s, i+1)
+			if len(stack) > 0 && stack[len(stack)-1].object && !stack[len(stack)-1].hasKey {
+				top := &stack[len(stack)-1]
+				top.key = pathKey(s[i:end])
+				top.hasKey = true
+			}
+			i = end - 1
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range stack {
+		if !f.object && f.inElem {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(f.index))
+			b.WriteByte(']')
+		} else if f.hasKey {
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(f.key)
+		}
+	}
+	return b.String()
+}
+
+// stringEnd returns the index after the closing quote of the string
+// starting at i, or len(s) if it isn't closed.
+func stringEnd(s []byte, i int) int {
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// pathKey returns the key of the quoted string s, which may be cut off.
+func pathKey(s []byte) string {
+	if bytes.IndexByte(s, '\\') < 0 {
+		return string(bytes.Trim(s, `"`))
+	}
+	if t, ok := UnquoteBytes(s); ok {
+		return string(t)
+	}
+	return string(s[1:])
+}
+
+// joinPath appends the path inner of a value to the path outer.
+func joinPath(outer string, inner string) string {
+	if outer == "" || inner == "" || inner[0] == '[' {
+		return outer + inner
+	}
+	return outer + "." + inner
+}
@@ -0,0 +1,77 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValuePath(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+	}{
+		{``, ""},
+		{`{`, ""},
+		{`{"a"`, "a"},
+		{`{"a":1,`, ""},
+		{`{"a":1,"b":tru`, "b"},
+		{`{"a":{"b":[1,2,{"c":"x,y]}"`, "a.b[2].c"},
+		{`{"a":{"b":[1,2,{"c":"x"}]},"d":`, "d"},
+		{`{"a":{"b":[1,2,{"c":"x"}]}`, "a"},
+		{`[[],[1,`, "[1]"},
+		{`[[],[1,2`, "[1][1]"},
+		{`[`, ""},
+		{`[{"id":1},{"id":"2`, "[1].id"},
+		{`{"k\"ey":{"é":`, `k"ey.é`},
+		{`{"a b":"c\"","d":[`, "d"},
+		{`{"a b":"c\"","d":[{`, "d[0]"},
+		{`{"a":1}`, ""},
+	}
+	for _, test := range tests {
+		path := valuePath([]byte(test.input))
+		if path != test.path {
+			t.Errorf("valuePath(%s): expected %q, got %q", test.input, test.path, path)
+		}
+	}
+}
+
+func TestWrapErrPath(t *testing.T) {
+	fs := NewFFLexer([]byte(`{"a":[{"b":"x"}],"c":1}`))
+	for fs.Scan() != FFTok_string || fs.Output.String() != "x" {
+	}
+	inner := NewFFLexer([]byte(`{"d":[true,nul]}`))
+	for inner.Scan() != FFTok_error {
+	}
+
+	err := fs.WrapErr(inner.WrapErr(errors.New("invalid value")))
+	le, ok := err.(*LexerError)
+	if !ok {
+		t.Fatalf("Expected a LexerError, got %T", err)
+	}
+	if le.Path() != "a[0].b.d[1]" || le.Offset() != 14 {
+		t.Fatalf("Unexpected location: %v", le)
+	}
+	if _, nested := le.Unwrap().(*LexerError); nested {
+		t.Fatalf("Expected the inner error to be unwrapped: %v", le)
+	}
+	if fs.WrapErr(err) != err {
+		t.Fatalf("Expected an error of the same lexer to be returned as it is")
+	}
+}
@@ -59,22 +59,39 @@ func (r *ffReader) Reset(d []byte) {
 // because this isn't counted for performance reasons,
 // it will iterate the buffer from the beginning, and should
 // only be used in error-paths.
+//
+// Both are 1-based and refer to the last byte read, the column counting
+// UTF-8 characters, like most editors do.
 func (r *ffReader) PosWithLine() (int, int) {
 	currentLine := 1
-	currentChar := 0
+	currentChar := 1
 
-	for i := 0; i < r.i; i++ {
+	// Start of the character containing the last byte read.
+	last := r.i - 1
+	for last > 0 && r.s[last]&0xC0 == 0x80 {
+		last--
+	}
+
+	for i := 0; i < last; i++ {
 		c := r.s[i]
-		currentChar++
 		if c == '\n' {
 			currentLine++
-			currentChar = 0
+			currentChar = 1
+		} else if c&0xC0 != 0x80 {
+			currentChar++
 		}
 	}
 
 	return currentLine, currentChar
 }
 
+// Path returns the path of the value containing the last byte read. Like
+// PosWithLine, it scans the buffer from the beginning, and should only be
+// used in error-paths.
+func (r *ffReader) Path() string {
+	return valuePath(r.s[:r.i])
+}
+
 func (r *ffReader) ReadByteNoWS() (byte, error) {
 	if r.i >= r.l {
 		return 0, io.EOF
@@ -71,3 +71,32 @@ func TestInvalidEscape(t *testing.T) {
 		t.Fatalf("expected SliceString escape decode error")
 	}
 }
+
+func TestPosWithLine(t *testing.T) {
+	input := []byte("{\n  \"ä\": 1,\r\n\t\"b\": x\n}")
+	for _, tc := range []struct {
+		pos  int
+		line int
+		char int
+	}{
+		{0, 1, 1},
+		{1, 1, 1},
+		{2, 1, 2},
+		{3, 2, 1},
+		{6, 2, 4},
+		{7, 2, 4},
+		{8, 2, 5},
+		{12, 2, 9},
+		{14, 2, 11},
+		{15, 3, 1},
+		{21, 3, 7},
+		{len(input), 4, 1},
+	} {
+		ffr := newffReader(input)
+		ffr.i = tc.pos
+		line, char := ffr.PosWithLine()
+		if line != tc.line || char != tc.char {
+			t.Fatalf("PosWithLine() at %d: expected %d:%d, got %d:%d", tc.pos, tc.line, tc.char, line, char)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var refTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// Refs tracks the pointers met while encoding or decoding a graph of
+// structs, by the JSON Pointer of their location in the document, so
+// that values shared by several pointers are only written once. Later
+// occurrences are written as {"$ref":"#/json/pointer"}, a reference to
+// the first one.
+type Refs struct {
+	path   []string
+	ptrs   map[interface{}]string
+	values map[string]interface{}
+	ref    string
+	hasRef bool
+}
+
+// NewRefs returns a Refs with root defined at the document root, "#".
+func NewRefs(root interface{}) *Refs {
+	r := &Refs{
+		ptrs:   make(map[interface{}]string),
+		values: make(map[string]interface{}),
+	}
+	r.Define(root)
+	return r
+}
+
+// Push descends into the object member name.
+func (r *Refs) Push(name string) {
+	r.path = append(r.path, refTokenEscaper.Replace(name))
+}
+
+// PushIndex descends into the array element i.
+func (r *Refs) PushIndex(i int) {
+	r.path = append(r.path, strconv.Itoa(i))
+}
+
+// Pop returns to the parent of the current location.
+func (r *Refs) Pop() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// Define records that the pointer p is written or read at the current
+// location.
+func (r *Refs) Define(p interface{}) {
+	pointer := r.pointer()
+	r.ptrs[p] = pointer
+	r.values[pointer] = p
+}
+
+// pointer returns the JSON Pointer of the current location.
+func (r *Refs) pointer() string {
+	if len(r.path) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(r.path, "/")
+}
+
+// Lookup returns the reference to the location p was first written at,
+// if it was defined before.
+func (r *Refs) Lookup(p interface{}) (string, bool) {
+	pointer, ok := r.ptrs[p]
+	if !ok {
+		return "", false
+	}
+	u := url.URL{Fragment: pointer}
+	return "#" + u.EscapedFragment(), true
+}
+
+// WriteRef writes the reference object for ref.
+func WriteRef(buf EncodingBuffer, ref string) {
+	buf.WriteString(`{"$ref":`)
+	WriteJsonString(buf, ref)
+	buf.WriteByte('}')
+}
+
+// SetRef records that the object just read was the reference ref.
+func (r *Refs) SetRef(ref string) {
+	r.ref = ref
+	r.hasRef = true
+}
+
+// TakeRef returns and clears the reference recorded by SetRef.
+func (r *Refs) TakeRef() (string, bool) {
+	ref, ok := r.ref, r.hasRef
+	r.ref, r.hasRef = "", false
+	return ref, ok
+}
+
+// Resolve returns the pointer defined at the location ref refers to.
+// Only locations read before and the objects containing the current
+// location can be resolved, not the current location itself: a
+// reference read there has no value of its own.
+func (r *Refs) Resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("ffjson: reference %q is not a JSON Pointer fragment", ref)
+	}
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("ffjson: invalid reference %q: %v", ref, err)
+	}
+	if pointer == r.pointer() {
+		return nil, fmt.Errorf("ffjson: reference %q refers to itself", ref)
+	}
+	p, ok := r.values[pointer]
+	if !ok {
+		return nil, fmt.Errorf("ffjson: reference %q does not refer to a value read before", ref)
+	}
+	return p, nil
+}
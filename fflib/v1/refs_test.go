@@ -0,0 +1,60 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestRefsPointer(t *testing.T) {
+	root, a, b := new(int), new(int), new(int)
+	r := NewRefs(root)
+	r.Push("a b/c~")
+	r.Define(a)
+	r.PushIndex(3)
+	r.Define(b)
+	r.Pop()
+	r.Pop()
+	r.Push("ref")
+
+	for _, test := range []struct {
+		p   *int
+		ref string
+	}{
+		{root, "#"},
+		{a, "#/a%20b~1c~0"},
+		{b, "#/a%20b~1c~0/3"},
+	} {
+		ref, ok := r.Lookup(test.p)
+		if !ok || ref != test.ref {
+			t.Errorf("Expected: %s\nGot: %s", test.ref, ref)
+		}
+		p, err := r.Resolve(ref)
+		if err != nil || p != test.p {
+			t.Errorf("Resolve(%s) = %v, %v", ref, p, err)
+		}
+	}
+
+	if _, err := r.Resolve("#/ref"); err == nil {
+		t.Errorf("expected an error for a reference to its own location")
+	}
+
+	if _, ok := r.Lookup(new(int)); ok {
+		t.Errorf("expected no reference to an unknown pointer")
+	}
+}
@@ -0,0 +1,108 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// registry holds the factories of RegisterType by discriminator value.
+var registry = struct {
+	sync.RWMutex
+	factories map[string]func() interface{}
+}{factories: map[string]func() interface{}{}}
+
+// RegisterType registers factory as the constructor of values whose
+// discriminator is name, for interface fields with the polymorphic
+// option. factory is called for each value decoded, and returns a new
+// pointer, or another value which decodes json. Registering a name twice,
+// or a nil factory, panics. Types are usually registered from init
+// functions, before anything is decoded.
+func RegisterType(name string, factory func() interface{}) {
+	if factory == nil {
+		panic("ffjson: RegisterType of " + name + " with a nil factory")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.factories[name]; ok {
+		panic("ffjson: RegisterType called twice for " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// UnmarshalRegistered decodes the object data into a new value of the
+// type registered for the value of its member key, and returns that
+// value. The member must be a string, and it is left to the decoder of
+// the registered type like any other member, so types usually ignore it
+// or have a field holding it. opts, which may be nil, are the options of
+// the decoder of the value, usually those of its lexer.
+func UnmarshalRegistered(data []byte, key string, opts *DecodeOptions) (interface{}, error) {
+	name, err := discriminator(data, key)
+	if err != nil {
+		return nil, err
+	}
+	registry.RLock()
+	factory, ok := registry.factories[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("ffjson: no type registered for %s %q", key, name)
+	}
+	v := factory()
+	err = unmarshalInto(data, opts, v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// discriminator returns the string member key of the object data.
+func discriminator(data []byte, key string) (string, error) {
+	fs := NewFFLexer(data)
+	tok := fs.Scan()
+	if tok != FFTok_left_bracket {
+		return "", fmt.Errorf("ffjson: wanted an object with a %s member, but got token: %v", key, tok)
+	}
+	tok = fs.Scan()
+	for tok == FFTok_string {
+		name := fs.Output.String()
+		if fs.Scan() != FFTok_colon {
+			break
+		}
+		tok = fs.Scan()
+		if name == key {
+			if tok != FFTok_string {
+				return "", fmt.Errorf("ffjson: %s member must be a string, but got token: %v", key, tok)
+			}
+			return fs.Output.String(), nil
+		}
+		err := fs.SkipField(tok)
+		if err != nil {
+			return "", err
+		}
+		tok = fs.Scan()
+		if tok == FFTok_comma {
+			tok = fs.Scan()
+		}
+	}
+	if tok == FFTok_right_bracket {
+		return "", fmt.Errorf("ffjson: object has no %s member", key)
+	}
+	return "", errors.New("ffjson: invalid object")
+}
@@ -0,0 +1,71 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+type registryPoint struct {
+	Kind string `json:"kind"`
+	X    int    `json:"x"`
+}
+
+func TestUnmarshalRegistered(t *testing.T) {
+	RegisterType("test-point", func() interface{} { return new(registryPoint) })
+	RegisterType("test-map", func() interface{} { return &map[string]interface{}{} })
+
+	v, err := UnmarshalRegistered([]byte(`{"x":{"kind":"nested"},"y":[1,"kind"],"kind":"test-point"}`), "kind", nil)
+	if err == nil {
+		t.Fatalf("Expected the object of x to fail decoding into registryPoint, got: %#v", v)
+	}
+
+	v, err = UnmarshalRegistered([]byte(`{"other":{"kind":"nested"},"kind":"test-point","x":2}`), "kind", nil)
+	if err != nil {
+		t.Fatalf("UnmarshalRegistered: %v", err)
+	}
+	if !reflect.DeepEqual(v, &registryPoint{Kind: "test-point", X: 2}) {
+		t.Fatalf("Unexpected value: %#v", v)
+	}
+
+	v, err = UnmarshalRegistered([]byte(`{"kind":"test-map","n":1}`), "kind", nil)
+	if err != nil {
+		t.Fatalf("UnmarshalRegistered: %v", err)
+	}
+	if !reflect.DeepEqual(v, &map[string]interface{}{"kind": "test-map", "n": 1.0}) {
+		t.Fatalf("Unexpected value: %#v", v)
+	}
+
+	for _, input := range []string{`{}`, `{"kind":null}`, `{"kind":"test-none"}`, `[]`, `{"a":1,}`, `{"a" 1}`} {
+		_, err := UnmarshalRegistered([]byte(input), "kind", nil)
+		if err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestRegisterTypeTwice(t *testing.T) {
+	RegisterType("test-twice", func() interface{} { return new(registryPoint) })
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected a panic registering a name twice")
+		}
+	}()
+	RegisterType("test-twice", func() interface{} { return new(registryPoint) })
+}
@@ -0,0 +1,81 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// PeekRoot returns the first token of the JSON document input, after
+// any whitespace: FFTok_left_bracket for an object, FFTok_left_brace for
+// an array, or the token of a scalar.
+func PeekRoot(input []byte) (FFTok, error) {
+	fs := NewFFLexer(input)
+	tok := fs.Scan()
+	if tok == FFTok_error || tok == FFTok_eof {
+		return tok, lexerTokError(fs)
+	}
+	return tok, nil
+}
+
+// ScanArray reads the elements of the array fs is in, after its "[",
+// calling elem with the first token of each element. elem must read the
+// rest of the element.
+func ScanArray(fs *FFLexer, elem func(tok FFTok) error) error {
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		return nil
+	}
+	for {
+		switch tok {
+		case FFTok_error, FFTok_eof:
+			return lexerTokError(fs)
+		case FFTok_right_brace, FFTok_right_bracket, FFTok_comma, FFTok_colon:
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted value, but got token: %v", tok))
+		}
+		err := elem(tok)
+		if err != nil {
+			return err
+		}
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_comma:
+			tok = fs.Scan()
+		case FFTok_right_brace:
+			return nil
+		case FFTok_error, FFTok_eof:
+			return lexerTokError(fs)
+		default:
+			return fs.WrapErr(fmt.Errorf("ffjson: wanted comma or end of array, but got token: %v", tok))
+		}
+	}
+}
+
+// ScanEnd checks that nothing but whitespace follows the root value read
+// by fs.
+func ScanEnd(fs *FFLexer) error {
+	tok := fs.Scan()
+	if tok == FFTok_eof {
+		return nil
+	}
+	if tok == FFTok_error {
+		return lexerTokError(fs)
+	}
+	return fs.WrapErr(fmt.Errorf("ffjson: unexpected token after the root value: %v", tok))
+}
@@ -0,0 +1,90 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestPeekRoot(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected FFTok
+	}{
+		{`{"a":1}`, FFTok_left_bracket},
+		{" \n\t[1]", FFTok_left_brace},
+		{`null`, FFTok_null},
+		{`"x"`, FFTok_string},
+	}
+
+	for _, test := range tests {
+		tok, err := PeekRoot([]byte(test.in))
+		if err != nil {
+			t.Errorf("PeekRoot(%q): %v", test.in, err)
+			continue
+		}
+		if tok != test.expected {
+			t.Errorf("PeekRoot(%q): expected %v, got %v", test.in, test.expected, tok)
+		}
+	}
+
+	for _, in := range []string{``, `  `} {
+		_, err := PeekRoot([]byte(in))
+		if err == nil {
+			t.Errorf("PeekRoot(%q): expected an error", in)
+		}
+	}
+}
+
+func TestScanArray(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected []FFTok
+		ok       bool
+	}{
+		{`[]`, nil, true},
+		{`[1, "a" ,null]`, []FFTok{FFTok_integer, FFTok_string, FFTok_null}, true},
+		{`[1,]`, []FFTok{FFTok_integer}, false},
+		{`[1 2]`, []FFTok{FFTok_integer}, false},
+		{`["a"`, []FFTok{FFTok_string}, false},
+		{`[,1]`, nil, false},
+	}
+
+	for _, test := range tests {
+		fs := NewFFLexer([]byte(test.in))
+		fs.Scan()
+		var toks []FFTok
+		err := ScanArray(fs, func(tok FFTok) error {
+			toks = append(toks, tok)
+			return nil
+		})
+		if (err == nil) != test.ok {
+			t.Errorf("ScanArray(%s): unexpected error %v", test.in, err)
+		}
+		if len(toks) != len(test.expected) {
+			t.Errorf("ScanArray(%s): expected elements %v, got %v", test.in, test.expected, toks)
+			continue
+		}
+		for i := range toks {
+			if toks[i] != test.expected[i] {
+				t.Errorf("ScanArray(%s): expected elements %v, got %v", test.in, test.expected, toks)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// RoundMode selects how UnscaleInt and UnscaleUint round a value that is
+// not a multiple of the scale.
+type RoundMode int
+
+const (
+	// RoundNearest rounds to the nearest integer, with halfway values
+	// rounded away from zero.
+	RoundNearest RoundMode = iota
+	// RoundTrunc rounds towards zero.
+	RoundTrunc
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundCeil rounds towards positive infinity.
+	RoundCeil
+	// RoundExact returns an error instead of rounding.
+	RoundExact
+)
+
+// ScaleInt returns v multiplied by scale, which must be positive. An error
+// is returned if the result doesn't fit into an int64.
+func ScaleInt(v int64, scale int64) (int64, error) {
+	r := v * scale
+	if r/scale != v {
+		return 0, fmt.Errorf("ffjson: %d scaled by %d overflows int64", v, scale)
+	}
+	return r, nil
+}
+
+// ScaleUint returns v multiplied by scale, which must be positive. An error
+// is returned if the result doesn't fit into an uint64.
+func ScaleUint(v uint64, scale uint64) (uint64, error) {
+	r := v * scale
+	if r/scale != v {
+		return 0, fmt.Errorf("ffjson: %d scaled by %d overflows uint64", v, scale)
+	}
+	return r, nil
+}
+
+// UnscaleInt returns v divided by scale, which must be positive, rounded
+// according to mode.
+func UnscaleInt(v int64, scale int64, mode RoundMode) (int64, error) {
+	q, r := v/scale, v%scale
+	if r == 0 {
+		return q, nil
+	}
+
+	switch mode {
+	case RoundTrunc:
+	case RoundFloor:
+		if v < 0 {
+			q--
+		}
+	case RoundCeil:
+		if v > 0 {
+			q++
+		}
+	case RoundExact:
+		return 0, fmt.Errorf("ffjson: %d is not a multiple of %d", v, scale)
+	default:
+		if r < 0 {
+			// |r| < scale, so this can't overflow.
+			if -r >= scale+r {
+				q--
+			}
+		} else if r >= scale-r {
+			q++
+		}
+	}
+	return q, nil
+}
+
+// UnscaleUint returns v divided by scale, which must be positive, rounded
+// according to mode.
+func UnscaleUint(v uint64, scale uint64, mode RoundMode) (uint64, error) {
+	q, r := v/scale, v%scale
+	if r == 0 {
+		return q, nil
+	}
+
+	switch mode {
+	case RoundTrunc, RoundFloor:
+	case RoundExact:
+		return 0, fmt.Errorf("ffjson: %d is not a multiple of %d", v, scale)
+	case RoundCeil:
+		q++
+	default:
+		if r >= scale-r {
+			q++
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,101 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnscaleInt(t *testing.T) {
+	for _, tc := range []struct {
+		v        int64
+		mode     RoundMode
+		expected int64
+	}{
+		{1500, RoundNearest, 2},
+		{1499, RoundNearest, 1},
+		{-1500, RoundNearest, -2},
+		{-1499, RoundNearest, -1},
+		{1999, RoundTrunc, 1},
+		{-1999, RoundTrunc, -1},
+		{1999, RoundFloor, 1},
+		{-1001, RoundFloor, -2},
+		{1001, RoundCeil, 2},
+		{-1999, RoundCeil, -1},
+		{-3000, RoundExact, -3},
+		{math.MaxInt64, RoundNearest, 9223372036854776},
+		{math.MinInt64, RoundNearest, -9223372036854776},
+	} {
+		got, err := UnscaleInt(tc.v, 1000, tc.mode)
+		if err != nil {
+			t.Fatalf("UnscaleInt(%d, %d): unexpected error: %v", tc.v, tc.mode, err)
+		}
+		if got != tc.expected {
+			t.Fatalf("UnscaleInt(%d, %d): Expected: %d\nGot: %d", tc.v, tc.mode, tc.expected, got)
+		}
+	}
+
+	_, err := UnscaleInt(1001, 1000, RoundExact)
+	if err == nil {
+		t.Fatalf("Expected error for inexact value")
+	}
+}
+
+func TestUnscaleUint(t *testing.T) {
+	for _, tc := range []struct {
+		v        uint64
+		mode     RoundMode
+		expected uint64
+	}{
+		{1500, RoundNearest, 2},
+		{1499, RoundNearest, 1},
+		{1999, RoundTrunc, 1},
+		{1999, RoundFloor, 1},
+		{1001, RoundCeil, 2},
+		{3000, RoundExact, 3},
+		{math.MaxUint64, RoundNearest, 18446744073709552},
+	} {
+		got, err := UnscaleUint(tc.v, 1000, tc.mode)
+		if err != nil {
+			t.Fatalf("UnscaleUint(%d, %d): unexpected error: %v", tc.v, tc.mode, err)
+		}
+		if got != tc.expected {
+			t.Fatalf("UnscaleUint(%d, %d): Expected: %d\nGot: %d", tc.v, tc.mode, tc.expected, got)
+		}
+	}
+}
+
+func TestScaleOverflow(t *testing.T) {
+	v, err := ScaleInt(-9223372036854775, 1000)
+	if err != nil || v != -9223372036854775000 {
+		t.Fatalf("Unexpected result: %d, %v", v, err)
+	}
+	_, err = ScaleInt(9223372036854776, 1000)
+	if err == nil {
+		t.Fatalf("Expected overflow error")
+	}
+	_, err = ScaleInt(math.MinInt64, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = ScaleUint(18446744073709552, 1000)
+	if err == nil {
+		t.Fatalf("Expected overflow error")
+	}
+}
@@ -0,0 +1,60 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// SSE returns data framed as a Server-Sent Event named event, as in
+// "event: <event>\ndata: <data>\n\n". Each line of data is written in a
+// data field of its own, so clients join them with newlines again. An
+// empty event is written without an event field, which clients receive
+// as a "message" event.
+func SSE(event string, data []byte) ([]byte, error) {
+	if strings.ContainsAny(event, "\r\n") {
+		return nil, fmt.Errorf("ffjson: SSE event name %q contains a line break", event)
+	}
+
+	var buf Buffer
+	if event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(event)
+		buf.WriteByte('\n')
+	}
+	for {
+		// Lines end with CRLF, LF or CR.
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			break
+		}
+		buf.WriteString("data: ")
+		buf.Write(data[:i])
+		buf.WriteByte('\n')
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		data = data[i+1:]
+	}
+	buf.WriteString("data: ")
+	buf.Write(data)
+	buf.WriteString("\n\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestSSE(t *testing.T) {
+	tests := []struct {
+		event    string
+		data     string
+		expected string
+	}{
+		{"update", `{"a":1}`, "event: update\ndata: {\"a\":1}\n\n"},
+		{"", `{"a":1}`, "data: {\"a\":1}\n\n"},
+		{"e", "{\n  \"a\": 1\n}", "event: e\ndata: {\ndata:   \"a\": 1\ndata: }\n\n"},
+		{"e", "a\r\nb\rc\n", "event: e\ndata: a\ndata: b\ndata: c\ndata: \n\n"},
+		{"e", "", "event: e\ndata: \n\n"},
+	}
+
+	for _, test := range tests {
+		out, err := SSE(test.event, []byte(test.data))
+		if err != nil {
+			t.Errorf("SSE(%q, %q): %v", test.event, test.data, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("SSE(%q, %q)\nExpected: %q\nGot: %q", test.event, test.data, test.expected, out)
+		}
+	}
+}
+
+func TestSSEInvalidEvent(t *testing.T) {
+	for _, event := range []string{"a\nb", "a\r"} {
+		_, err := SSE(event, []byte(`{}`))
+		if err == nil {
+			t.Errorf("SSE(%q): expected an error", event)
+		}
+	}
+}
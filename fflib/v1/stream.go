@@ -0,0 +1,276 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// StreamChunkSize is the chunk size of the ChunkedWriter used by the
+// generated EncodeJSON methods.
+const StreamChunkSize = 4096
+
+// ReadValue reads a single JSON value from r into buf, skipping the
+// whitespace before it, so it can be decoded from memory. The value may
+// span any number of reads.
+//
+// Objects, arrays, strings and literals end at their last byte, so r is
+// left right after the value and further values of a stream can be read
+// from it. A number ends at the byte after it, which is unread if r is an
+// io.ByteScanner, and lost otherwise.
+//
+// Values are only delimited, not validated: decoding the result reports
+// syntax errors. Readers which don't implement io.ByteReader, like
+// files and network connections, are read one byte at a time; wrap them
+// in a bufio.Reader, and keep using the bufio.Reader for the rest of
+// the stream.
+func ReadValue(r io.Reader, buf *Buffer) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &byteReader{r: r}
+	}
+
+	c, err := readByteNoWS(br)
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(c)
+
+	switch {
+	case c == '{' || c == '[':
+		return readNested(br, buf)
+	case c == '"':
+		return readString(br, buf)
+	case c == 't' || c == 'f' || c == 'n':
+		return readLiteral(br, buf, c)
+	}
+	return readNumber(br, buf)
+}
+
+// ArrayReader reads the elements of a JSON array from a reader one at a
+// time, so arrays of any length can be decoded with memory for a single
+// element.
+type ArrayReader struct {
+	br      byteScanReader
+	started bool
+	done    bool
+}
+
+type byteScanReader interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// NewArrayReader returns an ArrayReader reading the array at the start
+// of r. Readers which aren't an io.ByteScanner are wrapped in a
+// bufio.Reader, which may read past the end of the array.
+func NewArrayReader(r io.Reader) *ArrayReader {
+	br, ok := r.(byteScanReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &ArrayReader{br: br}
+}
+
+// Next reads the next element of the array into buf, and returns io.EOF
+// after the last one, once the closing bracket is read. Elements are
+// only delimited, as with ReadValue.
+func (a *ArrayReader) Next(buf *Buffer) error {
+	if a.done {
+		return io.EOF
+	}
+
+	if !a.started {
+		c, err := readByteNoWS(a.br)
+		if err != nil {
+			return err
+		}
+		if c != '[' {
+			return fmt.Errorf("ffjson: expected an array, found %q", c)
+		}
+		a.started = true
+
+		c, err = readByteNoWS(a.br)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if c == ']' {
+			a.done = true
+			return io.EOF
+		}
+		err = a.br.UnreadByte()
+		if err != nil {
+			return err
+		}
+		return a.readElement(buf)
+	}
+
+	c, err := readByteNoWS(a.br)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	switch c {
+	case ']':
+		a.done = true
+		return io.EOF
+	case ',':
+		return a.readElement(buf)
+	}
+	return fmt.Errorf("ffjson: expected , or ] after an array element, found %q", c)
+}
+
+func (a *ArrayReader) readElement(buf *Buffer) error {
+	err := ReadValue(a.br, buf)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func readByteNoWS(br io.ByteReader) (byte, error) {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c, nil
+		}
+	}
+}
+
+// readByte reads the next byte of a value, which must not end before it.
+func readByte(br io.ByteReader) (byte, error) {
+	c, err := br.ReadByte()
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return c, err
+}
+
+// readNested reads the rest of an object or array, after its first byte.
+func readNested(br io.ByteReader, buf *Buffer) error {
+	depth := 1
+	for depth > 0 {
+		c, err := readByte(br)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(c)
+		switch c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"':
+			err = readString(br, buf)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readString reads the rest of a string, after its opening quote.
+func readString(br io.ByteReader, buf *Buffer) error {
+	for {
+		c, err := readByte(br)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(c)
+		switch c {
+		case '"':
+			return nil
+		case '\\':
+			c, err = readByte(br)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// readLiteral reads the rest of true, false or null.
+func readLiteral(br io.ByteReader, buf *Buffer, first byte) error {
+	n := 3
+	if first == 'f' {
+		n = 4
+	}
+	for i := 0; i < n; i++ {
+		c, err := readByte(br)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(c)
+	}
+	return nil
+}
+
+// readNumber reads the rest of a number, or of an invalid value, up to
+// the next delimiter or the end of r.
+func readNumber(br io.ByteReader, buf *Buffer) error {
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r', ',', ']', '}', '{', '[', '"':
+			if bs, ok := br.(io.ByteScanner); ok {
+				return bs.UnreadByte()
+			}
+			return nil
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// byteReader reads one byte at a time from an io.Reader.
+type byteReader struct {
+	r io.Reader
+	b [1]byte
+}
+
+// ReadByte gives up after 100 empty reads without an error, like
+// bufio.Reader.
+func (b *byteReader) ReadByte() (byte, error) {
+	for i := 0; i < 100; i++ {
+		n, err := b.r.Read(b.b[:])
+		if n == 1 {
+			return b.b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, io.ErrNoProgress
+}
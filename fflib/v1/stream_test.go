@@ -0,0 +1,151 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		rest     string
+	}{
+		{`{"a":1}`, `{"a":1}`, ``},
+		{" \n\t{\"a\":[1,{\"b\":\"}]\"}]} {}", `{"a":[1,{"b":"}]"}]}`, ` {}`},
+		{`["\"]\\",2]3`, `["\"]\\",2]`, `3`},
+		{`"x\"y" 1`, `"x\"y"`, ` 1`},
+		{`null,`, `null`, `,`},
+		{`true]`, `true`, `]`},
+		{`false`, `false`, ``},
+		{`-1.5e3 {}`, `-1.5e3`, ` {}`},
+		{`12}`, `12`, `}`},
+		{`7`, `7`, ``},
+	}
+
+	for _, test := range tests {
+		// One byte per read, through a bufio.Reader, which is an
+		// io.ByteScanner.
+		r := bufio.NewReader(iotest.OneByteReader(strings.NewReader(test.input)))
+		var buf Buffer
+		err := ReadValue(r, &buf)
+		if err != nil {
+			t.Errorf("ReadValue(%q): %v", test.input, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("ReadValue(%q)\nExpected: %s\nGot: %s", test.input, test.expected, buf.String())
+		}
+		rest, _ := io.ReadAll(r)
+		if string(rest) != test.rest {
+			t.Errorf("ReadValue(%q): expected %q left, got %q", test.input, test.rest, rest)
+		}
+	}
+}
+
+func TestReadValueStream(t *testing.T) {
+	r := iotest.HalfReader(strings.NewReader(`{"a":1} [2,3]` + "\n" + `"s"`))
+	for _, expected := range []string{`{"a":1}`, `[2,3]`, `"s"`} {
+		var buf Buffer
+		err := ReadValue(r, &buf)
+		if err != nil {
+			t.Fatalf("ReadValue: %v", err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("Expected: %s\nGot: %s", expected, buf.String())
+		}
+	}
+	var buf Buffer
+	err := ReadValue(r, &buf)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF after the last value, got %v", err)
+	}
+}
+
+func TestReadValueTruncated(t *testing.T) {
+	for _, input := range []string{`{"a":1`, `["x`, `"a\`, `tr`, `{"a":"}"`} {
+		var buf Buffer
+		err := ReadValue(strings.NewReader(input), &buf)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("ReadValue(%q): expected io.ErrUnexpectedEOF, got %v", input, err)
+		}
+	}
+
+	var buf Buffer
+	err := ReadValue(iotest.ErrReader(io.ErrClosedPipe), &buf)
+	if err != io.ErrClosedPipe {
+		t.Fatalf("Expected the error of the reader, got %v", err)
+	}
+}
+
+func TestArrayReader(t *testing.T) {
+	r := bufio.NewReader(iotest.OneByteReader(strings.NewReader(` [ {"a":[1,2]} , "x,]" ,3,null ] {}`)))
+	a := NewArrayReader(r)
+	for _, expected := range []string{`{"a":[1,2]}`, `"x,]"`, `3`, `null`} {
+		var buf Buffer
+		err := a.Next(&buf)
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if buf.String() != expected {
+			t.Fatalf("Expected: %s\nGot: %s", expected, buf.String())
+		}
+	}
+	var buf Buffer
+	if err := a.Next(&buf); err != io.EOF {
+		t.Fatalf("Expected io.EOF after the last element, got %v", err)
+	}
+	rest, _ := io.ReadAll(r)
+	if string(rest) != ` {}` {
+		t.Fatalf("Expected the value after the array to be left, got %q", rest)
+	}
+}
+
+func TestArrayReaderEmpty(t *testing.T) {
+	a := NewArrayReader(strings.NewReader(`[ ]`))
+	var buf Buffer
+	if err := a.Next(&buf); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestArrayReaderInvalid(t *testing.T) {
+	for _, input := range []string{`{}`, `[1 2]`} {
+		a := NewArrayReader(strings.NewReader(input))
+		var err error
+		for err == nil {
+			var buf Buffer
+			err = a.Next(&buf)
+		}
+		if err == io.EOF {
+			t.Errorf("Next(%q): expected an error", input)
+		}
+	}
+
+	a := NewArrayReader(strings.NewReader(`[1,`))
+	var buf Buffer
+	a.Next(&buf)
+	if err := a.Next(&buf); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// streamChunkSize is the size of the reads of WriteJsonReader.
+const streamChunkSize = 4096
+
+// WriteJsonReader writes what is read from r until io.EOF as a JSON
+// string, escaped as by WriteJson, without holding more than a chunk of
+// it in memory besides buf. UTF-8 sequences split between two reads are
+// kept until the next read, so the output is the same as WriteJson's of
+// all the content at once. Errors of r other than io.EOF are returned,
+// leaving a partial string in buf.
+func WriteJsonReader(buf JsonStringWriter, r io.Reader) error {
+	var chunk [streamChunkSize]byte
+	n := 0
+
+	buf.WriteByte('"')
+	for {
+		m, err := r.Read(chunk[n:])
+		n += m
+		end := n
+		if err == nil {
+			end = fullRunes(chunk[:n])
+		}
+		writeJsonChars(buf, chunk[:end])
+		n = copy(chunk[:], chunk[end:n])
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// fullRunes returns the length of p without an incomplete UTF-8 sequence
+// at its end.
+func fullRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i > len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(p)
+}
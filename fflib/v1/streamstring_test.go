@@ -0,0 +1,70 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWriteJsonReader(t *testing.T) {
+	tests := []string{
+		"",
+		"plain",
+		"quote \" backslash \\ newline \n tab \t <&>",
+		"é ü 日本語 \U0001F600    ",
+		"invalid \xff\xfe utf-8 \xe6\x97",
+		strings.Repeat("日本語 \"\x01", streamChunkSize),
+	}
+
+	for _, s := range tests {
+		var expected Buffer
+		WriteJson(&expected, []byte(s))
+
+		readers := map[string]io.Reader{
+			"whole":    strings.NewReader(s),
+			"one byte": iotest.OneByteReader(strings.NewReader(s)),
+			"half":     iotest.HalfReader(strings.NewReader(s)),
+			"data err": iotest.DataErrReader(strings.NewReader(s)),
+		}
+		for name, r := range readers {
+			var buf Buffer
+			err := WriteJsonReader(&buf, r)
+			if err != nil {
+				t.Errorf("WriteJsonReader(%s, %.20q): %v", name, s, err)
+				continue
+			}
+			if !bytes.Equal(buf.Bytes(), expected.Bytes()) {
+				t.Errorf("WriteJsonReader(%s, %.20q)\nExpected: %.60s\nGot: %.60s", name, s, expected.Bytes(), buf.Bytes())
+			}
+		}
+	}
+}
+
+func TestWriteJsonReaderError(t *testing.T) {
+	fail := errors.New("fail")
+	var buf Buffer
+	err := WriteJsonReader(&buf, io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(fail)))
+	if err != fail {
+		t.Fatalf("expected %v, got %v", fail, err)
+	}
+}
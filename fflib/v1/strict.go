@@ -0,0 +1,42 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"sync/atomic"
+)
+
+var disallowUnknownFields int32
+
+// SetDisallowUnknownFields makes the generated decoders of all structs
+// fail on keys not matching a field if on is set, as the decoders of
+// structs with ffjson: strict always do. It can be switched while
+// decoding from other goroutines.
+func SetDisallowUnknownFields(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&disallowUnknownFields, v)
+}
+
+// DisallowUnknownFields returns whether the generated decoders fail on
+// unknown keys.
+func DisallowUnknownFields() bool {
+	return atomic.LoadInt32(&disallowUnknownFields) != 0
+}
@@ -0,0 +1,53 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+)
+
+var useNumber int32
+
+// SetUseNumber makes the generated decoders of all structs store numbers
+// in interface{} values as json.Number instead of float64 if on is set,
+// as the decoders generated with -use-number always do. It can be
+// switched while decoding from other goroutines.
+func SetUseNumber(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&useNumber, v)
+}
+
+// UseNumber returns whether the generated decoders store numbers in
+// interface{} values as json.Number.
+func UseNumber() bool {
+	return atomic.LoadInt32(&useNumber) != 0
+}
+
+// UnmarshalUseNumber decodes the value data with encoding/json into v,
+// storing numbers in interface{} values as json.Number. data holds a
+// single value, as captured by the lexer; anything after it is ignored.
+func UnmarshalUseNumber(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
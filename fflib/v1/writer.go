@@ -0,0 +1,161 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"io"
+)
+
+var errInvalidWrite = errors.New("ffjson: invalid write count")
+
+// ChunkedWriter is an EncodingBuffer writing its contents to an
+// io.Writer whenever more than a chunk is buffered, so encoding a large
+// value needs memory for about one chunk instead of the whole output.
+// The last byte written is held back, as generated code may rewind a
+// trailing comma.
+//
+// Errors of the underlying writer are sticky: once a write failed, all
+// further output is dropped and the error is returned by Flush.
+type ChunkedWriter struct {
+	buf   Buffer
+	w     io.Writer
+	chunk int
+	err   error
+}
+
+// NewChunkedWriter returns a ChunkedWriter writing to w in chunks of at
+// least chunk bytes. With a chunk of 0 or less, nothing is written
+// before Flush.
+func NewChunkedWriter(w io.Writer, chunk int) *ChunkedWriter {
+	return &ChunkedWriter{w: w, chunk: chunk}
+}
+
+// Reset discards the buffered output and the error of a failed write,
+// so the ChunkedWriter can be reused.
+func (c *ChunkedWriter) Reset() {
+	c.buf.Reset()
+	c.err = nil
+}
+
+// Flush writes all buffered output.
+func (c *ChunkedWriter) Flush() error {
+	c.write(c.buf.Len())
+	return c.err
+}
+
+func (c *ChunkedWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.buf.Write(p)
+	c.flushChunk()
+	return len(p), nil
+}
+
+func (c *ChunkedWriter) WriteByte(b byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.buf.WriteByte(b)
+	c.flushChunk()
+	return nil
+}
+
+func (c *ChunkedWriter) WriteString(s string) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.buf.WriteString(s)
+	c.flushChunk()
+	return len(s), nil
+}
+
+// WriteTo writes the buffered output not yet written to the underlying
+// writer to w.
+func (c *ChunkedWriter) WriteTo(w io.Writer) (int64, error) {
+	return c.buf.WriteTo(w)
+}
+
+// Truncate discards all but the first n buffered bytes.
+func (c *ChunkedWriter) Truncate(n int) {
+	c.buf.Truncate(n)
+}
+
+func (c *ChunkedWriter) Grow(n int) {
+	c.buf.Grow(n)
+}
+
+// Rewind removes the last n bytes, which must not have been written to
+// the underlying writer yet.
+func (c *ChunkedWriter) Rewind(n int) error {
+	if n > c.buf.Len() {
+		return errors.New("ffjson: cannot rewind output already written")
+	}
+	return c.buf.Rewind(n)
+}
+
+func (c *ChunkedWriter) Encode(v interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	err := c.buf.Encode(v)
+	c.flushChunk()
+	return err
+}
+
+// flushChunk writes all but the last buffered byte once more than a
+// chunk is buffered.
+func (c *ChunkedWriter) flushChunk() {
+	if c.chunk > 0 && c.buf.Len() > c.chunk {
+		c.write(c.buf.Len() - 1)
+	}
+}
+
+// write writes the first n buffered bytes, dropping the buffer if the
+// underlying writer fails.
+func (c *ChunkedWriter) write(n int) {
+	if c.err != nil {
+		return
+	}
+	err := WriteFull(c.w, c.buf.Next(n))
+	if err != nil {
+		c.err = err
+		c.buf.Reset()
+	}
+}
+
+// WriteFull writes all of p to w. Unlike a single call to w.Write, it
+// retries writing the remainder after a short write, as long as the
+// writer makes progress.
+func WriteFull(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if n < 0 || n > len(p) {
+			return errInvalidWrite
+		}
+		p = p[n:]
+		if err != nil && err != io.ErrShortWrite {
+			return err
+		}
+		if n == 0 && len(p) > 0 {
+			return io.ErrShortWrite
+		}
+	}
+	return nil
+}
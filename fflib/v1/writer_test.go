@@ -0,0 +1,85 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+var _ EncodingBuffer = (*ChunkedWriter)(nil)
+
+// oneByteWriter writes a single byte per call.
+type oneByteWriter struct {
+	bytes.Buffer
+	zero bool
+}
+
+func (w *oneByteWriter) Write(p []byte) (int, error) {
+	if w.zero || len(p) == 0 {
+		return 0, io.ErrShortWrite
+	}
+	w.Buffer.WriteByte(p[0])
+	if len(p) > 1 {
+		return 1, io.ErrShortWrite
+	}
+	return 1, nil
+}
+
+func TestWriteFull(t *testing.T) {
+	w := &oneByteWriter{}
+	err := WriteFull(w, []byte("hello"))
+	if err != nil {
+		t.Fatalf("WriteFull: %v", err)
+	}
+	if w.String() != "hello" {
+		t.Fatalf("Expected: hello\nGot: %s", w.String())
+	}
+
+	w = &oneByteWriter{zero: true}
+	err = WriteFull(w, []byte("hello"))
+	if err != io.ErrShortWrite {
+		t.Fatalf("expected io.ErrShortWrite, got %v", err)
+	}
+}
+
+func TestChunkedWriterRewind(t *testing.T) {
+	var out bytes.Buffer
+	c := NewChunkedWriter(&out, 4)
+	c.WriteString("[1,2,3,")
+	if out.String() != "[1,2,3" {
+		t.Fatalf("expected all but the last byte to be written, got %q", out.String())
+	}
+	err := c.Rewind(1)
+	if err != nil {
+		t.Fatalf("Rewind: %v", err)
+	}
+	err = c.Rewind(1)
+	if err == nil {
+		t.Fatalf("expected an error rewinding written output")
+	}
+	c.WriteByte(']')
+	err = c.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if out.String() != "[1,2,3]" {
+		t.Fatalf("Expected: [1,2,3]\nGot: %s", out.String())
+	}
+}
@@ -18,42 +18,397 @@
 package generator
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 )
 
+// Options are the settings of code generation shared by all the files
+// generated by one run.
+type Options struct {
+	// GoCmd is the path to the go command.
+	GoCmd string
+	// ImportName overrides the import path of the inputs.
+	ImportName string
+	// ForceRegenerate regenerates files even if the hash of their inputs
+	// is unchanged.
+	ForceRegenerate bool
+	// ResetFields resets the fields missing in the JSON when decoding.
+	ResetFields bool
+	// Tags are the comma-separated build tags to compile the package
+	// with.
+	Tags string
+	// Static takes the types from the type checked source of the package
+	// instead of an inception program.
+	Static bool
+}
+
+// GenerateFiles generates the code of the structs of inputPath into
+// outputPath.
 func GenerateFiles(goCmd string, inputPath string, outputPath string, importName string, forceRegenerate bool, resetFields bool) error {
+	return GenerateFile(inputPath, outputPath, Options{
+		GoCmd:           goCmd,
+		ImportName:      importName,
+		ForceRegenerate: forceRegenerate,
+		ResetFields:     resetFields,
+	})
+}
+
+// GenerateFile generates the code of the structs of inputPath into
+// outputPath with opts.
+func GenerateFile(inputPath string, outputPath string, opts Options) error {
+	return generateFiles([]Job{{InputPath: inputPath, OutputPath: outputPath}}, opts)[0]
+}
+
+// generateFiles generates the jobs, input files of one package, and
+// returns their errors. The files which aren't up to date share a single
+// inception program, so the package is built once for all of them.
+func generateFiles(jobs []Job, opts Options) []error {
+	errs := make([]error, len(jobs))
+	im := NewInceptionMain(opts.GoCmd, opts.ResetFields, opts.Tags)
+	var idx []int
+	for n, job := range jobs {
+		err := checkBuildTags(job.InputPath, opts.Tags)
+		if err != nil {
+			errs[n] = err
+			continue
+		}
 
-	if _, StatErr := os.Stat(outputPath); !os.IsNotExist(StatErr) {
-		inputFileInfo, inputFileErr := os.Stat(inputPath)
-		outputFileInfo, outputFileErr := os.Stat(outputPath)
+		packageName, structs, err := ExtractStructs(job.InputPath)
+		if err != nil {
+			errs[n] = err
+			continue
+		}
 
-		if nil == outputFileErr && nil == inputFileErr {
-			if !forceRegenerate && inputFileInfo.ModTime().Before(outputFileInfo.ModTime()) {
-				fmt.Println("File " + outputPath + " already exists.")
+		sources := packageSources(job.InputPath, job.OutputPath, opts.Tags)
+		hash, err := inputsHash(sources, structs, opts.ImportName, opts.ResetFields, opts.Tags, opts.Static)
+		if err != nil {
+			errs[n] = err
+			continue
+		}
+		if !opts.ForceRegenerate && isUpToDate(sources, job.OutputPath, hash) {
+			fmt.Println("File " + job.OutputPath + " already exists.")
+			continue
+		}
 
-				return nil
+		if opts.Static {
+			err := generateStatic(opts.GoCmd, job.InputPath, job.OutputPath, packageName, structs, opts.ImportName, opts.ResetFields, opts.Tags, hash)
+			if _, ok := err.(*notStaticError); !ok {
+				errs[n] = err
+				continue
 			}
+			warnNotStatic(job.InputPath, err)
 		}
+		im.AddFile(job.InputPath, getExposePath(job.InputPath), job.OutputPath, packageName, structs, hash)
+		idx = append(idx, n)
+	}
+	if len(idx) == 0 {
+		return errs
 	}
 
-	packageName, structs, err := ExtractStructs(inputPath)
+	fileErrs, err := runInception(im, opts.ImportName)
+	if err != nil && len(idx) > 1 {
+		// A file breaking the shared program, like an expose file which
+		// doesn't compile, only fails on its own.
+		fileErrs = make([]error, len(im.files))
+		for k, f := range im.files {
+			single := NewInceptionMain(opts.GoCmd, opts.ResetFields, opts.Tags)
+			single.AddFile(f.inputPath, f.exposePath, f.outputPath, f.packageName, f.structs, f.hash)
+			singleErrs, err := runInception(single, opts.ImportName)
+			if err == nil {
+				err = singleErrs[0]
+			}
+			fileErrs[k] = err
+		}
+		err = nil
+	}
+	for k, n := range idx {
+		if err != nil {
+			errs[n] = err
+		} else {
+			errs[n] = fileErrs[k]
+		}
+	}
+	return errs
+}
+
+// ErrNoStructs is returned by GeneratePackage for packages without
+// structs to generate code for, which patterns like ./... may match.
+var ErrNoStructs = errors.New("no structs to generate code for")
+
+// GeneratePackage generates the code of the structs of all files of the
+// package in dir into the single file outputPath. Files with build
+// constraints are left out, as the generated file is built with all of
+// the others.
+func GeneratePackage(dir string, outputPath string, opts Options) error {
+	inputs, err := packageInputs(dir, outputPath, opts.Tags)
 	if err != nil {
 		return err
 	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("%s: no files to generate code for", dir)
+	}
 
-	im := NewInceptionMain(goCmd, inputPath, outputPath, resetFields)
+	var packageName string
+	var structs []*StructInfo
+	for _, inputPath := range inputs {
+		name, st, err := ExtractStructs(inputPath)
+		if err != nil {
+			return err
+		}
+		packageName = name
+		structs = append(structs, st...)
+	}
+	if len(structs) == 0 {
+		return ErrNoStructs
+	}
 
-	err = im.Generate(packageName, structs, importName)
+	sources := packageSources(inputs[0], outputPath, opts.Tags)
+	hash, err := inputsHash(sources, structs, opts.ImportName, opts.ResetFields, opts.Tags, opts.Static)
 	if err != nil {
-		return errors.New(fmt.Sprintf("error=%v path=%q", err, im.TempMainPath))
+		return err
+	}
+	if !opts.ForceRegenerate && isUpToDate(sources, outputPath, hash) {
+		fmt.Println("File " + outputPath + " already exists.")
+
+		return nil
 	}
 
-	err = im.Run()
+	exposePath := strings.TrimSuffix(outputPath, ".go") + "_expose.go"
+	return generate(opts.GoCmd, inputs[0], exposePath, outputPath, packageName, structs, opts.ImportName, opts.ResetFields, opts.Tags, opts.Static, hash)
+}
+
+// generate writes the code of structs, declared in the package of
+// inputPath, to outputPath, recording hash, the hash of the inputs, in
+// its header.
+func generate(goCmd string, inputPath string, exposePath string, outputPath string, packageName string, structs []*StructInfo, importName string, resetFields bool, tags string, static bool, hash string) error {
+	if static {
+		err := generateStatic(goCmd, inputPath, outputPath, packageName, structs, importName, resetFields, tags, hash)
+		if _, ok := err.(*notStaticError); !ok {
+			return err
+		}
+		warnNotStatic(inputPath, err)
+	}
+
+	im := NewInceptionMain(goCmd, resetFields, tags)
+	im.AddFile(inputPath, exposePath, outputPath, packageName, structs, hash)
+	errs, err := runInception(im, importName)
 	if err != nil {
 		return err
 	}
+	return errs[0]
+}
+
+// runInception generates and runs im, returning the error of each of
+// its files, or the error of generating or running the program.
+func runInception(im *InceptionMain, importName string) ([]error, error) {
+	err := im.Generate(importName)
+	if err != nil {
+		im.Cleanup()
+		return nil, errors.New(fmt.Sprintf("error=%v path=%q", err, im.TempMainPath))
+	}
+
+	return im.Run()
+}
+
+// isUpToDate reports whether outputPath was generated from inputs with
+// the hash hash, or, for files generated by older versions without a
+// hash, whether outputPath is newer than all inputPaths.
+func isUpToDate(inputPaths []string, outputPath string, hash string) bool {
+	if h := outputHash(outputPath); h != "" {
+		return h == hash
+	}
+	outputFileInfo, err := os.Stat(outputPath)
+	if err != nil {
+		return false
+	}
+	for _, inputPath := range inputPaths {
+		inputFileInfo, err := os.Stat(inputPath)
+		if err != nil || !inputFileInfo.ModTime().Before(outputFileInfo.ModTime()) {
+			return false
+		}
+	}
+	return true
+}
+
+// packageInputs returns the files of the package in dir to generate
+// outputPath from.
+func packageInputs(dir string, outputPath string, tags string) ([]string, error) {
+	ctx := build.Default
+	ctx.BuildTags = splitTags(tags)
+	bp, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var inputs []string
+	for _, name := range bp.GoFiles {
+		path := filepath.Join(dir, name)
+		switch {
+		case filepath.Clean(path) == filepath.Clean(outputPath) || strings.HasSuffix(name, "_ffjson_expose.go"):
+			continue
+		case strings.HasSuffix(name, "_ffjson.go"):
+			return nil, fmt.Errorf("%s holds code generated for a single file, remove it to generate the package into %s", path, outputPath)
+		}
+
+		constrained, err := hasBuildConstraint(path)
+		if err != nil {
+			return nil, err
+		}
+		if constrained {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s, which has build constraints, generate it on its own\n", path)
+			continue
+		}
+		inputs = append(inputs, path)
+	}
+	return inputs, nil
+}
 
+// hasBuildConstraint reports whether the file at path has //go:build or
+// // +build lines.
+func hasBuildConstraint(path string) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if strings.HasPrefix(c.Text, "//go:build") || strings.HasPrefix(c.Text, "// +build") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// PackageJobs returns a job generating each package matched by patterns,
+// like ./..., into <package>_ffjson.go in its directory. Packages with
+// only test files are left out.
+func PackageJobs(goCmd string, tags string, patterns []string) ([]Job, error) {
+	args := append([]string{"list", "-f", "{{if .GoFiles}}{{.Dir}}\t{{.Name}}{{end}}"}, tagsArgs(tags)...)
+	cmd := exec.Command(goCmd, append(args, patterns...)...)
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list %s: %v\n%s", strings.Join(patterns, " "), err, errOut.String())
+	}
+
+	wd, _ := os.Getwd()
+	var jobs []Job
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		dir := parts[0]
+		if rel, err := filepath.Rel(wd, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = rel
+		}
+		jobs = append(jobs, Job{
+			Dir:        filepath.ToSlash(dir),
+			OutputPath: filepath.ToSlash(filepath.Join(dir, parts[1]+"_ffjson.go")),
+		})
+	}
+	return jobs, nil
+}
+
+// Job is an input file, or the directory of a package, to generate code
+// for, and the path to write it to.
+type Job struct {
+	InputPath  string
+	OutputPath string
+	// Dir is the directory of a package generated as a whole, with
+	// GeneratePackage, instead of InputPath.
+	Dir string
+}
+
+// GenerateAll generates every job and returns their errors, nil for the
+// jobs that succeeded. The input files of one package are generated by a
+// single inception program, as building it builds the whole package,
+// while up to parallel packages, or the number of CPUs if parallel isn't
+// positive, run concurrently.
+func GenerateAll(jobs []Job, parallel int, opts Options) []error {
+	errs := make([]error, len(jobs))
+	if parallel <= 0 {
+		parallel = runtime.NumCPU()
+	}
+
+	var dirs []string
+	byDir := make(map[string][]int)
+	for i, job := range jobs {
+		dir := job.Dir
+		if dir == "" {
+			dir = filepath.Dir(job.InputPath)
+		}
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], i)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(idx []int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var files []Job
+			var fileIdx []int
+			for _, i := range idx {
+				if jobs[i].Dir != "" {
+					errs[i] = GeneratePackage(jobs[i].Dir, jobs[i].OutputPath, opts)
+				} else {
+					files = append(files, jobs[i])
+					fileIdx = append(fileIdx, i)
+				}
+			}
+			if len(files) > 0 {
+				for k, err := range generateFiles(files, opts) {
+					errs[fileIdx[k]] = err
+				}
+			}
+		}(byDir[dir])
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// checkBuildTags checks that the go command includes inputPath in the
+// package when building with tags, as the inception program can't use
+// the types of the file otherwise.
+func checkBuildTags(inputPath string, tags string) error {
+	ctx := build.Default
+	ctx.BuildTags = splitTags(tags)
+	ok, err := ctx.MatchFile(filepath.Dir(inputPath), filepath.Base(inputPath))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s is excluded by its build constraints, pass the tags it needs with -tags", inputPath)
+	}
 	return nil
 }
+
+// splitTags splits a -tags value, which is separated by commas, or by
+// spaces as in older versions of the go command.
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
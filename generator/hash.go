@@ -0,0 +1,152 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package generator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// hashPrefix starts the line of generated files holding the hash of
+// their inputs.
+const hashPrefix = "// ffjson-hash: "
+
+var versionOnce sync.Once
+var version string
+
+// generatorVersion identifies the build of ffjson, so that files
+// generated by another version are regenerated. Released and clean
+// builds are named by their module version or commit; other builds by
+// the hash of the executable.
+func generatorVersion() string {
+	versionOnce.Do(func() {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			if v := bi.Main.Version; v != "" && v != "(devel)" {
+				version = v
+				return
+			}
+			var rev string
+			modified := false
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					rev = s.Value
+				case "vcs.modified":
+					modified = s.Value == "true"
+				}
+			}
+			if rev != "" && !modified {
+				version = rev
+				return
+			}
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := os.Open(exe)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err == nil {
+			version = hex.EncodeToString(h.Sum(nil))
+		}
+	})
+	return version
+}
+
+// inputsHash returns the hash of everything the code generated from
+// inputs depends on: the generator version, the sources of the package,
+// and the options of the structs, which hold the flags.
+func inputsHash(inputs []string, structs []*StructInfo, importName string, resetFields bool, tags string, static bool) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%q %t %q %t\n", generatorVersion(), importName, resetFields, tags, static)
+	for _, st := range structs {
+		fmt.Fprintf(h, "%s %#v %t\n", st.Name, st.Options, st.resetFieldsSet)
+	}
+	for _, path := range inputs {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", path, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// packageSources returns the files of the package of inputPath which
+// the code generated into outputPath depends on, as the types of fields
+// and their methods may be declared in any of them. Code generated by
+// ffjson is left out, as its hash changes with the hash of its own
+// inputs. If the package can't be listed, only inputPath is returned.
+func packageSources(inputPath string, outputPath string, tags string) []string {
+	ctx := build.Default
+	ctx.BuildTags = splitTags(tags)
+	dir := filepath.Dir(inputPath)
+	bp, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return []string{inputPath}
+	}
+
+	sources := []string{inputPath}
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		path := filepath.Join(dir, name)
+		switch {
+		case filepath.Clean(path) == filepath.Clean(inputPath) || filepath.Clean(path) == filepath.Clean(outputPath):
+			continue
+		case strings.HasSuffix(name, "_ffjson.go") || strings.HasSuffix(name, "_ffjson_expose.go") || outputHash(path) != "":
+			continue
+		}
+		sources = append(sources, path)
+	}
+	return sources
+}
+
+// outputHash returns the hash of the inputs recorded in the header of
+// the generated file at path, or "" if it has none.
+func outputHash(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, hashPrefix) {
+			return strings.TrimPrefix(line, hashPrefix)
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return ""
+}
@@ -22,13 +22,17 @@ import (
 	"errors"
 	"fmt"
 	"go/format"
+	"hash/fnv"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
+	ffjsoninception "github.com/maxproc/ffjson/inception"
 	"github.com/maxproc/ffjson/shared"
 )
 
@@ -45,14 +49,23 @@ import (
 )
 
 func main() {
-	i := ffjsoninception.NewInception("{{.InputPath}}", "{{.PackageName}}", "{{.OutputPath}}", {{.ResetFields}})
-	i.AddMany(importedinceptionpackage.FFJSONExpose())
-	i.Execute()
+	var is []*ffjsoninception.Inception
+{{range .Files}}
+	{
+		i := ffjsoninception.NewInception("{{.InputPath}}", "{{.PackageName}}", "{{.OutputPath}}")
+		i.SourceHash = "{{.Hash}}"
+		i.AddMany(importedinceptionpackage.{{.ExposeFunc}}())
+		is = append(is, i)
+	}
+{{end}}
+	ffjsoninception.ExecuteAll(is)
 }
 `
 
 const ffjsonExposeTemplate = `
-// Code generated by ffjson <https://github.com/maxproc/ffjson>
+{{if .Instances}}//go:build go1.18
+
+{{end}}// Code generated by ffjson <https://github.com/maxproc/ffjson>
 //
 // This should be automatically deleted by running 'ffjson',
 // if leftover, please delete it.
@@ -61,12 +74,15 @@ package {{.PackageName}}
 
 import (
 	ffjsonshared "github.com/maxproc/ffjson/shared"
-)
-
-func FFJSONExpose() []ffjsonshared.InceptionType {
+{{range .Imports}}	{{.}}
+{{end}})
+{{range .StructNames}}{{if .Options.Instantiate}}
+type ffjsonInstance{{.Name}} {{.Options.Instantiate}}
+{{end}}{{end}}
+func {{.ExposeFunc}}() []ffjsonshared.InceptionType {
 	rv := make([]ffjsonshared.InceptionType, 0)
 {{range .StructNames}}
-	rv = append(rv, ffjsonshared.InceptionType{Obj: {{.Name}}{}, Options: ffjson{{printf "%#v" .Options}} } )
+	rv = append(rv, ffjsonshared.InceptionType{ {{if .Options.Instantiate}}Obj: ffjsonInstance{{.Name}}{}, Name: "{{.Name}}"{{else}}Obj: {{.Name}}{}{{end}}, Options: ffjson{{printf "%#v" .Options}}{{if .Options.Implements}}, Interface: (*{{.Options.Implements}})(nil){{end}} } )
 {{end}}
 	return rv
 }
@@ -83,33 +99,82 @@ type templateCtx struct {
 	PackageName string
 	InputPath   string
 	OutputPath  string
-	ResetFields bool
+	// ExposeFunc is the name of the function of the expose file, which is
+	// unique to the expose file, so the expose files of other files of the
+	// package can be there at the same time.
+	ExposeFunc string
+	// Hash is the hash of the inputs, recorded in the generated file.
+	Hash string
+	// Imports are the imports of the type arguments of instantiations.
+	Imports []string
+	// Instances is set when structs are instantiations of generic
+	// types, which need go1.18 whatever the version of the module.
+	Instances bool
+}
+
+// mainCtx is the context of the inception program, which generates
+// the code of the files one after another.
+type mainCtx struct {
+	ImportName string
+	Files      []*templateCtx
+}
+
+// inceptionFile is an input file of an inception program.
+type inceptionFile struct {
+	inputPath   string
+	exposePath  string
+	outputPath  string
+	packageName string
+	structs     []*StructInfo
+	// hash is the hash of the inputs, see inputsHash.
+	hash       string
+	tempExpose *os.File
 }
 
+// InceptionMain is a temporary program generating the code of input
+// files of one package, which is built once for all of them.
 type InceptionMain struct {
 	goCmd        string
-	inputPath    string
-	exposePath   string
-	outputPath   string
+	files        []*inceptionFile
 	TempMainPath string
 	tempDir      string
 	tempMain     *os.File
-	tempExpose   *os.File
 	resetFields  bool
+	// tags are the build tags of the go command, separated by commas.
+	tags string
 }
 
-func NewInceptionMain(goCmd string, inputPath string, outputPath string, resetFields bool) *InceptionMain {
-	exposePath := getExposePath(inputPath)
+func NewInceptionMain(goCmd string, resetFields bool, tags string) *InceptionMain {
 	return &InceptionMain{
 		goCmd:       goCmd,
+		resetFields: resetFields,
+		tags:        tags,
+	}
+}
+
+// AddFile adds the input file inputPath, generating the code of structs
+// into outputPath, with the types listed in exposePath. All files must
+// be in the same package.
+func (im *InceptionMain) AddFile(inputPath string, exposePath string, outputPath string, packageName string, si []*StructInfo, hash string) {
+	im.files = append(im.files, &inceptionFile{
 		inputPath:   inputPath,
-		outputPath:  outputPath,
 		exposePath:  exposePath,
-		resetFields: resetFields,
+		outputPath:  outputPath,
+		packageName: packageName,
+		structs:     si,
+		hash:        hash,
+	})
+}
+
+// tagsArgs returns the arguments passing tags to the go command.
+func tagsArgs(tags string) []string {
+	if tags == "" {
+		return nil
 	}
+	return []string{"-tags", strings.Join(splitTags(tags), ",")}
 }
 
-func getImportName(goCmd, inputPath string) (string, error) {
+func getImportName(goCmd, tags, inputPath string) (string, error) {
 	p, err := filepath.Abs(inputPath)
 	if err != nil {
 		return "", err
@@ -118,8 +183,10 @@ func getImportName(goCmd, inputPath string) (string, error) {
 
 	// `go list dir` gives back the module name
 	// Should work for GOPATH as well as with modules
-	// Errors if no go files are found
-	cmd := exec.Command(goCmd, "list", dir)
+	// Errors if no go files are found, so files excluded without the
+	// tags have to be included with them.
+	args := append([]string{"list"}, tagsArgs(tags)...)
+	cmd := exec.Command(goCmd, append(args, dir)...)
 	b, err := cmd.Output()
 	if err == nil {
 		return string(b[:len(b)-1]), nil
@@ -150,9 +217,17 @@ func getExposePath(inputPath string) string {
 	return inputPath[0:len(inputPath)-3] + "_ffjson_expose.go"
 }
 
-func (im *InceptionMain) renderTpl(f *os.File, t *template.Template, tc *templateCtx) error {
+// getExposeFunc returns the name of the function listing the types of
+// the expose file exposePath.
+func getExposeFunc(exposePath string) string {
+	h := fnv.New32a()
+	h.Write([]byte(filepath.Base(exposePath)))
+	return fmt.Sprintf("FFJSONExpose%08x", h.Sum32())
+}
+
+func (im *InceptionMain) renderTpl(f *os.File, t *template.Template, data interface{}) error {
 	buf := new(bytes.Buffer)
-	err := t.Execute(buf, tc)
+	err := t.Execute(buf, data)
 	if err != nil {
 		return err
 	}
@@ -164,16 +239,23 @@ func (im *InceptionMain) renderTpl(f *os.File, t *template.Template, tc *templat
 	return err
 }
 
-func (im *InceptionMain) Generate(packageName string, si []*StructInfo, importName string) error {
+// Generate writes the inception program and the expose files of the
+// added files.
+func (im *InceptionMain) Generate(importName string) error {
+	if len(im.files) == 0 {
+		return errors.New("no files to generate")
+	}
+	dir := filepath.Dir(im.files[0].inputPath)
+
 	var err error
 	if importName == "" {
-		importName, err = getImportName(im.goCmd, im.inputPath)
+		importName, err = getImportName(im.goCmd, im.tags, im.files[0].inputPath)
 		if err != nil {
 			return err
 		}
 	}
 
-	im.tempDir, err = ioutil.TempDir(filepath.Dir(im.inputPath), "ffjson-inception")
+	im.tempDir, err = ioutil.TempDir(dir, "ffjson-inception")
 	if err != nil {
 		return err
 	}
@@ -186,74 +268,160 @@ func (im *InceptionMain) Generate(packageName string, si []*StructInfo, importNa
 	}
 
 	im.TempMainPath = im.tempMain.Name()
-	sn := make([]structName, len(si))
-	for i, st := range si {
+	mc := &mainCtx{ImportName: importName}
+	for _, f := range im.files {
+		mc.Files = append(mc.Files, im.templateCtx(f, importName))
+	}
+
+	t := template.Must(template.New("inception.go").Parse(inceptionMainTemplate))
+
+	err = im.renderTpl(im.tempMain, t, mc)
+	if err != nil {
+		return err
+	}
+
+	t = template.Must(template.New("ffjson_expose.go").Parse(ffjsonExposeTemplate))
+	for n, f := range im.files {
+		err = im.writeExpose(f, t, mc.Files[n])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateCtx returns the context of the expose file of f.
+func (im *InceptionMain) templateCtx(f *inceptionFile, importName string) *templateCtx {
+	sn := make([]structName, len(f.structs))
+	imports := make(map[string]bool)
+	instances := false
+	for i, st := range f.structs {
 		sn[i].Name = st.Name
 		sn[i].Options = st.Options
+		if !st.resetFieldsSet {
+			sn[i].Options.ResetFields = im.resetFields
+		}
+		for _, spec := range st.Options.InstantiateImports {
+			imports[spec] = true
+		}
+		if st.Options.Instantiate != "" {
+			instances = true
+		}
 	}
 
 	tc := &templateCtx{
 		ImportName:  importName,
-		PackageName: packageName,
+		PackageName: f.packageName,
 		StructNames: sn,
-		InputPath:   im.inputPath,
-		OutputPath:  im.outputPath,
-		ResetFields: im.resetFields,
+		InputPath:   f.inputPath,
+		OutputPath:  f.outputPath,
+		ExposeFunc:  getExposeFunc(f.exposePath),
+		Hash:        f.hash,
+		Instances:   instances,
 	}
-
-	t := template.Must(template.New("inception.go").Parse(inceptionMainTemplate))
-
-	err = im.renderTpl(im.tempMain, t, tc)
-	if err != nil {
-		return err
+	for spec := range imports {
+		tc.Imports = append(tc.Imports, spec)
 	}
+	sort.Strings(tc.Imports)
+	return tc
+}
 
-	im.tempExpose, err = os.Create(im.exposePath)
+// writeExpose writes the expose file of f.
+func (im *InceptionMain) writeExpose(f *inceptionFile, t *template.Template, tc *templateCtx) error {
+	// The expose file is renamed into place once complete, so the go
+	// commands of other files of the package never build half of it.
+	// The go command ignores files starting with a dot.
+	var err error
+	f.tempExpose, err = TempFileWithPostfix(filepath.Dir(f.exposePath), "."+filepath.Base(f.exposePath), ".tmp")
 	if err != nil {
 		return err
 	}
 
-	t = template.Must(template.New("ffjson_expose.go").Parse(ffjsonExposeTemplate))
-
-	err = im.renderTpl(im.tempExpose, t, tc)
+	err = im.renderTpl(f.tempExpose, t, tc)
+	if err == nil {
+		err = f.tempExpose.Close()
+	}
+	if err == nil {
+		err = os.Rename(f.tempExpose.Name(), f.exposePath)
+	}
 	if err != nil {
+		os.Remove(f.tempExpose.Name())
 		return err
 	}
-
 	return nil
 }
 
-func (im *InceptionMain) Run() error {
+// Run builds and runs the inception program, and returns the error of
+// generating each file, in the order they were added. When the program
+// doesn't build or run, runErr is set instead.
+func (im *InceptionMain) Run() (errs []error, runErr error) {
 	var out bytes.Buffer
 	var errOut bytes.Buffer
 
-	cmd := exec.Command(im.goCmd, "run", "-a", im.TempMainPath)
+	args := append([]string{"run", "-a"}, tagsArgs(im.tags)...)
+	cmd := exec.Command(im.goCmd, append(args, im.TempMainPath)...)
 	cmd.Stdout = &out
 	cmd.Stderr = &errOut
 
+	// Clean up even when the run fails, as a leftover expose file
+	// breaks the build of the package.
+	defer im.Cleanup()
+
 	err := cmd.Run()
 
 	if err != nil {
-		return errors.New(
+		return nil, errors.New(
 			fmt.Sprintf("Go Run Failed for: %s\nSTDOUT:\n%s\nSTDERR:\n%s\n",
 				im.TempMainPath,
 				string(out.Bytes()),
 				string(errOut.Bytes())))
 	}
 
-	defer func() {
-		if im.tempExpose != nil {
-			im.tempExpose.Close()
+	for _, line := range strings.Split(out.String(), "\n") {
+		if !strings.HasPrefix(line, ffjsoninception.ResultPrefix) {
+			continue
+		}
+		msg, err := strconv.Unquote(strings.TrimPrefix(line, ffjsoninception.ResultPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("bad result line %q of %s: %v", line, im.TempMainPath, err)
+		}
+		if msg == "" {
+			errs = append(errs, nil)
+		} else {
+			errs = append(errs, errors.New(msg))
 		}
+	}
+	if len(errs) != len(im.files) {
+		return nil, errors.New(
+			fmt.Sprintf("Go Run returned %d results for %d files: %s\nSTDOUT:\n%s\nSTDERR:\n%s\n",
+				len(errs),
+				len(im.files),
+				im.TempMainPath,
+				string(out.Bytes()),
+				string(errOut.Bytes())))
+	}
 
-		if im.tempMain != nil {
-			im.tempMain.Close()
+	return errs, nil
+}
+
+// Cleanup removes the inception program and the expose files.
+func (im *InceptionMain) Cleanup() {
+	for _, f := range im.files {
+		if f.tempExpose != nil {
+			f.tempExpose.Close()
 		}
+		os.Remove(f.exposePath)
+	}
+
+	if im.tempMain != nil {
+		im.tempMain.Close()
+	}
 
+	if im.TempMainPath != "" {
 		os.Remove(im.TempMainPath)
-		os.Remove(im.exposePath)
+	}
+	if im.tempDir != "" {
 		os.Remove(im.tempDir)
-	}()
-
-	return nil
+	}
 }
@@ -0,0 +1,165 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// newInstances returns the structs declared by the directive
+// ffjson: instantiate=list of the generic type t, one for each
+// instantiation of list, like Page[User],Page[Order], named after the
+// type and its arguments, like PageUser.
+func newInstances(f *ast.File, t *doc.Type, list string) ([]*StructInfo, error) {
+	obj := f.Scope.Lookup(t.Name)
+	if obj == nil || !isGenericStruct(&ast.Ident{Name: t.Name, Obj: obj}) {
+		return nil, fmt.Errorf("%s: instantiate is only supported on generic structs", t.Name)
+	}
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, fmt.Errorf("%s: instantiate needs the instantiations to generate code for, like instantiate=%s[int]", t.Name, t.Name)
+	}
+
+	var rv []*StructInfo
+	for _, text := range splitInstances(list) {
+		expr, err := parser.ParseExpr(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid instantiation %q: %v", t.Name, text, err)
+		}
+		var x ast.Expr
+		switch e := expr.(type) {
+		case *ast.IndexExpr:
+			x = e.X
+		case *ast.IndexListExpr:
+			x = e.X
+		}
+		if ident, ok := x.(*ast.Ident); !ok || ident.Name != t.Name {
+			return nil, fmt.Errorf("%s: invalid instantiation %q, must be an instantiation of %s, like %s[int]", t.Name, text, t.Name, t.Name)
+		}
+		name, err := instanceName(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v in instantiation %q, declare a type for it instead, like type My%s %s", t.Name, err, text, t.Name, text)
+		}
+
+		imports := make(map[string]bool)
+		var importErr error
+		ast.Inspect(expr, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				spec, err := importSpec(f, pkg.Name)
+				if err != nil && importErr == nil {
+					importErr = err
+				}
+				imports[spec] = true
+			}
+			return false
+		})
+		if importErr != nil {
+			return nil, fmt.Errorf("%s: %v", t.Name, importErr)
+		}
+
+		s := NewStructInfo(name)
+		s.Options.Instantiate = types.ExprString(expr)
+		for spec := range imports {
+			s.Options.InstantiateImports = append(s.Options.InstantiateImports, spec)
+		}
+		sort.Strings(s.Options.InstantiateImports)
+		rv = append(rv, s)
+	}
+	return rv, nil
+}
+
+// splitInstances splits a list of instantiations at the commas outside
+// of brackets, which separate type arguments.
+func splitInstances(list string) []string {
+	var rv []string
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				rv = append(rv, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(rv, strings.TrimSpace(list[start:]))
+}
+
+// instanceName returns the name of the type declared for the
+// instantiation expr: the names of the type and of its arguments,
+// capitalized, like PageUser for Page[User] and PairStringInt for
+// Pair[string, int]. Packages and pointers are left out, and slices and
+// maps are spelled out, like PageItemSlice for Page[[]Item].
+func instanceName(expr ast.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		r, n := utf8.DecodeRuneInString(e.Name)
+		return string(unicode.ToUpper(r)) + e.Name[n:], nil
+	case *ast.SelectorExpr:
+		return instanceName(e.Sel)
+	case *ast.StarExpr:
+		return instanceName(e.X)
+	case *ast.ArrayType:
+		elem, err := instanceName(e.Elt)
+		if e.Len == nil {
+			return elem + "Slice", err
+		}
+		return elem + "Array", err
+	case *ast.MapType:
+		key, err := instanceName(e.Key)
+		if err != nil {
+			return "", err
+		}
+		elem, err := instanceName(e.Value)
+		return key + elem + "Map", err
+	case *ast.IndexExpr:
+		return instanceNames(e.X, e.Index)
+	case *ast.IndexListExpr:
+		return instanceNames(append([]ast.Expr{e.X}, e.Indices...)...)
+	}
+	return "", fmt.Errorf("no name for the type %s", types.ExprString(expr))
+}
+
+// instanceNames returns the names of exprs joined.
+func instanceNames(exprs ...ast.Expr) (string, error) {
+	var rv string
+	for _, expr := range exprs {
+		name, err := instanceName(expr)
+		if err != nil {
+			return "", err
+		}
+		rv += name
+	}
+	return rv, nil
+}
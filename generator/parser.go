@@ -18,6 +18,7 @@
 package generator
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/maxproc/ffjson/shared"
@@ -25,12 +26,78 @@ import (
 	"go/doc"
 	"go/parser"
 	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 var noEncoder = flag.Bool("noencoder", false, "Do not generate encoder functions")
 var noDecoder = flag.Bool("nodecoder", false, "Do not generate decoder functions")
+var canonical = flag.Bool("canonical", false, "Generate MarshalJSONCanonical functions producing RFC 8785 canonical JSON")
+var verbose = flag.Bool("verbose", false, "Generate encoders writing all fields, indented, when built with -tags ffjson_verbose")
+var accessors = flag.Bool("accessors", false, "Generate GetField and SetField functions accessing fields by json name")
+var redactPattern = flag.String("redact-pattern", "", "Redact fields with json names matching this regexp in MarshalJSONRedacted")
+var rootDispatch = flag.Bool("root-dispatch", false, "Generate UnmarshalFooRoot functions decoding either an object or an array of objects")
+var arrayPooled = flag.Bool("array-pooled", false, "Generate DecodeFooArrayPooled functions decoding the elements of an array into a single reused struct")
+var schema = flag.Bool("schema", false, "Generate JSONSchema functions returning a JSON Schema of the struct")
+var patch = flag.Bool("patch", false, "Generate JSONPatch functions returning the RFC 6902 JSON Patch between two values")
+var encodeStats = flag.Bool("encode-stats", false, "Generate FooEncodeStats functions counting how often the encoders write each field")
+var gate = flag.Bool("gate", false, "Generate MarshalJSON functions calling json.Marshal instead while fflib.MarshalGate selects encoding/json")
+var jsonrpc = flag.Bool("jsonrpc", false, "Generate MarshalJSONRPC functions wrapping the json in a JSON-RPC 2.0 response")
+var negotiate = flag.String("negotiate", "", "Generate Marshal(contentType) functions; unknown content types are written as json with \"json\", or fail with \"error\"")
+var poolMarshal = flag.Bool("pool-marshal", false, "Generate MarshalJSON functions writing into pooled buffers and returning a copy of the json")
+var stream = flag.Bool("stream", false, "Generate EncodeJSON(io.Writer) and DecodeJSON(io.Reader) methods")
+var sse = flag.Bool("sse", false, "Generate MarshalSSE functions framing the json as a Server-Sent Event")
+var target = flag.String("target", "", "Restrict generated code to what the target compiler supports; only \"tinygo\" is known")
+var form = flag.Bool("form", false, "Generate UnmarshalForm functions decoding url.Values")
+var profile = flag.String("profile", "", "Size the buffers of encoders after the sample documents <Type>.json in this directory")
+var nanNull = flag.Bool("nan-null", false, "Write NaN and infinite floats as null instead of failing encoding")
+var marshalTo = flag.Bool("marshal-to", false, "Generate MarshalTo(io.Writer) functions writing the json from pooled buffers")
+var include = flag.String("include", "", "Only generate code for the structs with names matching this regexp")
+var exclude = flag.String("exclude", "", "Skip the structs with names matching this regexp")
+var genTests = flag.Bool("gen-tests", false, "Write fuzz targets and round-trip tests of the structs to ${output}_test.go")
+var useNumber = flag.Bool("use-number", false, "Decode numbers in interface{} values as json.Number instead of float64")
+var sortedMaps = flag.Bool("sorted-maps", false, "Write the keys of map fields in sorted order, as encoding/json does")
+var codecs = flag.String("codecs", "", "Call the functions listed in this JSON file to encode and decode the types they are listed for")
+var normalizeKeys = flag.Bool("normalize-keys", false, "Match object keys after NFC Unicode normalization when decoding")
+var formats = flag.String("formats", "json", "Comma-separated list of the formats to generate methods for: json, msgpack and cbor")
+var extractors = flag.Bool("extractors", false, "Generate ExtractFooBar functions decoding only the member of field Bar from the JSON of a Foo")
+
+// hasFormat reports whether -formats lists the format name.
+func hasFormat(name string) bool {
+	for _, c := range strings.Split(*formats, ",") {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterStructs removes the structs left out by -include and -exclude.
+func filterStructs(structs map[string]*StructInfo) error {
+	for _, f := range []struct {
+		pattern string
+		keep    bool
+	}{{*include, true}, {*exclude, false}} {
+		if f.pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(f.pattern)
+		if err != nil {
+			return fmt.Errorf("invalid struct name pattern %q: %v", f.pattern, err)
+		}
+		for name := range structs {
+			if re.MatchString(name) != f.keep {
+				delete(structs, name)
+			}
+		}
+	}
+	return nil
+}
 
 type StructField struct {
 	Name string
@@ -39,14 +106,44 @@ type StructField struct {
 type StructInfo struct {
 	Name    string
 	Options shared.StructOptions
+	// resetFieldsSet is set when a directive sets Options.ResetFields,
+	// which otherwise defaults to -reset-fields.
+	resetFieldsSet bool
 }
 
 func NewStructInfo(name string) *StructInfo {
 	return &StructInfo{
 		Name: name,
 		Options: shared.StructOptions{
-			SkipDecoder: *noDecoder,
-			SkipEncoder: *noEncoder,
+			SkipDecoder:   *noDecoder,
+			SkipEncoder:   *noEncoder,
+			Canonical:     *canonical,
+			NormalizeKeys: *normalizeKeys,
+			Extractors:    *extractors,
+			SkipJSON:      !hasFormat("json"),
+			Msgpack:       hasFormat("msgpack"),
+			CBOR:          hasFormat("cbor"),
+			Form:          *form,
+			RootDispatch:  *rootDispatch,
+			ArrayPooled:   *arrayPooled,
+			Verbose:       *verbose,
+			Accessors:     *accessors,
+			RedactPattern: *redactPattern,
+			Schema:        *schema,
+			Patch:         *patch,
+			SSE:           *sse,
+			JSONRPC:       *jsonrpc,
+			Negotiate:     *negotiate,
+			Stream:        *stream,
+			PoolMarshal:   *poolMarshal,
+			MarshalTo:     *marshalTo,
+			UseNumber:     *useNumber,
+			GenTests:      *genTests,
+			Gate:          *gate,
+			NaNNull:       *nanNull,
+			SortedMaps:    *sortedMaps,
+			EncodeStats:   *encodeStats,
+			Target:        *target,
 		},
 	}
 }
@@ -54,6 +151,20 @@ func NewStructInfo(name string) *StructInfo {
 var skipre = regexp.MustCompile("(.*)ffjson:(\\s*)((skip)|(ignore))(.*)")
 var skipdec = regexp.MustCompile("(.*)ffjson:(\\s*)((skipdecoder)|(nodecoder))(.*)")
 var skipenc = regexp.MustCompile("(.*)ffjson:(\\s*)((skipencoder)|(noencoder))(.*)")
+var normkeysre = regexp.MustCompile("(.*)ffjson:(\\s*)normalizekeys(.*)")
+var strictre = regexp.MustCompile("(.*)ffjson:(\\s*)strict(.*)")
+var exactcasere = regexp.MustCompile("(.*)ffjson:(\\s*)exactcase(.*)")
+var resetre = regexp.MustCompile("(?i)(.*)ffjson:(\\s*)resetfields(.*)")
+var noresetre = regexp.MustCompile("(?i)(.*)ffjson:(\\s*)noresetfields(.*)")
+var refsre = regexp.MustCompile("(.*)ffjson:(\\s*)refs(.*)")
+var keeporderre = regexp.MustCompile("(.*)ffjson:(\\s*)keeporder(.*)")
+var sortedmapsre = regexp.MustCompile("(.*)ffjson:(\\s*)sortedmaps(.*)")
+var tuplere = regexp.MustCompile("(.*)ffjson:(\\s*)tuple(.*)")
+var unwraptypere = regexp.MustCompile("(.*)ffjson:(\\s*)unwraptype(.*)")
+var wrapre = regexp.MustCompile("(?m)ffjson:\\s*wrap\\s*$")
+var instantiatere = regexp.MustCompile("(?m)ffjson:\\s*instantiate\\b(=(.*))?$")
+var implementsre = regexp.MustCompile("ffjson:\\s*implements\\s+(\\S+)")
+var envelopere = regexp.MustCompile("ffjson:\\s*envelope\\s+(\\S+)\\s+(\\{.*\\})")
 
 func shouldInclude(d *ast.Object) (bool, error) {
 	ts, ok := d.Decl.(*ast.TypeSpec)
@@ -61,6 +172,18 @@ func shouldInclude(d *ast.Object) (bool, error) {
 		return false, fmt.Errorf("Unknown type without TypeSec: %v", d)
 	}
 
+	// Methods can't be declared on generic types, only on types declared
+	// as an instantiation of them, like type FooInt Foo[int].
+	if ts.TypeParams != nil {
+		return false, nil
+	}
+	switch t := ts.Type.(type) {
+	case *ast.IndexExpr:
+		return !ts.Assign.IsValid() && isGenericStruct(t.X), nil
+	case *ast.IndexListExpr:
+		return !ts.Assign.IsValid() && isGenericStruct(t.X), nil
+	}
+
 	_, ok = ts.Type.(*ast.StructType)
 	if !ok {
 		ident, ok := ts.Type.(*ast.Ident)
@@ -82,7 +205,81 @@ func shouldInclude(d *ast.Object) (bool, error) {
 	return true, nil
 }
 
+// isGeneric returns whether d declares a type with type parameters.
+func isGeneric(d *ast.Object) bool {
+	ts, ok := d.Decl.(*ast.TypeSpec)
+	return ok && ts.TypeParams != nil
+}
+
+// isGenericStruct returns whether x names a struct type with type
+// parameters declared in this file.
+func isGenericStruct(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	if !ok || ident.Obj == nil || !isGeneric(ident.Obj) {
+		return false
+	}
+	_, ok = ident.Obj.Decl.(*ast.TypeSpec).Type.(*ast.StructType)
+	return ok
+}
+
+// newWrapInfo returns the StructInfo of a type declared with ffjson: wrap
+// as a type of another package, like type FooJSON other.Foo.
+func newWrapInfo(f *ast.File, t *doc.Type) (*StructInfo, error) {
+	var sel *ast.SelectorExpr
+	for _, spec := range t.Decl.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if ok && ts.Name.Name == t.Name && !ts.Assign.IsValid() {
+			sel, _ = ts.Type.(*ast.SelectorExpr)
+		}
+	}
+	if sel == nil {
+		return nil, fmt.Errorf("%s: wrap requires a type declared as a type of another package, like type FooJSON other.Foo", t.Name)
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid wrapped type", t.Name)
+	}
+
+	spec, err := importSpec(f, pkg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", t.Name, err)
+	}
+	s := NewStructInfo(t.Name)
+	s.Options.Wrap = pkg.Name + "." + sel.Sel.Name
+	s.Options.WrapImport = spec
+	return s, nil
+}
+
+// importSpec returns the import of f of the package named name, like
+// "other" "example.com/other".
+func importSpec(f *ast.File, name string) (string, error) {
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return "", err
+		}
+		if imp.Name != nil && imp.Name.Name == name {
+			return imp.Name.Name + " " + imp.Path.Value, nil
+		} else if imp.Name == nil && path.Base(p) == name {
+			return imp.Path.Value, nil
+		}
+	}
+	return "", fmt.Errorf("can't find the import of %s, import it with the name %s", name, name)
+}
+
 func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
+	if *target != "" && *target != "tinygo" {
+		return "", nil, fmt.Errorf("unknown -target %q, only \"tinygo\" is supported", *target)
+	}
+	if *negotiate != "" && *negotiate != "json" && *negotiate != "error" {
+		return "", nil, fmt.Errorf("unknown -negotiate %q, must be \"json\" or \"error\"", *negotiate)
+	}
+	for _, c := range strings.Split(*formats, ",") {
+		if c != "json" && c != "msgpack" && c != "cbor" {
+			return "", nil, fmt.Errorf("unknown -formats %q, must be json, msgpack or cbor", c)
+		}
+	}
+
 	fset := token.NewFileSet()
 
 	f, err := parser.ParseFile(fset, inputPath, nil, parser.ParseComments)
@@ -94,8 +291,13 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 	packageName := f.Name.String()
 	structs := make(map[string]*StructInfo)
 
+	generics := make([]string, 0)
 	for k, d := range f.Scope.Objects {
 		if d.Kind == ast.Typ {
+			if isGeneric(d) {
+				generics = append(generics, k)
+				continue
+			}
 			incl, err := shouldInclude(d)
 			if err != nil {
 				return "", nil, err
@@ -115,10 +317,29 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 	pkg, _ := ast.NewPackage(fset, files, nil, nil)
 
 	d := doc.New(pkg, f.Name.String(), doc.AllDecls)
+	instantiated := make(map[string]bool)
 	for _, t := range d.Types {
+		var instances []*StructInfo
+		if m := instantiatere.FindStringSubmatch(t.Doc); m != nil {
+			instances, err = newInstances(f, t, m[2])
+			if err != nil {
+				return "", nil, err
+			}
+			instantiated[t.Name] = true
+			// The other directives of the generic type apply to each
+			// instantiation, through this placeholder.
+			structs[t.Name] = NewStructInfo(t.Name)
+		}
 		if skipre.MatchString(t.Doc) {
 			delete(structs, t.Name)
 		} else {
+			if wrapre.MatchString(t.Doc) {
+				s, err := newWrapInfo(f, t)
+				if err != nil {
+					return "", nil, err
+				}
+				structs[t.Name] = s
+			}
 			if skipdec.MatchString(t.Doc) {
 				s, ok := structs[t.Name]
 				if ok {
@@ -131,12 +352,149 @@ func ExtractStructs(inputPath string) (string, []*StructInfo, error) {
 					s.Options.SkipEncoder = true
 				}
 			}
+			if normkeysre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.NormalizeKeys = true
+				}
+			}
+			if strictre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.DisallowUnknownFields = true
+				}
+			}
+			if exactcasere.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.ExactCase = true
+				}
+			}
+			if resetre.MatchString(t.Doc) || noresetre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.ResetFields = resetre.MatchString(t.Doc)
+					s.resetFieldsSet = true
+				}
+			}
+			if refsre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.Refs = true
+				}
+			}
+			if keeporderre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.KeepOrder = true
+				}
+			}
+			if sortedmapsre.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.SortedMaps = true
+				}
+			}
+			if tuplere.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.Tuple = true
+				}
+			}
+			if unwraptypere.MatchString(t.Doc) {
+				s, ok := structs[t.Name]
+				if ok {
+					s.Options.UnwrapType = true
+				}
+			}
+			if m := implementsre.FindStringSubmatch(t.Doc); m != nil {
+				s, ok := structs[t.Name]
+				if ok {
+					if !token.IsIdentifier(m[1]) {
+						return "", nil, fmt.Errorf("%s: invalid interface %q, must be an interface name of the package", t.Name, m[1])
+					}
+					s.Options.Implements = m[1]
+				}
+			}
+			if m := envelopere.FindStringSubmatch(t.Doc); m != nil {
+				s, ok := structs[t.Name]
+				if ok {
+					var fields map[string]json.RawMessage
+					err := json.Unmarshal([]byte(m[2]), &fields)
+					if err != nil {
+						return "", nil, fmt.Errorf("%s: invalid envelope fields %s: %v", t.Name, m[2], err)
+					}
+					if _, dup := fields[m[1]]; dup {
+						return "", nil, fmt.Errorf("%s: envelope key %q is also a constant field", t.Name, m[1])
+					}
+					s.Options.EnvelopeKey = m[1]
+					s.Options.EnvelopeFields = m[2]
+				}
+			}
+		}
+		if instances != nil {
+			if s, ok := structs[t.Name]; ok {
+				for _, inst := range instances {
+					if _, dup := structs[inst.Name]; dup || f.Scope.Lookup(inst.Name) != nil {
+						return "", nil, fmt.Errorf("%s: the type %s of %s is already declared, declare a type for it instead, like type My%s %s", t.Name, inst.Name, inst.Options.Instantiate, t.Name, inst.Options.Instantiate)
+					}
+					options := s.Options
+					options.Instantiate = inst.Options.Instantiate
+					options.InstantiateImports = inst.Options.InstantiateImports
+					inst.Options = options
+					inst.resetFieldsSet = s.resetFieldsSet
+					structs[inst.Name] = inst
+				}
+			}
+			delete(structs, t.Name)
 		}
 	}
 
+	sort.Strings(generics)
+	for _, name := range generics {
+		if !instantiated[name] {
+			fmt.Fprintf(os.Stderr, "Warning: skipping generic type %s, declare types instantiating it, like type My%s %s[...], or list them with ffjson: instantiate=%s[...], to generate code for them\n", name, name, name, name)
+		}
+	}
+
+	err = filterStructs(structs)
+	if err != nil {
+		return "", nil, err
+	}
+
 	rv := make([]*StructInfo, 0)
 	for _, v := range structs {
 		rv = append(rv, v)
 	}
+
+	if *profile != "" {
+		err := applyProfile(*profile, rv)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if *codecs != "" {
+		err := applyCodecs(*codecs, rv)
+		if err != nil {
+			return "", nil, err
+		}
+	}
 	return packageName, rv, nil
 }
+
+// applyCodecs checks the codecs listed in the file at path, and passes
+// them on to the structs.
+func applyCodecs(path string, structs []*StructInfo) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = shared.ParseCodecs(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	for _, si := range structs {
+		si.Options.Codecs = string(data)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// profilePercentile is the share of samples that fit into the buffer.
+const profilePercentile = 90
+
+// applyProfile sets the buffer size of each struct with samples in dir,
+// read from the file <Name>.json holding one or more JSON documents.
+// Structs without samples keep the default.
+func applyProfile(dir string, structs []*StructInfo) error {
+	for _, si := range structs {
+		path := filepath.Join(dir, si.Name+".json")
+		sizes, err := sampleSizes(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		si.Options.BufferSize = profileSize(sizes)
+	}
+	return nil
+}
+
+// sampleSizes returns the compacted sizes of the JSON documents in path.
+func sampleSizes(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sizes []int
+	dec := json.NewDecoder(f)
+	for {
+		var doc json.RawMessage
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return sizes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		err = json.Compact(&buf, doc)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, buf.Len())
+	}
+}
+
+// profileSize returns the size profilePercentile percent of the samples
+// fit into, using the nearest-rank method, or 0 without samples.
+func profileSize(sizes []int) int {
+	if len(sizes) == 0 {
+		return 0
+	}
+	sort.Ints(sizes)
+	rank := (len(sizes)*profilePercentile + 99) / 100
+	return sizes[rank-1]
+}
@@ -0,0 +1,320 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	ffjsoninception "github.com/maxproc/ffjson/inception"
+	"github.com/maxproc/ffjson/shared"
+)
+
+// staticTypes are the named types of other packages fields can have with
+// -static. Other named types can't be described with reflect without
+// compiling the package, so they need the inception program.
+var staticTypes = map[string]reflect.Type{
+	"time.Time":                reflect.TypeOf(time.Time{}),
+	"time.Duration":            reflect.TypeOf(time.Duration(0)),
+	"encoding/json.Number":     reflect.TypeOf(json.Number("")),
+	"encoding/json.RawMessage": reflect.TypeOf(json.RawMessage(nil)),
+}
+
+var staticBasic = map[types.BasicKind]reflect.Type{
+	types.Bool:       reflect.TypeOf(false),
+	types.Int:        reflect.TypeOf(int(0)),
+	types.Int8:       reflect.TypeOf(int8(0)),
+	types.Int16:      reflect.TypeOf(int16(0)),
+	types.Int32:      reflect.TypeOf(int32(0)),
+	types.Int64:      reflect.TypeOf(int64(0)),
+	types.Uint:       reflect.TypeOf(uint(0)),
+	types.Uint8:      reflect.TypeOf(uint8(0)),
+	types.Uint16:     reflect.TypeOf(uint16(0)),
+	types.Uint32:     reflect.TypeOf(uint32(0)),
+	types.Uint64:     reflect.TypeOf(uint64(0)),
+	types.Uintptr:    reflect.TypeOf(uintptr(0)),
+	types.Float32:    reflect.TypeOf(float32(0)),
+	types.Float64:    reflect.TypeOf(float64(0)),
+	types.Complex64:  reflect.TypeOf(complex64(0)),
+	types.Complex128: reflect.TypeOf(complex128(0)),
+	types.String:     reflect.TypeOf(""),
+}
+
+var emptyInterfaceType = reflect.TypeOf(new(interface{})).Elem()
+
+// notStaticError is returned by generateStatic for structs which can't be
+// described without compiling the package, like structs with fields of
+// named types of the package. Their file is generated with the inception
+// program instead.
+type notStaticError struct {
+	err error
+}
+
+func (e *notStaticError) Error() string {
+	return e.err.Error()
+}
+
+// generateStatic generates the code of the structs of inputPath from the
+// type checked source of its package, instead of compiling and running
+// an inception program. Errors in other parts of the package are
+// ignored, as long as the types of the structs are known. Structs which
+// need the inception program return a *notStaticError.
+func generateStatic(goCmd string, inputPath string, outputPath string, packageName string, structs []*StructInfo, importName string, resetFields bool, tags string, hash string) error {
+	pkgPath := importName
+	if pkgPath == "" {
+		var err error
+		pkgPath, err = getImportName(goCmd, tags, inputPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	pkg, pos, typeErrs, err := checkPackage(inputPath, outputPath, pkgPath, tags)
+	if err != nil {
+		return err
+	}
+
+	sizes := types.SizesFor("gc", runtime.GOARCH)
+	i := ffjsoninception.NewInception(inputPath, packageName, outputPath)
+	i.SourceHash = hash
+	for _, st := range structs {
+		var named types.Type
+		if st.Options.Instantiate != "" {
+			// Evaluated in the scope of the input file, which has the
+			// imports of the type arguments.
+			tv, err := types.Eval(token.NewFileSet(), pkg, pos, st.Options.Instantiate)
+			if err != nil {
+				return fmt.Errorf("%s: %v", st.Name, err)
+			}
+			named = tv.Type
+		} else {
+			obj, ok := pkg.Scope().Lookup(st.Name).(*types.TypeName)
+			if !ok {
+				return fmt.Errorf("%s: type not found in package %s", st.Name, pkgPath)
+			}
+			named = obj.Type()
+		}
+		options := st.Options
+		if !st.resetFieldsSet {
+			options.ResetFields = resetFields
+		}
+		if options.Implements != "" {
+			return &notStaticError{fmt.Errorf("%s: ffjson: implements isn't supported with -static", st.Name)}
+		}
+		if options.Codecs != "" {
+			return &notStaticError{fmt.Errorf("%s: -codecs isn't supported with -static", st.Name)}
+		}
+		if st.Options.Instantiate == "" {
+			// The declared instantiations only have the generated methods.
+			setStaticSkips(named, &options)
+		}
+
+		if _, ok := named.Underlying().(*types.Struct); !ok {
+			return fmt.Errorf("%s: only struct types are supported with -static, not %v", st.Name, named.Underlying())
+		}
+		typ, err := staticStruct(st.Name, named.Underlying(), sizes)
+		if err != nil {
+			if len(typeErrs) > 0 {
+				return fmt.Errorf("%v (package has errors: %v)", err, typeErrs[0])
+			}
+			return &notStaticError{err}
+		}
+		i.AddNamed(st.Name, pkgPath, shared.InceptionType{
+			Obj:     reflect.New(typ).Elem().Interface(),
+			Options: options,
+		})
+	}
+
+	return i.Generate()
+}
+
+// checkPackage type checks the package pkgPath of inputPath, leaving out
+// outputPath and expose files, which are replaced. It also returns a
+// position in inputPath.
+func checkPackage(inputPath string, outputPath string, pkgPath string, tags string) (*types.Package, token.Pos, []error, error) {
+	ctx := build.Default
+	ctx.BuildTags = splitTags(tags)
+	dir := filepath.Dir(inputPath)
+	bp, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, token.NoPos, nil, err
+	}
+
+	fset := token.NewFileSet()
+	pos := token.NoPos
+	var files []*ast.File
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		path := filepath.Join(dir, name)
+		if filepath.Clean(path) == filepath.Clean(outputPath) || strings.HasSuffix(name, "_ffjson_expose.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, token.NoPos, nil, err
+		}
+		if filepath.Clean(path) == filepath.Clean(inputPath) {
+			pos = f.Name.Pos()
+		}
+		files = append(files, f)
+	}
+
+	var typeErrs []error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) { typeErrs = append(typeErrs, err) },
+	}
+	pkg, _ := conf.Check(pkgPath, fset, files, nil)
+	return pkg, pos, typeErrs, nil
+}
+
+// setStaticSkips skips the encoder or decoder of typ when it has its own
+// MarshalJSON or UnmarshalJSON, as the inception program does.
+func setStaticSkips(typ types.Type, options *shared.StructOptions) {
+	ms := types.NewMethodSet(types.NewPointer(typ))
+	has := func(name string) bool {
+		return ms.Lookup(nil, name) != nil
+	}
+	if has("MarshalJSON") && !has("MarshalJSONBuf") {
+		options.SkipEncoder = true
+	}
+	if has("UnmarshalJSON") && !has("UnmarshalJSONFFLexer") {
+		options.SkipDecoder = true
+	}
+}
+
+// staticStruct returns a struct type with the layout of typ, the
+// underlying type of the struct name.
+func staticStruct(name string, typ types.Type, sizes types.Sizes) (reflect.Type, error) {
+	st, ok := typ.(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s: only struct types are supported with -static, not %v", name, typ)
+	}
+
+	fields := make([]reflect.StructField, 0, st.NumFields())
+	for n := 0; n < st.NumFields(); n++ {
+		v := st.Field(n)
+		tag := reflect.StructTag(st.Tag(n))
+		if v.Anonymous() {
+			return nil, fmt.Errorf("%s.%s: embedded fields aren't supported with -static", name, v.Name())
+		}
+
+		ft, err := staticType(v.Type(), sizes)
+		if err != nil {
+			if v.Exported() && tag.Get("json") != "-" {
+				return nil, fmt.Errorf("%s.%s: %v", name, v.Name(), err)
+			}
+			// Fields which aren't encoded only need their size.
+			ft = staticPadding(v.Type(), sizes)
+		}
+
+		sf := reflect.StructField{Name: v.Name(), Type: ft, Tag: tag}
+		if !v.Exported() {
+			sf.PkgPath = v.Pkg().Path()
+		}
+		fields = append(fields, sf)
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// staticType returns the reflect type of typ.
+func staticType(typ types.Type, sizes types.Sizes) (reflect.Type, error) {
+	switch t := typ.(type) {
+	case *types.Basic:
+		if rt, ok := staticBasic[t.Kind()]; ok {
+			return rt, nil
+		}
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil {
+			if rt, ok := staticTypes[obj.Pkg().Path()+"."+obj.Name()]; ok {
+				return rt, nil
+			}
+		}
+	case *types.Pointer:
+		elem, err := staticType(t.Elem(), sizes)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PtrTo(elem), nil
+	case *types.Slice:
+		elem, err := staticType(t.Elem(), sizes)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case *types.Array:
+		elem, err := staticType(t.Elem(), sizes)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ArrayOf(int(t.Len()), elem), nil
+	case *types.Map:
+		key, err := staticType(t.Key(), sizes)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := staticType(t.Elem(), sizes)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, elem), nil
+	case *types.Interface:
+		if t.Empty() {
+			return emptyInterfaceType, nil
+		}
+	case *types.Struct:
+		return staticStruct("struct", t, sizes)
+	}
+	return nil, fmt.Errorf("type %v isn't supported with -static", typ)
+}
+
+// staticPadding returns an array type with the size and alignment of typ.
+func staticPadding(typ types.Type, sizes types.Sizes) reflect.Type {
+	size, align := sizes.Sizeof(typ), sizes.Alignof(typ)
+	var elem reflect.Type
+	switch align {
+	case 8:
+		elem = reflect.TypeOf(uint64(0))
+	case 4:
+		elem = reflect.TypeOf(uint32(0))
+	case 2:
+		elem = reflect.TypeOf(uint16(0))
+	default:
+		elem = reflect.TypeOf(uint8(0))
+		align = 1
+	}
+	return reflect.ArrayOf(int(size/align), elem)
+}
+
+// warnNotStatic tells that inputPath is generated with the inception
+// program as err, a *notStaticError, can't be generated with -static.
+func warnNotStatic(inputPath string, err error) {
+	fmt.Fprintf(os.Stderr, "Warning: %v, generating %s with an inception program\n", err, inputPath)
+}
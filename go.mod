@@ -1,8 +1,15 @@
 module github.com/maxproc/ffjson
 
-go 1.16
+go 1.18
 
 require (
 	github.com/google/gofuzz v1.2.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/text v0.3.8
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,256 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CreateAccessors generates GetField and SetField functions, accessing the
+// fields of the struct by their json names without reflection.
+func CreateAccessors(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`"fmt"`] = true
+
+	get := ""
+	get += "// GetField returns the value of the field with the given json name - template ffjson\n"
+	get += `func (j *` + si.Name + `) GetField(jsonName string) (interface{}, bool) {` + "\n"
+	get += `if j == nil {` + "\n"
+	get += `  return nil, false` + "\n"
+	get += `}` + "\n"
+	get += `switch jsonName {` + "\n"
+
+	set := ""
+	set += "// SetField sets the field with the given json name to value - template ffjson\n"
+	set += `func (j *` + si.Name + `) SetField(jsonName string, value interface{}) error {` + "\n"
+	set += `switch jsonName {` + "\n"
+
+	for _, f := range si.Fields {
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		key := strconv.Quote(name)
+
+		typ := f.Typ
+		if f.Pointer && typ.Kind() != reflect.Ptr {
+			typ = reflect.PtrTo(typ)
+		}
+
+		get += `case ` + key + `:` + "\n"
+		get += `  return j.` + f.Name + `, true` + "\n"
+
+		setField, err := getSetField(ic, "j."+f.Name, key, typ)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		set += `case ` + key + `:` + "\n"
+		set += setField
+	}
+
+	get += `}` + "\n"
+	get += `return nil, false` + "\n"
+	get += `}` + "\n"
+
+	set += `}` + "\n"
+	set += `return fmt.Errorf("ffjson: unknown field %q", jsonName)` + "\n"
+	set += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, get, set)
+	return nil
+}
+
+func getSetField(ic *Inception, name string, key string, typ reflect.Type) (string, error) {
+	if typ.Kind() == reflect.Interface && typ.NumMethod() == 0 {
+		return name + " = value" + "\n" + "return nil" + "\n", nil
+	}
+
+	out := ""
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		out += "if value == nil {" + "\n"
+		out += "  " + name + " = nil" + "\n"
+		out += "  return nil" + "\n"
+		out += "}" + "\n"
+	}
+
+	texpr, err := getTypeExpr(ic, name, typ)
+	if err != nil {
+		return "", err
+	}
+	out += "if v, ok := value.(" + texpr + "); ok {" + "\n"
+	out += "  " + name + " = v" + "\n"
+	out += "  return nil" + "\n"
+	out += "}" + "\n"
+	out += "return fmt.Errorf(\"ffjson: cannot set field %q of type %s to %T\", " + key + ", " + strconv.Quote(texpr) + ", value)" + "\n"
+	return out, nil
+}
+
+// getTypeExpr returns the Go expression for typ, also for unnamed
+// composite types of named types, which are written with getType so they
+// are unqualified in the generated package and imported otherwise.
+// Unnamed structs and interfaces with unexported members of another
+// package can't be written, and return an error.
+func getTypeExpr(ic *Inception, name string, typ reflect.Type) (string, error) {
+	if typ.Name() != "" {
+		return getType(ic, name, typ), nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := getTypeExpr(ic, name, typ.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := getTypeExpr(ic, name, typ.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := getTypeExpr(ic, name, typ.Elem())
+		return fmt.Sprintf("[%d]", typ.Len()) + elem, err
+	case reflect.Map:
+		key, err := getTypeExpr(ic, name, typ.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := getTypeExpr(ic, name, typ.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Chan:
+		elem, err := getTypeExpr(ic, name, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		switch typ.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + elem, nil
+		case reflect.SendDir:
+			return "chan<- " + elem, nil
+		}
+		if typ.Elem().Kind() == reflect.Chan && typ.Elem().Name() == "" && typ.Elem().ChanDir() == reflect.RecvDir {
+			// chan <-chan T would be chan<- (chan T).
+			return "chan (" + elem + ")", nil
+		}
+		return "chan " + elem, nil
+	case reflect.Func:
+		sig, err := getSignatureExpr(ic, name, typ)
+		return "func" + sig, err
+	case reflect.Struct:
+		out := "struct {"
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" && f.PkgPath != ic.PackagePath {
+				return "", fmt.Errorf("ffjson: can't write %s in package %s: unexported field %s of package %s", typ, ic.PackagePath, f.Name, f.PkgPath)
+			}
+			ftyp, err := getTypeExpr(ic, name, f.Type)
+			if err != nil {
+				return "", err
+			}
+			if i > 0 {
+				out += ";"
+			}
+			if f.Anonymous {
+				out += " " + ftyp
+			} else {
+				out += " " + f.Name + " " + ftyp
+			}
+			if f.Tag != "" && !strings.Contains(string(f.Tag), "`") {
+				out += " `" + string(f.Tag) + "`"
+			} else if f.Tag != "" {
+				out += " " + strconv.Quote(string(f.Tag))
+			}
+		}
+		if typ.NumField() > 0 {
+			out += " "
+		}
+		return out + "}", nil
+	case reflect.Interface:
+		if typ.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+		out := "interface {"
+		for i := 0; i < typ.NumMethod(); i++ {
+			m := typ.Method(i)
+			if m.PkgPath != "" && m.PkgPath != ic.PackagePath {
+				return "", fmt.Errorf("ffjson: can't write %s in package %s: unexported method %s of package %s", typ, ic.PackagePath, m.Name, m.PkgPath)
+			}
+			sig, err := getSignatureExpr(ic, name, m.Type)
+			if err != nil {
+				return "", err
+			}
+			if i > 0 {
+				out += ";"
+			}
+			out += " " + m.Name + sig
+		}
+		return out + " }", nil
+	}
+	return "", fmt.Errorf("ffjson: can't write the type %s", typ)
+}
+
+// mustTypeExpr is getTypeExpr for code written by functions without an
+// error result, panicking like tplStr does when a template fails.
+func mustTypeExpr(ic *Inception, name string, typ reflect.Type) string {
+	texpr, err := getTypeExpr(ic, name, typ)
+	if err != nil {
+		panic(err)
+	}
+	return texpr
+}
+
+// getSignatureExpr returns the parameters and results of the func type
+// typ, as written after func or the name of a method.
+func getSignatureExpr(ic *Inception, name string, typ reflect.Type) (string, error) {
+	out := "("
+	for i := 0; i < typ.NumIn(); i++ {
+		in := typ.In(i)
+		prefix := ""
+		if typ.IsVariadic() && i == typ.NumIn()-1 {
+			in = in.Elem()
+			prefix = "..."
+		}
+		texpr, err := getTypeExpr(ic, name, in)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			out += ", "
+		}
+		out += prefix + texpr
+	}
+	out += ")"
+
+	results := ""
+	for i := 0; i < typ.NumOut(); i++ {
+		texpr, err := getTypeExpr(ic, name, typ.Out(i))
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			results += ", "
+		}
+		results += texpr
+	}
+	switch {
+	case typ.NumOut() == 1:
+		out += " " + results
+	case typ.NumOut() > 1:
+		out += " (" + results + ")"
+	}
+	return out, nil
+}
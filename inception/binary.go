@@ -0,0 +1,656 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+)
+
+var binaryMarshalerType = reflect.TypeOf(new(fflib.BinaryMarshaler)).Elem()
+var binaryUnmarshalerType = reflect.TypeOf(new(fflib.BinaryUnmarshaler)).Elem()
+var byteType = reflect.TypeOf(byte(0))
+
+// binaryFormat is a binary format of -formats, with the names of its
+// methods and of the functions creating its writers and readers.
+type binaryFormat struct {
+	Name   string
+	Method string
+	Writer string
+	Reader string
+}
+
+var msgpackFormat = binaryFormat{"MessagePack", "Msgpack", "NewMsgpackWriter", "NewMsgpackReader"}
+var cborFormat = binaryFormat{"CBOR", "CBOR", "NewCBORWriter", "NewCBORReader"}
+
+// binaryGen generates the code writing and reading the fields of a
+// struct with a fflib.BinaryWriter and a fflib.BinaryReader.
+type binaryGen struct {
+	ic *Inception
+	si *StructInfo
+	// n numbers the variables of nested values.
+	n int
+}
+
+func (b *binaryGen) tmp(prefix string) string {
+	b.n++
+	return prefix + strconv.Itoa(b.n)
+}
+
+// CreateBinary generates the MarshalBinaryFF and UnmarshalBinaryFF
+// methods, writing a struct as a map of the json names of its fields to
+// their values, and the methods of each binary format of -formats calling
+// them.
+func CreateBinary(ic *Inception, si *StructInfo) error {
+	switch {
+	case si.Options.Tuple:
+		return fmt.Errorf("%s: -formats can't be combined with tuple", si.Name)
+	case si.Options.Refs:
+		return fmt.Errorf("%s: -formats can't be combined with refs", si.Name)
+	case si.Options.EnvelopeKey != "":
+		return fmt.Errorf("%s: -formats can't be combined with envelope", si.Name)
+	case si.Options.UnwrapType:
+		return fmt.Errorf("%s: -formats can't be combined with unwraptype", si.Name)
+	}
+	for _, f := range si.Fields {
+		if f.TriState {
+			return fmt.Errorf("%s.%s: tristate can't be used with -formats", si.Name, f.Name)
+		}
+	}
+
+	var formats []binaryFormat
+	if si.Options.Msgpack {
+		formats = append(formats, msgpackFormat)
+	}
+	if si.Options.CBOR {
+		formats = append(formats, cborFormat)
+	}
+	methods := make([]string, 0, len(formats))
+	for _, f := range formats {
+		methods = append(methods, f.Method)
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	b := &binaryGen{ic: ic, si: si}
+	out := ""
+	if !si.Options.SkipEncoder {
+		body, err := b.encodeStruct("j.", si.Fields)
+		if err != nil {
+			return err
+		}
+		out += "// MarshalBinaryFF writes j as a map of its fields, for Marshal" + strings.Join(methods, " and Marshal") + " - template of ffjson\n"
+		out += "func (j *" + si.Name + ") MarshalBinaryFF(w fflib.BinaryWriter) error {" + "\n"
+		out += "if j == nil {" + "\n"
+		out += "w.WriteNil()" + "\n"
+		out += "return nil" + "\n"
+		out += "}" + "\n"
+		out += "var err error" + "\n"
+		out += "_ = err" + "\n"
+		out += body
+		out += "return nil" + "\n"
+		out += "}" + "\n\n"
+
+		for _, f := range formats {
+			out += "// Marshal" + f.Method + " marshals j as " + f.Name + " - template of ffjson\n"
+			out += "func (j *" + si.Name + ") Marshal" + f.Method + "() ([]byte, error) {" + "\n"
+			out += "w := fflib." + f.Writer + "(nil)" + "\n"
+			out += "err := j.MarshalBinaryFF(w)" + "\n"
+			out += "if err != nil {" + "\n"
+			out += "return nil, err" + "\n"
+			out += "}" + "\n"
+			out += "return w.Bytes(), nil" + "\n"
+			out += "}" + "\n\n"
+		}
+	}
+
+	if !si.Options.SkipDecoder {
+		body, err := b.decodeStruct("j.", si.Fields)
+		if err != nil {
+			return err
+		}
+		out += "// UnmarshalBinaryFF reads the map of the fields of j, for Unmarshal" + strings.Join(methods, " and Unmarshal") + " - template of ffjson\n"
+		out += "func (j *" + si.Name + ") UnmarshalBinaryFF(r fflib.BinaryReader) error {" + "\n"
+		out += "var err error" + "\n"
+		out += "_ = err" + "\n"
+		out += "if r.ReadNil() {" + "\n"
+		out += "return nil" + "\n"
+		out += "}" + "\n"
+		out += body
+		out += "return nil" + "\n"
+		out += "}" + "\n\n"
+
+		for _, f := range formats {
+			out += "// Unmarshal" + f.Method + " unmarshals the " + f.Name + " of data into j - template of ffjson\n"
+			out += "func (j *" + si.Name + ") Unmarshal" + f.Method + "(data []byte) error {" + "\n"
+			out += "r := fflib." + f.Reader + "(data)" + "\n"
+			out += "err := j.UnmarshalBinaryFF(r)" + "\n"
+			out += "if err != nil {" + "\n"
+			out += "return err" + "\n"
+			out += "}" + "\n"
+			out += "return r.Finish()" + "\n"
+			out += "}" + "\n\n"
+		}
+	}
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// binaryFieldType returns the type of the value of f.
+func binaryFieldType(f *StructField) reflect.Type {
+	if f.Pointer && f.Typ.Kind() != reflect.Ptr {
+		return reflect.PtrTo(f.Typ)
+	}
+	return f.Typ
+}
+
+// binaryKey returns the Go string literal of the json name of f.
+func binaryKey(f *StructField) (string, error) {
+	var name string
+	err := json.Unmarshal([]byte(f.JsonName), &name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Quote(name), nil
+}
+
+// binaryCondition returns the condition of writing f, or "" if it is
+// always written.
+func (b *binaryGen) binaryCondition(f *StructField, prefix string) string {
+	var conds []string
+	if len(f.Embedded) > 0 {
+		conds = append(conds, getEmbeddedCheck(f, prefix))
+	}
+	if f.OmitEmpty {
+		name := prefix + f.Name
+		switch typ := binaryFieldType(f); typ.Kind() {
+		case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+			conds = append(conds, "len("+name+") != 0")
+		case reflect.Bool:
+			conds = append(conds, name)
+		case reflect.Ptr, reflect.Interface:
+			conds = append(conds, name+" != nil")
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			conds = append(conds, name+" != 0")
+		}
+	}
+	if f.OmitZero {
+		conds = append(conds, getOmitZero(b.ic, f, prefix+f.Name))
+	}
+	return strings.Join(conds, " && ")
+}
+
+// encodeStruct writes fields as a map.
+func (b *binaryGen) encodeStruct(prefix string, fields []*StructField) (string, error) {
+	out := ""
+	n := b.tmp("n")
+	count := 0
+	for _, f := range fields {
+		if cond := b.binaryCondition(f, prefix); cond != "" {
+			out += "if " + cond + " {" + "\n"
+			out += n + "++" + "\n"
+			out += "}" + "\n"
+		} else {
+			count++
+		}
+	}
+	if out == "" {
+		out = "w.WriteMapHeader(" + strconv.Itoa(count) + ")" + "\n"
+	} else {
+		out = n + " := " + strconv.Itoa(count) + "\n" + out
+		out += "w.WriteMapHeader(" + n + ")" + "\n"
+	}
+
+	for _, f := range fields {
+		key, err := binaryKey(f)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", b.si.Name, f.Name, err)
+		}
+		value, err := b.encodeValue(prefix+f.Name, binaryFieldType(f))
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", b.si.Name, f.Name, err)
+		}
+		cond := b.binaryCondition(f, prefix)
+		if cond != "" {
+			out += "if " + cond + " {" + "\n"
+		}
+		out += "w.WriteString(" + key + ")" + "\n"
+		out += value
+		if cond != "" {
+			out += "}" + "\n"
+		}
+	}
+	return out, nil
+}
+
+// hasBinaryType reports whether typ has MarshalBinaryFF and
+// UnmarshalBinaryFF methods, or will get them.
+func (b *binaryGen) hasBinaryType(typ reflect.Type, iface reflect.Type, encode bool) bool {
+	if typ.Implements(iface) || reflect.PtrTo(typ).Implements(iface) {
+		return true
+	}
+	for _, si := range b.ic.objs {
+		if si.Typ == typ {
+			if encode && si.Options.SkipEncoder || !encode && si.Options.SkipDecoder {
+				return false
+			}
+			return si.Options.Msgpack || si.Options.CBOR
+		}
+	}
+	return false
+}
+
+// encodeValue writes the value name of type typ.
+func (b *binaryGen) encodeValue(name string, typ reflect.Type) (string, error) {
+	switch {
+	case typ == timeType:
+		return "w.WriteTime(" + name + ")" + "\n", nil
+	case typ.Kind() == reflect.Ptr:
+		value, err := b.encodeValue("(*"+name+")", typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out := "if " + name + " == nil {" + "\n"
+		out += "w.WriteNil()" + "\n"
+		out += "} else {" + "\n"
+		out += value
+		out += "}" + "\n"
+		return out, nil
+	case typ.Kind() == reflect.Interface:
+		// Written with the type of their value.
+	case b.hasBinaryType(typ, binaryMarshalerType, true):
+		out := "err = " + name + ".MarshalBinaryFF(w)" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType):
+		text := b.tmp("text")
+		out := "{" + "\n"
+		out += text + ", err := " + name + ".MarshalText()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "w.WriteString(string(" + text + "))" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "w.WriteBool(bool(" + name + "))" + "\n", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "w.WriteInt(int64(" + name + "))" + "\n", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "w.WriteUint(uint64(" + name + "))" + "\n", nil
+	case reflect.Float32:
+		return "w.WriteFloat32(float32(" + name + "))" + "\n", nil
+	case reflect.Float64:
+		return "w.WriteFloat64(float64(" + name + "))" + "\n", nil
+	case reflect.String:
+		return "w.WriteString(string(" + name + "))" + "\n", nil
+	case reflect.Interface:
+		out := "err = fflib.WriteBinaryValue(w, " + name + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		out := ""
+		if typ.Kind() == reflect.Slice {
+			out += "if " + name + " == nil {" + "\n"
+			out += "w.WriteNil()" + "\n"
+			out += "} else {" + "\n"
+			if typ.Elem() == byteType {
+				out += "w.WriteBytes([]byte(" + name + "))" + "\n"
+				out += "}" + "\n"
+				return out, nil
+			}
+		}
+		i := b.tmp("i")
+		value, err := b.encodeValue(name+"["+i+"]", typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out += "w.WriteArrayHeader(len(" + name + "))" + "\n"
+		out += "for " + i + " := range " + name + " {" + "\n"
+		out += value
+		out += "}" + "\n"
+		if typ.Kind() == reflect.Slice {
+			out += "}" + "\n"
+		}
+		return out, nil
+	case reflect.Map:
+		err := checkBinaryKey(typ.Key())
+		if err != nil {
+			return "", err
+		}
+		k, v := b.tmp("k"), b.tmp("v")
+		key, err := b.encodeValue(k, typ.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := b.encodeValue(v, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out := "if " + name + " == nil {" + "\n"
+		out += "w.WriteNil()" + "\n"
+		out += "} else {" + "\n"
+		out += "w.WriteMapHeader(len(" + name + "))" + "\n"
+		out += "for " + k + ", " + v + " := range " + name + " {" + "\n"
+		out += key
+		out += value
+		out += "}" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Struct:
+		if typ.Name() == "" {
+			fields := extractFields(reflect.New(typ).Elem().Interface())
+			return b.encodeStruct(name+".", fields)
+		}
+		return "", fmt.Errorf("type %v has no MarshalBinaryFF method, it needs code generated with -formats", typ)
+	}
+	return "", fmt.Errorf("type %v isn't supported with -formats", typ)
+}
+
+// checkBinaryKey checks that maps with keys of type key can be written
+// and read.
+func checkBinaryKey(key reflect.Type) error {
+	switch key.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return nil
+	}
+	if key.Implements(textMarshalerType) && reflect.PtrTo(key).Implements(textUnmarshalerType) {
+		return nil
+	}
+	return fmt.Errorf("map key type %v must be a string or an integer, or implement encoding.TextMarshaler and TextUnmarshaler", key)
+}
+
+// decodeStruct reads a map into fields.
+func (b *binaryGen) decodeStruct(prefix string, fields []*StructField) (string, error) {
+	n, i, key := b.tmp("n"), b.tmp("i"), b.tmp("key")
+	out := "var " + n + " int" + "\n"
+	out += n + ", err = r.ReadMapHeader()" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "return err" + "\n"
+	out += "}" + "\n"
+	out += "for " + i + " := 0; " + i + " < " + n + "; " + i + "++ {" + "\n"
+	out += "var " + key + " []byte" + "\n"
+	out += key + ", err = r.ReadKey()" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "return err" + "\n"
+	out += "}" + "\n"
+	out += "switch string(" + key + ") {" + "\n"
+	for _, f := range fields {
+		k, err := binaryKey(f)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", b.si.Name, f.Name, err)
+		}
+		value, err := b.decodeValue(prefix+f.Name, binaryFieldType(f))
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", b.si.Name, f.Name, err)
+		}
+		out += "case " + k + ":" + "\n"
+		for _, e := range f.Embedded {
+			out += "if " + prefix + e.Name + " == nil {" + "\n"
+			out += prefix + e.Name + " = new(" + getType(b.ic, e.Name, e.Typ) + ")" + "\n"
+			out += "}" + "\n"
+		}
+		out += value
+	}
+	out += "default:" + "\n"
+	if b.si.Options.DisallowUnknownFields {
+		out += "return r.Errorf(\"unknown field %q\", " + key + ")" + "\n"
+	} else {
+		out += "if fflib.DisallowUnknownFields() {" + "\n"
+		out += "return r.Errorf(\"unknown field %q\", " + key + ")" + "\n"
+		out += "}" + "\n"
+		out += "err = r.Skip()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+	}
+	out += "}" + "\n"
+	out += "}" + "\n"
+	return out, nil
+}
+
+// decodeValue reads the value name of type typ. nil sets pointers,
+// slices, maps and interfaces to nil, and leaves other values unchanged,
+// as null does with encoding/json.
+func (b *binaryGen) decodeValue(name string, typ reflect.Type) (string, error) {
+	value, err := b.decodeNonNil(name, typ)
+	if err != nil {
+		return "", err
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		out := "if r.ReadNil() {" + "\n"
+		out += name + " = nil" + "\n"
+		out += "} else {" + "\n"
+		out += value
+		out += "}" + "\n"
+		return out, nil
+	}
+	out := "if !r.ReadNil() {" + "\n"
+	out += value
+	out += "}" + "\n"
+	return out, nil
+}
+
+// decodeNonNil reads the value name of type typ, which isn't nil.
+func (b *binaryGen) decodeNonNil(name string, typ reflect.Type) (string, error) {
+	texpr, err := getTypeExpr(b.ic, name, typ)
+	if err != nil {
+		return "", err
+	}
+	// read reads a value with the method of the reader into a variable
+	// of type vtyp, and converts it.
+	read := func(method string, vtyp string, check string) string {
+		v := b.tmp("v")
+		out := "var " + v + " " + vtyp + "\n"
+		out += v + ", err = r." + method + "()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += name + " = " + texpr + "(" + v + ")" + "\n"
+		if check != "" {
+			out += "if " + check + "(" + name + ") != " + v + " {" + "\n"
+			out += "return r.Errorf(\"integer %d overflows " + typ.Kind().String() + "\", " + v + ")" + "\n"
+			out += "}" + "\n"
+		}
+		return out
+	}
+
+	switch {
+	case typ == timeType:
+		out := name + ", err = r.ReadTime()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case typ.Kind() == reflect.Ptr:
+		value, err := b.decodeValue("(*"+name+")", typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		elem, err := getTypeExpr(b.ic, name, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out := "if " + name + " == nil {" + "\n"
+		out += name + " = new(" + elem + ")" + "\n"
+		out += "}" + "\n"
+		out += value
+		return out, nil
+	case typ.Kind() == reflect.Interface:
+	case b.hasBinaryType(typ, binaryUnmarshalerType, false):
+		out := "err = " + name + ".UnmarshalBinaryFF(r)" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.PtrTo(typ).Implements(textUnmarshalerType):
+		text := b.tmp("text")
+		out := "{" + "\n"
+		out += "var " + text + " []byte" + "\n"
+		out += text + ", err = r.ReadKey()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "err = " + name + ".UnmarshalText(" + text + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return read("ReadBool", "bool", ""), nil
+	case reflect.Int64:
+		return read("ReadInt", "int64", ""), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return read("ReadInt", "int64", "int64"), nil
+	case reflect.Uint64:
+		return read("ReadUint", "uint64", ""), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uintptr:
+		return read("ReadUint", "uint64", "uint64"), nil
+	case reflect.Float32, reflect.Float64:
+		return read("ReadFloat", "float64", ""), nil
+	case reflect.String:
+		return read("ReadString", "string", ""), nil
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			return "return r.Errorf(" + strconv.Quote("cannot decode into "+texpr) + ")" + "\n", nil
+		}
+		out := name + ", err = fflib.ReadBinaryValue(r)" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Slice:
+		if typ.Elem() == byteType {
+			return read("ReadBytes", "[]byte", ""), nil
+		}
+		n, i := b.tmp("n"), b.tmp("i")
+		value, err := b.decodeValue(name+"["+i+"]", typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out := "var " + n + " int" + "\n"
+		out += n + ", err = r.ReadArrayHeader()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += name + " = make(" + texpr + ", " + n + ")" + "\n"
+		out += "for " + i + " := range " + name + " {" + "\n"
+		out += value
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Array:
+		n, i := b.tmp("n"), b.tmp("i")
+		value, err := b.decodeValue(name+"["+i+"]", typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		elem, err := getTypeExpr(b.ic, name, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		// Like encoding/json, elements missing are zero, and extra
+		// elements are skipped.
+		zero := b.tmp("zero")
+		out := "var " + n + " int" + "\n"
+		out += n + ", err = r.ReadArrayHeader()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "for " + i + " := 0; " + i + " < " + n + "; " + i + "++ {" + "\n"
+		out += "if " + i + " >= len(" + name + ") {" + "\n"
+		out += "err = r.Skip()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "continue" + "\n"
+		out += "}" + "\n"
+		out += value
+		out += "}" + "\n"
+		out += "var " + zero + " " + elem + "\n"
+		out += "for " + i + " := " + n + "; " + i + " < len(" + name + "); " + i + "++ {" + "\n"
+		out += name + "[" + i + "] = " + zero + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Map:
+		err := checkBinaryKey(typ.Key())
+		if err != nil {
+			return "", err
+		}
+		n, i, k, v := b.tmp("n"), b.tmp("i"), b.tmp("k"), b.tmp("v")
+		key, err := b.decodeValue(k, typ.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := b.decodeValue(v, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		ktyp, err := getTypeExpr(b.ic, k, typ.Key())
+		if err != nil {
+			return "", err
+		}
+		vtyp, err := getTypeExpr(b.ic, v, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		out := "var " + n + " int" + "\n"
+		out += n + ", err = r.ReadMapHeader()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "return err" + "\n"
+		out += "}" + "\n"
+		out += "if " + name + " == nil {" + "\n"
+		out += name + " = make(" + texpr + ", " + n + ")" + "\n"
+		out += "}" + "\n"
+		out += "for " + i + " := 0; " + i + " < " + n + "; " + i + "++ {" + "\n"
+		out += "var " + k + " " + ktyp + "\n"
+		out += key
+		out += "var " + v + " " + vtyp + "\n"
+		out += value
+		out += name + "[" + k + "] = " + v + "\n"
+		out += "}" + "\n"
+		return out, nil
+	case reflect.Struct:
+		if typ.Name() == "" {
+			fields := extractFields(reflect.New(typ).Elem().Interface())
+			return b.decodeStruct(name+".", fields)
+		}
+		return "", fmt.Errorf("type %v has no UnmarshalBinaryFF method, it needs code generated with -formats", typ)
+	}
+	return "", fmt.Errorf("type %v isn't supported with -formats", typ)
+}
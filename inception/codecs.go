@@ -0,0 +1,68 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/maxproc/ffjson/shared"
+)
+
+// getCodec returns the codec of -codecs for typ, if any.
+func getCodec(ic *Inception, typ reflect.Type) (shared.Codec, bool) {
+	if typ.Name() == "" || typ.PkgPath() == "" {
+		return shared.Codec{}, false
+	}
+	c, ok := ic.codecs[typ.PkgPath()+"."+typ.Name()]
+	return c, ok
+}
+
+// getCodecFunc returns the function fn of the codec c, importing its
+// package as ffjc<name>, so it can't clash with the other imports.
+func getCodecFunc(ic *Inception, c shared.Codec, fn string) string {
+	if c.Package == "" || c.Package == ic.PackagePath {
+		return fn
+	}
+	base := c.Package[strings.LastIndex(c.Package, "/")+1:]
+	name := "ffjc" + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, base)
+	ic.OutputImports[name+` "`+c.Package+`"`] = true
+	return name + "." + fn
+}
+
+// getCodecValue writes the value name of type typ, a pointer to it if
+// ptr is set, with the marshal function of its codec.
+func getCodecValue(ic *Inception, name string, typ reflect.Type, ptr bool, c shared.Codec) string {
+	ptname := name
+	if ptr {
+		ptname = "*" + name
+	}
+	out := ic.q.Flush()
+	out += "/* Codec. type=" + typ.String() + " */\n"
+	out += "err = " + getCodecFunc(ic, c, c.Marshal) + "(buf, " + ptname + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	return out
+}
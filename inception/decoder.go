@@ -18,11 +18,16 @@
 package ffjsoninception
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
+	fflib "github.com/maxproc/ffjson/fflib/v1"
 	"github.com/maxproc/ffjson/shared"
+	"golang.org/x/text/unicode/norm"
 )
 
 var validValues []string = []string{
@@ -38,28 +43,386 @@ var validValues []string = []string{
 func CreateUnmarshalJSON(ic *Inception, si *StructInfo) error {
 	out := ""
 	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	if si.Options.Tuple {
+		return createTupleUnmarshal(ic, si)
+	}
 	if len(si.Fields) > 0 {
 		ic.OutputImports[`"bytes"`] = true
 	}
 	ic.OutputImports[`"fmt"`] = true
 
+	// Grouped fields are decoded by the functions of their groups.
+	var groups []*groupMember
+	if hasGroups(si) {
+		groups = groupFields(si.Fields)
+		top := *si
+		top.Fields = groupDecodeFields(si, groups)
+		si = &top
+	}
+
+	// Split times are decoded as a field for each part.
+	if hasSplitTimes(si) {
+		top := *si
+		top.Fields = splitDecodeFields(si.Fields)
+		si = &top
+	}
+
+	if si.Options.NormalizeKeys {
+		ic.OutputImports[`"golang.org/x/text/unicode/norm"`] = true
+		err := normalizeKeys(si)
+		if err != nil {
+			return err
+		}
+	}
+	for _, f := range si.Fields {
+		if f.TriState {
+			ic.OutputImports[`"github.com/maxproc/ffjson/shared"`] = true
+		}
+	}
+
 	out += tplStr(decodeTpl["header"], header{
 		IC: ic,
 		SI: si,
 	})
 
+	lexerFunc := "UnmarshalJSONFFLexer"
+	if si.Options.EnvelopeKey != "" || si.Options.UnwrapType {
+		lexerFunc = "unmarshalJSONFFLexerPayload"
+	}
+	if si.Options.Refs {
+		lexerFunc = "unmarshalJSONFFLexerRefs"
+	}
+
 	out += tplStr(decodeTpl["ujFunc"], ujFunc{
 		SI:          si,
 		IC:          ic,
 		ValidValues: validValues,
-		ResetFields: ic.ResetFields,
+		ResetFields: si.Options.ResetFields,
+		LexerFunc:   lexerFunc,
 	})
 
 	ic.OutputFuncs = append(ic.OutputFuncs, out)
 
+	if groups != nil {
+		err := createGroupUnmarshal(ic, si, groups)
+		if err != nil {
+			return err
+		}
+	}
+	if si.Options.Refs {
+		createRefsUnmarshal(ic, si)
+	}
+	if si.Options.EnvelopeKey != "" {
+		return createEnvelopeUnmarshal(ic, si)
+	}
+	if si.Options.UnwrapType {
+		createUnwrapTypeUnmarshal(ic, si)
+	}
+	return nil
+}
+
+// normalizeKeys converts the keys matched by the decoder to Unicode NFC,
+// the form incoming keys are converted to before matching.
+func normalizeKeys(si *StructInfo) error {
+	seen := make(map[string]string, len(si.Fields))
+	for _, f := range si.Fields {
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		name = norm.NFC.String(name)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("%s: fields %s and %s have the same normalized key %q", si.Name, other, f.Name, name)
+		}
+		seen[name] = f.Name
+
+		var buf bytes.Buffer
+		fflib.WriteJsonString(&buf, name)
+		f.KeyName = buf.String()
+		f.FoldFuncName = foldFunc([]byte(name))
+	}
 	return nil
 }
 
+// handleStructField generates the decoder for a top level struct field,
+// taking the options from the field's ffjson tag into account.
+func handleStructField(ic *Inception, name string, sf *StructField) string {
+	out := ""
+	if sf.TriState {
+		out += "if tok == fflib.FFTok_null {\n"
+		out += name + "State = shared.TriStateNull\n"
+		out += "} else {\n"
+		out += name + "State = shared.TriStateSet\n"
+		out += "}\n"
+	}
+	out += handleFieldOptions(ic, name, sf)
+	if sf.Min != "" || sf.Max != "" {
+		out += handleRange(name, sf)
+	}
+	if sf.NullAsEmpty {
+		out = handleNullAsEmpty(ic, name, sf, out)
+	}
+	if sf.Unwrap != "" {
+		return tplStr(decodeTpl["handleUnwrap"], handleUnwrap{
+			Name:     name,
+			JsonName: sf.JsonName,
+			First:    sf.Unwrap == "first",
+			Handler:  out,
+		})
+	}
+	return out
+}
+
+// handleRange checks that a decoded numeric value is within the bounds of
+// the min and max options. null leaves the field unchanged, so it isn't
+// checked.
+func handleRange(name string, sf *StructField) string {
+	// The json name is part of the format string.
+	jsonName := strings.Replace(sf.JsonName, "%", "%%", -1)
+	value := name
+	cond := "tok != fflib.FFTok_null"
+	if sf.Pointer {
+		value = "*" + name
+		cond = name + " != nil"
+	}
+
+	out := "/* range */\n"
+	if sf.Min != "" {
+		out += "if " + cond + " && " + value + " < " + sf.Min + " {\n"
+		out += "return fs.WrapErr(fmt.Errorf(" + strconv.Quote("ffjson: %v is below the minimum "+sf.Min+" for "+jsonName) + ", " + value + "))\n"
+		out += "}\n"
+	}
+	if sf.Max != "" {
+		out += "if " + cond + " && " + value + " > " + sf.Max + " {\n"
+		out += "return fs.WrapErr(fmt.Errorf(" + strconv.Quote("ffjson: %v is above the maximum "+sf.Max+" for "+jsonName) + ", " + value + "))\n"
+		out += "}\n"
+	}
+	return out
+}
+
+// handleNullAsEmpty wraps the handler of a slice or map field, setting
+// it to an empty value for null instead.
+func handleNullAsEmpty(ic *Inception, name string, sf *StructField, handler string) string {
+	empty := "make(" + mustTypeExpr(ic, name, sf.Typ) + ", 0"
+	if sf.SliceCap > 0 {
+		empty += ", " + strconv.Itoa(sf.SliceCap)
+	}
+	empty += ")"
+
+	out := "/* nullasempty */\n"
+	out += "if tok == fflib.FFTok_null {\n"
+	out += name + " = " + empty + "\n"
+	out += "} else {\n"
+	out += handler
+	out += "}\n"
+	return out
+}
+
+func handleFieldOptions(ic *Inception, name string, sf *StructField) string {
+	if sf.GroupFunc != "" {
+		out := fmt.Sprintf("/* handler: %s group=%s */\n", name, sf.JsonName)
+		return out + tplStr(decodeTpl["handleGroup"], handleGroup{
+			Name: strings.Trim(sf.JsonName, `"`),
+			Func: sf.GroupFunc,
+		})
+	}
+	if sf.SplitPart != "" {
+		out := fmt.Sprintf("/* handler: %s split=%s */\n", name, sf.SplitPart)
+		return out + tplStr(decodeTpl["handleSplitPart"], handleSplitPart{
+			Name: sf.SplitField,
+			Var:  "ffjSplit" + sf.Name,
+		})
+	}
+	switch sf.Ref {
+	case "pointer":
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v ref*/\n", name, sf.Typ, sf.Typ.Kind())
+		return out + handleRef(ic, name, sf.Typ, "refs.Push("+strconv.Quote(sf.RefName)+")")
+	case "slice":
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v ref*/\n", name, sf.Typ, sf.Typ.Kind())
+		return out + tplStr(decodeTpl["handleRefSlice"], handleRefSlice{
+			IC:    ic,
+			Name:  name,
+			Typ:   sf.Typ,
+			Token: strconv.Quote(sf.RefName),
+		})
+	}
+	if len(sf.Candidates) > 0 {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v candidates=%v*/\n", name, sf.Typ, sf.Typ.Kind(), sf.Candidates)
+		if sf.Typ.Kind() == reflect.Slice {
+			return out + tplStr(decodeTpl["handleSlice"], handleArray{
+				IC:         ic,
+				Name:       name,
+				Typ:        sf.Typ,
+				Ptr:        reflect.Ptr,
+				Cap:        sf.SliceCap,
+				Candidates: sf.Candidates,
+			})
+		}
+		return out + handleCandidates(name, sf.Candidates)
+	}
+	if sf.Polymorphic != "" {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v polymorphic=%s*/\n", name, sf.Typ, sf.Typ.Kind(), sf.Polymorphic)
+		if sf.Typ.Kind() == reflect.Slice {
+			return out + tplStr(decodeTpl["handleSlice"], handleArray{
+				IC:          ic,
+				Name:        name,
+				Typ:         sf.Typ,
+				Ptr:         reflect.Ptr,
+				Cap:         sf.SliceCap,
+				Polymorphic: sf.Polymorphic,
+			})
+		}
+		return out + handlePolymorphic(ic, name, sf.Typ, sf.Polymorphic)
+	}
+	if sf.Scale != "" {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v scale=%s*/\n", name, sf.Typ, sf.Typ.Kind(), sf.Scale)
+		return out + tplStr(decodeTpl["handleScaled"], handleScaled{
+			IC:       ic,
+			Name:     name,
+			Typ:      sf.Typ,
+			TakeAddr: sf.Pointer,
+			Scale:    sf.Scale,
+			Round:    sf.ScaleRound,
+		})
+	}
+	if sf.Epoch != "" {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v epoch=%s*/\n", name, sf.Typ, sf.Typ.Kind(), sf.Epoch)
+		return out + tplStr(decodeTpl["handleEpoch"], handleEpoch{
+			Name:      name,
+			TakeAddr:  sf.Pointer,
+			Epoch:     sf.Epoch,
+			PerSecond: sf.EpochUnit,
+		})
+	}
+	if sf.Layout != "" {
+		ic.OutputImports[`"time"`] = true
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v layout=%q*/\n", name, sf.Typ, sf.Typ.Kind(), sf.Layout)
+		return out + tplStr(decodeTpl["handleLayout"], handleLayout{
+			IC:       ic,
+			Name:     name,
+			Typ:      sf.Typ,
+			TakeAddr: sf.Pointer,
+			Slice:    sf.Typ.Kind() == reflect.Slice,
+			Layout:   strconv.Quote(sf.Layout),
+		})
+	}
+	if sf.GroupSep != 0 {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v grouped=%q*/\n", name, sf.Typ, sf.Typ.Kind(), sf.GroupSep)
+		parseFunc := "ParseFloat"
+		switch kind := sf.Typ.Kind(); {
+		case kind >= reflect.Int && kind <= reflect.Int64:
+			parseFunc = "ParseInt"
+		case kind >= reflect.Uint && kind <= reflect.Uint64:
+			parseFunc = "ParseUint"
+		}
+		return out + tplStr(decodeTpl["handleGrouped"], handleGrouped{
+			IC:        ic,
+			Name:      name,
+			Typ:       sf.Typ,
+			TakeAddr:  sf.Pointer,
+			ParseFunc: parseFunc,
+			Sep:       strconv.QuoteRune(rune(sf.GroupSep)),
+			Point:     strconv.QuoteRune(rune(sf.GroupPoint)),
+		})
+	}
+	if sf.StreamString {
+		ic.OutputImports[`"bytes"`] = true
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v streamstring*/\n", name, sf.Typ, sf.Typ.Kind())
+		return out + tplStr(decodeTpl["handleStream"], handleStream{
+			Name: name,
+		})
+	}
+	if len(sf.Flags) > 0 {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v flags=%s*/\n", name, sf.Typ, sf.Typ.Kind(), strings.Join(sf.Flags, "|"))
+		return out + tplStr(decodeTpl["handleFlags"], handleFlags{
+			IC:     ic,
+			Name:   name,
+			Typ:    sf.Typ,
+			Names:  getFlagsNames(sf.Flags),
+			Ignore: sf.FlagsIgnore,
+		})
+	}
+	if len(sf.Enum) > 0 {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v enum=%s*/\n", name, sf.Typ, sf.Typ.Kind(), strings.Join(sf.Enum, "|"))
+		return out + tplStr(decodeTpl["handleEnum"], handleEnum{
+			IC:       ic,
+			Name:     name,
+			JsonName: sf.JsonName,
+			Typ:      sf.Typ,
+			Values:   getEnumValues(sf.Enum),
+			Unknown:  sf.EnumUnknown,
+		})
+	}
+	if sf.BoolString != "" && !hasUnmarshaler(ic, sf.Typ) {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v boolstring=%s*/\n", name, sf.Typ, sf.Typ.Kind(), sf.BoolString)
+		return out + tplStr(decodeTpl["handleBoolString"], handleBoolString{
+			IC:       ic,
+			Name:     name,
+			Typ:      sf.Typ,
+			TakeAddr: sf.Pointer,
+			Fold:     sf.BoolString == "fold",
+		})
+	}
+	if sf.SliceCap > 0 && !hasUnmarshaler(ic, sf.Typ) {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v cap=%d*/\n", name, sf.Typ, sf.Typ.Kind(), sf.SliceCap)
+		return out + getArrayHandler(ic, name, sf.Typ, sf.Pointer, sf.SliceCap)
+	}
+	return handleField(ic, name, sf.Typ, sf.Pointer, sf.ForceString)
+}
+
+// handleFallbackValue generates the decode of the current value with
+// encoding/json, for types without generated code.
+func handleFallbackValue(ic *Inception, name string, typ reflect.Type) string {
+	ic.fallback(typ)
+	if !ic.useNumber {
+		ic.OutputImports[`"encoding/json"`] = true
+	}
+	return tplStr(decodeTpl["handleFallback"], handleFallback{
+		Name:      name,
+		Typ:       typ,
+		Kind:      typ.Kind(),
+		UseNumber: ic.useNumber,
+	})
+}
+
+// handleCandidates generates a trial decode of the current value into
+// each of the candidate types, assigning the first one that succeeds.
+func handleCandidates(name string, candidates []string) string {
+	return tplStr(decodeTpl["handleCandidates"], handleCandidatesData{
+		Name:       name,
+		Candidates: candidates,
+	})
+}
+
+// handlePolymorphic generates the decode of the current value into a new
+// value of the type registered for its discriminator member key.
+func handlePolymorphic(ic *Inception, name string, typ reflect.Type, key string) string {
+	return tplStr(decodeTpl["handlePolymorphic"], handlePolymorphicData{
+		IC:   ic,
+		Name: name,
+		Typ:  typ,
+		Key:  key,
+	})
+}
+
+// handleRef generates the decoder of a pointer to a struct with refs,
+// resolving references to the values read before.
+func handleRef(ic *Inception, name string, typ reflect.Type, push string) string {
+	return tplStr(decodeTpl["handleRef"], handleRefData{
+		IC:   ic,
+		Name: name,
+		Typ:  typ,
+		Push: push,
+	})
+}
+
+func hasUnmarshaler(ic *Inception, typ reflect.Type) bool {
+	return typ.Implements(unmarshalFasterType) || typeInInception(ic, typ, shared.MustDecoder) ||
+		reflect.PtrTo(typ).Implements(unmarshalFasterType) ||
+		typ.Implements(unmarshalerType) || reflect.PtrTo(typ).Implements(unmarshalerType)
+}
+
 func handleField(ic *Inception, name string, typ reflect.Type, ptr bool, quoted bool) string {
 	return handleFieldAddr(ic, name, false, typ, ptr, quoted)
 }
@@ -67,6 +430,17 @@ func handleField(ic *Inception, name string, typ reflect.Type, ptr bool, quoted
 func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type, ptr bool, quoted bool) string {
 	out := fmt.Sprintf("/* handler: %s type=%v kind=%v quoted=%t*/\n", name, typ, typ.Kind(), quoted)
 
+	if c, ok := getCodec(ic, typ); ok && c.Unmarshal != "" {
+		out += tplStr(decodeTpl["handleCodec"], handleCodec{
+			IC:       ic,
+			Name:     name,
+			Typ:      typ,
+			TakeAddr: takeAddr || ptr,
+			Func:     getCodecFunc(ic, c, c.Unmarshal),
+		})
+		return out
+	}
+
 	umlx := typ.Implements(unmarshalFasterType) || typeInInception(ic, typ, shared.MustDecoder)
 	umlx = umlx || reflect.PtrTo(typ).Implements(unmarshalFasterType)
 
@@ -103,7 +477,8 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 		reflect.Uint8,
 		reflect.Uint16,
 		reflect.Uint32,
-		reflect.Uint64:
+		reflect.Uint64,
+		reflect.Uintptr:
 
 		allowed := buildTokens(quoted, "FFTok_string", "FFTok_integer", "FFTok_null")
 		out += getAllowTokens(typ.Name(), allowed...)
@@ -126,6 +501,7 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 		out += getAllowTokens(typ.Name(), allowed...)
 
 		out += tplStr(decodeTpl["handleBool"], handleBool{
+			IC:       ic,
 			Name:     name,
 			Typ:      typ,
 			TakeAddr: takeAddr || ptr,
@@ -141,18 +517,15 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 
 	case reflect.Array,
 		reflect.Slice:
-		out += getArrayHandler(ic, name, typ, ptr)
+		out += getArrayHandler(ic, name, typ, ptr, 0)
 
 	case reflect.String:
-		// Is it a json.Number?
-		if typ.PkgPath() == "encoding/json" && typ.Name() == "Number" {
-			// Fall back to json package to rely on the valid number check.
-			// See: https://github.com/golang/go/blob/f05c3aa24d815cd3869153750c9875e35fc48a6e/src/encoding/json/decode.go#L897
-			ic.OutputImports[`"encoding/json"`] = true
-			out += tplStr(decodeTpl["handleFallback"], handleFallback{
-				Name: name,
-				Typ:  typ,
-				Kind: typ.Kind(),
+		if isJSONNumber(typ) {
+			out += tplStr(decodeTpl["handleNumber"], handleString{
+				IC:       ic,
+				Name:     name,
+				Typ:      typ,
+				TakeAddr: takeAddr || ptr,
 			})
 		} else {
 			out += tplStr(decodeTpl["handleString"], handleString{
@@ -164,12 +537,7 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 			})
 		}
 	case reflect.Interface:
-		ic.OutputImports[`"encoding/json"`] = true
-		out += tplStr(decodeTpl["handleFallback"], handleFallback{
-			Name: name,
-			Typ:  typ,
-			Kind: typ.Kind(),
-		})
+		out += handleFallbackValue(ic, name, typ)
 	case reflect.Map:
 		out += tplStr(decodeTpl["handleObject"], handleObject{
 			IC:       ic,
@@ -179,23 +547,19 @@ func handleFieldAddr(ic *Inception, name string, takeAddr bool, typ reflect.Type
 			TakeAddr: takeAddr || ptr,
 		})
 	default:
-		ic.OutputImports[`"encoding/json"`] = true
-		out += tplStr(decodeTpl["handleFallback"], handleFallback{
-			Name: name,
-			Typ:  typ,
-			Kind: typ.Kind(),
-		})
+		out += handleFallbackValue(ic, name, typ)
 	}
 
 	return out
 }
 
-func getArrayHandler(ic *Inception, name string, typ reflect.Type, ptr bool) string {
+func getArrayHandler(ic *Inception, name string, typ reflect.Type, ptr bool, sliceCap int) string {
 	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
 		ic.OutputImports[`"encoding/base64"`] = true
 		useReflectToSet := false
-		if typ.Elem().Name() != "byte" {
+		if typ.Elem().PkgPath() != "" {
 			ic.OutputImports[`"reflect"`] = true
+			ic.fallback(typ)
 			useReflectToSet = true
 		}
 
@@ -215,13 +579,7 @@ func getArrayHandler(ic *Inception, name string, typ reflect.Type, ptr bool) str
 	if (typ.Elem().Kind() == reflect.Struct || typ.Elem().Kind() == reflect.Map) ||
 		typ.Elem().Kind() == reflect.Array || typ.Elem().Kind() == reflect.Slice &&
 		typ.Elem().Name() == "" {
-		ic.OutputImports[`"encoding/json"`] = true
-
-		return tplStr(decodeTpl["handleFallback"], handleFallback{
-			Name: name,
-			Typ:  typ,
-			Kind: typ.Kind(),
-		})
+		return handleFallbackValue(ic, name, typ)
 	}
 
 sliceOrArray:
@@ -242,6 +600,7 @@ sliceOrArray:
 		Typ:   typ,
 		IsPtr: ptr,
 		Ptr:   reflect.Ptr,
+		Cap:   sliceCap,
 	})
 }
 
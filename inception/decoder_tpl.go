@@ -34,6 +34,8 @@ func init() {
 		"handleFallback":    handleFallbackTxt,
 		"handleString":      handleStringTxt,
 		"handleObject":      handleObjectTxt,
+		"handleNumber":      handleNumberTxt,
+		"handleTextKey":     handleTextKeyTxt,
 		"handleArray":       handleArrayTxt,
 		"handleSlice":       handleSliceTxt,
 		"handleByteSlice":   handleByteSliceTxt,
@@ -41,17 +43,41 @@ func init() {
 		"handlePtr":         handlePtrTxt,
 		"header":            headerTxt,
 		"ujFunc":            ujFuncTxt,
+		"ujTuple":           ujTupleTxt,
+		"ujExtract":         ujExtractTxt,
 		"handleUnmarshaler": handleUnmarshalerTxt,
+		"handleCodec":       handleCodecTxt,
+		"handleCandidates":  handleCandidatesTxt,
+		"handlePolymorphic": handlePolymorphicTxt,
+		"handleScaled":      handleScaledTxt,
+		"handleUnwrap":      handleUnwrapTxt,
+		"handleFlags":       handleFlagsTxt,
+		"handleBoolString":  handleBoolStringTxt,
+		"handleEnum":        handleEnumTxt,
+		"handleRef":         handleRefTxt,
+		"handleRefSlice":    handleRefSliceTxt,
+		"handleGroup":       handleGroupTxt,
+		"handleSplitPart":   handleSplitPartTxt,
+		"handleEpoch":       handleEpochTxt,
+		"handleLayout":      handleLayoutTxt,
+		"handleGrouped":     handleGroupedTxt,
+		"handleStream":      handleStreamTxt,
 	}
 
 	tplFuncs := template.FuncMap{
-		"getAllowTokens":  getAllowTokens,
-		"getNumberSize":   getNumberSize,
-		"getType":         getType,
-		"handleField":     handleField,
-		"handleFieldAddr": handleFieldAddr,
-		"unquoteField":    unquoteField,
-		"getTmpVarFor":    getTmpVarFor,
+		"getAllowTokens":    getAllowTokens,
+		"getNumberSize":     getNumberSize,
+		"getType":           getType,
+		"getTypeExpr":       getTypeExpr,
+		"handleField":       handleField,
+		"handleFieldAddr":   handleFieldAddr,
+		"handleStructField": handleStructField,
+		"handleMapKey":      handleMapKey,
+		"handleCandidates":  handleCandidates,
+		"handlePolymorphic": handlePolymorphic,
+		"handleRef":         handleRef,
+		"unquoteField":      unquoteField,
+		"getTmpVarFor":      getTmpVarFor,
 	}
 
 	for k, v := range funcs {
@@ -108,10 +134,177 @@ var allowTokensTxt = `
 }
 `
 
+type handleFlags struct {
+	IC     *Inception
+	Name   string
+	Typ    reflect.Type
+	Names  string
+	Ignore bool
+}
+
+var handleFlagsTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_left_brace" "FFTok_null"}}
+	if tok != fflib.FFTok_null {
+		tval, err := fflib.ParseFlags(fs, {{.Names}}, {{.Ignore}})
+		if err != nil {
+			return err
+		}
+		{{.Name}} = {{getType $ic .Name .Typ}}(tval)
+	}
+}
+`
+
+type handleEnum struct {
+	IC       *Inception
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	Values   string
+	Unknown  string
+}
+
+var handleEnumTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_string" "FFTok_null"}}
+	if tok != fflib.FFTok_null {
+		tval := fs.Output.String()
+		switch tval {
+		case {{.Values}}:
+			{{.Name}} = {{getType $ic .Name .Typ}}(tval)
+		default:
+			{{if eq .Unknown ""}}
+			return fs.WrapErr(fmt.Errorf("ffjson: %q is not a valid value for %s", tval, {{printf "%q" .JsonName}}))
+			{{else}}
+			{{.Name}} = {{.Unknown}}
+			{{end}}
+		}
+	}
+}
+`
+
+type handleUnwrap struct {
+	Name     string
+	JsonName string
+	First    bool
+	Handler  string
+}
+
+var handleUnwrapTxt = `
+{
+	tunwrap := tok == fflib.FFTok_left_brace
+	if tunwrap {
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+		if tok == fflib.FFTok_right_brace {
+			return fs.WrapErr(fmt.Errorf("ffjson: cannot unwrap an empty array into %s", {{printf "%q" .JsonName}}))
+		}
+	}
+
+	{{.Handler}}
+
+	if tunwrap {
+		tok = fs.Scan()
+		{{if eq .First true}}
+		for tok == fflib.FFTok_comma {
+			err = fs.SkipField(fs.Scan())
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+			tok = fs.Scan()
+		}
+		{{else}}
+		if tok == fflib.FFTok_comma {
+			return fs.WrapErr(fmt.Errorf("ffjson: cannot unwrap an array with more than one element into %s", {{printf "%q" .JsonName}}))
+		}
+		{{end}}
+		if tok != fflib.FFTok_right_brace {
+			wantedTok = fflib.FFTok_right_brace
+			goto wrongtokenerror
+		}
+	}
+}
+`
+
+type handleScaled struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	TakeAddr bool
+	Scale    string
+	Round    string
+}
+
+var handleScaledTxt = `
+{
+	{{$ic := .IC}}
+
+	{{if eq .Round ""}}
+	{{getAllowTokens .Typ.Name "FFTok_integer" "FFTok_double" "FFTok_null"}}
+	{{else}}
+	{{getAllowTokens .Typ.Name "FFTok_integer" "FFTok_null"}}
+	{{end}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		{{if eq .Round ""}}
+		tval, err := fflib.ParseFloat(fs.Output.Bytes(), 64)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		ttypval := {{getType $ic .Name .Typ}}(tval / {{.Scale}})
+		{{else}}
+		{{if le .Typ.Kind ` + strconv.FormatUint(uint64(reflect.Int64), 10) + `}}
+		tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		tval, err = fflib.UnscaleInt(tval, {{.Scale}}, fflib.{{.Round}})
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		ttypval := {{getType $ic .Name .Typ}}(tval)
+		if int64(ttypval) != tval {
+			return fs.WrapErr(fmt.Errorf("ffjson: scaled value %d overflows {{getType $ic .Name .Typ}}", tval))
+		}
+		{{else}}
+		tval, err := fflib.ParseUint(fs.Output.Bytes(), 10, 64)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		tval, err = fflib.UnscaleUint(tval, {{.Scale}}, fflib.{{.Round}})
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		ttypval := {{getType $ic .Name .Typ}}(tval)
+		if uint64(ttypval) != tval {
+			return fs.WrapErr(fmt.Errorf("ffjson: scaled value %d overflows {{getType $ic .Name .Typ}}", tval))
+		}
+		{{end}}
+		{{end}}
+
+		{{if eq .TakeAddr true}}
+		{{.Name}} = &ttypval
+		{{else}}
+		{{.Name}} = ttypval
+		{{end}}
+	}
+}
+`
+
 type handleFallback struct {
-	Name string
-	Typ  reflect.Type
-	Kind reflect.Kind
+	Name      string
+	Typ       reflect.Type
+	Kind      reflect.Kind
+	UseNumber bool
 }
 
 var handleFallbackTxt = `
@@ -122,10 +315,89 @@ var handleFallbackTxt = `
 		return fs.WrapErr(err)
 	}
 
-	err = json.Unmarshal(tbuf, &{{.Name}})
+	{{if .UseNumber}}
+	err = fflib.UnmarshalUseNumber(tbuf, &{{.Name}})
+	{{else}}
+	if fs.UseNumber || fflib.UseNumber() {
+		err = fflib.UnmarshalUseNumber(tbuf, &{{.Name}})
+	} else {
+		err = json.Unmarshal(tbuf, &{{.Name}})
+	}
+	{{end}}
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+}
+`
+
+type handleCandidatesData struct {
+	Name       string
+	Candidates []string
+}
+
+var handleCandidatesTxt = `
+{
+	/* Trying candidate types {{range $i, $c := .Candidates}}{{if $i}}, {{end}}{{$c}}{{end}} */
+	tbuf, err := fs.CaptureField(tok)
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		{{range $i, $c := .Candidates}}
+		{{if eq (index $c 0) '*'}}
+		tcand{{$i}} := new({{slice $c 1}})
+		{{else}}
+		var tcand{{$i}} {{$c}}
+		{{end}}
+		{{end}}
+
+		i, err := fflib.UnmarshalCandidates(tbuf, fs.Options(){{range $i, $c := .Candidates}}, {{if ne (index $c 0) '*'}}&{{end}}tcand{{$i}}{{end}})
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+
+		switch i {
+		{{range $i, $c := .Candidates}}
+		case {{$i}}:
+			{{$.Name}} = tcand{{$i}}
+		{{end}}
+		}
+	}
+}
+`
+
+type handlePolymorphicData struct {
+	IC   *Inception
+	Name string
+	Typ  reflect.Type
+	Key  string
+}
+
+var handlePolymorphicTxt = `
+{
+	/* Decoding the type registered for {{printf "%q" .Key}} */
+	tbuf, err := fs.CaptureField(tok)
 	if err != nil {
 		return fs.WrapErr(err)
 	}
+
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		v, err := fflib.UnmarshalRegistered(tbuf, {{printf "%q" .Key}}, fs.Options())
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+
+		tval, ok := v.({{getType .IC .Name .Typ}})
+		if !ok {
+			return fs.WrapErr(fmt.Errorf("ffjson: registered type %T doesn't implement {{getType .IC .Name .Typ}}", v))
+		}
+		{{.Name}} = tval
+	}
 }
 `
 
@@ -162,6 +434,32 @@ var handleStringTxt = `
 }
 `
 
+// handleNumberTxt decodes a json.Number from the text of a number, or of
+// a string holding a number, without parsing it.
+var handleNumberTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_integer" "FFTok_double" "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+	{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+	{{end}}
+	} else {
+		outBuf := fs.Output.Bytes()
+		if tok == fflib.FFTok_string && !fflib.IsValidNumber(string(outBuf)) {
+			return fs.WrapErr(fmt.Errorf("json: invalid number literal, trying to unmarshal %q into Number", outBuf))
+		}
+	{{if eq .TakeAddr true}}
+		tval := {{getType $ic .Name .Typ}}(string(outBuf))
+		{{.Name}} = &tval
+	{{else}}
+		{{.Name}} = {{getType $ic .Name .Typ}}(string(outBuf))
+	{{end}}
+	}
+}
+`
+
 type handleObject struct {
 	IC       *Inception
 	Name     string
@@ -181,29 +479,29 @@ var handleObjectTxt = `
 		{{if eq .TakeAddr true}}
 			{{if eq .Typ.Elem.Kind .Ptr }}
 				{{if eq .Typ.Key.Kind .Ptr }}
-				var tval = make(map[*{{getType $ic .Name .Typ.Key.Elem}}]*{{getType $ic .Name .Typ.Elem.Elem}}, 0)
+				var tval = make(map[*{{getTypeExpr $ic .Name .Typ.Key.Elem}}]*{{getTypeExpr $ic .Name .Typ.Elem.Elem}}, 0)
 				{{else}}
-				var tval = make(map[{{getType $ic .Name .Typ.Key}}]*{{getType $ic .Name .Typ.Elem.Elem}}, 0)
+				var tval = make(map[{{getTypeExpr $ic .Name .Typ.Key}}]*{{getTypeExpr $ic .Name .Typ.Elem.Elem}}, 0)
 				{{end}}
 			{{else}}
 				{{if eq .Typ.Key.Kind .Ptr }}
-				var tval = make(map[*{{getType $ic .Name .Typ.Key.Elem}}]{{getType $ic .Name .Typ.Elem}}, 0)
+				var tval = make(map[*{{getTypeExpr $ic .Name .Typ.Key.Elem}}]{{getTypeExpr $ic .Name .Typ.Elem}}, 0)
 				{{else}}
-				var tval = make(map[{{getType $ic .Name .Typ.Key}}]{{getType $ic .Name .Typ.Elem}}, 0)
+				var tval = make(map[{{getTypeExpr $ic .Name .Typ.Key}}]{{getTypeExpr $ic .Name .Typ.Elem}}, 0)
 				{{end}}
 			{{end}}
 		{{else}}
 			{{if eq .Typ.Elem.Kind .Ptr }}
 				{{if eq .Typ.Key.Kind .Ptr }}
-				{{.Name}} = make(map[*{{getType $ic .Name .Typ.Key.Elem}}]*{{getType $ic .Name .Typ.Elem.Elem}}, 0)
+				{{.Name}} = make(map[*{{getTypeExpr $ic .Name .Typ.Key.Elem}}]*{{getTypeExpr $ic .Name .Typ.Elem.Elem}}, 0)
 				{{else}}
-				{{.Name}} = make(map[{{getType $ic .Name .Typ.Key}}]*{{getType $ic .Name .Typ.Elem.Elem}}, 0)
+				{{.Name}} = make(map[{{getTypeExpr $ic .Name .Typ.Key}}]*{{getTypeExpr $ic .Name .Typ.Elem.Elem}}, 0)
 				{{end}}
 			{{else}}
 				{{if eq .Typ.Key.Kind .Ptr }}
-				{{.Name}} = make(map[*{{getType $ic .Name .Typ.Key.Elem}}]{{getType $ic .Name .Typ.Elem}}, 0)
+				{{.Name}} = make(map[*{{getTypeExpr $ic .Name .Typ.Key.Elem}}]{{getTypeExpr $ic .Name .Typ.Elem}}, 0)
 				{{else}}
-				{{.Name}} = make(map[{{getType $ic .Name .Typ.Key}}]{{getType $ic .Name .Typ.Elem}}, 0)
+				{{.Name}} = make(map[{{getTypeExpr $ic .Name .Typ.Key}}]{{getTypeExpr $ic .Name .Typ.Elem}}, 0)
 				{{end}}
 			{{end}}
 		{{end}}
@@ -214,18 +512,18 @@ var handleObjectTxt = `
 		{{$keyPtr := false}}
 		{{if eq .Typ.Key.Kind .Ptr }}
 			{{$keyPtr := true}}
-			var k *{{getType $ic .Name .Typ.Key.Elem}}
+			var k *{{getTypeExpr $ic .Name .Typ.Key.Elem}}
 		{{else}}
-			var k {{getType $ic .Name .Typ.Key}}
+			var k {{getTypeExpr $ic .Name .Typ.Key}}
 		{{end}}
 
 		{{$valPtr := false}}
 		{{$tmpVar := getTmpVarFor .Name}}
 		{{if eq .Typ.Elem.Kind .Ptr }}
 			{{$valPtr := true}}
-			var {{$tmpVar}} *{{getType $ic .Name .Typ.Elem.Elem}}
+			var {{$tmpVar}} *{{getTypeExpr $ic .Name .Typ.Elem.Elem}}
 		{{else}}
-			var {{$tmpVar}} {{getType $ic .Name .Typ.Elem}}
+			var {{$tmpVar}} {{getTypeExpr $ic .Name .Typ.Elem}}
 		{{end}}
 
 			tok = fs.Scan()
@@ -247,7 +545,7 @@ var handleObjectTxt = `
 				wantVal = true
 			}
 
-			{{handleField .IC "k" .Typ.Key $keyPtr false}}
+			{{handleMapKey .IC "k" .Typ.Key $keyPtr}}
 
 			// Expect ':' after key
 			tok = fs.Scan()
@@ -273,6 +571,20 @@ var handleObjectTxt = `
 }
 `
 
+type handleTextKey struct {
+	Name string
+	Typ  reflect.Type
+}
+
+var handleTextKeyTxt = `
+{
+	{{getAllowTokens .Typ.Name "FFTok_string"}}
+	if err := {{.Name}}.UnmarshalText(fs.Output.Bytes()); err != nil {
+		return fs.WrapErr(err)
+	}
+}
+`
+
 type handleArray struct {
 	IC              *Inception
 	Name            string
@@ -280,6 +592,9 @@ type handleArray struct {
 	Ptr             reflect.Kind
 	UseReflectToSet bool
 	IsPtr           bool
+	Cap             int
+	Candidates      []string
+	Polymorphic     string
 }
 
 var handleArrayTxt = `
@@ -346,7 +661,14 @@ var handleSliceTxt = `
 	if tok == fflib.FFTok_null {
 		{{.Name}} = nil
 	} else {
-		{{if eq .Typ.Elem.Kind .Ptr }}
+		{{if gt .Cap 0}}
+			{{if eq .IsPtr true}}
+				{{.Name}} = new({{if eq .Typ.Elem.Kind .Ptr}}[]*{{getType $ic .Name .Typ.Elem.Elem}}{{else}}[]{{getType $ic .Name .Typ.Elem}}{{end}})
+				*{{.Name}} = make({{if eq .Typ.Elem.Kind .Ptr}}[]*{{getType $ic .Name .Typ.Elem.Elem}}{{else}}[]{{getType $ic .Name .Typ.Elem}}{{end}}, 0, {{.Cap}})
+			{{else}}
+				{{.Name}} = make({{if eq .Typ.Elem.Kind .Ptr}}[]*{{getType $ic .Name .Typ.Elem.Elem}}{{else}}[]{{getType $ic .Name .Typ.Elem}}{{end}}, 0, {{.Cap}})
+			{{end}}
+		{{else if eq .Typ.Elem.Kind .Ptr }}
 			{{if eq .IsPtr true}}
 				{{.Name}} = &[]*{{getType $ic .Name .Typ.Elem.Elem}}{}
 			{{else}}
@@ -391,7 +713,13 @@ var handleSliceTxt = `
 				wantVal = true
 			}
 
+			{{if .Candidates}}
+			{{handleCandidates $tmpVar .Candidates}}
+			{{else if .Polymorphic}}
+			{{handlePolymorphic .IC $tmpVar .Typ.Elem .Polymorphic}}
+			{{else}}
 			{{handleField .IC $tmpVar .Typ.Elem $ptr false}}
+			{{end}}
 			{{if eq .IsPtr true}}
 				*{{.Name}} = append(*{{.Name}}, {{$tmpVar}})
 			{{else}}
@@ -418,13 +746,14 @@ var handleByteSliceTxt = `
 			v := reflect.ValueOf(&{{.Name}}).Elem()
 			v.SetBytes(b[0:n])
 		{{else}}
-			{{.Name}} = append([]byte(), b[0:n]...)
+			{{.Name}} = append([]byte{}, b[0:n]...)
 		{{end}}
 	}
 }
 `
 
 type handleBool struct {
+	IC       *Inception
 	Name     string
 	Typ      reflect.Type
 	TakeAddr bool
@@ -440,7 +769,7 @@ var handleBoolTxt = `
 		tmpb := fs.Output.Bytes()
 
 		{{if eq .TakeAddr true}}
-		var tval bool
+		var tval {{getType .IC .Name .Typ}}
 		{{end}}
 
 		if bytes.Compare([]byte{'t', 'r', 'u', 'e'}, tmpb) == 0 {
@@ -467,76 +796,426 @@ var handleBoolTxt = `
 }
 `
 
-type handlePtr struct {
-	IC     *Inception
-	Name   string
-	Typ    reflect.Type
-	Quoted bool
+type handleBoolString struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	TakeAddr bool
+	Fold     bool
 }
 
-var handlePtrTxt = `
+var handleBoolStringTxt = `
 {
 	{{$ic := .IC}}
 
+	{{getAllowTokens .Typ.Name "FFTok_bool" "FFTok_string" "FFTok_null"}}
 	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
 		{{.Name}} = nil
+		{{end}}
 	} else {
-		if {{.Name}} == nil {
-			{{.Name}} = new({{getType $ic .Typ.Elem.Name .Typ.Elem}})
+		tval, ok := fflib.ParseBool(fs.Output.Bytes(), {{.Fold}})
+		if !ok {
+			return fs.WrapErr(fmt.Errorf("ffjson: cannot decode %q into a bool", fs.Output.Bytes()))
 		}
-
-		{{handleFieldAddr .IC .Name true .Typ.Elem false .Quoted}}
+		{{if eq .TakeAddr true}}
+		ttypval := {{getType $ic .Name .Typ}}(tval)
+		{{.Name}} = &ttypval
+		{{else}}
+		{{.Name}} = {{getType $ic .Name .Typ}}(tval)
+		{{end}}
 	}
 }
 `
 
-type header struct {
-	IC *Inception
-	SI *StructInfo
+type handleRefData struct {
+	IC   *Inception
+	Name string
+	Typ  reflect.Type
+	Push string
 }
 
-var headerTxt = `
-const (
-	ffjt{{.SI.Name}}base = iota
-	ffjt{{.SI.Name}}nosuchkey
-	{{with $si := .SI}}
-		{{range $index, $field := $si.Fields}}
-			{{if ne $field.JsonName "-"}}
-		ffjt{{$si.Name}}{{$field.Name}}
-			{{end}}
-		{{end}}
-	{{end}}
-)
-
-{{with $si := .SI}}
-	{{range $index, $field := $si.Fields}}
-		{{if ne $field.JsonName "-"}}
-var ffjKey{{$si.Name}}{{$field.Name}} = []byte({{$field.JsonName}})
-		{{end}}
-	{{end}}
-{{end}}
-
-`
-
-type ujFunc struct {
-	IC          *Inception
-	SI          *StructInfo
-	ValidValues []string
-	ResetFields bool
-}
+var handleRefTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_left_bracket" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		tval := new({{getType $ic .Name .Typ}})
+		{{.Push}}
+		refs.Define(tval)
+		err = tval.unmarshalJSONFFLexerRefs(fs, fflib.FFParse_want_key, refs)
+		if err != nil {
+			return err
+		}
+		if ref, ok := refs.TakeRef(); ok {
+			v, err := refs.Resolve(ref)
+			if err != nil {
+				return fs.WrapErr(err)
+			}
+			tref, ok := v.(*{{getType $ic .Name .Typ}})
+			if !ok {
+				return fs.WrapErr(fmt.Errorf("ffjson: reference %q is a %T, not a %T", ref, v, tval))
+			}
+			tval = tref
+			// References to this location share the value too.
+			refs.Define(tval)
+		}
+		refs.Pop()
+		{{.Name}} = tval
+	}
+}
+`
+
+type handleRefSlice struct {
+	IC    *Inception
+	Name  string
+	Typ   reflect.Type
+	Token string
+}
+
+var handleRefSliceTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_left_brace" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		{{.Name}} = []*{{getType $ic .Name .Typ.Elem.Elem}}{}
+		refs.Push({{.Token}})
+
+		wantVal := true
+		idx := 0
+		for {
+			{{$tmpVar := getTmpVarFor .Name}}
+			var {{$tmpVar}} *{{getType $ic .Name .Typ.Elem.Elem}}
+
+			tok = fs.Scan()
+			if tok == fflib.FFTok_error {
+				goto tokerror
+			}
+			if tok == fflib.FFTok_right_brace {
+				break
+			}
+
+			if tok == fflib.FFTok_comma {
+				if wantVal == true {
+					return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+				}
+				continue
+			} else {
+				wantVal = true
+			}
+
+			{{handleRef $ic $tmpVar .Typ.Elem.Elem "refs.PushIndex(idx)"}}
+
+			{{.Name}} = append({{.Name}}, {{$tmpVar}})
+			idx++
+			wantVal = false
+		}
+		refs.Pop()
+	}
+}
+`
+
+type handleGroup struct {
+	Name string
+	Func string
+}
+
+var handleGroupTxt = `
+{
+	{{getAllowTokens .Name "FFTok_left_bracket" "FFTok_null"}}
+	if tok == fflib.FFTok_left_bracket {
+		err = j.{{.Func}}(fs, fflib.FFParse_want_key)
+		if err != nil {
+			return err
+		}
+	}
+}
+`
+
+type handleGrouped struct {
+	IC        *Inception
+	Name      string
+	Typ       reflect.Type
+	TakeAddr  bool
+	ParseFunc string
+	Sep       string
+	Point     string
+}
+
+var handleGroupedTxt = `
+{
+	{{$ic := .IC}}
+
+	{{getAllowTokens .Typ.Name "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		tbuf, err := fflib.Ungroup(fs.Output.Bytes(), {{.Sep}}, {{.Point}})
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		{{if eq .ParseFunc "ParseFloat" }}
+		tval, err := fflib.{{ .ParseFunc}}(tbuf, {{getNumberSize .Typ}})
+		{{else}}
+		tval, err := fflib.{{ .ParseFunc}}(tbuf, 10, {{getNumberSize .Typ}})
+		{{end}}
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		{{if eq .TakeAddr true}}
+		ttypval := {{getType $ic .Name .Typ}}(tval)
+		{{.Name}} = &ttypval
+		{{else}}
+		{{.Name}} = {{getType $ic .Name .Typ}}(tval)
+		{{end}}
+	}
+}
+`
+
+type handleEpoch struct {
+	Name      string
+	TakeAddr  bool
+	Epoch     string
+	PerSecond string
+}
+
+var handleEpochTxt = `
+{
+	{{getAllowTokens "time.Time" "FFTok_integer" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		tval, err := fflib.ParseInt(fs.Output.Bytes(), 10, 64)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		t, err := fflib.EpochTime(tval, {{.Epoch}}, {{.PerSecond}})
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		{{if eq .TakeAddr true}}
+		{{.Name}} = &t
+		{{else}}
+		{{.Name}} = t
+		{{end}}
+	}
+}
+`
+
+type handleLayout struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	TakeAddr bool
+	Slice    bool
+	Layout   string
+}
+
+var handleLayoutTxt = `
+{
+	{{$ic := .IC}}
+	{{if eq .Slice true}}
+	{{getAllowTokens .Typ.String "FFTok_left_brace" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		{{.Name}} = {{getType $ic .Name .Typ}}{}
+
+		wantVal := true
+
+		for {
+			tok = fs.Scan()
+			if tok == fflib.FFTok_error {
+				goto tokerror
+			}
+			if tok == fflib.FFTok_right_brace {
+				break
+			}
+
+			if tok == fflib.FFTok_comma {
+				if wantVal == true {
+					return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+				}
+				continue
+			} else {
+				wantVal = true
+			}
+
+			// null elements are zero times, as with encoding/json.
+			var t time.Time
+			{{getAllowTokens "time.Time" "FFTok_string" "FFTok_null"}}
+			if tok != fflib.FFTok_null {
+				var err error
+				t, err = time.Parse({{.Layout}}, string(fs.Output.Bytes()))
+				if err != nil {
+					return fs.WrapErr(err)
+				}
+			}
+			{{.Name}} = append({{.Name}}, t)
+			wantVal = false
+		}
+	}
+	{{else}}
+	{{getAllowTokens "time.Time" "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		t, err := time.Parse({{.Layout}}, string(fs.Output.Bytes()))
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		{{if eq .TakeAddr true}}
+		{{.Name}} = &t
+		{{else}}
+		{{.Name}} = t
+		{{end}}
+	}
+	{{end}}
+}
+`
+
+type handleStream struct {
+	Name string
+}
+
+var handleStreamTxt = `
+{
+	{{getAllowTokens "io.Reader" "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		{{.Name}} = bytes.NewReader(append([]byte(nil), fs.Output.Bytes()...))
+	}
+}
+`
+
+type handleSplitPart struct {
+	Name string
+	Var  string
+}
+
+var handleSplitPartTxt = `
+{
+	{{getAllowTokens .Name "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Var}} = nil
+	} else {
+		v := fs.Output.String()
+		{{.Var}} = &v
+	}
+}
+`
+
+type handlePtr struct {
+	IC     *Inception
+	Name   string
+	Typ    reflect.Type
+	Quoted bool
+}
+
+var handlePtrTxt = `
+{
+	{{$ic := .IC}}
+
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getType $ic .Typ.Elem.Name .Typ.Elem}})
+		}
+
+		{{handleFieldAddr .IC .Name true .Typ.Elem false .Quoted}}
+	}
+}
+`
+
+type header struct {
+	IC *Inception
+	SI *StructInfo
+}
+
+var headerTxt = `
+const (
+	ffjt{{.SI.Name}}base = iota
+	ffjt{{.SI.Name}}nosuchkey
+	{{if eq .SI.Options.Refs true}}
+	ffjt{{.SI.Name}}ref
+	{{end}}
+	{{with $si := .SI}}
+		{{range $index, $field := $si.Fields}}
+			{{if ne $field.JsonName "-"}}
+		ffjt{{$si.Name}}{{$field.Ident}}
+			{{end}}
+		{{end}}
+	{{end}}
+)
+
+{{with $si := .SI}}
+	{{range $index, $field := $si.Fields}}
+		{{if ne $field.JsonName "-"}}
+var ffjKey{{$si.Name}}{{$field.Ident}} = []byte({{$field.KeyName}})
+		{{end}}
+	{{end}}
+{{end}}
+
+`
+
+type ujFunc struct {
+	IC          *Inception
+	SI          *StructInfo
+	ValidValues []string
+	ResetFields bool
+	LexerFunc   string
+	// Group decodes the nested object of a group, without UnmarshalJSON.
+	Group bool
+}
 
 var ujFuncTxt = `
 {{$si := .SI}}
 {{$ic := .IC}}
 
+{{if eq .Group false}}
 // UnmarshalJSON umarshall json - template of ffjson
 func (j *{{.SI.Name}}) UnmarshalJSON(input []byte) error {
+    return j.UnmarshalJSONOpts(input, nil)
+}
+
+// UnmarshalJSONOpts umarshall json with the options opts, or the defaults if nil - template of ffjson
+func (j *{{.SI.Name}}) UnmarshalJSONOpts(input []byte, opts *fflib.DecodeOptions) error {
     fs := fflib.NewFFLexer(input)
+    fs.SetOptions(opts)
+    {{with $h := .SI.RawHash}}
+    err := j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    if err != nil {
+        return err
+    }
+    sum := {{$h.Sum}}(input)
+    {{if eq $h.Hex true}}
+    j.{{$h.Field}} = hex.EncodeToString(sum[:])
+    {{else}}
+    j.{{$h.Field}} = sum[:]
+    {{end}}
+    return nil
+    {{else}}
     return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    {{end}}
 }
+{{end}}
 
-// UnmarshalJSONFFLexer fast json unmarshall - template ffjson
-func (j *{{.SI.Name}}) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+// {{.LexerFunc}} fast json unmarshall - template ffjson
+func (j *{{.SI.TypeName}}) {{.LexerFunc}}(fs *fflib.FFLexer, state fflib.FFParseState{{if eq .SI.Options.Refs true}}, refs *fflib.Refs{{end}}) error {
 	var err error
 	currentKey := ffjt{{.SI.Name}}base
 	_ = currentKey
@@ -545,10 +1224,24 @@ func (j *{{.SI.Name}}) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFPar
 
 				{{if eq .ResetFields true}}
 				{{range $index, $field := $si.Fields}}
-				var ffjSet{{$si.Name}}{{$field.Name}} = false
+				var ffjSet{{$si.Name}}{{$field.Ident}} = false
  				{{end}}
 				{{end}}
 
+	{{range $index, $field := $si.Fields}}
+	{{if eq $field.TriState true}}
+	j.{{$field.Name}}State = shared.TriStateAbsent
+	{{end}}
+	{{end}}
+	{{if eq $si.Options.KeepOrder true}}
+	j.keyOrder = nil
+	{{end}}
+	{{range $index, $field := $si.Fields}}
+	{{if ne $field.SplitPart ""}}
+	var ffjSplit{{$field.Name}} *string
+	{{end}}
+	{{end}}
+
 mainparse:
 	for {
 		tok = fs.Scan()
@@ -588,33 +1281,61 @@ mainparse:
 			}
 
 			kn := fs.Output.Bytes()
+			{{if eq .SI.Options.NormalizeKeys true}}
+			kn = norm.NFC.Bytes(kn)
+			{{end}}
+			{{if eq .SI.Options.Refs true}}
+			if bytes.Equal(kn, []byte("$ref")) {
+				currentKey = ffjt{{.SI.Name}}ref
+				state = fflib.FFParse_want_colon
+				goto mainparse
+			}
+			{{end}}
 			if len(kn) <= 0 {
 				// "" case. hrm.
+				{{if eq .SI.Options.DisallowUnknownFields true}}
+				return fs.WrapErr(fmt.Errorf("json: unknown field %q", kn))
+				{{else}}
+				if fs.DisallowUnknownFields || fflib.DisallowUnknownFields() {
+					return fs.WrapErr(fmt.Errorf("json: unknown field %q", kn))
+				}
 				currentKey = ffjt{{.SI.Name}}nosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
+				{{end}}
 			} else {
 				switch kn[0] {
 				{{range $byte, $fields := $si.FieldsByFirstByte}}
 				case '{{$byte}}':
 					{{range $index, $field := $fields}}
-						{{if ne $index 0 }}} else if {{else}}if {{end}} bytes.Equal(ffjKey{{$si.Name}}{{$field.Name}}, kn) {
-						currentKey = ffjt{{$si.Name}}{{$field.Name}}
+						{{if ne $index 0 }}} else if {{else}}if {{end}} bytes.Equal(ffjKey{{$si.Name}}{{$field.Ident}}, kn) {
+						currentKey = ffjt{{$si.Name}}{{$field.Ident}}
 						state = fflib.FFParse_want_colon
 						goto mainparse
 					{{end}} }
 				{{end}}
 				}
+				{{if and (ne .SI.Options.ExactCase true) $si.ReverseFields}}
+				if !fs.ExactCase {
 				{{range $index, $field := $si.ReverseFields}}
-				if {{$field.FoldFuncName}}(ffjKey{{$si.Name}}{{$field.Name}}, kn) {
-					currentKey = ffjt{{$si.Name}}{{$field.Name}}
+				if {{$field.FoldFuncName}}(ffjKey{{$si.Name}}{{$field.Ident}}, kn) {
+					currentKey = ffjt{{$si.Name}}{{$field.Ident}}
 					state = fflib.FFParse_want_colon
 					goto mainparse
 				}
 				{{end}}
+				}
+				{{end}}
+				{{if eq .SI.Options.DisallowUnknownFields true}}
+				return fs.WrapErr(fmt.Errorf("json: unknown field %q", kn))
+				{{else}}
+				if fs.DisallowUnknownFields || fflib.DisallowUnknownFields() {
+					return fs.WrapErr(fmt.Errorf("json: unknown field %q", kn))
+				}
 				currentKey = ffjt{{.SI.Name}}nosuchkey
 				state = fflib.FFParse_want_colon
 				goto mainparse
+				{{end}}
 			}
 
 		case fflib.FFParse_want_colon:
@@ -629,8 +1350,18 @@ mainparse:
 			if {{range $index, $v := .ValidValues}}{{if ne $index 0 }}||{{end}}tok == fflib.{{$v}}{{end}} {
 				switch currentKey {
 				{{range $index, $field := $si.Fields}}
-				case ffjt{{$si.Name}}{{$field.Name}}:
-					goto handle_{{$field.Name}}
+				case ffjt{{$si.Name}}{{$field.Ident}}:
+					goto handle_{{$field.Ident}}
+				{{end}}
+				{{if eq $si.Options.Refs true}}
+				case ffjt{{$si.Name}}ref:
+					if tok != fflib.FFTok_string {
+						wantedTok = fflib.FFTok_string
+						goto wrongtokenerror
+					}
+					refs.SetRef(fs.Output.String())
+					state = fflib.FFParse_after_value
+					goto mainparse
 				{{end}}
 				case ffjt{{$si.Name}}nosuchkey:
 					err = fs.SkipField(tok)
@@ -646,11 +1377,19 @@ mainparse:
 		}
 	}
 {{range $index, $field := $si.Fields}}
-handle_{{$field.Name}}:
+handle_{{$field.Ident}}:
+	{{range $field.Embedded}}
+	if j.{{.Name}} == nil {
+		j.{{.Name}} = new({{getType $ic .Name .Typ}})
+	}
+	{{end}}
 	{{with $fieldName := $field.Name | printf "j.%s"}}
-		{{handleField $ic $fieldName $field.Typ $field.Pointer $field.ForceString}}
+		{{if eq $si.Options.KeepOrder true}}
+		j.keyOrder = append(j.keyOrder, ffjOrder{{$si.Name}}[{{$index}}])
+		{{end}}
+		{{handleStructField $ic $fieldName $field}}
 		{{if eq $.ResetFields true}}
-		ffjSet{{$si.Name}}{{$field.Name}} = true
+		ffjSet{{$si.Name}}{{$field.Ident}} = true
 		{{end}}
 		state = fflib.FFParse_after_value
 		goto mainparse
@@ -671,9 +1410,20 @@ tokerror:
 	}
 	panic("ffjson-generated: unreachable, please report bug.")
 done:
+{{range $index, $field := $si.Fields}}
+{{if eq $field.SplitPart "date"}}
+	if ffjSplit{{$field.SplitField}}_date != nil || ffjSplit{{$field.SplitField}}_time != nil {
+		t, err := fflib.JoinDateTime(ffjSplit{{$field.SplitField}}_date, ffjSplit{{$field.SplitField}}_time)
+		if err != nil {
+			return fs.WrapErr(fmt.Errorf("%v for {{$field.SplitField}}", err))
+		}
+		j.{{$field.SplitField}} = t
+	}
+{{end}}
+{{end}}
 {{if eq .ResetFields true}}
 {{range $index, $field := $si.Fields}}
-	if !ffjSet{{$si.Name}}{{$field.Name}} {
+	if !ffjSet{{$si.Name}}{{$field.Ident}}{{range $field.Embedded}} && j.{{.Name}} != nil{{end}} {
 	{{with $fieldName := $field.Name | printf "j.%s"}}
 	{{if eq $field.Pointer true}}
 		{{$fieldName}} = nil
@@ -702,6 +1452,234 @@ done:
 }
 `
 
+var ujTupleTxt = `
+{{$si := .SI}}
+{{$ic := .IC}}
+
+// UnmarshalJSON umarshall json - template of ffjson
+func (j *{{.SI.Name}}) UnmarshalJSON(input []byte) error {
+    return j.UnmarshalJSONOpts(input, nil)
+}
+
+// UnmarshalJSONOpts umarshall json with the options opts, or the defaults if nil - template of ffjson
+func (j *{{.SI.Name}}) UnmarshalJSONOpts(input []byte, opts *fflib.DecodeOptions) error {
+    fs := fflib.NewFFLexer(input)
+    fs.SetOptions(opts)
+    {{with $h := .SI.RawHash}}
+    err := j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    if err != nil {
+        return err
+    }
+    sum := {{$h.Sum}}(input)
+    {{if eq $h.Hex true}}
+    j.{{$h.Field}} = hex.EncodeToString(sum[:])
+    {{else}}
+    j.{{$h.Field}} = sum[:]
+    {{end}}
+    return nil
+    {{else}}
+    return j.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+    {{end}}
+}
+
+// UnmarshalJSONFFLexer fast json unmarshall of the tuple array - template ffjson
+func (j *{{.SI.Name}}) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
+	var err error
+	tok := fflib.FFTok_init
+	wantedTok := fflib.FFTok_init
+	_ = err
+
+	if state == fflib.FFParse_map_start {
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+		if tok != fflib.FFTok_left_brace {
+			wantedTok = fflib.FFTok_left_brace
+			goto wrongtokenerror
+		}
+	}
+
+{{range $index, $field := $si.Fields}}
+	tok = fs.Scan()
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
+	// Missing elements leave the fields after them unchanged.
+	if tok == fflib.FFTok_right_brace {
+		goto done
+	}
+	{{if ne $index 0}}
+	if tok != fflib.FFTok_comma {
+		wantedTok = fflib.FFTok_comma
+		goto wrongtokenerror
+	}
+	tok = fs.Scan()
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
+	{{end}}
+	if {{range $i, $v := $.ValidValues}}{{if ne $i 0 }}&&{{end}}tok != fflib.{{$v}}{{end}} {
+		goto wantedvalue
+	}
+	{
+	{{with $fieldName := $field.Name | printf "j.%s"}}
+		{{handleStructField $ic $fieldName $field}}
+	{{end}}
+	}
+{{end}}
+
+	tok = fs.Scan()
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
+	if tok != fflib.FFTok_right_brace {
+		{{if gt (len $si.Fields) 0}}
+		if tok == fflib.FFTok_comma {
+			return fs.WrapErr(fmt.Errorf("ffjson: {{$si.Name}} is a tuple of {{len $si.Fields}} elements, but got more"))
+		}
+		{{end}}
+		wantedTok = fflib.FFTok_right_brace
+		goto wrongtokenerror
+	}
+
+done:
+	return nil
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+}
+`
+
+type ujExtract struct {
+	IC          *Inception
+	SI          *StructInfo
+	Field       *StructField
+	ValidValues []string
+	// Func is the name of the function, like ExtractFooBar.
+	Func string
+	// Type is the type of the field.
+	Type string
+	// Match is the condition of the key kn being the key of the field.
+	Match string
+}
+
+var ujExtractTxt = `
+{{$ic := .IC}}
+{{$field := .Field}}
+
+// {{.Func}} decodes the member {{$field.JsonName}} of the {{.SI.Name}} object data, as UnmarshalJSON decodes it into {{$field.Name}}, skipping the other members without decoding them - template ffjson
+func {{.Func}}(data []byte) ({{.Type}}, error) {
+	var out {{.Type}}
+	fs := fflib.NewFFLexer(data)
+	err := ffj{{.Func}}(fs, &out)
+	return out, err
+}
+
+func ffj{{.Func}}(fs *fflib.FFLexer, out *{{.Type}}) error {
+	var v {{.Type}}
+	var err error
+	wantedTok := fflib.FFTok_init
+	state := fflib.FFParse_map_start
+	_ = err
+	_ = state
+
+	tok := fs.Scan()
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
+	if tok != fflib.FFTok_left_bracket {
+		wantedTok = fflib.FFTok_left_bracket
+		goto wrongtokenerror
+	}
+
+	for {
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+		// The member is absent.
+		if tok == fflib.FFTok_right_bracket {
+			return nil
+		}
+		if tok != fflib.FFTok_string {
+			wantedTok = fflib.FFTok_string
+			goto wrongtokenerror
+		}
+
+		kn := fs.Output.Bytes()
+		{{if eq .SI.Options.NormalizeKeys true}}
+		kn = norm.NFC.Bytes(kn)
+		{{end}}
+		match := {{.Match}}
+
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+		if tok != fflib.FFTok_colon {
+			wantedTok = fflib.FFTok_colon
+			goto wrongtokenerror
+		}
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+
+		if match {
+			if {{range $i, $v := $.ValidValues}}{{if ne $i 0 }}&&{{end}}tok != fflib.{{$v}}{{end}} {
+				goto wantedvalue
+			}
+			{
+				{{handleStructField $ic "v" $field}}
+			}
+			*out = v
+			return nil
+		}
+
+		err = fs.SkipField(tok)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+		tok = fs.Scan()
+		if tok == fflib.FFTok_error {
+			goto tokerror
+		}
+		if tok == fflib.FFTok_right_bracket {
+			return nil
+		}
+		if tok != fflib.FFTok_comma {
+			wantedTok = fflib.FFTok_comma
+			goto wrongtokenerror
+		}
+	}
+
+wantedvalue:
+	return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+wrongtokenerror:
+	return fs.WrapErr(fmt.Errorf("ffjson: wanted token: %v, but got token: %v output=%s", wantedTok, tok, fs.Output.String()))
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+}
+`
+
 type handleUnmarshaler struct {
 	IC                   *Inception
 	Name                 string
@@ -746,6 +1724,9 @@ var handleUnmarshalerTxt = `
 	{{if eq .Unmarshaler true}}
 	{
 		if tok == fflib.FFTok_null {
+			{{if eq .Typ.Kind .Ptr }}
+				{{.Name}} = nil
+			{{end}}
 			{{if eq .TakeAddr true }}
 				{{.Name}} = nil
 			{{end}}
@@ -756,6 +1737,11 @@ var handleUnmarshalerTxt = `
 				return fs.WrapErr(err)
 			}
 
+			{{if eq .Typ.Kind .Ptr }}
+			if {{.Name}} == nil {
+				{{.Name}} = new({{getType $ic .Typ.Elem.Name .Typ.Elem}})
+			}
+			{{end}}
 			{{if eq .TakeAddr true }}
 			if {{.Name}} == nil {
 				{{.Name}} = new({{getType $ic .Typ.Name .Typ}})
@@ -771,3 +1757,41 @@ var handleUnmarshalerTxt = `
 	{{end}}
 	{{end}}
 `
+
+type handleCodec struct {
+	IC       *Inception
+	Name     string
+	Typ      reflect.Type
+	TakeAddr bool
+	// Func is the unmarshal function of the codec of Typ.
+	Func string
+}
+
+var handleCodecTxt = `
+{
+	{{$ic := .IC}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		tbuf, err := fs.CaptureField(tok)
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+
+		{{if eq .TakeAddr true}}
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getType $ic .Typ.Name .Typ}})
+		}
+		err = {{.Func}}(tbuf, {{.Name}})
+		{{else}}
+		err = {{.Func}}(tbuf, &{{.Name}})
+		{{end}}
+		if err != nil {
+			return fs.WrapErr(err)
+		}
+	}
+	state = fflib.FFParse_after_value
+}
+`
@@ -20,6 +20,8 @@ package ffjsoninception
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/maxproc/ffjson/shared"
 )
@@ -80,53 +82,74 @@ func getOmitEmpty(ic *Inception, sf *StructField) string {
 func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceString bool) string {
 	var out = ""
 
-	if typ.Key().Kind() != reflect.String {
-		out += fmt.Sprintf("/* Falling back. type=%v kind=%v */\n", typ, typ.Kind())
-		out += ic.q.Flush()
-		out += "err = buf.Encode(" + name + ")" + "\n"
-		out += "if err != nil {" + "\n"
-		out += "  return err" + "\n"
-		out += "}" + "\n"
-		return out
-	}
-
-	var elemKind reflect.Kind
-	elemKind = typ.Elem().Kind()
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
 
-	switch elemKind {
-	case reflect.String,
-		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-		reflect.Float32,
-		reflect.Float64,
-		reflect.Bool:
-
-		ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
-
-		out += "if " + name + " == nil  {" + "\n"
-		ic.q.Write("null")
-		out += ic.q.GetQueued()
-		ic.q.DeleteLast()
-		out += "} else {" + "\n"
-		out += ic.q.WriteFlush("{ ")
+	out += "if " + name + " == nil  {" + "\n"
+	ic.q.Write("null")
+	out += ic.q.GetQueued()
+	ic.q.DeleteLast()
+	out += "} else {" + "\n"
+	out += ic.q.WriteFlush("{ ")
+	if typ.Key().Kind() == reflect.String {
+		value := getGetInnerValue(ic, "value", typ.Elem(), false, forceString)
+		// Maps of other values were written by encoding/json, sorted by
+		// their keys, before the generated code wrote them, so they stay
+		// sorted.
+		if ic.sortMaps || !isScalar(typ.Elem()) {
+			out += getSortedMapLoop(ic, name, typ, value)
+		} else {
+			// The keys are sorted when fflib.SetSortMapKeys is on.
+			out += "if fflib.SortMapKeys() {" + "\n"
+			out += getSortedMapLoop(ic, name, typ, value)
+			out += "} else {" + "\n"
+			out += "  for key, value := range " + name + " {" + "\n"
+			out += "    fflib.WriteJsonString(buf, string(key))" + "\n"
+			out += "    buf.WriteString(`:`)" + "\n"
+			out += value
+			out += "    buf.WriteByte(',')" + "\n"
+			out += "  }" + "\n"
+			out += "}" + "\n"
+		}
+	} else {
+		// Other keys are written as text, sorted like encoding/json
+		// sorts them.
+		out += "  ffjText := make([]string, 0, len(" + name + "))" + "\n"
+		out += "  ffjValues := make([]" + mustTypeExpr(ic, name, typ.Elem()) + ", 0, len(" + name + "))" + "\n"
 		out += "  for key, value := range " + name + " {" + "\n"
-		out += "    fflib.WriteJsonString(buf, key)" + "\n"
+		out += getMapKeyText(ic, "key", typ.Key())
+		out += "    ffjValues = append(ffjValues, value)" + "\n"
+		out += "  }" + "\n"
+		out += "  for _, i := range fflib.SortedKeys(ffjText) {" + "\n"
+		out += "    value := ffjValues[i]" + "\n"
+		out += "    fflib.WriteJsonString(buf, ffjText[i])" + "\n"
 		out += "    buf.WriteString(`:`)" + "\n"
 		out += getGetInnerValue(ic, "value", typ.Elem(), false, forceString)
 		out += "    buf.WriteByte(',')" + "\n"
 		out += "  }" + "\n"
-		out += "buf.Rewind(1)" + "\n"
-		out += ic.q.WriteFlush("}")
-		out += "}" + "\n"
-
-	default:
-		out += ic.q.Flush()
-		out += fmt.Sprintf("/* Falling back. type=%v kind=%v */\n", typ, typ.Kind())
-		out += "err = buf.Encode(" + name + ")" + "\n"
-		out += "if err != nil {" + "\n"
-		out += "  return err" + "\n"
-		out += "}" + "\n"
 	}
+	out += "buf.Rewind(1)" + "\n"
+	out += ic.q.WriteFlush("}")
+	out += "}" + "\n"
+
+	return out
+}
+
+// getSortedMapLoop writes the members of the map name with string keys
+// in the order of the keys, writing each value with the code of value.
+func getSortedMapLoop(ic *Inception, name string, typ reflect.Type, value string) string {
+	ic.OutputImports[`"sort"`] = true
+	out := "  ffjKeys := make([]string, 0, len(" + name + "))" + "\n"
+	out += "  for key := range " + name + " {" + "\n"
+	out += "    ffjKeys = append(ffjKeys, string(key))" + "\n"
+	out += "  }" + "\n"
+	out += "  sort.Strings(ffjKeys)" + "\n"
+	out += "  for _, key := range ffjKeys {" + "\n"
+	out += "    value := (" + name + ")[" + mustTypeExpr(ic, name, typ.Key()) + "(key)]" + "\n"
+	out += "    fflib.WriteJsonString(buf, key)" + "\n"
+	out += "    buf.WriteString(`:`)" + "\n"
+	out += value
+	out += "    buf.WriteByte(',')" + "\n"
+	out += "  }" + "\n"
 	return out
 }
 
@@ -138,6 +161,23 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += ic.q.Flush()
 	}
 
+	if c, ok := getCodec(ic, typ); ok && c.Marshal != "" {
+		return out + getCodecValue(ic, name, typ, ptr, c)
+	}
+
+	if ic.redacting && (redactsType(ic, typ) || typ.Kind() == reflect.Ptr && redactsType(ic, typ.Elem())) {
+		out += ic.q.Flush()
+		out += tplStr(encodeTpl["handleMarshaler"], handleMarshaler{
+			IC:             ic,
+			Name:           name,
+			Typ:            typ,
+			Ptr:            reflect.Ptr,
+			MarshalJSONBuf: true,
+			Method:         "marshalJSONBufRedacted",
+		})
+		return out
+	}
+
 	if typ.Implements(marshalerFasterType) ||
 		reflect.PtrTo(typ).Implements(marshalerFasterType) ||
 		typeInInception(ic, typ, shared.MustEncoder) ||
@@ -168,7 +208,7 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		reflect.Int32,
 		reflect.Int64:
 		ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
-		out += "fflib.FormatBits2(buf, uint64(" + ptname + "), 10, " + ptname + " < 0)" + "\n"
+		out += "fflib.WriteInt(buf, int64(" + ptname + "))" + "\n"
 	case reflect.Uint,
 		reflect.Uint8,
 		reflect.Uint16,
@@ -176,13 +216,13 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		reflect.Uint64,
 		reflect.Uintptr:
 		ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
-		out += "fflib.FormatBits2(buf, uint64(" + ptname + "), 10, false)" + "\n"
+		out += "fflib.WriteUint(buf, uint64(" + ptname + "))" + "\n"
 	case reflect.Float32:
 		ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 32)" + "\n"
+		out += getFloatValue(ic, "float64("+ptname+")", 32, forceString)
 	case reflect.Float64:
 		ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 64)" + "\n"
+		out += getFloatValue(ic, "float64("+ptname+")", 64, forceString)
 	case reflect.Array,
 		reflect.Slice:
 
@@ -199,8 +239,9 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 			out += "buf.WriteString(`\"`)" + "\n"
 			out += `{` + "\n"
 			out += `enc := base64.NewEncoder(base64.StdEncoding, buf)` + "\n"
-			if typ.Elem().Name() != "byte" {
+			if typ.Elem().PkgPath() != "" {
 				ic.OutputImports[`"reflect"`] = true
+				ic.fallback(typ)
 				out += `enc.Write(reflect.Indirect(reflect.ValueOf(` + ptname + `)).Bytes())` + "\n"
 
 			} else {
@@ -225,15 +266,20 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 			out += "}" + "\n"
 		}
 	case reflect.String:
-		// Is it a json.Number?
-		if typ.PkgPath() == "encoding/json" && typ.Name() == "Number" {
-			// Fall back to json package to rely on the valid number check.
-			// See: https://github.com/golang/go/blob/92cd6e3af9f423ab4d8ac78f24e7fd81c31a8ce6/src/encoding/json/encode.go#L550
+		if isJSONNumber(typ) {
+			// Written as it is, after checking that it is a number.
+			ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
 			out += fmt.Sprintf("/* json.Number */\n")
-			out += "err = buf.Encode(" + name + ")" + "\n"
+			if forceString {
+				out += "buf.WriteByte('\"')" + "\n"
+			}
+			out += "err = fflib.WriteNumber(buf, " + ptname + ")" + "\n"
 			out += "if err != nil {" + "\n"
 			out += "  return err" + "\n"
 			out += "}" + "\n"
+			if forceString {
+				out += "buf.WriteByte('\"')" + "\n"
+			}
 		} else {
 			ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
 			if forceString {
@@ -271,6 +317,7 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += "}" + "\n"
 	case reflect.Interface:
 		out += fmt.Sprintf("/* Interface types must use runtime reflection. type=%v kind=%v */\n", typ, typ.Kind())
+		ic.fallback(typ)
 		out += "err = buf.Encode(" + name + ")" + "\n"
 		out += "if err != nil {" + "\n"
 		out += "  return err" + "\n"
@@ -289,6 +336,11 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 			for _, field := range fields {
 				// Adjust field name
 				field.Name = name + "." + field.Name
+				embedded := make([]EmbeddedPtr, 0, len(field.Embedded))
+				for _, e := range field.Embedded {
+					embedded = append(embedded, EmbeddedPtr{Name: name + "." + e.Name, Typ: e.Typ})
+				}
+				field.Embedded = embedded
 				out += getField(ic, field, "")
 			}
 
@@ -302,6 +354,7 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		} else {
 			out += fmt.Sprintf("/* Struct fall back. type=%v kind=%v */\n", typ, typ.Kind())
 			out += ic.q.Flush()
+			ic.fallback(typ)
 			if ptr {
 				out += "err = buf.Encode(" + name + ")" + "\n"
 			} else {
@@ -314,6 +367,7 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		}
 	default:
 		out += fmt.Sprintf("/* Falling back. type=%v kind=%v */\n", typ, typ.Kind())
+		ic.fallback(typ)
 		out += "err = buf.Encode(" + name + ")" + "\n"
 		out += "if err != nil {" + "\n"
 		out += "  return err" + "\n"
@@ -345,7 +399,28 @@ func getValue(ic *Inception, sf *StructField, prefix string) string {
 			closequote = true
 		}
 	}
-	out := getGetInnerValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.ForceString)
+	if sf.SortedMap && !ic.sortMaps {
+		ic.sortMaps = true
+		defer func() { ic.sortMaps = false }()
+	}
+	var out string
+	if sf.Scale != "" {
+		out = getScaledValue(ic, prefix+sf.Name, sf)
+	} else if sf.Epoch != "" {
+		out = getEpochValue(ic, prefix+sf.Name, sf)
+	} else if sf.Layout != "" {
+		out = getLayoutValue(ic, prefix+sf.Name, sf)
+	} else if sf.GroupSep != 0 {
+		out = getGroupedValue(ic, prefix+sf.Name, sf)
+	} else if sf.StreamString {
+		out = getStreamStringValue(ic, prefix+sf.Name)
+	} else if len(sf.Flags) > 0 {
+		out = getFlagsValue(ic, prefix+sf.Name, sf)
+	} else if sf.EnumUnknown != "" && !sf.EnumKeep {
+		out = getEnumValue(ic, prefix+sf.Name, sf)
+	} else {
+		out = getGetInnerValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.ForceString)
+	}
 	if closequote {
 		if sf.Pointer {
 			out += ic.q.WriteFlush(`"`)
@@ -357,6 +432,187 @@ func getValue(ic *Inception, sf *StructField, prefix string) string {
 	return out
 }
 
+// getFlagsValue writes a bitmask field as an array of flag names.
+func getFlagsValue(ic *Inception, name string, sf *StructField) string {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Flags %s. type=%v kind=%v */\n", strings.Join(sf.Flags, "|"), sf.Typ, sf.Typ.Kind())
+	out += "err = fflib.WriteFlags(buf, " + getFlagsBits(name, sf.Typ) + ", " + getFlagsNames(sf.Flags) + ", " + strconv.FormatBool(sf.FlagsIgnore) + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getEpochValue writes a time field as the number of units since its
+// epoch.
+func getEpochValue(ic *Inception, name string, sf *StructField) string {
+	ptname := name
+	if sf.Pointer {
+		ptname = "*" + name
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Epoch %s, %s per second. type=%v kind=%v */\n", sf.Epoch, sf.EpochUnit, sf.Typ, sf.Typ.Kind())
+	out += "{" + "\n"
+	out += "v, err := fflib.EpochCount(" + ptname + ", " + sf.Epoch + ", " + sf.EpochUnit + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	out += "fflib.WriteInt(buf, v)" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getLayoutValue writes a time field, or the times of a slice, as
+// strings formatted with the layout of the field.
+func getLayoutValue(ic *Inception, name string, sf *StructField) string {
+	layout := strconv.Quote(sf.Layout)
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Layout. type=%v kind=%v */\n", sf.Typ, sf.Typ.Kind())
+	if sf.Typ.Kind() != reflect.Slice {
+		out += "fflib.WriteJsonString(buf, " + name + ".Format(" + layout + "))" + "\n"
+		return out
+	}
+	out += "if " + name + " == nil {" + "\n"
+	out += `  buf.WriteString("null")` + "\n"
+	out += "} else {" + "\n"
+	out += "  buf.WriteByte('[')" + "\n"
+	out += "  for i, v := range " + name + " {" + "\n"
+	out += "    if i != 0 {" + "\n"
+	out += "      buf.WriteByte(',')" + "\n"
+	out += "    }" + "\n"
+	out += "    fflib.WriteJsonString(buf, v.Format(" + layout + "))" + "\n"
+	out += "  }" + "\n"
+	out += "  buf.WriteByte(']')" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getGroupedValue writes a number with separators between groups of
+// digits.
+func getGroupedValue(ic *Inception, name string, sf *StructField) string {
+	ptname := name
+	if sf.Pointer {
+		ptname = "*" + name
+	}
+	sep := strconv.QuoteRune(rune(sf.GroupSep))
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Grouped by %s. type=%v kind=%v */\n", sep, sf.Typ, sf.Typ.Kind())
+	switch kind := sf.Typ.Kind(); {
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		out += "err = fflib.WriteGroupedFloat(buf, float64(" + ptname + "), " + strconv.Itoa(sf.Typ.Bits()) + ", " + sep + ", " + strconv.QuoteRune(rune(sf.GroupPoint)) + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		out += "fflib.WriteGroupedInt(buf, int64(" + ptname + "), " + sep + ")" + "\n"
+	default:
+		out += "fflib.WriteGroupedUint(buf, uint64(" + ptname + "), " + sep + ")" + "\n"
+	}
+	return out
+}
+
+// getEnumValue writes an enum field, writing the unknown constant for
+// values not in the enum.
+func getEnumValue(ic *Inception, name string, sf *StructField) string {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Enum %s. type=%v kind=%v */\n", strings.Join(sf.Enum, "|"), sf.Typ, sf.Typ.Kind())
+	out += "switch " + name + " {" + "\n"
+	out += "case " + getEnumValues(sf.Enum) + ":" + "\n"
+	out += "fflib.WriteJsonString(buf, string(" + name + "))" + "\n"
+	out += "default:" + "\n"
+	out += "fflib.WriteJsonString(buf, string(" + sf.EnumUnknown + "))" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+func getEnumValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// getFlagsBits converts an integer to uint64 without sign extension.
+func getFlagsBits(name string, typ reflect.Type) string {
+	if typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64 {
+		return "uint64(uint" + strconv.Itoa(typ.Bits()) + "(" + name + "))"
+	}
+	return "uint64(" + name + ")"
+}
+
+func getFlagsNames(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, n := range names {
+		quoted = append(quoted, strconv.Quote(n))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// getFloatValue writes the float64 expression expr, which is a float32
+// if bits is 32. NaN and infinities fail the encoding, or are written as
+// null with -nan-null. Quoted floats, of fields with the string option,
+// are written in the form of encoding/json instead of the shorter 'g'
+// form, as they are compared as strings.
+func getFloatValue(ic *Inception, expr string, bits int, quoted bool) string {
+	if ic.nanNull {
+		return "fflib.WriteFloatOrNull(buf, " + expr + ", " + strconv.Itoa(bits) + ")" + "\n"
+	}
+	write := "WriteFiniteFloat"
+	if quoted {
+		write = "WriteStdFloat"
+	}
+	out := "err = fflib." + write + "(buf, " + expr + ", " + strconv.Itoa(bits) + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getScaledValue writes a numeric field multiplied by its scale.
+func getScaledValue(ic *Inception, name string, sf *StructField) string {
+	ptname := name
+	if sf.Pointer {
+		ptname = "*" + name
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += fmt.Sprintf("/* Scaled by %s. type=%v kind=%v */\n", sf.Scale, sf.Typ, sf.Typ.Kind())
+	switch sf.Typ.Kind() {
+	case reflect.Float32:
+		out += getFloatValue(ic, "float64("+ptname+"*"+sf.Scale+")", 32, false)
+	case reflect.Float64:
+		out += getFloatValue(ic, "float64("+ptname+"*"+sf.Scale+")", 64, false)
+	default:
+		signed := sf.Typ.Kind() >= reflect.Int && sf.Typ.Kind() <= reflect.Int64
+		out += "{" + "\n"
+		if signed {
+			out += "v, err := fflib.ScaleInt(int64(" + ptname + "), " + sf.Scale + ")" + "\n"
+		} else {
+			out += "v, err := fflib.ScaleUint(uint64(" + ptname + "), " + sf.Scale + ")" + "\n"
+		}
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		if signed {
+			out += "fflib.WriteInt(buf, v)" + "\n"
+		} else {
+			out += "fflib.WriteUint(buf, v)" + "\n"
+		}
+		out += "}" + "\n"
+	}
+	return out
+}
+
 func p2(v uint32) uint32 {
 	v--
 	v |= v >> 1
@@ -410,6 +666,9 @@ func getTotalSize(si *StructInfo) uint32 {
 }
 
 func getBufGrowSize(si *StructInfo) uint32 {
+	if si.Options.BufferSize > 0 {
+		return p2(uint32(si.Options.BufferSize))
+	}
 
 	// TOOD(pquerna): automatically calc a better grow size based on history
 	// of a struct.
@@ -432,6 +691,21 @@ func isIntish(t reflect.Type) bool {
 }
 
 func getField(ic *Inception, f *StructField, prefix string) string {
+	if len(f.Embedded) > 0 {
+		// Like encoding/json, fields promoted through nil embedded
+		// pointers are left out.
+		promoted := *f
+		promoted.Embedded = nil
+		out := ic.q.Flush()
+		out += "if " + getEmbeddedCheck(f, prefix) + " {" + "\n"
+		out += getField(ic, &promoted, prefix)
+		out += ic.q.Flush()
+		out += "}" + "\n"
+		return out
+	}
+	if len(f.Split) > 0 {
+		return getSplitTime(ic, f, prefix)
+	}
 	out := ""
 	if f.OmitEmpty {
 		out += ic.q.Flush()
@@ -440,19 +714,36 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 		}
 		out += getOmitEmpty(ic, f)
 	}
+	if f.OmitZero {
+		out += ic.q.Flush()
+		out += "if " + getOmitZero(ic, f, prefix+f.Name) + " {" + "\n"
+	}
+
+	if f.TriState {
+		// Absent fields are left out, and null fields are written as null.
+		ic.OutputImports[`"github.com/maxproc/ffjson/shared"`] = true
+		out += ic.q.Flush()
+		out += "if " + prefix + f.Name + "State != shared.TriStateAbsent {" + "\n"
+	}
 
 	if f.Pointer && !f.OmitEmpty {
 		// Pointer values encode as the value pointed to. A nil pointer encodes as the null JSON object.
-		out += "if " + prefix + f.Name + " != nil {" + "\n"
+		if f.TriState {
+			out += "if " + prefix + f.Name + " != nil && " + prefix + f.Name + "State != shared.TriStateNull {" + "\n"
+		} else {
+			out += "if " + prefix + f.Name + " != nil {" + "\n"
+		}
 	}
 
+	out += getFieldStats(ic, f)
+
 	// JsonName is already escaped and quoted.
 	// getInnervalue should flush
 	ic.q.Write(f.JsonName + ":")
 	// We save a copy in case we need it
 	t := ic.q
 
-	out += getValue(ic, f, prefix)
+	out += getFieldValue(ic, f, prefix)
 	ic.q.Write(",")
 
 	if f.Pointer && !f.OmitEmpty {
@@ -461,6 +752,11 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 		out += "}" + "\n"
 	}
 
+	if f.OmitZero {
+		out += ic.q.Flush()
+		out += "}" + "\n"
+	}
+
 	if f.OmitEmpty {
 		out += ic.q.Flush()
 		if f.Pointer {
@@ -468,6 +764,41 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 		}
 		out += "}" + "\n"
 	}
+
+	if f.TriState {
+		out += ic.q.Flush()
+		out += "}" + "\n"
+	}
+	return out
+}
+
+// getEmbeddedCheck returns the condition of the embedded pointers f is
+// promoted through not being nil.
+func getEmbeddedCheck(f *StructField, prefix string) string {
+	checks := make([]string, 0, len(f.Embedded))
+	for _, e := range f.Embedded {
+		checks = append(checks, prefix+e.Name+" != nil")
+	}
+	return strings.Join(checks, " && ")
+}
+
+// getFieldValue writes the value of f, which isn't nil if it is a
+// pointer.
+func getFieldValue(ic *Inception, f *StructField, prefix string) string {
+	out := ""
+	if f.Redacted {
+		ic.q.Write(`"[REDACTED]"`)
+	} else if f.Ref != "" {
+		out += getRefValue(ic, f, prefix+f.Name)
+	} else if f.RedactNested {
+		out += ic.q.Flush()
+		out += "err = " + prefix + f.Name + ".marshalJSONBufRedacted(buf)" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+	} else {
+		out += getValue(ic, f, prefix)
+	}
 	return out
 }
 
@@ -475,70 +806,311 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 func lastConditional(fields []*StructField) bool {
 	if len(fields) > 0 {
 		f := fields[len(fields)-1]
-		return f.OmitEmpty
+		return f.OmitEmpty || f.OmitZero || f.TriState || len(f.Embedded) > 0
 	}
 	return false
 }
 
 func CreateMarshalJSON(ic *Inception, si *StructInfo) error {
-	conditionalWrites := lastConditional(si.Fields)
 	out := ""
 
+	if si.Options.PoolMarshal || si.Options.MarshalTo {
+		ic.OutputImports[`"sync"`] = true
+		var users []string
+		if si.Options.PoolMarshal {
+			users = append(users, si.Name+".MarshalJSON")
+		}
+		if si.Options.MarshalTo {
+			users = append(users, si.Name+".MarshalTo")
+		}
+		out += "// ffjBufPool" + si.Name + " holds the buffers of " + strings.Join(users, " and ") + ".\n"
+		out += "var ffjBufPool" + si.Name + " = sync.Pool{New: func() interface{} { return new(fflib.Buffer) }}\n\n"
+	}
+
 	out += "// MarshalJSON marshal bytes to json - template\n"
 	out += `func (j *` + si.Name + `) MarshalJSON() ([]byte, error) {` + "\n"
-	out += `var buf fflib.Buffer` + "\n"
+	if si.Options.PoolMarshal {
+		out += `if j == nil {` + "\n"
+		out += `  return []byte("null"), nil` + "\n"
+		out += `}` + "\n"
+	} else {
+		out += `var buf fflib.Buffer` + "\n"
 
-	out += `if j == nil {` + "\n"
-	out += `  buf.WriteString("null")` + "\n"
-	out += "  return buf.Bytes(), nil" + "\n"
-	out += `}` + "\n"
+		out += `if j == nil {` + "\n"
+		out += `  buf.WriteString("null")` + "\n"
+		out += "  return buf.Bytes(), nil" + "\n"
+		out += `}` + "\n"
+	}
 
-	out += `err := j.MarshalJSONBuf(&buf)` + "\n"
+	if si.Options.Gate {
+		// The local type has the fields of the struct but not its
+		// methods, so json.Marshal doesn't call MarshalJSON again.
+		ic.OutputImports[`"encoding/json"`] = true
+		out += `if fflib.MarshalGate.Stdlib() {` + "\n"
+		out += `  type ffjStdlib ` + si.Name + "\n"
+		out += `  return json.Marshal((*ffjStdlib)(j))` + "\n"
+		out += `}` + "\n"
+	}
+
+	if si.Options.PoolMarshal {
+		// Buffers which grew past 64KB are left to the garbage collector,
+		// so a few large values don't keep their memory in the pool.
+		out += `buf := ffjBufPool` + si.Name + `.Get().(*fflib.Buffer)` + "\n"
+		out += `defer func() {` + "\n"
+		out += `  if buf.Len() <= 1<<16 {` + "\n"
+		out += `    buf.Reset()` + "\n"
+		out += `    ffjBufPool` + si.Name + `.Put(buf)` + "\n"
+		out += `  }` + "\n"
+		out += `}()` + "\n"
+	}
+	if si.Options.BufferSize > 0 {
+		// Sized by -profile.
+		out += fmt.Sprintf("buf.Grow(%d)", getBufGrowSize(si)) + "\n"
+	}
+	if si.Options.PoolMarshal {
+		out += `err := j.MarshalJSONBuf(buf)` + "\n"
+	} else {
+		out += `err := j.MarshalJSONBuf(&buf)` + "\n"
+	}
 	out += `if err != nil {` + "\n"
 	out += "  return nil, err" + "\n"
 	out += `}` + "\n"
-	out += `return buf.Bytes(), nil` + "\n"
+	if si.Options.Verbose {
+		out += `if fflib.Verbose {` + "\n"
+		out += "  return fflib.Indent(buf.Bytes())" + "\n"
+		out += `}` + "\n"
+	}
+	if si.Options.PoolMarshal {
+		// The buffer goes back to the pool, so the caller gets a copy.
+		out += `rv := make([]byte, buf.Len())` + "\n"
+		out += `copy(rv, buf.Bytes())` + "\n"
+		out += `return rv, nil` + "\n"
+	} else {
+		out += `return buf.Bytes(), nil` + "\n"
+	}
 	out += `}` + "\n"
 
-	out += "// MarshalJSONBuf marshal buff to json - template\n"
-	out += `func (j *` + si.Name + `) MarshalJSONBuf(buf fflib.EncodingBuffer) (error) {` + "\n"
+	bufFunc := "MarshalJSONBuf"
+	if si.Options.EnvelopeKey != "" {
+		bufFunc = "marshalJSONBufPayload"
+	}
+	if si.Options.Refs {
+		bufFunc = "marshalJSONBufRefs"
+		out += createRefsMarshal(si)
+	}
+	if si.Options.KeepOrder {
+		ordered, err := createOrderedMarshal(ic, si, bufFunc, "marshalJSONBufFields")
+		if err != nil {
+			return err
+		}
+		out += ordered
+		bufFunc = "marshalJSONBufFields"
+	}
+
+	if si.Options.Verbose {
+		// The verbose variant writes all fields, ignoring omitempty. It is
+		// only called when building with the ffjson_verbose tag.
+		verboseFunc := "marshalJSONBufVerbose"
+		if si.Options.EnvelopeKey != "" {
+			verboseFunc = "marshalJSONBufPayloadVerbose"
+		}
+		fields := make([]*StructField, 0, len(si.Fields))
+		for _, f := range si.Fields {
+			vf := *f
+			vf.OmitEmpty = false
+			vf.OmitZero = false
+			fields = append(fields, &vf)
+		}
+		out += getMarshalJSONBuf(ic, si, bufFunc, si.Fields, verboseFunc)
+		out += getMarshalJSONBuf(ic, si, verboseFunc, fields, "")
+	} else {
+		out += getMarshalJSONBuf(ic, si, bufFunc, si.Fields, "")
+	}
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+
+	if si.Options.EnvelopeKey != "" {
+		return createEnvelopeMarshal(ic, si)
+	}
+	return nil
+}
+
+// getMarshalJSONBuf generates the function writing fields of si into a
+// buffer. If verboseFunc is set, verbose builds call it instead. Structs
+// with refs also take the references met so far.
+func getMarshalJSONBuf(ic *Inception, si *StructInfo, funcName string, fields []*StructField, verboseFunc string) string {
+	out := ""
+
+	params := "buf fflib.EncodingBuffer"
+	if si.Options.Refs {
+		params += ", refs *fflib.Refs"
+	}
+
+	out += "// " + funcName + " marshal buff to json - template\n"
+	out += `func (j *` + si.Name + `) ` + funcName + `(` + params + `) (error) {` + "\n"
 	out += `  if j == nil {` + "\n"
 	out += `    buf.WriteString("null")` + "\n"
 	out += "    return nil" + "\n"
 	out += `  }` + "\n"
 
+	if verboseFunc != "" {
+		out += `  if fflib.Verbose {` + "\n"
+		out += "    return j." + verboseFunc + "(buf)" + "\n"
+		out += `  }` + "\n"
+	}
+	out += getEncodeStats(ic, si)
+
 	out += `var err error` + "\n"
 	out += `var obj []byte` + "\n"
 	out += `_ = obj` + "\n"
 	out += `_ = err` + "\n"
 
-	ic.q.Write("{")
-
-	// The extra space is inserted here.
-	// If nothing is written to the field this will be deleted
-	// instead of the last comma.
-	if conditionalWrites || len(si.Fields) == 0 {
-		ic.q.Write(" ")
+	if si.Options.Tuple {
+		out += getTupleArray(ic, fields, "j.")
+	} else {
+		out += getGroupObject(ic, groupFields(fields), "j.")
 	}
+	out += ic.q.Flush()
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	return out
+}
 
-	for _, f := range si.Fields {
-		out += getField(ic, f, "j.")
+func CreateMarshalJSONCanonical(ic *Inception, si *StructInfo) error {
+	out := ""
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+
+	out += "// MarshalJSONCanonical marshal bytes to RFC 8785 canonical json - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONCanonical() ([]byte, error) {` + "\n"
+	out += `if j == nil {` + "\n"
+	out += `  return []byte("null"), nil` + "\n"
+	out += `}` + "\n"
+	out += `var buf fflib.Buffer` + "\n"
+	out += `err := j.MarshalJSONBuf(&buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.Canonicalize(buf.Bytes())` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateJSONPatch generates a JSONPatch function, diffing the regular
+// MarshalJSON output of two values.
+func CreateJSONPatch(ic *Inception, si *StructInfo) error {
+	out := ""
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+
+	out += "// JSONPatch returns the RFC 6902 JSON Patch transforming base into j - template\n"
+	out += `func (j *` + si.Name + `) JSONPatch(base *` + si.Name + `) ([]byte, error) {` + "\n"
+	out += `from, err := base.MarshalJSON()` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `to, err := j.MarshalJSON()` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.JSONPatch(from, to)` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateMarshalSSE generates a MarshalSSE function, framing the regular
+// MarshalJSON output as a Server-Sent Event.
+func CreateMarshalSSE(ic *Inception, si *StructInfo) error {
+	out := ""
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+
+	out += "// MarshalSSE marshal j as a Server-Sent Event named event - template\n"
+	out += `func (j *` + si.Name + `) MarshalSSE(event string) ([]byte, error) {` + "\n"
+	out += `data, err := j.MarshalJSON()` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.SSE(event, data)` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateMarshalJSONRPC generates MarshalJSONRPC and MarshalJSONRPCError
+// functions, wrapping the regular MarshalJSON output in a JSON-RPC 2.0
+// response as its result or as the data of its error.
+func CreateMarshalJSONRPC(ic *Inception, si *StructInfo) error {
+	out := ""
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+
+	out += "// MarshalJSONRPC marshal j as the result of a JSON-RPC 2.0 response with id - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONRPC(id interface{}) ([]byte, error) {` + "\n"
+	out += `result, err := j.MarshalJSON()` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.JSONRPCResult(id, result)` + "\n"
+	out += `}` + "\n\n"
+
+	out += "// MarshalJSONRPCError marshal j as the data of a JSON-RPC 2.0 error response with id - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONRPCError(id interface{}, code int, message string) ([]byte, error) {` + "\n"
+	out += `if j == nil {` + "\n"
+	out += "  return fflib.JSONRPCError(id, code, message, nil)" + "\n"
+	out += `}` + "\n"
+	out += `data, err := j.MarshalJSON()` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.JSONRPCError(id, code, message, data)` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateMarshalNegotiated generates a Marshal function, calling the
+// marshaler of the format matching a content type.
+func CreateMarshalNegotiated(ic *Inception, si *StructInfo) error {
+	if _, ok := si.Typ.FieldByName("Marshal"); ok {
+		return fmt.Errorf("%s: -negotiate can't be used on structs with a Marshal field", si.Name)
 	}
+	out := ""
 
-	// Handling the last comma is tricky.
-	// If the last field has omitempty, conditionalWrites is set.
-	// If something has been written, we delete the last comma,
-	// by backing up the buffer, otherwise it will delete a space.
-	if conditionalWrites {
-		out += ic.q.Flush()
-		out += `buf.Rewind(1)` + "\n"
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+
+	out += "// Marshal marshal j in the format of contentType, as json for json types - template\n"
+	out += `func (j *` + si.Name + `) Marshal(contentType string) ([]byte, error) {` + "\n"
+	out += `mt := fflib.MediaType(contentType)` + "\n"
+	out += `switch {` + "\n"
+	out += `case fflib.IsJSONMediaType(mt):` + "\n"
+	out += "  return j.MarshalJSON()" + "\n"
+	if si.Options.SSE {
+		out += `case mt == "text/event-stream":` + "\n"
+		out += `  return j.MarshalSSE("")` + "\n"
+	}
+	if si.Options.Msgpack {
+		out += `case mt == "application/msgpack" || mt == "application/x-msgpack":` + "\n"
+		out += "  return j.MarshalMsgpack()" + "\n"
+	}
+	if si.Options.CBOR {
+		out += `case mt == "application/cbor":` + "\n"
+		out += "  return j.MarshalCBOR()" + "\n"
+	}
+	out += `}` + "\n"
+	if si.Options.Negotiate == "error" {
+		out += `return nil, &fflib.UnsupportedMediaTypeError{ContentType: contentType}` + "\n"
 	} else {
-		ic.q.DeleteLast()
+		out += `return j.MarshalJSON()` + "\n"
 	}
-
-	out += ic.q.WriteFlush("}")
-	out += `return nil` + "\n"
 	out += `}` + "\n"
+
 	ic.OutputFuncs = append(ic.OutputFuncs, out)
 	return nil
 }
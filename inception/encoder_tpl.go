@@ -44,6 +44,8 @@ type handleMarshaler struct {
 	Ptr            reflect.Kind
 	MarshalJSONBuf bool
 	Marshaler      bool
+	// Method is the method writing to buf, MarshalJSONBuf unless set.
+	Method string
 }
 
 var handleMarshalerTxt = `
@@ -55,7 +57,7 @@ var handleMarshalerTxt = `
 		{{end}}
 
 		{{if eq .MarshalJSONBuf true}}
-		err = {{.Name}}.MarshalJSONBuf(buf)
+		err = {{.Name}}.{{if .Method}}{{.Method}}{{else}}MarshalJSONBuf{{end}}(buf)
 		if err != nil {
 			return err
 		}
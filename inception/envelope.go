@@ -0,0 +1,128 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+)
+
+type envelopeField struct {
+	Name  string
+	Value string
+}
+
+// getEnvelopeFields returns the constant envelope members of si sorted by
+// name, with their values in canonical form.
+func getEnvelopeFields(si *StructInfo) ([]envelopeField, error) {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal([]byte(si.Options.EnvelopeFields), &raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid envelope fields: %v", si.Name, err)
+	}
+
+	rv := make([]envelopeField, 0, len(raw))
+	for k, v := range raw {
+		cv, err := fflib.Canonicalize(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid envelope field %q: %v", si.Name, k, err)
+		}
+		rv = append(rv, envelopeField{Name: k, Value: string(cv)})
+	}
+	sort.Slice(rv, func(i, j int) bool { return rv[i].Name < rv[j].Name })
+	return rv, nil
+}
+
+func jsonKey(name string) string {
+	var buf bytes.Buffer
+	fflib.WriteJsonString(&buf, name)
+	return buf.String()
+}
+
+// getEnvelopePrefix returns the json written before the payload.
+func getEnvelopePrefix(si *StructInfo) (string, error) {
+	fields, err := getEnvelopeFields(si)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := "{"
+	for _, f := range fields {
+		prefix += jsonKey(f.Name) + ":" + f.Value + ","
+	}
+	prefix += jsonKey(si.Options.EnvelopeKey) + ":"
+	return prefix, nil
+}
+
+func createEnvelopeMarshal(ic *Inception, si *StructInfo) error {
+	prefix, err := getEnvelopePrefix(si)
+	if err != nil {
+		return err
+	}
+
+	out := ""
+	out += "// MarshalJSONBuf marshal buff to json wrapped in an envelope - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONBuf(buf fflib.EncodingBuffer) (error) {` + "\n"
+	out += `  if j == nil {` + "\n"
+	out += `    buf.WriteString("null")` + "\n"
+	out += "    return nil" + "\n"
+	out += `  }` + "\n"
+	out += "buf.WriteString(" + strconv.Quote(prefix) + ")\n"
+	out += `err := j.marshalJSONBufPayload(buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += "buf.WriteByte('}')\n"
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+func createEnvelopeUnmarshal(ic *Inception, si *StructInfo) error {
+	fields, err := getEnvelopeFields(si)
+	if err != nil {
+		return err
+	}
+
+	out := ""
+	out += "var ffjEnvelope" + si.Name + " = &fflib.Envelope{\n"
+	out += "Key: " + strconv.Quote(si.Options.EnvelopeKey) + ",\n"
+	out += "Fields: map[string][]byte{\n"
+	for _, f := range fields {
+		out += strconv.Quote(f.Name) + ": []byte(" + strconv.Quote(f.Value) + "),\n"
+	}
+	out += "},\n"
+	out += "}\n\n"
+
+	out += "// UnmarshalJSONFFLexer fast json unmarshall of the envelope - template ffjson\n"
+	out += `func (j *` + si.Name + `) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {` + "\n"
+	out += "return ffjEnvelope" + si.Name + ".Unmarshal(fs, state, func() error {\n"
+	out += "  return j.unmarshalJSONFFLexerPayload(fs, fflib.FFParse_want_key)\n"
+	out += "})\n"
+	out += "}\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
@@ -0,0 +1,116 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CreateExtractors generates an ExtractFooBar function for each field Bar
+// of the struct Foo, decoding the field's member of a Foo object as
+// UnmarshalJSON would, while only scanning over the other members.
+func CreateExtractors(ic *Inception, si *StructInfo) error {
+	switch {
+	case si.Options.Tuple:
+		return fmt.Errorf("%s: -extractors can't be combined with tuple", si.Name)
+	case si.Options.EnvelopeKey != "":
+		return fmt.Errorf("%s: -extractors can't be combined with envelope", si.Name)
+	case si.Options.UnwrapType:
+		return fmt.Errorf("%s: -extractors can't be combined with unwraptype", si.Name)
+	}
+
+	fields := make([]*StructField, 0, len(si.Fields))
+	for _, f := range si.Fields {
+		// These fields don't map to a single member.
+		if f.TriState || len(f.Split) > 0 || f.Group != "" || f.Ref != "" || f.JsonName == `"-"` {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"bytes"`] = true
+	ic.OutputImports[`"fmt"`] = true
+	if si.Options.NormalizeKeys {
+		ic.OutputImports[`"golang.org/x/text/unicode/norm"`] = true
+	}
+
+	out := "var (\n"
+	for _, f := range si.Fields {
+		out += "ffjExtractKey" + si.Name + f.Ident() + " = []byte(" + f.KeyName + ")\n"
+	}
+	out += ")\n"
+
+	for _, f := range fields {
+		typ := f.Typ
+		if f.Pointer && typ.Kind() != reflect.Ptr {
+			typ = reflect.PtrTo(typ)
+		}
+		texpr, err := getTypeExpr(ic, f.Name, typ)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		out += tplStr(decodeTpl["ujExtract"], ujExtract{
+			IC:          ic,
+			SI:          si,
+			Field:       f,
+			ValidValues: validValues,
+			Func:        "Extract" + si.Name + strings.Replace(f.Name, ".", "", -1),
+			Type:        texpr,
+			Match:       getExtractMatch(si, f),
+		})
+	}
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// getExtractMatch returns the condition of the key kn selecting f in the
+// decoder: an exact match, or else a case insensitive match for which no
+// other field matches first.
+func getExtractMatch(si *StructInfo, f *StructField) string {
+	key := func(f *StructField) string {
+		return "ffjExtractKey" + si.Name + f.Ident()
+	}
+	match := "bytes.Equal(" + key(f) + ", kn)"
+	if si.Options.ExactCase {
+		return match
+	}
+
+	fold := f.FoldFuncName + "(" + key(f) + ", kn)"
+	after := false
+	for _, other := range si.Fields {
+		if other == f {
+			after = true
+			continue
+		}
+		if !strings.EqualFold(other.JsonName, f.JsonName) {
+			continue
+		}
+		fold += " && !bytes.Equal(" + key(other) + ", kn)"
+		// The decoder tries the fields in reverse order.
+		if after {
+			fold += " && !" + other.FoldFuncName + "(" + key(other) + ", kn)"
+		}
+	}
+	return match + " || (" + fold + ")"
+}
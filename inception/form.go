@@ -0,0 +1,155 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
+// CreateUnmarshalForm generates an UnmarshalForm function, decoding
+// url.Values into the struct using the json names of its fields.
+func CreateUnmarshalForm(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`"net/url"`] = true
+	ic.OutputImports[`"fmt"`] = true
+
+	out := ""
+	out += "// UnmarshalForm decodes form values, using the json names of the fields - template ffjson\n"
+	out += `func (j *` + si.Name + `) UnmarshalForm(values url.Values) error {` + "\n"
+
+	for _, f := range si.Fields {
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		out += getFormField(ic, "j."+f.Name, strconv.Quote(name), f.Typ, f.Pointer)
+	}
+
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+func getFormField(ic *Inception, name string, key string, typ reflect.Type, ptr bool) string {
+	if !ptr && typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8 {
+		elem := typ.Elem()
+		if !formSupported(elem) {
+			return fmt.Sprintf("/* Form values not supported. type=%v kind=%v */\n", typ, typ.Kind())
+		}
+
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v */\n", name, typ, typ.Kind())
+		out += "if vs, ok := values[" + key + "]; ok {" + "\n"
+		out += "tslice := make([]" + getType(ic, name, elem) + ", 0, len(vs))" + "\n"
+		out += "for _, v := range vs {" + "\n"
+		if elem.Kind() != reflect.String || reflect.PtrTo(elem).Implements(textUnmarshalerType) {
+			out += "if v == \"\" {" + "\n"
+			out += "  continue" + "\n"
+			out += "}" + "\n"
+		}
+		out += "var telem " + getType(ic, name, elem) + "\n"
+		out += getFormValue(ic, "telem", key, elem)
+		out += "tslice = append(tslice, telem)" + "\n"
+		out += "}" + "\n"
+		out += name + " = tslice" + "\n"
+		out += "}" + "\n"
+		return out
+	}
+
+	if !formSupported(typ) {
+		return fmt.Sprintf("/* Form values not supported. type=%v kind=%v */\n", typ, typ.Kind())
+	}
+
+	out := fmt.Sprintf("/* handler: %s type=%v kind=%v */\n", name, typ, typ.Kind())
+	if typ.Kind() == reflect.String && !reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		out += "if vs := values[" + key + "]; len(vs) != 0 {" + "\n"
+	} else {
+		// Empty inputs of other types are treated like missing ones.
+		out += "if vs := values[" + key + "]; len(vs) != 0 && vs[0] != \"\" {" + "\n"
+	}
+	out += "v := vs[0]" + "\n"
+	if ptr {
+		out += "var tptr " + getType(ic, name, typ) + "\n"
+		out += getFormValue(ic, "tptr", key, typ)
+		out += name + " = &tptr" + "\n"
+	} else {
+		out += getFormValue(ic, name, key, typ)
+	}
+	out += "}" + "\n"
+	return out
+}
+
+func formSupported(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// getFormValue parses the form value v into name, which has type typ.
+func getFormValue(ic *Inception, name string, key string, typ reflect.Type) string {
+	errOut := "if err != nil {" + "\n"
+	errOut += "  return fmt.Errorf(\"ffjson: form field %q: %v\", " + key + ", err)" + "\n"
+	errOut += "}" + "\n"
+
+	out := "{" + "\n"
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		out += "err := " + name + ".UnmarshalText([]byte(v))" + "\n"
+		out += errOut
+		out += "}" + "\n"
+		return out
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	switch typ.Kind() {
+	case reflect.String:
+		out += name + " = " + getType(ic, name, typ) + "(v)" + "\n"
+	case reflect.Bool:
+		out += "tval, err := fflib.ParseFormBool(v)" + "\n"
+		out += errOut
+		out += name + " = " + getType(ic, name, typ) + "(tval)" + "\n"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		out += "tval, err := fflib.ParseInt([]byte(v), 10, " + getNumberSize(typ) + ")" + "\n"
+		out += errOut
+		out += name + " = " + getType(ic, name, typ) + "(tval)" + "\n"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		out += "tval, err := fflib.ParseUint([]byte(v), 10, " + getNumberSize(typ) + ")" + "\n"
+		out += errOut
+		out += name + " = " + getType(ic, name, typ) + "(tval)" + "\n"
+	case reflect.Float32, reflect.Float64:
+		out += "tval, err := fflib.ParseFloat([]byte(v), " + getNumberSize(typ) + ")" + "\n"
+		out += errOut
+		out += name + " = " + getType(ic, name, typ) + "(tval)" + "\n"
+	}
+	out += "}" + "\n"
+	return out
+}
@@ -0,0 +1,267 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"bytes"
+	"go/format"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+const testsTemplate = `
+{{with .BuildConstraint}}{{.}}
+
+{{end}}// Code generated by ffjson <https://github.com/maxproc/ffjson>. DO NOT EDIT.
+// source: {{.InputPath}}
+
+package {{.PackageName}}
+
+import (
+	{{if .Std}}"encoding/json"{{end}}
+	{{if .Random}}"math/rand"{{end}}
+	"reflect"
+	"testing"
+	{{if .Random}}"testing/quick"{{end}}
+)
+{{range .Structs}}
+{{if .Std}}
+// ffjTestStd{{.Name}} has the fields of {{.Name}} without its methods, so
+// encoding/json handles it on its own.
+type ffjTestStd{{.Name}} {{.Name}}
+{{end}}
+
+// ffjTestCheck{{.Name}} checks that the json out, written by the encoder of
+// {{.Name}}, decodes and encodes again to the same value{{if .Std}}, and that
+// encoding/json decodes it to the same value too{{end}}.
+func ffjTestCheck{{.Name}}(t *testing.T, out []byte) {
+	var v {{.Name}}
+	err := v.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("decoding the json written by MarshalJSON: %v\n%s", err, out)
+	}
+	again, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatalf("encoding %#v decoded from %s: %v", v, out, err)
+	}
+	var w {{.Name}}
+	err = w.UnmarshalJSON(again)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", again, err)
+	}
+	if !reflect.DeepEqual(v, w) {
+		t.Fatalf("round trip changed the value\nfrom: %s\nto:   %s\n%#v\n%#v", out, again, v, w)
+	}
+	{{if .Std}}
+	var std ffjTestStd{{.Name}}
+	err = json.Unmarshal(out, &std)
+	if err != nil {
+		t.Fatalf("encoding/json can't decode %s: %v", out, err)
+	}
+	if !reflect.DeepEqual({{.Name}}(std), v) {
+		t.Fatalf("encoding/json decodes %s differently\nffjson:        %#v\nencoding/json: %#v", out, v, {{.Name}}(std))
+	}
+	{{end}}
+}
+
+// Fuzz{{.Name}} decodes arbitrary input with the generated decoder of
+// {{.Name}}, and checks the json of what it accepts.
+func Fuzz{{.Name}}(f *testing.F) {
+	f.Add([]byte(` + "`{}`" + `))
+	f.Add([]byte(` + "`null`" + `))
+	if seed, err := new({{.Name}}).MarshalJSON(); err == nil {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v {{.Name}}
+		if v.UnmarshalJSON(data) != nil {
+			return
+		}
+		out, err := v.MarshalJSON()
+		if err != nil {
+			// Some values decode without encoding, like out of range times.
+			return
+		}
+		ffjTestCheck{{.Name}}(t, out)
+	})
+}
+
+// Test{{.Name}}RoundTrip checks the json of {{if .Random}}random values{{else}}the zero value{{end}} of {{.Name}}.
+func Test{{.Name}}RoundTrip(t *testing.T) {
+	{{if .Random}}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		rv, ok := quick.Value(reflect.TypeOf({{.Name}}{}), rnd)
+		if !ok {
+			t.Fatalf("testing/quick can't generate values of {{.Name}}")
+		}
+		// Values are made canonical by encoding/json first, which for
+		// example drops empty slices with omitempty.
+		b, err := json.Marshal(ffjTestStd{{.Name}}(rv.Interface().({{.Name}})))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var std ffjTestStd{{.Name}}
+		err = json.Unmarshal(b, &std)
+		if err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+		v := {{.Name}}(std)
+
+		out, err := v.MarshalJSON()
+		if err != nil {
+			t.Fatalf("encoding %#v: %v", v, err)
+		}
+		var w {{.Name}}
+		err = w.UnmarshalJSON(out)
+		if err != nil {
+			t.Fatalf("decoding %s: %v", out, err)
+		}
+		if !reflect.DeepEqual(v, w) {
+			t.Fatalf("round trip through %s changed the value\nfrom: %#v\nto:   %#v", out, v, w)
+		}
+		ffjTestCheck{{.Name}}(t, out)
+	}
+	{{else}}
+	out, err := new({{.Name}}).MarshalJSON()
+	if err != nil {
+		t.Skipf("the zero value doesn't encode: %v", err)
+	}
+	ffjTestCheck{{.Name}}(t, out)
+	{{end}}
+}
+{{end}}
+`
+
+// testStruct is a struct getting tests in the file written by -gen-tests.
+type testStruct struct {
+	Name string
+	// Std is set when encoding/json decodes the json of the struct like
+	// the generated decoder does.
+	Std bool
+	// Random is set when testing/quick can generate values of the
+	// struct, and so they are used instead of the zero value.
+	Random bool
+}
+
+// testsPath returns the path of the test file of outputPath.
+func testsPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, ".go") + "_test.go"
+}
+
+// renderTests returns the test file with fuzz targets and round-trip
+// tests of the structs with Options.GenTests, or nil if there are none.
+func renderTests(ic *Inception) ([]byte, error) {
+	data := struct {
+		*Inception
+		Structs []testStruct
+		Std     bool
+		Random  bool
+	}{Inception: ic}
+	for _, si := range ic.objs {
+		if !si.Options.GenTests || !ic.wantMarshal(si) || !ic.wantUnmarshal(si) {
+			continue
+		}
+		ts := testStruct{Name: si.TypeName, Std: stdCompatible(si)}
+		ts.Random = ts.Std && quickable(ic, si.Typ, map[reflect.Type]bool{})
+		data.Std = data.Std || ts.Std
+		data.Random = data.Random || ts.Random
+		data.Structs = append(data.Structs, ts)
+	}
+	if len(data.Structs) == 0 {
+		return nil, nil
+	}
+
+	t := template.Must(template.New("ffjson_test.go").Parse(testsTemplate))
+	buf := new(bytes.Buffer)
+	err := t.Execute(buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// stdCompatible returns whether encoding/json decodes the json of si to
+// the same value, which isn't the case with ffjson tags on its fields or
+// directives changing the shape of its json.
+func stdCompatible(si *StructInfo) bool {
+	o := si.Options
+	if o.Tuple || o.Refs || o.KeepOrder || o.EnvelopeKey != "" || o.Wrap != "" {
+		return false
+	}
+	return !hasFFJSONTags(si.Typ)
+}
+
+func hasFFJSONTags(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Tag.Get("ffjson") != "" {
+			return true
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && hasFFJSONTags(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// quickable returns whether testing/quick can generate values of typ
+// whose json round-trips: numbers, strings and booleans, and pointers,
+// slices, arrays, maps and structs of them with exported fields only.
+// Types of other packages with json methods are left out, as their
+// values may not be valid, and so are structs of the package whose json
+// encoding/json can't decode, and recursive types.
+func quickable(ic *Inception, typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	if !typeInInception(ic, typ, 0) && (typ.Implements(marshalerType) || reflect.PtrTo(typ).Implements(marshalerType) ||
+		typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType)) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.String:
+		return !isJSONNumber(typ)
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return quickable(ic, typ.Elem(), seen)
+	case reflect.Map:
+		return typ.Key().Kind() == reflect.String && quickable(ic, typ.Elem(), seen)
+	case reflect.Struct:
+		for _, si := range ic.objs {
+			if si.Typ == typ && !stdCompatible(si) {
+				return false
+			}
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" || !quickable(ic, f.Type, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldGroup is a nested object holding the fields tagged with the same
+// ffjson:"group=..." path, and the groups nested in it.
+type fieldGroup struct {
+	// JsonName is escaped and quoted, like StructField.JsonName.
+	JsonName string
+	// Ident names the decoder constants and function of the group.
+	Ident   string
+	Members []*groupMember
+}
+
+// groupMember is either a field or a nested group.
+type groupMember struct {
+	Field *StructField
+	Group *fieldGroup
+}
+
+// groupFields arranges fields into the members of the outer object.
+// Each group is placed where its first field is, and keeps the order of
+// its fields.
+func groupFields(fields []*StructField) []*groupMember {
+	root := &fieldGroup{}
+	groups := make(map[string]*fieldGroup)
+
+	for _, f := range fields {
+		parent := root
+		if f.Group != "" {
+			path := ""
+			for _, name := range strings.Split(f.Group, ".") {
+				path += "." + name
+				g, ok := groups[path]
+				if !ok {
+					g = &fieldGroup{
+						JsonName: jsonKey(name),
+						Ident:    groupIdent(root, parent),
+					}
+					groups[path] = g
+					parent.Members = append(parent.Members, &groupMember{Group: g})
+				}
+				parent = g
+			}
+		}
+		parent.Members = append(parent.Members, &groupMember{Field: f})
+	}
+	return root.Members
+}
+
+// groupIdent returns the Ident of the next group nested in parent:
+// group0, group1, ... in the outer object, group0_0, ... in group0.
+func groupIdent(root, parent *fieldGroup) string {
+	n := 0
+	for _, m := range parent.Members {
+		if m.Group != nil {
+			n++
+		}
+	}
+	if parent == root {
+		return "group" + strconv.Itoa(n)
+	}
+	return parent.Ident + "_" + strconv.Itoa(n)
+}
+
+func hasGroups(si *StructInfo) bool {
+	for _, f := range si.Fields {
+		if f.Group != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareGroups checks that the groups of si don't collide with other
+// members of the objects they are written into.
+func prepareGroups(ic *Inception, si *StructInfo) error {
+	switch {
+	case !hasGroups(si):
+		return nil
+	case si.Options.Refs:
+		return fmt.Errorf("%s: groups can't be combined with refs", si.Name)
+	case si.Options.Form:
+		return fmt.Errorf("%s: groups can't be combined with -form", si.Name)
+	case si.Options.Accessors:
+		return fmt.Errorf("%s: groups can't be combined with -accessors", si.Name)
+	case si.Options.ResetFields && ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: groups can't be combined with resetfields", si.Name)
+	}
+	return checkGroupMembers(si, "", groupFields(si.Fields))
+}
+
+func checkGroupMembers(si *StructInfo, path string, members []*groupMember) error {
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		name := ""
+		if m.Group != nil {
+			name = m.Group.JsonName
+		} else {
+			name = m.Field.JsonName
+		}
+		if seen[name] {
+			return fmt.Errorf("%s: the json name %s is used more than once in the object%s", si.Name, name, path)
+		}
+		seen[name] = true
+
+		if m.Group != nil {
+			var n string
+			err := json.Unmarshal([]byte(name), &n)
+			if err != nil {
+				return fmt.Errorf("%s: %v", si.Name, err)
+			}
+			err = checkGroupMembers(si, path+"."+n, m.Group.Members)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// memberConditional returns whether the last member may not be written.
+func memberConditional(members []*groupMember) bool {
+	if len(members) > 0 {
+		m := members[len(members)-1]
+		return m.Field != nil && (m.Field.OmitEmpty || m.Field.OmitZero || m.Field.TriState || len(m.Field.Embedded) > 0)
+	}
+	return false
+}
+
+// getGroupObject writes the members of an object, with the nested
+// objects of its groups. Groups are always written, and empty ones are
+// written as {}.
+func getGroupObject(ic *Inception, members []*groupMember, prefix string) string {
+	conditionalWrites := memberConditional(members)
+	out := ""
+
+	ic.q.Write("{")
+
+	// The extra space is inserted here.
+	// If nothing is written to the field this will be deleted
+	// instead of the last comma.
+	if conditionalWrites || len(members) == 0 {
+		ic.q.Write(" ")
+	}
+
+	for _, m := range members {
+		if m.Group != nil {
+			ic.q.Write(m.Group.JsonName + ":")
+			out += getGroupObject(ic, m.Group.Members, prefix)
+			ic.q.Write(",")
+		} else {
+			out += getField(ic, m.Field, prefix)
+		}
+	}
+
+	// Handling the last comma is tricky.
+	// If the last field has omitempty, conditionalWrites is set.
+	// If something has been written, we delete the last comma,
+	// by backing up the buffer, otherwise it will delete a space.
+	if conditionalWrites {
+		out += ic.q.Flush()
+		out += `buf.Rewind(1)` + "\n"
+	} else {
+		ic.q.DeleteLast()
+	}
+
+	ic.q.Write("}")
+	return out
+}
+
+// groupDecodeFields returns the fields the decoder of an object matches:
+// its fields, and a field for each of its groups, decoded by the
+// function of the group.
+func groupDecodeFields(si *StructInfo, members []*groupMember) []*StructField {
+	fields := make([]*StructField, 0, len(members))
+	for _, m := range members {
+		if m.Field != nil {
+			fields = append(fields, m.Field)
+			continue
+		}
+		var name string
+		json.Unmarshal([]byte(m.Group.JsonName), &name)
+		fields = append(fields, &StructField{
+			Name:         m.Group.Ident,
+			JsonName:     m.Group.JsonName,
+			KeyName:      m.Group.JsonName,
+			FoldFuncName: foldFunc([]byte(name)),
+			Typ:          si.Typ,
+			GroupFunc:    groupFunc(m.Group),
+		})
+	}
+	return fields
+}
+
+func groupFunc(g *fieldGroup) string {
+	return "unmarshalJSONFFLexerGroup" + strings.TrimPrefix(g.Ident, "group")
+}
+
+// createGroupUnmarshal generates the functions decoding the nested
+// objects of the groups in members, setting the fields of the struct.
+func createGroupUnmarshal(ic *Inception, si *StructInfo, members []*groupMember) error {
+	for _, m := range members {
+		if m.Group == nil {
+			continue
+		}
+		gsi := *si
+		gsi.Name = si.Name + m.Group.Ident
+		gsi.Fields = groupDecodeFields(si, m.Group.Members)
+		gsi.Options.EnvelopeKey = ""
+
+		if si.Options.NormalizeKeys {
+			err := normalizeKeys(&gsi)
+			if err != nil {
+				return err
+			}
+		}
+
+		out := tplStr(decodeTpl["header"], header{
+			IC: ic,
+			SI: &gsi,
+		})
+		out += tplStr(decodeTpl["ujFunc"], ujFunc{
+			SI:          &gsi,
+			IC:          ic,
+			ValidValues: validValues,
+			LexerFunc:   groupFunc(m.Group),
+			Group:       true,
+		})
+		ic.OutputFuncs = append(ic.OutputFuncs, out)
+
+		err := createGroupUnmarshal(ic, si, m.Group.Members)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
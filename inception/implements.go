@@ -0,0 +1,98 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// getterField returns the name of the field a method of an interface
+// gets: Name for Name() or GetName(), or "" if m isn't a getter.
+func getterField(m reflect.Method) string {
+	if m.PkgPath != "" || m.Type.NumIn() != 0 || m.Type.NumOut() != 1 {
+		return ""
+	}
+	name := m.Name
+	if rest := strings.TrimPrefix(name, "Get"); rest != name {
+		r, _ := utf8.DecodeRuneInString(rest)
+		if unicode.IsUpper(r) {
+			name = rest
+		}
+	}
+	return name
+}
+
+// prepareImplements checks that each getter of the interface of si is
+// covered by a field of the same type, written to json.
+func prepareImplements(ic *Inception, si *StructInfo) error {
+	iface := si.Interface
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("%s: %s is not an interface", si.Name, si.Options.Implements)
+	}
+	if !reflect.PtrTo(si.Typ).Implements(iface) {
+		return fmt.Errorf("%s: *%s doesn't implement %s", si.Name, si.Name, si.Options.Implements)
+	}
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		m := iface.Method(i)
+		name := getterField(m)
+		if name == "" {
+			continue
+		}
+		field := getterStructField(si, name)
+		if field == nil {
+			return fmt.Errorf("%s: the getter %s.%s has no field %s or json member %q", si.Name, si.Options.Implements, m.Name, name, name)
+		}
+		sf, _ := si.Typ.FieldByName(field.Name)
+		if sf.Type != m.Type.Out(0) {
+			return fmt.Errorf("%s.%s: the type %v doesn't match the getter %s.%s, returning %v", si.Name, field.Name, sf.Type, si.Options.Implements, m.Name, m.Type.Out(0))
+		}
+	}
+	return nil
+}
+
+// getterStructField returns the field written to json covering the
+// getter of name: the field with that name or else, as a getter can't
+// have the name of a field, the field with that json name, ignoring case.
+func getterStructField(si *StructInfo, name string) *StructField {
+	for _, f := range si.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	for _, f := range si.Fields {
+		var jsonName string
+		err := json.Unmarshal([]byte(f.JsonName), &jsonName)
+		if err == nil && strings.EqualFold(jsonName, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// createImplementsCheck asserts that the struct implements its interface
+// when compiling.
+func createImplementsCheck(ic *Inception, si *StructInfo) {
+	out := "var _ " + si.Options.Implements + " = (*" + si.Name + ")(nil)" + "\n"
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
@@ -23,8 +23,10 @@ import (
 	"github.com/maxproc/ffjson/shared"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 )
 
 type Inception struct {
@@ -36,10 +38,31 @@ type Inception struct {
 	OutputImports map[string]bool
 	OutputFuncs   []string
 	q             ConditionalWrite
-	ResetFields   bool
+	// BuildConstraint holds the build constraint lines of the input file.
+	BuildConstraint string
+	// SourceHash is the hash of the inputs of the generator, written in
+	// the header so unchanged files aren't generated again.
+	SourceHash string
+	// fallbacks lists the types of the current struct handled using
+	// reflection.
+	fallbacks []reflect.Type
+	// nanNull is set when the current struct writes NaN and infinite
+	// floats as null.
+	nanNull bool
+	// useNumber is set when the current struct decodes numbers in
+	// interface{} values as json.Number.
+	useNumber bool
+	// sortMaps is set while writing map fields with sorted keys.
+	sortMaps bool
+	// redacting is set while writing marshalJSONBufRedacted, so nested
+	// structs with redaction are written with theirs.
+	redacting bool
+	// codecs are the functions of -codecs encoding and decoding types,
+	// by package path and type name.
+	codecs map[string]shared.Codec
 }
 
-func NewInception(inputPath string, packageName string, outputPath string, resetFields bool) *Inception {
+func NewInception(inputPath string, packageName string, outputPath string) *Inception {
 	return &Inception{
 		objs:          make([]*StructInfo, 0),
 		InputPath:     inputPath,
@@ -47,10 +70,15 @@ func NewInception(inputPath string, packageName string, outputPath string, reset
 		PackageName:   packageName,
 		OutputFuncs:   make([]string, 0),
 		OutputImports: make(map[string]bool),
-		ResetFields:   resetFields,
 	}
 }
 
+// fallback records that typ is encoded or decoded by encoding/json or
+// reflect, instead of generated code.
+func (i *Inception) fallback(typ reflect.Type) {
+	i.fallbacks = append(i.fallbacks, typ)
+}
+
 func (i *Inception) AddMany(objs []shared.InceptionType) {
 	for _, obj := range objs {
 		i.Add(obj)
@@ -62,8 +90,20 @@ func (i *Inception) Add(obj shared.InceptionType) {
 	i.PackagePath = i.objs[0].Typ.PkgPath()
 }
 
+// AddNamed adds obj as the type name of the package pkgPath. The type of
+// obj doesn't have to be named, so types known only from the source, as
+// with the static mode of the generator, can be described with
+// reflect.StructOf.
+func (i *Inception) AddNamed(name string, pkgPath string, obj shared.InceptionType) {
+	si := NewStructInfo(obj)
+	si.Name = name
+	si.TypeName = name
+	i.objs = append(i.objs, si)
+	i.PackagePath = pkgPath
+}
+
 func (i *Inception) wantUnmarshal(si *StructInfo) bool {
-	if si.Options.SkipDecoder {
+	if si.Options.SkipDecoder || si.Options.SkipJSON {
 		return false
 	}
 	typ := si.Typ
@@ -77,7 +117,7 @@ func (i *Inception) wantUnmarshal(si *StructInfo) bool {
 }
 
 func (i *Inception) wantMarshal(si *StructInfo) bool {
-	if si.Options.SkipEncoder {
+	if si.Options.SkipEncoder || si.Options.SkipJSON {
 		return false
 	}
 	typ := si.Typ
@@ -103,11 +143,158 @@ func (i *Inception) generateCode() error {
 	sorted.Sort()
 
 	for _, si := range sorted {
+		for _, f := range si.Fields {
+			if f.TagError != nil {
+				return fmt.Errorf("%s.%s: %v", si.Name, f.Name, f.TagError)
+			}
+		}
+		err := checkMapKeys(i, si)
+		if err != nil {
+			return err
+		}
+		i.fallbacks = i.fallbacks[:0]
+		i.nanNull = si.Options.NaNNull
+		i.useNumber = si.Options.UseNumber
+		i.sortMaps = si.Options.SortedMaps
+		i.codecs, err = shared.ParseCodecs(si.Options.Codecs)
+		if err != nil {
+			return err
+		}
+
+		err = prepareGroups(i, si)
+		if err != nil {
+			return err
+		}
+
+		if si.Options.Refs {
+			err := prepareRefs(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = prepareSplitTimes(i, si)
+		if err != nil {
+			return err
+		}
+
+		err = prepareRawHash(i, si)
+		if err != nil {
+			return err
+		}
+
+		if si.Options.Wrap != "" {
+			err := prepareWrap(i, si)
+			if err != nil {
+				return err
+			}
+			createWrapHelpers(i, si)
+		}
+
+		if si.Options.Instantiate != "" {
+			err := prepareInstantiation(i, si)
+			if err != nil {
+				return err
+			}
+			createInstantiation(i, si)
+		}
+
+		if si.Interface != nil {
+			err := prepareImplements(i, si)
+			if err != nil {
+				return err
+			}
+			createImplementsCheck(i, si)
+		}
+
+		if si.Options.UnwrapType {
+			err := prepareUnwrapType(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		if si.Options.EncodeStats {
+			err := prepareEncodeStats(i, si)
+			if err != nil {
+				return err
+			}
+			createEncodeStats(i, si)
+		}
+
+		if si.Options.KeepOrder {
+			err := prepareKeyOrder(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		if si.Options.Tuple {
+			err := prepareTuple(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
 		if i.wantMarshal(si) {
 			err := CreateMarshalJSON(i, si)
 			if err != nil {
 				return err
 			}
+
+			if si.Options.Canonical {
+				err = CreateMarshalJSONCanonical(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = CreateMarshalJSONRedacted(i, si)
+			if err != nil {
+				return err
+			}
+
+			if si.Options.Patch {
+				err = CreateJSONPatch(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.SSE {
+				err = CreateMarshalSSE(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.JSONRPC {
+				err = CreateMarshalJSONRPC(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.Negotiate != "" {
+				err = CreateMarshalNegotiated(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.Stream {
+				err = CreateEncodeJSON(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.MarshalTo {
+				err = CreateMarshalTo(i, si)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		if i.wantUnmarshal(si) {
@@ -115,12 +302,79 @@ func (i *Inception) generateCode() error {
 			if err != nil {
 				return err
 			}
+
+			if si.Options.Form {
+				err = CreateUnmarshalForm(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.RootDispatch {
+				err = CreateUnmarshalRoot(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.Extractors {
+				err = CreateExtractors(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.ArrayPooled {
+				err = CreateDecodeArrayPooled(i, si)
+				if err != nil {
+					return err
+				}
+			}
+
+			if si.Options.Stream {
+				err = CreateDecodeJSON(i, si)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if si.Options.Msgpack || si.Options.CBOR {
+			err := CreateBinary(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		if si.Options.Accessors {
+			err := CreateAccessors(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		if si.Options.Schema {
+			err := CreateJSONSchema(i, si)
+			if err != nil {
+				return err
+			}
+		}
+
+		if si.Options.Target == "tinygo" {
+			err := checkTinyGo(i, si)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
 func (i *Inception) handleError(err error) {
+	handleError(err)
+}
+
+func handleError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %s:\n\n", err)
 	os.Exit(1)
 }
@@ -131,30 +385,91 @@ func (i *Inception) Execute() {
 		return
 	}
 
-	err := i.generateCode()
+	err := i.Generate()
 	if err != nil {
 		i.handleError(err)
 		return
 	}
+}
+
+// ResultPrefix starts the lines ExecuteAll writes the result of each
+// Inception on.
+const ResultPrefix = "ffjson-result: "
+
+// ExecuteAll generates the code of each of is, the input files of an
+// inception program built once for all of them. A file failing doesn't
+// stop the others: the error of each, or an empty string, is written
+// quoted to stdout after ResultPrefix, in order.
+func ExecuteAll(is []*Inception) {
+	if len(os.Args) != 1 {
+		handleError(errors.New(fmt.Sprintf("Internal ffjson error: inception executable takes no args: %v", os.Args)))
+		return
+	}
+
+	for _, i := range is {
+		msg := ""
+		if err := i.Generate(); err != nil {
+			msg = err.Error()
+		}
+		fmt.Println(ResultPrefix + strconv.Quote(msg))
+	}
+}
+
+// Generate writes the code of the added types to the output path.
+func (i *Inception) Generate() error {
+	err := i.generateCode()
+	if err != nil {
+		return err
+	}
+
+	i.BuildConstraint, err = buildConstraint(i.InputPath)
+	if err != nil {
+		return err
+	}
 
 	data, err := RenderTemplate(i)
 	if err != nil {
-		i.handleError(err)
-		return
+		return err
 	}
 
 	stat, err := os.Stat(i.InputPath)
+	if err != nil {
+		return err
+	}
 
+	err = writeFileAtomic(i.OutputPath, data, stat.Mode())
 	if err != nil {
-		i.handleError(err)
-		return
+		return err
 	}
 
-	err = ioutil.WriteFile(i.OutputPath, data, stat.Mode())
+	tests, err := renderTests(i)
+	if err != nil || tests == nil {
+		return err
+	}
+	return writeFileAtomic(testsPath(i.OutputPath), tests, stat.Mode())
+}
 
+// writeFileAtomic writes data to a temporary file next to path and
+// renames it into place, so the go commands of other files of the
+// package never see half of the file.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	// The go command ignores files starting with a dot.
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		i.handleError(err)
-		return
+		return err
 	}
-
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
 }
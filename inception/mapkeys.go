@@ -0,0 +1,126 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isIntegerKind returns whether k is a signed or unsigned integer kind.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// checkMapKeys checks that the map keys in the fields of si can be
+// encoded and decoded like encoding/json does: strings, integers, and
+// types implementing encoding.TextMarshaler and TextUnmarshaler.
+func checkMapKeys(ic *Inception, si *StructInfo) error {
+	encode := ic.wantMarshal(si)
+	decode := ic.wantUnmarshal(si)
+	for _, f := range si.Fields {
+		err := mapKeyError(f.Typ, encode, decode)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func mapKeyError(typ reflect.Type, encode bool, decode bool) error {
+	// Types with their own methods encode the maps in them.
+	if typ.Implements(marshalerType) || reflect.PtrTo(typ).Implements(marshalerType) {
+		encode = false
+	}
+	if reflect.PtrTo(typ).Implements(unmarshalerType) {
+		decode = false
+	}
+	if !encode && !decode {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return mapKeyError(typ.Elem(), encode, decode)
+	case reflect.Map:
+		key := typ.Key()
+		simple := key.Kind() == reflect.String || isIntegerKind(key.Kind())
+		if encode && !simple && !key.Implements(textMarshalerType) {
+			return fmt.Errorf("map key type %v must be a string or an integer, or implement encoding.TextMarshaler", key)
+		}
+		if decode && !simple && !reflect.PtrTo(key).Implements(textUnmarshalerType) {
+			return fmt.Errorf("map key type %v must be a string or an integer, or implement encoding.TextUnmarshaler with a pointer receiver", key)
+		}
+		return mapKeyError(typ.Elem(), encode, decode)
+	}
+	return nil
+}
+
+// getMapKeyText returns the code appending the json text of the map key
+// name of type typ to ffjText. Keys of string kind are written directly
+// instead, before checking for TextMarshaler, as encoding/json does.
+func getMapKeyText(ic *Inception, name string, typ reflect.Type) string {
+	out := ""
+	switch {
+	case typ.Implements(textMarshalerType):
+		if typ.Kind() == reflect.Ptr {
+			out += "if " + name + " == nil {" + "\n"
+			out += "  ffjText = append(ffjText, \"\")" + "\n"
+			out += "} else {" + "\n"
+		}
+		out += "tb, err := " + name + ".MarshalText()" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		out += "ffjText = append(ffjText, string(tb))" + "\n"
+		if typ.Kind() == reflect.Ptr {
+			out += "}" + "\n"
+		}
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		ic.OutputImports[`"strconv"`] = true
+		out += "ffjText = append(ffjText, strconv.FormatInt(int64(" + name + "), 10))" + "\n"
+	default:
+		ic.OutputImports[`"strconv"`] = true
+		out += "ffjText = append(ffjText, strconv.FormatUint(uint64(" + name + "), 10))" + "\n"
+	}
+	return out
+}
+
+// handleMapKey returns the code decoding the key of a map of type typ,
+// the current string token, into name.
+func handleMapKey(ic *Inception, name string, typ reflect.Type, ptr bool) string {
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return tplStr(decodeTpl["handleTextKey"], handleTextKey{
+			Name: name,
+			Typ:  typ,
+		})
+	}
+	// Integer keys are parsed from the text of the string.
+	switch {
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		return getAllowTokens(typ.Name(), "FFTok_string") + getNumberHandler(ic, name, ptr, typ, "ParseInt")
+	case isIntegerKind(typ.Kind()):
+		return getAllowTokens(typ.Name(), "FFTok_string") + getNumberHandler(ic, name, ptr, typ, "ParseUint")
+	}
+	return handleField(ic, name, typ, ptr, false)
+}
@@ -0,0 +1,94 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"reflect"
+)
+
+// isZeroer is implemented by types deciding themselves whether they are
+// zero for omitzero, like time.Time.
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+// getOmitZero returns the condition of the field f, named name, not
+// being zero, as encoding/json checks it for omitzero: with the IsZero
+// method of its type if it has one, as the zero value of its type
+// otherwise.
+func getOmitZero(ic *Inception, f *StructField, name string) string {
+	typ := f.Typ
+	if f.Pointer && typ.Kind() != reflect.Ptr {
+		typ = reflect.PtrTo(typ)
+	}
+
+	switch {
+	case typ.Implements(isZeroerType):
+		if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Interface {
+			return name + " != nil && !" + name + ".IsZero()"
+		}
+		return "!" + name + ".IsZero()"
+	case reflect.PtrTo(typ).Implements(isZeroerType):
+		// Fields are addressable, so methods on the pointer are called.
+		return "!" + name + ".IsZero()"
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		// Empty maps and slices aren't zero.
+		return name + " != nil"
+	case reflect.Bool:
+		return name
+	case reflect.String:
+		return name + ` != ""`
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return name + " != 0"
+	}
+
+	if typ.Name() != "" && comparableZero(typ) {
+		return name + " != (" + getType(ic, name, typ) + "{})"
+	}
+	// Unnamed structs and arrays, and values == can't compare to the zero
+	// value, like structs with slices, are checked by reflection.
+	ic.OutputImports[`"reflect"`] = true
+	ic.fallback(typ)
+	return "!reflect.ValueOf(" + name + ").IsZero()"
+}
+
+// comparableZero returns whether values of typ can be compared to its
+// zero value with ==, which would panic for interfaces holding values of
+// types that can't be compared.
+func comparableZero(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Interface, reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	case reflect.Array:
+		return comparableZero(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !comparableZero(typ.Field(i).Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
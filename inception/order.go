@@ -0,0 +1,101 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// keyOrderField is the field of structs with keeporder recording the
+// decoded keys.
+const keyOrderField = "keyOrder"
+
+// prepareKeyOrder checks that a struct with keeporder has a keyOrder
+// field, and can be written in any order.
+func prepareKeyOrder(ic *Inception, si *StructInfo) error {
+	switch {
+	case !ic.wantMarshal(si) || !ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: keeporder requires both an encoder and a decoder", si.Name)
+	case si.Options.Refs:
+		return fmt.Errorf("%s: keeporder can't be combined with refs", si.Name)
+	case si.Options.Verbose:
+		return fmt.Errorf("%s: keeporder can't be combined with -verbose", si.Name)
+	case hasGroups(si):
+		return fmt.Errorf("%s: keeporder can't be combined with groups", si.Name)
+	}
+
+	sf, ok := si.Typ.FieldByName(keyOrderField)
+	if !ok || len(sf.Index) != 1 || sf.Type != reflect.TypeOf([]string(nil)) {
+		return fmt.Errorf("%s: keeporder requires a field %s []string", si.Name, keyOrderField)
+	}
+	return nil
+}
+
+// createOrderedMarshal generates the function funcName, writing the
+// fields of si in the order of keyOrder using the function fieldsFunc
+// when nothing was recorded.
+func createOrderedMarshal(ic *Inception, si *StructInfo, funcName string, fieldsFunc string) (string, error) {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ""
+
+	names := ""
+	for i, f := range si.Fields {
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		if i != 0 {
+			names += ", "
+		}
+		names += strconv.Quote(name)
+	}
+	out += "var ffjOrder" + si.Name + " = []string{" + names + "}" + "\n\n"
+
+	out += "// " + funcName + " marshal buff to json, in the order of the decoded keys - template\n"
+	out += `func (j *` + si.Name + `) ` + funcName + `(buf fflib.EncodingBuffer) (error) {` + "\n"
+	out += `if j == nil || len(j.` + keyOrderField + `) == 0 {` + "\n"
+	out += "  return j." + fieldsFunc + "(buf)" + "\n"
+	out += `}` + "\n"
+	out += getEncodeStats(ic, si)
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+
+	// As for a conditional last field, the space is deleted instead of
+	// the last comma if no field is written.
+	out += "buf.WriteString(`{ `)" + "\n"
+	out += "for _, i := range fflib.KeyOrder(j." + keyOrderField + ", ffjOrder" + si.Name + ") {" + "\n"
+	out += "switch i {" + "\n"
+	for i, f := range si.Fields {
+		out += "case " + strconv.Itoa(i) + ":" + "\n"
+		out += getField(ic, f, "j.")
+		out += ic.q.Flush()
+	}
+	out += "}" + "\n"
+	out += "}" + "\n"
+	out += `buf.Rewind(1)` + "\n"
+	out += "buf.WriteByte('}')" + "\n"
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	return out, nil
+}
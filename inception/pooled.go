@@ -0,0 +1,92 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+)
+
+// CreateDecodeArrayPooled generates the Reset method and the
+// DecodeFooArrayPooled function decoding each element of an array into
+// the same pooled struct.
+func CreateDecodeArrayPooled(ic *Inception, si *StructInfo) error {
+	switch {
+	case si.Options.Refs:
+		return fmt.Errorf("%s: -array-pooled can't be combined with refs", si.Name)
+	case si.Options.Tuple:
+		return fmt.Errorf("%s: -array-pooled can't be combined with tuple", si.Name)
+	case si.RawHash != nil:
+		return fmt.Errorf("%s: -array-pooled can't be combined with rawhash, as the hash covers the whole input", si.Name)
+	}
+	if _, ok := si.Typ.FieldByName("Reset"); ok {
+		return fmt.Errorf("%s: -array-pooled can't be used on structs with a Reset field", si.Name)
+	}
+
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"fmt"`] = true
+	ic.OutputImports[`"sync"`] = true
+	out := ""
+
+	name := si.Name
+	pool := "ffjPool" + name
+	out += "// Reset sets j to the zero value - template\n"
+	out += `func (j *` + name + `) Reset() {` + "\n"
+	out += "*j = " + name + "{}" + "\n"
+	out += `}` + "\n\n"
+
+	out += `var ` + pool + ` = sync.Pool{New: func() interface{} { return new(` + name + `) }}` + "\n\n"
+
+	out += "// Decode" + name + "ArrayPooled decodes data, an array of objects, calling fn with each element.\n"
+	out += "// All elements are decoded into the same pooled " + name + ", reset before each of them:\n"
+	out += "// fn must not retain the pointer after returning - template\n"
+	out += `func Decode` + name + `ArrayPooled(data []byte, fn func(*` + name + `)) error {` + "\n"
+	out += `tok, err := fflib.PeekRoot(data)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `if tok != fflib.FFTok_left_brace {` + "\n"
+	out += `return fmt.Errorf("ffjson: wanted an array at the root of ` + name + `, but got token: %v", tok)` + "\n"
+	out += `}` + "\n"
+	out += `fs := fflib.NewFFLexer(data)` + "\n"
+	out += `fs.Scan()` + "\n"
+	out += `v := ` + pool + `.Get().(*` + name + `)` + "\n"
+	out += `defer func() {` + "\n"
+	out += `v.Reset()` + "\n"
+	out += pool + `.Put(v)` + "\n"
+	out += `}()` + "\n"
+	out += `err = fflib.ScanArray(fs, func(tok fflib.FFTok) error {` + "\n"
+	out += `if tok != fflib.FFTok_left_bracket {` + "\n"
+	out += `return fs.WrapErr(fmt.Errorf("ffjson: wanted an object in the array, but got token: %v", tok))` + "\n"
+	out += `}` + "\n"
+	out += `v.Reset()` + "\n"
+	out += `err := v.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `fn(v)` + "\n"
+	out += "return nil" + "\n"
+	out += `})` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `return fflib.ScanEnd(fs)` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
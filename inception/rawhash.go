@@ -0,0 +1,86 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// rawHash is the field of a struct set to the hash of the input of
+// UnmarshalJSON.
+type rawHash struct {
+	Field string
+	// Import is the package of the hash, and Sum its function returning
+	// an array.
+	Import string
+	Sum    string
+	// Hex is set for string fields, holding the hash in hexadecimal.
+	Hex bool
+}
+
+var rawHashes = map[string]rawHash{
+	"md5":    {Import: "crypto/md5", Sum: "md5.Sum"},
+	"sha1":   {Import: "crypto/sha1", Sum: "sha1.Sum"},
+	"sha256": {Import: "crypto/sha256", Sum: "sha256.Sum256"},
+	"sha512": {Import: "crypto/sha512", Sum: "sha512.Sum512"},
+}
+
+// prepareRawHash finds the field tagged with ffjson:"rawhash" in si.
+// It must be excluded from json, so isn't in si.Fields.
+func prepareRawHash(ic *Inception, si *StructInfo) error {
+	for i := 0; i < si.Typ.NumField(); i++ {
+		sf := si.Typ.Field(i)
+		opts := tagOptions(sf.Tag.Get("ffjson"))
+		alg, ok := opts.Value("rawhash")
+		if !ok && opts.Contains("rawhash") {
+			alg, ok = "sha256", true
+		}
+		if !ok {
+			continue
+		}
+
+		switch {
+		case si.RawHash != nil:
+			return fmt.Errorf("%s: only one field can have rawhash", si.Name)
+		case !ic.wantUnmarshal(si):
+			return fmt.Errorf("%s.%s: rawhash requires a decoder", si.Name, sf.Name)
+		case sf.Tag.Get("json") != "-":
+			return fmt.Errorf("%s.%s: rawhash requires the field to be tagged with json:\"-\"", si.Name, sf.Name)
+		}
+		h, ok := rawHashes[alg]
+		if !ok {
+			return fmt.Errorf("%s.%s: invalid rawhash algorithm %q, must be md5, sha1, sha256 or sha512", si.Name, sf.Name, alg)
+		}
+		switch sf.Type {
+		case reflect.TypeOf(""):
+			h.Hex = true
+		case reflect.TypeOf([]byte(nil)):
+		default:
+			return fmt.Errorf("%s.%s: rawhash is only supported on string and []byte fields, not %v", si.Name, sf.Name, sf.Type)
+		}
+		h.Field = sf.Name
+		si.RawHash = &h
+
+		ic.OutputImports[`"`+h.Import+`"`] = true
+		if h.Hex {
+			ic.OutputImports[`"encoding/hex"`] = true
+		}
+	}
+	return nil
+}
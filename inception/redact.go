@@ -0,0 +1,198 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// CreateMarshalJSONRedacted generates a MarshalJSONRedacted function for
+// structs with redacted fields, or for all structs if a redact pattern
+// is set. Redacted fields are written as "[REDACTED]".
+func CreateMarshalJSONRedacted(ic *Inception, si *StructInfo) error {
+	var pattern *regexp.Regexp
+	if si.Options.RedactPattern != "" {
+		var err error
+		pattern, err = regexp.Compile(si.Options.RedactPattern)
+		if err != nil {
+			return fmt.Errorf("invalid redact pattern: %v", err)
+		}
+	}
+
+	redacting := hasRedaction(si)
+	fields := make([]*StructField, 0, len(si.Fields))
+	for _, f := range si.Fields {
+		rf := *f
+		switch f.Redact {
+		case "always":
+			rf.Redacted = true
+		case "":
+			if pattern != nil {
+				var name string
+				err := json.Unmarshal([]byte(f.JsonName), &name)
+				if err != nil {
+					return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+				}
+				rf.Redacted = pattern.MatchString(name)
+			}
+		}
+		if !rf.Redacted && f.Typ.Kind() == reflect.Struct {
+			rf.RedactNested = redactsType(ic, f.Typ)
+		}
+		if pattern != nil && !rf.Redacted && f.Redact != "never" {
+			err := checkRedactReach(ic, pattern, f.Typ)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %v, tag the field with redact or noredact", si.Name, f.Name, err)
+			}
+		}
+		fields = append(fields, &rf)
+	}
+	if !redacting {
+		return nil
+	}
+
+	out := ""
+	out += "// MarshalJSONRedacted marshal bytes to json, redacting sensitive fields - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONRedacted() ([]byte, error) {` + "\n"
+	out += `var buf fflib.Buffer` + "\n"
+
+	out += `if j == nil {` + "\n"
+	out += `  buf.WriteString("null")` + "\n"
+	out += "  return buf.Bytes(), nil" + "\n"
+	out += `}` + "\n"
+
+	if si.Options.EnvelopeKey != "" {
+		prefix, err := getEnvelopePrefix(si)
+		if err != nil {
+			return err
+		}
+		out += "buf.WriteString(" + strconv.Quote(prefix) + ")\n"
+	}
+	out += `err := j.marshalJSONBufRedacted(&buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	if si.Options.EnvelopeKey != "" {
+		out += "buf.WriteByte('}')\n"
+	}
+	out += `return buf.Bytes(), nil` + "\n"
+	out += `}` + "\n"
+
+	ic.redacting = true
+	out += getMarshalJSONBuf(ic, si, "marshalJSONBufRedacted", fields, "")
+	ic.redacting = false
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// hasRedaction returns whether a MarshalJSONRedacted function is
+// generated for si.
+func hasRedaction(si *StructInfo) bool {
+	if si.Options.RedactPattern != "" {
+		return true
+	}
+	for _, f := range si.Fields {
+		if f.Redact == "always" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedMarshalerType is implemented by structs with a generated
+// MarshalJSONRedacted function.
+var redactedMarshalerType = reflect.TypeOf(new(interface {
+	MarshalJSONRedacted() ([]byte, error)
+})).Elem()
+
+// redactsType returns whether typ is a struct of this file with a
+// generated MarshalJSONRedacted function, or a struct of another file of
+// the package generated with one, so nested values of it are redacted
+// too with its marshalJSONBufRedacted function.
+func redactsType(ic *Inception, typ reflect.Type) bool {
+	for _, si := range ic.objs {
+		if si.Typ == typ {
+			return ic.wantMarshal(si) && hasRedaction(si)
+		}
+	}
+	ptr := reflect.PtrTo(typ)
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == ic.PackagePath &&
+		ptr.Implements(marshalerFasterType) && ptr.Implements(redactedMarshalerType)
+}
+
+// checkRedactReach returns an error if values of typ, a field which
+// isn't redacted itself, may hold fields that the redact pattern can't
+// reach: interfaces, structs with generated code but no redaction, and
+// structs encoded by reflection. Types with their own MarshalJSON or
+// MarshalText, like time.Time, are written as they are.
+func checkRedactReach(ic *Inception, pattern *regexp.Regexp, typ reflect.Type) error {
+	if _, ok := getCodec(ic, typ); ok {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return checkRedactReach(ic, pattern, typ.Elem())
+	case reflect.Interface:
+		return fmt.Errorf("redaction can't reach into the values of %v", typ)
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	if redactsType(ic, typ) {
+		return nil
+	}
+	if typ.Name() == "" {
+		for _, f := range extractFields(reflect.New(typ).Elem().Interface()) {
+			if f.Redact != "" {
+				continue
+			}
+			var name string
+			err := json.Unmarshal([]byte(f.JsonName), &name)
+			if err != nil {
+				return err
+			}
+			if pattern.MatchString(name) {
+				return fmt.Errorf("the field %s of the inline struct matches the redact pattern", f.Name)
+			}
+			err = checkRedactReach(ic, pattern, f.Typ)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ptr := reflect.PtrTo(typ)
+	inFile := false
+	for _, si := range ic.objs {
+		inFile = inFile || si.Typ == typ
+	}
+	if !inFile && ptr.Implements(marshalerFasterType) {
+		return fmt.Errorf("redaction can't reach into %v, whose generated code has no MarshalJSONRedacted", typ)
+	}
+	if ptr.Implements(marshalerType) || ptr.Implements(textMarshalerType) {
+		return nil
+	}
+	return fmt.Errorf("redaction can't reach into %v, which is encoded by reflection", typ)
+}
@@ -23,21 +23,84 @@ import (
 
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"go/token"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 type StructField struct {
 	Name             string
 	JsonName         string
+	KeyName          string
 	FoldFuncName     string
 	Typ              reflect.Type
 	OmitEmpty        bool
+	OmitZero         bool
 	ForceString      bool
 	HasMarshalJSON   bool
 	HasUnmarshalJSON bool
 	Pointer          bool
 	Tagged           bool
+	SliceCap         int
+	Candidates       []string
+	Polymorphic      string
+	Scale            string
+	ScaleRound       string
+	TriState         bool
+	Unwrap           string
+	Flags            []string
+	FlagsIgnore      bool
+	BoolString       string
+	Enum             []string
+	EnumUnknown      string
+	EnumKeep         bool
+	Ref              string
+	RefName          string
+	Group            string
+	GroupFunc        string
+	Split            []string
+	SplitPart        string
+	SplitField       string
+	Epoch            string
+	EpochUnit        string
+	Layout           string
+	GroupSep         byte
+	GroupPoint       byte
+	StreamString     bool
+	NullAsEmpty      bool
+	SortedMap        bool
+	Min              string
+	Max              string
+	Stats            []string
+	Redact           string
+	Redacted         bool
+	RedactNested     bool
+	TagError         error
+	// Index is the index sequence of the field, as for
+	// reflect.Type.FieldByIndex.
+	Index []int
+	// Embedded are the embedded pointers the field is promoted through,
+	// which are nil checked when encoding and allocated when decoding.
+	Embedded []EmbeddedPtr
+}
+
+// EmbeddedPtr is an embedded pointer to a struct, named by its selector
+// from the struct the fields are extracted from, like Base.Inner.
+type EmbeddedPtr struct {
+	Name string
+	Typ  reflect.Type
+}
+
+// Ident returns the name of the field as an identifier, as the names of
+// promoted fields are selectors, like Base.ID.
+func (sf *StructField) Ident() string {
+	return strings.Replace(sf.Name, ".", "_", -1)
 }
 
 type FieldByJsonName []*StructField
@@ -47,28 +110,46 @@ func (a FieldByJsonName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a FieldByJsonName) Less(i, j int) bool { return a[i].JsonName < a[j].JsonName }
 
 type StructInfo struct {
-	Name    string
-	Obj     interface{}
-	Typ     reflect.Type
-	Fields  []*StructField
-	Options shared.StructOptions
+	Name string
+	// TypeName is the name of the type the methods are declared on,
+	// which differs from Name for the groups of a struct.
+	TypeName string
+	Obj      interface{}
+	Typ      reflect.Type
+	Fields   []*StructField
+	Options  shared.StructOptions
+	// Interface is the interface named by Options.Implements.
+	Interface reflect.Type
+	// RawHash is the field holding the hash of the decoded input.
+	RawHash *rawHash
+	// Stats are the keys of the EncodeStats function of -encode-stats.
+	Stats []statsKey
 }
 
 func NewStructInfo(obj shared.InceptionType) *StructInfo {
 	t := reflect.TypeOf(obj.Obj)
-	return &StructInfo{
-		Obj:     obj.Obj,
-		Name:    t.Name(),
-		Typ:     t,
-		Fields:  extractFields(obj.Obj),
-		Options: obj.Options,
+	si := &StructInfo{
+		Obj:      obj.Obj,
+		Name:     t.Name(),
+		TypeName: t.Name(),
+		Typ:      t,
+		Fields:   extractFields(obj.Obj),
+		Options:  obj.Options,
+	}
+	if obj.Name != "" {
+		si.Name = obj.Name
+		si.TypeName = obj.Name
+	}
+	if obj.Interface != nil {
+		si.Interface = reflect.TypeOf(obj.Interface).Elem()
 	}
+	return si
 }
 
 func (si *StructInfo) FieldsByFirstByte() map[string][]*StructField {
 	rv := make(map[string][]*StructField)
 	for _, f := range si.Fields {
-		b := string(f.JsonName[1])
+		b := string(f.KeyName[1])
 		rv[b] = append(rv[b], f)
 	}
 	return rv
@@ -130,8 +211,8 @@ var unmarshalFasterType = reflect.TypeOf(new(UnmarshalFaster)).Elem()
 func extractFields(obj interface{}) []*StructField {
 	t := reflect.TypeOf(obj)
 	// Anonymous fields to explore at the current level and the next.
-	current := []StructField{}
-	next := []StructField{{Typ: t}}
+	current := []embeddedStruct{}
+	next := []embeddedStruct{{typ: t}}
 
 	// Count of queued names for current level and the next.
 	count := map[reflect.Type]int{}
@@ -148,15 +229,26 @@ func extractFields(obj interface{}) []*StructField {
 		count, nextCount = nextCount, map[reflect.Type]int{}
 
 		for _, f := range current {
-			if visited[f.Typ] {
+			if visited[f.typ] {
 				continue
 			}
-			visited[f.Typ] = true
+			visited[f.typ] = true
 
 			// Scan f.typ for fields to include.
-			for i := 0; i < f.Typ.NumField(); i++ {
-				sf := f.Typ.Field(i)
-				if sf.PkgPath != "" { // unexported
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					et := sf.Type
+					if et.Kind() == reflect.Ptr {
+						et = et.Elem()
+					}
+					if sf.PkgPath != "" && et.Kind() != reflect.Struct {
+						// Ignore embedded fields of unexported non-struct types.
+						continue
+					}
+					// Do not ignore embedded fields of unexported struct types
+					// since they may have exported fields.
+				} else if sf.PkgPath != "" { // unexported
 					continue
 				}
 				tag := sf.Tag.Get("json")
@@ -167,6 +259,9 @@ func extractFields(obj interface{}) []*StructField {
 				if !isValidTag(name) {
 					name = ""
 				}
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
 
 				ft := sf.Type
 				ptr := false
@@ -192,19 +287,31 @@ func extractFields(obj interface{}) []*StructField {
 					field := &StructField{
 						Name:             sf.Name,
 						JsonName:         string(buf.Bytes()),
+						KeyName:          string(buf.Bytes()),
 						FoldFuncName:     foldFunc([]byte(name)),
 						Typ:              ft,
 						HasMarshalJSON:   ft.Implements(marshalerType),
 						HasUnmarshalJSON: ft.Implements(unmarshalerType),
 						OmitEmpty:        opts.Contains("omitempty"),
+						OmitZero:         opts.Contains("omitzero"),
 						ForceString:      opts.Contains("string"),
 						Pointer:          ptr,
 						Tagged:           tagged,
+						Index:            index,
+						Embedded:         f.ptrs,
+					}
+
+					field.TagError = parseFFTag(field, f.typ, tagOptions(sf.Tag.Get("ffjson")))
+					if f.path != "" {
+						field.Name = f.path + "." + sf.Name
+					}
+					if f.err != nil && field.TagError == nil {
+						field.TagError = f.err
 					}
 
 					fields = append(fields, field)
 
-					if count[f.Typ] > 1 {
+					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
 						// It only cares about the distinction between 1 or 2,
@@ -217,15 +324,32 @@ func extractFields(obj interface{}) []*StructField {
 				// Record new anonymous struct to explore in next round.
 				nextCount[ft]++
 				if nextCount[ft] == 1 {
-					next = append(next, StructField{
-						Name: ft.Name(),
-						Typ:  ft,
-					})
+					next = append(next, embedStruct(t, f, sf, ft, index))
 				}
 			}
 		}
 	}
 
+	// Sort the fields by name, breaking ties with depth, then whether
+	// the field has a JSON tag, then the index sequence, as encoding/json
+	// does.
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].JsonName != x[j].JsonName {
+			return x[i].JsonName < x[j].JsonName
+		}
+		if x[i].Group != x[j].Group {
+			return x[i].Group < x[j].Group
+		}
+		if len(x[i].Index) != len(x[j].Index) {
+			return len(x[i].Index) < len(x[j].Index)
+		}
+		if x[i].Tagged != x[j].Tagged {
+			return x[i].Tagged
+		}
+		return indexLess(x[i].Index, x[j].Index)
+	})
+
 	// Delete all fields that are hidden by the Go rules for embedded fields,
 	// except that fields with JSON tags are promoted.
 
@@ -240,7 +364,7 @@ func extractFields(obj interface{}) []*StructField {
 		name := fi.JsonName
 		for advance = 1; i+advance < len(fields); advance++ {
 			fj := fields[i+advance]
-			if fj.JsonName != name {
+			if fj.JsonName != name || fj.Group != fi.Group {
 				break
 			}
 		}
@@ -255,35 +379,588 @@ func extractFields(obj interface{}) []*StructField {
 	}
 
 	fields = out
+	// Fields are written in the order of their index sequences.
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].Index, fields[j].Index)
+	})
 
 	return fields
 }
 
-// dominantField looks through the fields, all of which are known to
-// have the same name, to find the single field that dominates the
-// others using Go's embedding rules, modified by the presence of
-// JSON tags. If there are multiple top-level fields, the boolean
-// will be false: This condition is an error in Go and we skip all
-// the fields.
-func dominantField(fields []*StructField) (*StructField, bool) {
-	tagged := -1 // Index of first tagged field.
-	for i, f := range fields {
-		if f.Tagged {
-			if tagged >= 0 {
-				// Multiple tagged fields at the same level: conflict.
-				// Return no field.
-				return nil, false
+// embeddedStruct is a struct whose fields are promoted to the struct
+// fields are extracted from.
+type embeddedStruct struct {
+	typ   reflect.Type
+	index []int
+	// path is the selector of the struct, empty for the top struct.
+	path string
+	// ptrs are the embedded pointers on the way to the struct.
+	ptrs []EmbeddedPtr
+	// err is set if the promoted fields can't be accessed.
+	err error
+}
+
+// embedStruct returns the struct ft embedded by the field sf of f, with
+// the index sequence index, in the top struct t.
+func embedStruct(t reflect.Type, f embeddedStruct, sf reflect.StructField, ft reflect.Type, index []int) embeddedStruct {
+	e := embeddedStruct{
+		typ:   ft,
+		index: index,
+		path:  sf.Name,
+		ptrs:  f.ptrs,
+		err:   f.err,
+	}
+	if f.path != "" {
+		e.path = f.path + "." + sf.Name
+	}
+	if sf.PkgPath != "" && sf.PkgPath != t.PkgPath() && e.err == nil {
+		// The generated code is in the package of t, so it can't name
+		// unexported fields of other packages.
+		e.err = fmt.Errorf("ffjson: fields promoted through the unexported embedded field %s of package %s aren't supported", e.path, sf.PkgPath)
+	}
+	if sf.Type.Kind() == reflect.Ptr {
+		ptrs := make([]EmbeddedPtr, len(f.ptrs), len(f.ptrs)+1)
+		copy(ptrs, f.ptrs)
+		e.ptrs = append(ptrs, EmbeddedPtr{Name: e.path, Typ: ft})
+	}
+	return e
+}
+
+// indexLess orders index sequences as the fields of a struct and the
+// fields they embed are declared.
+func indexLess(a, b []int) bool {
+	for k, xik := range a {
+		if k >= len(b) {
+			return false
+		}
+		if xik != b[k] {
+			return xik < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// parseFFTag applies the ffjson specific options given in the
+// `ffjson:"..."` struct tag of a field.
+func parseFFTag(field *StructField, parent reflect.Type, opts tagOptions) error {
+	if v, ok := opts.Value("cap"); ok {
+		if field.Typ.Kind() != reflect.Slice {
+			return fmt.Errorf("ffjson: cap is only supported on slice fields, not %v", field.Typ)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("ffjson: invalid slice capacity %q", v)
+		}
+		field.SliceCap = n
+	}
+	if opts.Contains("nullasempty") {
+		kind := field.Typ.Kind()
+		if field.Pointer || (kind != reflect.Slice && kind != reflect.Map) {
+			return fmt.Errorf("ffjson: nullasempty is only supported on slice and map fields, not %v", field.Typ)
+		}
+		field.NullAsEmpty = true
+	}
+	if opts.Contains("sorted") {
+		if field.Typ.Kind() != reflect.Map {
+			return fmt.Errorf("ffjson: sorted is only supported on map fields, not %v", field.Typ)
+		}
+		field.SortedMap = true
+	}
+	if err := parseRange(field, opts); err != nil {
+		return err
+	}
+	if v, ok := opts.Value("candidates"); ok {
+		typ := field.Typ
+		if typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Interface || field.Pointer {
+			return fmt.Errorf("ffjson: candidates are only supported on interface fields and slices of interfaces, not %v", field.Typ)
+		}
+		for _, c := range strings.Split(v, "|") {
+			if !token.IsIdentifier(strings.TrimPrefix(c, "*")) {
+				return fmt.Errorf("ffjson: invalid candidate type %q, must be a type name of the package", c)
 			}
-			tagged = i
+			field.Candidates = append(field.Candidates, c)
+		}
+	}
+	if v, ok := opts.Value("polymorphic"); ok {
+		err := parsePolymorphic(field, v)
+		if err != nil {
+			return err
 		}
 	}
-	if tagged >= 0 {
-		return fields[tagged], true
+	if v, ok := opts.Value("scale"); ok {
+		err := parseScale(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("round"); ok {
+		if field.Scale == "" || !isIntish(field.Typ) {
+			return fmt.Errorf("ffjson: round is only supported on scaled integer fields")
+		}
+		round, ok := roundModes[v]
+		if !ok {
+			return fmt.Errorf("ffjson: invalid rounding mode %q", v)
+		}
+		field.ScaleRound = round
+	}
+	if opts.Contains("tristate") {
+		if !field.Pointer {
+			return fmt.Errorf("ffjson: tristate is only supported on pointer fields, not %v", field.Typ)
+		}
+		if field.OmitEmpty || field.OmitZero {
+			return fmt.Errorf("ffjson: tristate can't be combined with omitempty or omitzero")
+		}
+		state := field.Name + "State"
+		sf, ok := parent.FieldByName(state)
+		if !ok || sf.Type != triStateType || sf.Tag.Get("json") != "-" {
+			return fmt.Errorf("ffjson: tristate requires a field %s of type shared.TriState, tagged with json:\"-\"", state)
+		}
+		field.TriState = true
+	}
+	if opts.Contains("unwrap") {
+		field.Unwrap = "strict"
+	} else if v, ok := opts.Value("unwrap"); ok {
+		if v != "first" {
+			return fmt.Errorf("ffjson: invalid unwrap mode %q", v)
+		}
+		field.Unwrap = v
+	}
+	if v, ok := opts.Value("flags"); ok {
+		err := parseFlags(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("unknownflags"); ok {
+		if len(field.Flags) == 0 {
+			return fmt.Errorf("ffjson: unknownflags requires the flags option")
+		}
+		switch v {
+		case "error":
+		case "ignore":
+			field.FlagsIgnore = true
+		default:
+			return fmt.Errorf("ffjson: invalid unknownflags mode %q", v)
+		}
+	}
+	if opts.Contains("boolstring") {
+		field.BoolString = "fold"
+	} else if v, ok := opts.Value("boolstring"); ok {
+		if v != "exact" {
+			return fmt.Errorf("ffjson: invalid boolstring mode %q", v)
+		}
+		field.BoolString = v
+	}
+	if field.BoolString != "" && field.Typ.Kind() != reflect.Bool {
+		return fmt.Errorf("ffjson: boolstring is only supported on bool fields, not %v", field.Typ)
+	}
+	if v, ok := opts.Value("group"); ok {
+		for _, name := range strings.Split(v, ".") {
+			if name == "" {
+				return fmt.Errorf("ffjson: invalid group %q", v)
+			}
+		}
+		if field.TriState {
+			return fmt.Errorf("ffjson: group can't be combined with tristate")
+		}
+		field.Group = v
+	}
+	if v, ok := opts.Value("split"); ok {
+		err := parseSplit(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("epoch"); ok {
+		err := parseEpoch(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("unit"); ok {
+		if field.Epoch == "" {
+			return fmt.Errorf("ffjson: unit requires the epoch option")
+		}
+		unit, ok := epochUnits[v]
+		if !ok {
+			return fmt.Errorf("ffjson: invalid epoch unit %q, must be s, ms, us or ns", v)
+		}
+		field.EpochUnit = unit
+	}
+	if v, ok := opts.Value("layout"); ok {
+		err := parseLayout(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("format"); ok {
+		err := parseTimeFormat(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Contains("grouped") {
+		err := parseGrouped(field, "comma")
+		if err != nil {
+			return err
+		}
+	} else if v, ok := opts.Value("grouped"); ok {
+		err := parseGrouped(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Contains("streamstring") {
+		if field.Typ != readerType || field.ForceString || len(field.Candidates) > 0 {
+			return fmt.Errorf("ffjson: streamstring is only supported on io.Reader fields, not %v", field.Typ)
+		}
+		field.StreamString = true
+	}
+	if v, ok := opts.Value("enum"); ok {
+		err := parseEnum(field, v)
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := opts.Value("unknown"); ok {
+		if len(field.Enum) == 0 {
+			return fmt.Errorf("ffjson: unknown requires the enum option")
+		}
+		if !token.IsIdentifier(v) {
+			return fmt.Errorf("ffjson: invalid unknown constant %q, must be a constant name of the package", v)
+		}
+		field.EnumUnknown = v
+	}
+	if v, ok := opts.Value("unknownencode"); ok {
+		if field.EnumUnknown == "" {
+			return fmt.Errorf("ffjson: unknownencode requires the unknown option")
+		}
+		switch v {
+		case "unknown":
+		case "keep":
+			field.EnumKeep = true
+		default:
+			return fmt.Errorf("ffjson: invalid unknownencode mode %q", v)
+		}
+	}
+	if _, ok := opts.Value("rawhash"); ok || opts.Contains("rawhash") {
+		return fmt.Errorf("ffjson: rawhash requires the field to be tagged with json:\"-\"")
+	}
+	if opts.Contains("redact") {
+		field.Redact = "always"
+	}
+	if opts.Contains("noredact") {
+		if field.Redact != "" {
+			return fmt.Errorf("ffjson: redact can't be combined with noredact")
+		}
+		field.Redact = "never"
 	}
-	// All remaining fields have the same length. If there's more than one,
-	// we have a conflict (two fields named "X" at the same level) and we
-	// return no field.
-	if len(fields) > 1 {
+	if field.Unwrap != "" {
+		switch field.Typ.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+			return fmt.Errorf("ffjson: unwrap is only supported on scalar and struct fields, not %v", field.Typ)
+		}
+	}
+	return nil
+}
+
+var triStateType = reflect.TypeOf(shared.TriState(0))
+
+var roundModes = map[string]string{
+	"nearest": "RoundNearest",
+	"trunc":   "RoundTrunc",
+	"floor":   "RoundFloor",
+	"ceil":    "RoundCeil",
+	"exact":   "RoundExact",
+}
+
+func parseFlags(field *StructField, v string) error {
+	kind := field.Typ.Kind()
+	if kind < reflect.Int || kind > reflect.Uintptr || field.Pointer || field.ForceString || field.Scale != "" {
+		return fmt.Errorf("ffjson: flags are only supported on integer fields, not %v", field.Typ)
+	}
+	names := strings.Split(v, "|")
+	if len(names) > field.Typ.Bits() {
+		return fmt.Errorf("ffjson: %d flags don't fit into %v", len(names), field.Typ)
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("ffjson: duplicate flag %q", name)
+		}
+		if name != "" {
+			seen[name] = true
+		}
+	}
+	if len(seen) == 0 {
+		return fmt.Errorf("ffjson: flags requires at least one name")
+	}
+	field.Flags = names
+	return nil
+}
+
+func parseSplit(field *StructField, v string) error {
+	if field.Typ != timeType || field.Pointer || field.ForceString {
+		return fmt.Errorf("ffjson: split is only supported on time.Time fields, not %v", field.Typ)
+	}
+	if field.TriState || field.Group != "" || field.Unwrap != "" {
+		return fmt.Errorf("ffjson: split can't be combined with tristate, group or unwrap")
+	}
+	names := strings.Split(v, "|")
+	if len(names) != 2 || names[0] == "" || names[1] == "" || names[0] == names[1] {
+		return fmt.Errorf("ffjson: split requires two different names, not %q", v)
+	}
+	field.Split = []string{jsonKey(names[0]), jsonKey(names[1])}
+	return nil
+}
+
+var groupSeparators = map[string]byte{
+	"comma":      ',',
+	"dot":        '.',
+	"space":      ' ',
+	"apostrophe": '\'',
+	"underscore": '_',
+}
+
+func parseGrouped(field *StructField, v string) error {
+	if !isNumber(field.Typ) || !field.ForceString {
+		return fmt.Errorf("ffjson: grouped is only supported on numeric fields with the string option, not %v", field.Typ)
+	}
+	if field.Scale != "" {
+		return fmt.Errorf("ffjson: grouped can't be combined with scale")
+	}
+	sep, ok := groupSeparators[v]
+	if !ok {
+		return fmt.Errorf("ffjson: invalid digit separator %q, must be comma, dot, space, apostrophe or underscore", v)
+	}
+	field.GroupSep = sep
+	field.GroupPoint = '.'
+	if sep == '.' {
+		field.GroupPoint = ','
+	}
+	return nil
+}
+
+// parseRange parses the min and max options of a numeric field into Go
+// literals of its type.
+func parseRange(field *StructField, opts tagOptions) error {
+	min, hasMin := opts.Value("min")
+	max, hasMax := opts.Value("max")
+	if !hasMin && !hasMax {
+		return nil
+	}
+	if !isNumber(field.Typ) {
+		return fmt.Errorf("ffjson: min and max are only supported on numeric fields, not %v", field.Typ)
+	}
+
+	var err error
+	if hasMin {
+		field.Min, err = parseBound(field.Typ, "min", min)
+		if err != nil {
+			return err
+		}
+	}
+	if hasMax {
+		field.Max, err = parseBound(field.Typ, "max", max)
+		if err != nil {
+			return err
+		}
+	}
+	if hasMin && hasMax {
+		lo, _ := strconv.ParseFloat(field.Min, 64)
+		hi, _ := strconv.ParseFloat(field.Max, 64)
+		if lo > hi {
+			return fmt.Errorf("ffjson: min %s is greater than max %s", min, max)
+		}
+	}
+	return nil
+}
+
+func parseBound(typ reflect.Type, name string, v string) (string, error) {
+	var err error
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(v, typ.Bits())
+		if err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, typ.Bits()), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(v, 10, typ.Bits())
+		if err == nil {
+			return strconv.FormatUint(n, 10), nil
+		}
+	default:
+		var n int64
+		n, err = strconv.ParseInt(v, 10, typ.Bits())
+		if err == nil {
+			return strconv.FormatInt(n, 10), nil
+		}
+	}
+	return "", fmt.Errorf("ffjson: invalid %s %q for %v", name, v, typ)
+}
+
+// isJSONNumber returns whether typ is json.Number.
+func isJSONNumber(typ reflect.Type) bool {
+	return typ.PkgPath() == "encoding/json" && typ.Name() == "Number"
+}
+
+func isNumber(typ reflect.Type) bool {
+	kind := typ.Kind()
+	return (kind >= reflect.Int && kind <= reflect.Uint64) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+var epochUnits = map[string]string{
+	"s":  "1",
+	"ms": "1000",
+	"us": "1000000",
+	"ns": "1000000000",
+}
+
+func parseEpoch(field *StructField, v string) error {
+	if field.Typ != timeType || field.ForceString || len(field.Split) > 0 {
+		return fmt.Errorf("ffjson: epoch is only supported on time.Time fields, not %v", field.Typ)
+	}
+	epoch, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		epoch, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fmt.Errorf("ffjson: invalid epoch %q, must be YYYY-MM-DD or RFC 3339", v)
+		}
+	}
+	if epoch.Nanosecond() != 0 {
+		return fmt.Errorf("ffjson: epoch %q must be a whole second", v)
+	}
+	field.Epoch = strconv.FormatInt(epoch.Unix(), 10)
+	field.EpochUnit = epochUnits["s"]
+	return nil
+}
+
+func parseLayout(field *StructField, v string) error {
+	typ := field.Typ
+	if typ.Kind() == reflect.Slice && !field.Pointer {
+		typ = typ.Elem()
+	}
+	if typ != timeType || field.ForceString {
+		return fmt.Errorf("ffjson: layout is only supported on time.Time, *time.Time and []time.Time fields, not %v", field.Typ)
+	}
+	if len(field.Split) > 0 || field.Epoch != "" {
+		return fmt.Errorf("ffjson: layout can't be combined with split or epoch")
+	}
+	if v == "" {
+		return fmt.Errorf("ffjson: layout requires a time layout")
+	}
+	field.Layout = v
+	return nil
+}
+
+func parsePolymorphic(field *StructField, v string) error {
+	typ := field.Typ
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Interface || field.Pointer {
+		return fmt.Errorf("ffjson: polymorphic is only supported on interface fields and slices of interfaces, not %v", field.Typ)
+	}
+	if len(field.Candidates) > 0 {
+		return fmt.Errorf("ffjson: polymorphic can't be combined with candidates")
+	}
+	if v == "" {
+		return fmt.Errorf("ffjson: polymorphic requires the name of the discriminator member")
+	}
+	field.Polymorphic = v
+	return nil
+}
+
+// unixFormats are the formats of the format option written as Unix
+// timestamps, with their epoch units.
+var unixFormats = map[string]string{
+	"unix":      "s",
+	"unixmilli": "ms",
+	"unixmicro": "us",
+	"unixnano":  "ns",
+}
+
+// namedLayouts are the formats of the format option naming a layout of
+// the time package.
+var namedLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+}
+
+// parseTimeFormat sets the epoch or layout of a time field from the
+// format option, a shorthand for the two.
+func parseTimeFormat(field *StructField, v string) error {
+	if field.Epoch != "" || field.Layout != "" {
+		return fmt.Errorf("ffjson: format can't be combined with epoch or layout")
+	}
+	if unit, ok := unixFormats[v]; ok {
+		err := parseEpoch(field, "1970-01-01")
+		if err != nil {
+			return err
+		}
+		field.EpochUnit = epochUnits[unit]
+		return nil
+	}
+	if layout, ok := namedLayouts[v]; ok {
+		v = layout
+	}
+	return parseLayout(field, v)
+}
+
+func parseEnum(field *StructField, v string) error {
+	if field.Typ.Kind() != reflect.String || field.Pointer || field.ForceString {
+		return fmt.Errorf("ffjson: enum is only supported on string fields, not %v", field.Typ)
+	}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(v, "|") {
+		if seen[name] {
+			return fmt.Errorf("ffjson: duplicate enum value %q", name)
+		}
+		seen[name] = true
+		field.Enum = append(field.Enum, name)
+	}
+	return nil
+}
+
+func parseScale(field *StructField, v string) error {
+	if field.ForceString {
+		return fmt.Errorf("ffjson: scale can't be combined with the string option")
+	}
+	switch field.Typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("ffjson: scale of integer field must be a positive integer, not %q", v)
+		}
+		field.Scale = strconv.FormatInt(n, 10)
+		field.ScaleRound = roundModes["nearest"]
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || !(f > 0) || f > math.MaxFloat64 {
+			return fmt.Errorf("ffjson: scale must be a positive number, not %q", v)
+		}
+		field.Scale = strconv.FormatFloat(f, 'g', -1, 64)
+	default:
+		return fmt.Errorf("ffjson: scale is only supported on numeric fields, not %v", field.Typ)
+	}
+	return nil
+}
+
+// dominantField looks through the fields, all of which are known to
+// have the same name, to find the single field that dominates the
+// others using Go's embedding rules, modified by the presence of
+// JSON tags. The fields are sorted by depth, with tagged fields first.
+// If there are multiple fields at the top depth, with or without tags
+// alike, the boolean will be false: This condition is an error in Go
+// and we skip all the fields.
+func dominantField(fields []*StructField) (*StructField, bool) {
+	if len(fields) > 1 && len(fields[0].Index) == len(fields[1].Index) && fields[0].Tagged == fields[1].Tagged {
 		return nil, false
 	}
 	return fields[0], true
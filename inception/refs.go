@@ -0,0 +1,169 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// prepareRefs checks the fields of a struct with refs, and marks those
+// holding pointers to structs with refs, which are written once and
+// referenced by JSON Pointer afterwards.
+func prepareRefs(ic *Inception, si *StructInfo) error {
+	switch {
+	case !ic.wantMarshal(si) || !ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: refs requires both an encoder and a decoder", si.Name)
+	case si.Options.EnvelopeKey != "":
+		return fmt.Errorf("%s: refs can't be combined with an envelope", si.Name)
+	case si.Options.Verbose:
+		return fmt.Errorf("%s: refs can't be combined with -verbose", si.Name)
+	case si.Options.Schema:
+		return fmt.Errorf("%s: refs can't be combined with -schema", si.Name)
+	case hasRedaction(si):
+		return fmt.Errorf("%s: refs can't be combined with redaction", si.Name)
+	}
+
+	for _, f := range si.Fields {
+		if f.JsonName == `"$ref"` {
+			return fmt.Errorf("%s.%s: the json name $ref is reserved for references", si.Name, f.Name)
+		}
+		switch {
+		case f.Pointer && refsType(ic, f.Typ):
+			f.Ref = "pointer"
+		case f.Typ.Kind() == reflect.Slice && f.Typ.Elem().Kind() == reflect.Ptr && refsType(ic, f.Typ.Elem().Elem()):
+			f.Ref = "slice"
+		case containsRefs(ic, f.Typ):
+			return fmt.Errorf("%s.%s: structs with refs can only be referenced by pointers and slices of pointers, not %v", si.Name, f.Name, f.Typ)
+		default:
+			continue
+		}
+		err := json.Unmarshal([]byte(f.JsonName), &f.RefName)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// refsType returns whether typ is a struct of this file with refs.
+func refsType(ic *Inception, typ reflect.Type) bool {
+	for _, si := range ic.objs {
+		if si.Typ == typ {
+			return si.Options.Refs
+		}
+	}
+	return false
+}
+
+// containsRefs returns whether values of typ hold structs with refs.
+func containsRefs(ic *Inception, typ reflect.Type) bool {
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return refsType(ic, typ)
+		}
+	}
+}
+
+// createRefsMarshal generates the MarshalJSONBuf function of a struct
+// with refs, starting a new set of references rooted at the struct.
+func createRefsMarshal(si *StructInfo) string {
+	out := ""
+	out += "// MarshalJSONBuf marshal buff to json, writing shared values once - template\n"
+	out += `func (j *` + si.Name + `) MarshalJSONBuf(buf fflib.EncodingBuffer) (error) {` + "\n"
+	out += `return j.marshalJSONBufRefs(buf, fflib.NewRefs(j))` + "\n"
+	out += `}` + "\n"
+	return out
+}
+
+// getRefValue writes a field holding pointers to structs with refs,
+// writing a reference for each pointer met before.
+func getRefValue(ic *Inception, f *StructField, name string) string {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	if f.Ref == "pointer" {
+		out += "refs.Push(" + strconv.Quote(f.RefName) + ")" + "\n"
+		out += getRefElem(name)
+		out += "refs.Pop()" + "\n"
+		return out
+	}
+
+	out += "if " + name + " != nil {" + "\n"
+	out += "buf.WriteString(`[`)" + "\n"
+	out += "refs.Push(" + strconv.Quote(f.RefName) + ")" + "\n"
+	out += "for i, v := range " + name + " {" + "\n"
+	out += "if i != 0 {" + "\n"
+	out += "buf.WriteString(`,`)" + "\n"
+	out += "}" + "\n"
+	out += "if v == nil {" + "\n"
+	out += "buf.WriteString(`null`)" + "\n"
+	out += "continue" + "\n"
+	out += "}" + "\n"
+	out += "refs.PushIndex(i)" + "\n"
+	out += getRefElem("v")
+	out += "refs.Pop()" + "\n"
+	out += "}" + "\n"
+	out += "refs.Pop()" + "\n"
+	out += "buf.WriteString(`]`)" + "\n"
+	out += "} else {" + "\n"
+	out += "buf.WriteString(`null`)" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getRefElem writes the non-nil pointer name at the current location of
+// refs.
+func getRefElem(name string) string {
+	out := ""
+	out += "if ref, ok := refs.Lookup(" + name + "); ok {" + "\n"
+	out += "fflib.WriteRef(buf, ref)" + "\n"
+	out += "} else {" + "\n"
+	out += "refs.Define(" + name + ")" + "\n"
+	out += "err = " + name + ".marshalJSONBufRefs(buf, refs)" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "return err" + "\n"
+	out += "}" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// createRefsUnmarshal generates the UnmarshalJSONFFLexer function of a
+// struct with refs, starting a new set of references rooted at the
+// struct.
+func createRefsUnmarshal(ic *Inception, si *StructInfo) {
+	out := ""
+	out += "// UnmarshalJSONFFLexer fast json unmarshall, resolving references - template ffjson\n"
+	out += `func (j *` + si.Name + `) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {` + "\n"
+	out += "refs := fflib.NewRefs(j)" + "\n"
+	out += "err := j.unmarshalJSONFFLexerRefs(fs, state, refs)" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "return err" + "\n"
+	out += "}" + "\n"
+	out += "if ref, ok := refs.TakeRef(); ok {" + "\n"
+	out += "return fs.WrapErr(fmt.Errorf(\"ffjson: cannot resolve the reference %q of the outermost value\", ref))" + "\n"
+	out += "}" + "\n"
+	out += "return nil" + "\n"
+	out += "}" + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
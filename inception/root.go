@@ -0,0 +1,89 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+// CreateUnmarshalRoot generates the UnmarshalFooRoot function decoding
+// a document whose root is either a struct or an array of structs,
+// calling the handler of the shape found.
+func CreateUnmarshalRoot(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"errors"`] = true
+	out := ""
+
+	name := si.Name
+	out += "// Unmarshal" + name + "Root decodes input, which is either an object or an array of objects, calling object or array with the result - template\n"
+	out += `func Unmarshal` + name + `Root(input []byte, object func(*` + name + `) error, array func([]*` + name + `) error) error {` + "\n"
+	out += `tok, err := fflib.PeekRoot(input)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `fs := fflib.NewFFLexer(input)` + "\n"
+	out += `switch {` + "\n"
+
+	out += `case (tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_null) && object != nil:` + "\n"
+	out += `var v *` + name + "\n"
+	out += `if tok == fflib.FFTok_null {` + "\n"
+	out += `fs.Scan()` + "\n"
+	out += `} else {` + "\n"
+	out += `v = new(` + name + `)` + "\n"
+	out += `err = v.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `}` + "\n"
+	out += `err = fflib.ScanEnd(fs)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `return object(v)` + "\n"
+
+	out += `case tok == fflib.FFTok_left_brace && array != nil:` + "\n"
+	out += `fs.Scan()` + "\n"
+	out += `vs := []*` + name + `{}` + "\n"
+	out += `err = fflib.ScanArray(fs, func(tok fflib.FFTok) error {` + "\n"
+	out += `if tok == fflib.FFTok_null {` + "\n"
+	out += `vs = append(vs, nil)` + "\n"
+	out += "return nil" + "\n"
+	out += `}` + "\n"
+	out += `if tok != fflib.FFTok_left_bracket {` + "\n"
+	out += `return fs.WrapErr(fmt.Errorf("ffjson: wanted an object or null in the array, but got token: %v", tok))` + "\n"
+	out += `}` + "\n"
+	out += `v := new(` + name + `)` + "\n"
+	out += `vs = append(vs, v)` + "\n"
+	out += `return v.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)` + "\n"
+	out += `})` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `err = fflib.ScanEnd(fs)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `return array(vs)` + "\n"
+
+	out += `case tok == fflib.FFTok_left_bracket || tok == fflib.FFTok_null:` + "\n"
+	out += `return errors.New("ffjson: unexpected object at the root of ` + name + `")` + "\n"
+	out += `case tok == fflib.FFTok_left_brace:` + "\n"
+	out += `return errors.New("ffjson: unexpected array at the root of ` + name + `")` + "\n"
+	out += `}` + "\n"
+	out += `return fs.WrapErr(fmt.Errorf("ffjson: wanted an object or an array at the root, but got token: %v", tok))` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
@@ -0,0 +1,389 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxproc/ffjson/shared"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaObject is a JSON object keeping the order of its members.
+type schemaObject []schemaMember
+
+type schemaMember struct {
+	Key   string
+	Value interface{}
+}
+
+func (o schemaObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(jsonKey(m.Key))
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// set replaces the value of the member key, or appends it.
+func (o schemaObject) set(key string, value interface{}) schemaObject {
+	for i, m := range o {
+		if m.Key == key {
+			o[i].Value = value
+			return o
+		}
+	}
+	return append(o, schemaMember{key, value})
+}
+
+type schemaBuilder struct {
+	ic *Inception
+	// refs maps struct types to their $ref.
+	refs map[reflect.Type]string
+	defs schemaObject
+	used map[string]bool
+}
+
+// CreateJSONSchema generates a JSONSchema function, returning a JSON Schema
+// describing the json produced and accepted for the struct. Other named
+// structs are included in $defs.
+func CreateJSONSchema(ic *Inception, si *StructInfo) error {
+	b := &schemaBuilder{
+		ic:   ic,
+		refs: map[reflect.Type]string{},
+		used: map[string]bool{},
+	}
+
+	doc := schemaObject{
+		{"$schema", schemaDraft},
+		{"title", si.Name},
+	}
+
+	if si.Options.EnvelopeKey == "" {
+		b.refs[si.Typ] = "#"
+		doc = append(doc, b.structSchema(si.Typ)...)
+	} else {
+		fields, err := getEnvelopeFields(si)
+		if err != nil {
+			return err
+		}
+		props := schemaObject{}
+		required := []string{}
+		for _, f := range fields {
+			props = append(props, schemaMember{f.Name, schemaObject{{"const", json.RawMessage(f.Value)}}})
+			required = append(required, f.Name)
+		}
+		props = append(props, schemaMember{si.Options.EnvelopeKey, b.typeSchema(si.Typ)})
+		required = append(required, si.Options.EnvelopeKey)
+		doc = append(doc,
+			schemaMember{"type", "object"},
+			schemaMember{"properties", props},
+			schemaMember{"required", required})
+	}
+
+	if len(b.defs) > 0 {
+		doc = append(doc, schemaMember{"$defs", b.defs})
+	}
+
+	schema, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	out := ""
+	out += "// JSONSchema returns a JSON Schema (draft 2020-12) describing the json of the struct - template ffjson\n"
+	out += `func (j ` + si.Name + `) JSONSchema() []byte {` + "\n"
+	out += `return []byte(` + strconv.Quote(string(schema)) + `)` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+func (b *schemaBuilder) structFields(typ reflect.Type) []*StructField {
+	for _, si := range b.ic.objs {
+		if si.Typ == typ {
+			return si.Fields
+		}
+	}
+	return extractFields(reflect.Zero(typ).Interface())
+}
+
+func (b *schemaBuilder) structSchema(typ reflect.Type) schemaObject {
+	for _, si := range b.ic.objs {
+		if si.Typ == typ && si.Options.Tuple {
+			return b.tupleSchema(si.Fields)
+		}
+	}
+	return b.objectSchema(groupFields(b.structFields(typ)))
+}
+
+// tupleSchema describes the array written for a struct with ffjson:
+// tuple, which has an element for each field.
+func (b *schemaBuilder) tupleSchema(fields []*StructField) schemaObject {
+	items := []schemaObject{}
+	for _, f := range fields {
+		items = append(items, b.fieldSchema(f))
+	}
+	return schemaObject{
+		{"type", "array"},
+		{"prefixItems", items},
+		{"items", false},
+		{"minItems", len(items)},
+	}
+}
+
+func (b *schemaBuilder) objectSchema(members []*groupMember) schemaObject {
+	props := schemaObject{}
+	required := []string{}
+	for _, m := range members {
+		if m.Group != nil {
+			var name string
+			json.Unmarshal([]byte(m.Group.JsonName), &name)
+			props = append(props, schemaMember{name, b.objectSchema(m.Group.Members)})
+			// Groups are always written.
+			required = append(required, name)
+			continue
+		}
+		f := m.Field
+		if len(f.Split) > 0 {
+			for i, format := range []string{"date", "time"} {
+				var name string
+				json.Unmarshal([]byte(f.Split[i]), &name)
+				props = append(props, schemaMember{name, schemaObject{{"type", "string"}, {"format", format}}})
+				if !f.OmitEmpty && !f.OmitZero {
+					required = append(required, name)
+				}
+			}
+			continue
+		}
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			continue
+		}
+		props = append(props, schemaMember{name, b.fieldSchema(f)})
+		// Fields without omitempty or omitzero are always written.
+		if !f.OmitEmpty && !f.OmitZero && !f.TriState {
+			required = append(required, name)
+		}
+	}
+
+	s := schemaObject{{"type", "object"}, {"properties", props}}
+	if len(required) > 0 {
+		s = append(s, schemaMember{"required", required})
+	}
+	return s
+}
+
+func (b *schemaBuilder) fieldSchema(f *StructField) schemaObject {
+	typ := f.Typ
+	if f.Pointer && typ.Kind() != reflect.Ptr {
+		typ = reflect.PtrTo(typ)
+	}
+
+	var s schemaObject
+	switch {
+	case len(f.Candidates) > 0:
+		s = b.candidatesSchema(f.Candidates)
+		if f.Typ.Kind() == reflect.Slice {
+			s = schemaObject{{"type", "array"}, {"items", s}}
+		}
+	case f.Polymorphic != "":
+		props := schemaObject{{f.Polymorphic, schemaObject{{"type", "string"}}}}
+		s = schemaObject{{"type", "object"}, {"properties", props}, {"required", []string{f.Polymorphic}}}
+		if f.Typ.Kind() == reflect.Slice {
+			s = schemaObject{{"type", "array"}, {"items", s}}
+		}
+	case len(f.Flags) > 0:
+		names := []string{}
+		for _, n := range f.Flags {
+			if n != "" {
+				names = append(names, n)
+			}
+		}
+		s = schemaObject{{"type", "array"}, {"items", schemaObject{{"enum", names}}}, {"uniqueItems", true}}
+	case len(f.Enum) > 0 && f.EnumUnknown == "":
+		s = schemaObject{{"type", "string"}, {"enum", f.Enum}}
+	case f.Epoch != "":
+		s = schemaObject{{"type", "integer"}}
+	case f.Layout != "":
+		s = schemaObject{{"type", "string"}}
+		if f.Typ.Kind() == reflect.Slice {
+			s = schemaObject{{"type", "array"}, {"items", s}}
+		}
+	case f.Scale != "":
+		s = schemaObject{{"type", "number"}}
+	case f.GroupSep != 0:
+		s = schemaObject{{"type", "string"}}
+	case f.StreamString:
+		return nullable(schemaObject{{"type", "string"}})
+	case f.ForceString && isScalar(f.Typ):
+		s = schemaObject{{"type", "string"}}
+	case f.Min != "" || f.Max != "":
+		s = append(s, b.typeSchema(f.Typ)...)
+		if f.Min != "" {
+			s = s.set("minimum", json.Number(f.Min))
+		}
+		if f.Max != "" {
+			s = s.set("maximum", json.Number(f.Max))
+		}
+	default:
+		return b.typeSchema(typ)
+	}
+	if typ.Kind() == reflect.Ptr {
+		return nullable(s)
+	}
+	return s
+}
+
+func (b *schemaBuilder) candidatesSchema(candidates []string) schemaObject {
+	var anyOf []schemaObject
+	for _, c := range candidates {
+		for _, si := range b.ic.objs {
+			if si.Name == strings.TrimPrefix(c, "*") {
+				anyOf = append(anyOf, b.typeSchema(si.Typ))
+			}
+		}
+	}
+	return schemaObject{{"anyOf", anyOf}}
+}
+
+func (b *schemaBuilder) typeSchema(typ reflect.Type) schemaObject {
+	if typ == timeType {
+		return schemaObject{{"type", "string"}, {"format", "date-time"}}
+	}
+	if typ.Kind() == reflect.Ptr {
+		return nullable(b.typeSchema(typ.Elem()))
+	}
+
+	generated := typeInInception(b.ic, typ, shared.MustEncoder)
+	if !generated && (typ.Implements(marshalerType) || reflect.PtrTo(typ).Implements(marshalerType)) {
+		// Anything can be written by a custom marshaler.
+		return schemaObject{}
+	}
+	if !generated && (typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType)) {
+		return schemaObject{{"type", "string"}}
+	}
+
+	if isJSONNumber(typ) {
+		return schemaObject{{"type", "number"}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return schemaObject{{"type", "boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schemaObject{{"type", "integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return schemaObject{{"type", "integer"}, {"minimum", 0}}
+	case reflect.Float32, reflect.Float64:
+		return schemaObject{{"type", "number"}}
+	case reflect.String:
+		return schemaObject{{"type", "string"}}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return schemaObject{{"type", "string"}, {"contentEncoding", "base64"}}
+		}
+		return schemaObject{{"type", "array"}, {"items", b.typeSchema(typ.Elem())}}
+	case reflect.Array:
+		return schemaObject{
+			{"type", "array"},
+			{"items", b.typeSchema(typ.Elem())},
+			{"minItems", typ.Len()},
+			{"maxItems", typ.Len()},
+		}
+	case reflect.Map:
+		return schemaObject{{"type", "object"}, {"additionalProperties", b.typeSchema(typ.Elem())}}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return b.structSchema(typ)
+		}
+		return schemaObject{{"$ref", b.ref(typ)}}
+	}
+
+	// Interfaces can hold any value.
+	return schemaObject{}
+}
+
+// ref returns the $ref of a named struct type, adding it to $defs.
+func (b *schemaBuilder) ref(typ reflect.Type) string {
+	if ref, ok := b.refs[typ]; ok {
+		return ref
+	}
+
+	name := typ.Name()
+	if b.used[name] {
+		name = strings.Replace(typ.String(), ".", "_", -1)
+	}
+	b.used[name] = true
+
+	ref := "#/$defs/" + name
+	b.refs[typ] = ref
+	b.defs = append(b.defs, schemaMember{name, nil})
+	i := len(b.defs) - 1
+	// structSchema may append to b.defs, so b.defs is only read once it
+	// returned.
+	v := b.structSchema(typ)
+	b.defs[i].Value = v
+	return ref
+}
+
+// nullable allows null in addition to the values of s.
+func nullable(s schemaObject) schemaObject {
+	if len(s) == 0 {
+		return s
+	}
+	if len(s) == 1 && s[0].Key == "type" {
+		if t, ok := s[0].Value.(string); ok {
+			return schemaObject{{"type", []string{t, "null"}}}
+		}
+	}
+	return schemaObject{{"anyOf", []interface{}{s, schemaObject{{"type", "null"}}}}}
+}
+
+func isScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+func hasSplitTimes(si *StructInfo) bool {
+	for _, f := range si.Fields {
+		if len(f.Split) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareSplitTimes checks that the date and time parts of the fields of
+// si with ffjson:"split=..." don't collide with other members.
+func prepareSplitTimes(ic *Inception, si *StructInfo) error {
+	switch {
+	case !hasSplitTimes(si):
+		return nil
+	case si.Options.Form:
+		return fmt.Errorf("%s: split times can't be combined with -form", si.Name)
+	case si.Options.Accessors:
+		return fmt.Errorf("%s: split times can't be combined with -accessors", si.Name)
+	case si.Options.KeepOrder:
+		return fmt.Errorf("%s: split times can't be combined with keeporder", si.Name)
+	case si.Options.ResetFields && ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: split times can't be combined with resetfields", si.Name)
+	}
+
+	seen := make(map[string]bool, len(si.Fields)+2)
+	for _, f := range si.Fields {
+		names := f.Split
+		if len(names) == 0 {
+			names = []string{f.JsonName}
+		}
+		for _, name := range names {
+			if seen[name] {
+				return fmt.Errorf("%s.%s: the json name %s is used more than once", si.Name, f.Name, name)
+			}
+			seen[name] = true
+		}
+	}
+	return nil
+}
+
+// getSplitTime writes the date and time parts of a time.Time field as two
+// members.
+func getSplitTime(ic *Inception, f *StructField, prefix string) string {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	name := prefix + f.Name
+	out := ""
+	if f.OmitEmpty || f.OmitZero {
+		out += ic.q.Flush()
+		out += "if !" + name + ".IsZero() {" + "\n"
+	}
+	out += getFieldStats(ic, f)
+
+	if f.Redacted {
+		ic.q.Write(f.Split[0] + `:"[REDACTED]",` + f.Split[1] + `:"[REDACTED]"`)
+	} else {
+		ic.q.Write(f.Split[0] + `:"`)
+		out += ic.q.Flush()
+		out += "buf.WriteString(" + name + ".Format(fflib.DateFormat))" + "\n"
+		ic.q.Write(`",` + f.Split[1] + `:"`)
+		out += ic.q.Flush()
+		out += "buf.WriteString(" + name + ".Format(fflib.TimeFormat))" + "\n"
+		ic.q.Write(`"`)
+	}
+	ic.q.Write(",")
+
+	if f.OmitEmpty || f.OmitZero {
+		out += ic.q.Flush()
+		out += "}" + "\n"
+	}
+	return out
+}
+
+// splitDecodeFields returns the fields the decoder matches, with a field
+// for each part of the split times, joined when decoding is done.
+func splitDecodeFields(fields []*StructField) []*StructField {
+	out := make([]*StructField, 0, len(fields)+2)
+	for _, f := range fields {
+		if len(f.Split) == 0 {
+			out = append(out, f)
+			continue
+		}
+		for i, part := range []string{"date", "time"} {
+			var name string
+			json.Unmarshal([]byte(f.Split[i]), &name)
+			out = append(out, &StructField{
+				Name:         f.Name + "_" + part,
+				JsonName:     f.Split[i],
+				KeyName:      f.Split[i],
+				FoldFuncName: foldFunc([]byte(name)),
+				Typ:          reflect.TypeOf(""),
+				SplitPart:    part,
+				SplitField:   f.Name,
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// statsKey is a key of the map returned by the EncodeStats function of a
+// struct, and the counter behind it.
+type statsKey struct {
+	Name    string
+	Counter string
+}
+
+// prepareEncodeStats assigns a counter to each field of si, counting how
+// often the field is written. The first counter counts encoded values.
+func prepareEncodeStats(ic *Inception, si *StructInfo) error {
+	if !ic.wantMarshal(si) {
+		return fmt.Errorf("%s: -encode-stats requires an encoder", si.Name)
+	}
+	keys := []statsKey{{"", statsCounter(si, 0)}}
+	for _, f := range si.Fields {
+		names := f.Split
+		if len(names) == 0 {
+			names = []string{f.JsonName}
+		}
+		f.Stats = nil
+		for _, jsonName := range names {
+			var name string
+			err := json.Unmarshal([]byte(jsonName), &name)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+			}
+			if f.Group != "" {
+				name = f.Group + "." + name
+			}
+			counter := statsCounter(si, len(keys))
+			keys = append(keys, statsKey{name, counter})
+			f.Stats = append(f.Stats, counter)
+		}
+	}
+	si.Stats = keys
+	return nil
+}
+
+func statsCounter(si *StructInfo, i int) string {
+	return "ffjStats" + si.Name + "[" + strconv.Itoa(i) + "]"
+}
+
+// getFieldStats counts a write of f.
+func getFieldStats(ic *Inception, f *StructField) string {
+	if len(f.Stats) == 0 {
+		return ""
+	}
+	ic.OutputImports[`"sync/atomic"`] = true
+	out := ic.q.Flush()
+	for _, counter := range f.Stats {
+		out += "atomic.AddInt64(&" + counter + ", 1)" + "\n"
+	}
+	return out
+}
+
+// getEncodeStats counts an encoded value of si.
+func getEncodeStats(ic *Inception, si *StructInfo) string {
+	if len(si.Stats) == 0 {
+		return ""
+	}
+	ic.OutputImports[`"sync/atomic"`] = true
+	return "atomic.AddInt64(&" + si.Stats[0].Counter + ", 1)" + "\n"
+}
+
+// createEncodeStats generates the counters of si, and the functions
+// reading and resetting them.
+func createEncodeStats(ic *Inception, si *StructInfo) {
+	ic.OutputImports[`"sync/atomic"`] = true
+	out := ""
+	out += "var ffjStats" + si.Name + " [" + strconv.Itoa(len(si.Stats)) + "]int64" + "\n\n"
+
+	out += "// " + si.Name + "EncodeStats returns how often each field of " + si.Name + " was written\n"
+	out += "// by its encoders, by json name, and under \"\" the number of encoded values - template\n"
+	out += "func " + si.Name + "EncodeStats() map[string]int64 {" + "\n"
+	out += "return map[string]int64{" + "\n"
+	for _, k := range si.Stats {
+		out += strconv.Quote(k.Name) + ": atomic.LoadInt64(&" + k.Counter + ")," + "\n"
+	}
+	out += "}" + "\n"
+	out += "}" + "\n\n"
+
+	out += "// Reset" + si.Name + "EncodeStats sets the counts of " + si.Name + "EncodeStats to zero - template\n"
+	out += "func Reset" + si.Name + "EncodeStats() {" + "\n"
+	out += "for i := range ffjStats" + si.Name + " {" + "\n"
+	out += "atomic.StoreInt64(&ffjStats" + si.Name + "[i], 0)" + "\n"
+	out += "}" + "\n"
+	out += "}" + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
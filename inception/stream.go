@@ -0,0 +1,101 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+)
+
+// CreateEncodeJSON generates the EncodeJSON method, streaming the output
+// of MarshalJSONBuf to an io.Writer.
+func CreateEncodeJSON(ic *Inception, si *StructInfo) error {
+	if _, ok := si.Typ.FieldByName("EncodeJSON"); ok {
+		return fmt.Errorf("%s: -stream can't be used on structs with an EncodeJSON field", si.Name)
+	}
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"io"`] = true
+	out := ""
+
+	out += "// EncodeJSON writes the json of j to w, in chunks of fflib.StreamChunkSize bytes - template\n"
+	out += `func (j *` + si.Name + `) EncodeJSON(w io.Writer) error {` + "\n"
+	out += `out := fflib.NewChunkedWriter(w, fflib.StreamChunkSize)` + "\n"
+	out += `err := j.MarshalJSONBuf(out)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `return out.Flush()` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateMarshalTo generates the MarshalTo method, writing the json to an
+// io.Writer from a buffer of the pool declared by CreateMarshalJSON.
+func CreateMarshalTo(ic *Inception, si *StructInfo) error {
+	if _, ok := si.Typ.FieldByName("MarshalTo"); ok {
+		return fmt.Errorf("%s: -marshal-to can't be used on structs with a MarshalTo field", si.Name)
+	}
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"io"`] = true
+	out := ""
+
+	out += "// MarshalTo writes the json of j to w with a single Write, from a pooled buffer - template\n"
+	out += `func (j *` + si.Name + `) MarshalTo(w io.Writer) error {` + "\n"
+	out += `buf := ffjBufPool` + si.Name + `.Get().(*fflib.Buffer)` + "\n"
+	out += `defer func() {` + "\n"
+	out += `  if buf.Len() <= 1<<16 {` + "\n"
+	out += `    buf.Reset()` + "\n"
+	out += `    ffjBufPool` + si.Name + `.Put(buf)` + "\n"
+	out += `  }` + "\n"
+	out += `}()` + "\n"
+	out += `err := j.MarshalJSONBuf(buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `_, err = w.Write(buf.Bytes())` + "\n"
+	out += `return err` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// CreateDecodeJSON generates the DecodeJSON method, reading a value from
+// an io.Reader and decoding it with UnmarshalJSON.
+func CreateDecodeJSON(ic *Inception, si *StructInfo) error {
+	if _, ok := si.Typ.FieldByName("DecodeJSON"); ok {
+		return fmt.Errorf("%s: -stream can't be used on structs with a DecodeJSON field", si.Name)
+	}
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	ic.OutputImports[`"io"`] = true
+	out := ""
+
+	out += "// DecodeJSON reads a json value from r and decodes it into j - template\n"
+	out += `func (j *` + si.Name + `) DecodeJSON(r io.Reader) error {` + "\n"
+	out += `var buf fflib.Buffer` + "\n"
+	out += `err := fflib.ReadValue(r, &buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return err" + "\n"
+	out += `}` + "\n"
+	out += `return j.UnmarshalJSON(buf.Bytes())` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
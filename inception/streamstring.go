@@ -0,0 +1,42 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"io"
+	"reflect"
+)
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// getStreamStringValue writes the content of an io.Reader as a string,
+// reading it while writing. A nil reader is written as null.
+func getStreamStringValue(ic *Inception, name string) string {
+	ic.OutputImports[`fflib "github.com/maxproc/ffjson/fflib/v1"`] = true
+	out := ic.q.Flush()
+	out += "/* Streamed from an io.Reader */\n"
+	out += "if " + name + " == nil {" + "\n"
+	out += ic.q.WriteFlush("null")
+	out += "} else {" + "\n"
+	out += "err = fflib.WriteJsonReader(buf, " + name + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	out += "}" + "\n"
+	return out
+}
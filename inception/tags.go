@@ -59,6 +59,24 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
+// Value returns the value of a "name=value" option in a comma-separated
+// list of options, and whether the option was present at all.
+func (o tagOptions) Value(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var next string
+		i := strings.Index(s, ",")
+		if i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if strings.HasPrefix(s, optionName+"=") {
+			return s[len(optionName)+1:], true
+		}
+		s = next
+	}
+	return "", false
+}
+
 func isValidTag(s string) bool {
 	if s == "" {
 		return false
@@ -19,14 +19,21 @@ package ffjsoninception
 
 import (
 	"bytes"
+	"go/build/constraint"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
 	"text/template"
 )
 
 const ffjsonTemplate = `
-// Code generated by ffjson <https://github.com/maxproc/ffjson>. DO NOT EDIT.
-// source: {{.InputPath}}
+{{with .BuildConstraint}}{{.}}
 
+{{end}}// Code generated by ffjson <https://github.com/maxproc/ffjson>. DO NOT EDIT.
+// source: {{.InputPath}}
+{{with .SourceHash}}// ffjson-hash: {{.}}
+{{end}}
 package {{.PackageName}}
 
 import (
@@ -50,6 +57,29 @@ func RenderTemplate(ic *Inception) ([]byte, error) {
 	return format.Source(buf.Bytes())
 }
 
+// buildConstraint returns the //go:build and // +build lines of the Go
+// file at path, so the generated code is built exactly when the types it
+// uses are.
+func buildConstraint(path string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{}
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if constraint.IsGoBuild(c.Text) || constraint.IsPlusBuild(c.Text) {
+				lines = append(lines, c.Text)
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func tplStr(t *template.Template, data interface{}) string {
 	buf := bytes.Buffer{}
 	err := t.Execute(&buf, data)
@@ -0,0 +1,57 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maxproc/ffjson/shared"
+)
+
+// checkTinyGo returns an error if the code generated for si relies on
+// reflection, which TinyGo supports only partially. It must run after
+// all functions of si have been generated.
+func checkTinyGo(ic *Inception, si *StructInfo) error {
+	if len(ic.fallbacks) > 0 {
+		return fmt.Errorf("%s: type %v requires reflection, which is not supported with -target=tinygo", si.Name, ic.fallbacks[0])
+	}
+
+	if !si.Options.HasFeature(shared.MustDecoder) {
+		return nil
+	}
+	for _, f := range si.Fields {
+		for _, c := range f.Candidates {
+			if !candidateInInception(ic, strings.TrimPrefix(c, "*")) {
+				return fmt.Errorf("%s.%s: candidate type %s requires reflection, which is not supported with -target=tinygo", si.Name, f.Name, c)
+			}
+		}
+	}
+	return nil
+}
+
+// candidateInInception reports whether the named candidate type gets a
+// generated decoder.
+func candidateInInception(ic *Inception, name string) bool {
+	for _, v := range ic.objs {
+		if v.Name == name {
+			return v.Options.HasFeature(shared.MustDecoder)
+		}
+	}
+	return false
+}
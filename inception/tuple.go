@@ -0,0 +1,110 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"sort"
+)
+
+// prepareTuple checks that the fields of a struct with ffjson: tuple can
+// be written as the elements of an array: each field is always written,
+// at its position.
+func prepareTuple(ic *Inception, si *StructInfo) error {
+	switch {
+	case si.Options.Refs:
+		return fmt.Errorf("%s: tuple can't be combined with refs", si.Name)
+	case si.Options.KeepOrder:
+		return fmt.Errorf("%s: tuple can't be combined with keeporder", si.Name)
+	case si.Options.EnvelopeKey != "":
+		return fmt.Errorf("%s: tuple can't be combined with envelope", si.Name)
+	case si.Options.UnwrapType:
+		return fmt.Errorf("%s: tuple can't be combined with unwraptype", si.Name)
+	case si.Options.Form:
+		return fmt.Errorf("%s: tuple can't be combined with -form", si.Name)
+	case si.Options.RootDispatch:
+		return fmt.Errorf("%s: tuple can't be combined with -root-dispatch", si.Name)
+	case si.Options.Gate:
+		return fmt.Errorf("%s: tuple can't be combined with -gate, as encoding/json writes an object", si.Name)
+	case si.Options.ResetFields && ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: tuple can't be combined with resetfields", si.Name)
+	}
+	for _, f := range si.Fields {
+		switch {
+		case f.OmitEmpty:
+			return fmt.Errorf("%s.%s: omitempty can't be used in a tuple, as the fields after it would move", si.Name, f.Name)
+		case f.OmitZero:
+			return fmt.Errorf("%s.%s: omitzero can't be used in a tuple, as the fields after it would move", si.Name, f.Name)
+		case f.TriState:
+			return fmt.Errorf("%s.%s: tristate can't be used in a tuple", si.Name, f.Name)
+		case f.Group != "":
+			return fmt.Errorf("%s.%s: group can't be used in a tuple", si.Name, f.Name)
+		case len(f.Split) > 0:
+			return fmt.Errorf("%s.%s: split can't be used in a tuple", si.Name, f.Name)
+		case len(f.Embedded) > 0:
+			return fmt.Errorf("%s.%s: fields promoted through embedded pointers can't be used in a tuple", si.Name, f.Name)
+		}
+	}
+	// The elements are the fields of the struct, followed by the fields
+	// of embedded structs, level by level, unlike the members of objects.
+	sort.SliceStable(si.Fields, func(i, j int) bool {
+		return len(si.Fields[i].Index) < len(si.Fields[j].Index)
+	})
+	return nil
+}
+
+// getTupleArray writes fields as the elements of an array, in order.
+func getTupleArray(ic *Inception, fields []*StructField, prefix string) string {
+	out := ""
+
+	ic.q.Write("[")
+	for _, f := range fields {
+		if f.Pointer {
+			out += ic.q.Flush()
+			out += "if " + prefix + f.Name + " != nil {" + "\n"
+		}
+		out += getFieldStats(ic, f)
+		out += getFieldValue(ic, f, prefix)
+		if f.Pointer {
+			out += ic.q.Flush()
+			out += "} else {" + "\n"
+			out += ic.q.WriteFlush("null")
+			out += "}" + "\n"
+		}
+		ic.q.Write(",")
+	}
+	if len(fields) > 0 {
+		ic.q.DeleteLast()
+	}
+	ic.q.Write("]")
+	return out
+}
+
+// createTupleUnmarshal generates the decoder of a struct with ffjson:
+// tuple, reading the elements of an array into the fields in order.
+func createTupleUnmarshal(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`"fmt"`] = true
+
+	out := tplStr(decodeTpl["ujTuple"], ujFunc{
+		SI:          si,
+		IC:          ic,
+		ValidValues: validValues,
+	})
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
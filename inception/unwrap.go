@@ -0,0 +1,68 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// prepareUnwrapType checks that a root object wrapped in an object named
+// after si can't be mistaken for si itself.
+func prepareUnwrapType(ic *Inception, si *StructInfo) error {
+	switch {
+	case !ic.wantUnmarshal(si):
+		return fmt.Errorf("%s: unwraptype requires a decoder", si.Name)
+	case si.Options.EnvelopeKey != "":
+		return fmt.Errorf("%s: unwraptype can't be combined with an envelope", si.Name)
+	case si.Options.Refs:
+		return fmt.Errorf("%s: unwraptype can't be combined with refs", si.Name)
+	}
+
+	for _, f := range si.Fields {
+		var name string
+		err := json.Unmarshal([]byte(f.JsonName), &name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", si.Name, f.Name, err)
+		}
+		// Keys are matched to fields ignoring case.
+		if strings.EqualFold(name, si.Name) {
+			return fmt.Errorf("%s.%s: the json name %s can't be told apart from the unwrapped type name", si.Name, f.Name, f.JsonName)
+		}
+	}
+	return nil
+}
+
+// createUnwrapTypeUnmarshal generates the UnmarshalJSONFFLexer function
+// of a struct with unwraptype, unwrapping root objects with
+// fflib.Envelope.Unwrap before decoding them.
+func createUnwrapTypeUnmarshal(ic *Inception, si *StructInfo) {
+	out := ""
+	out += "var ffjUnwrap" + si.Name + " = &fflib.Envelope{Key: " + strconv.Quote(si.Name) + "}\n\n"
+
+	out += "// UnmarshalJSONFFLexer fast json unmarshall, unwrapping {\"" + si.Name + "\":{...}} - template ffjson\n"
+	out += `func (j *` + si.Name + `) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {` + "\n"
+	out += "return ffjUnwrap" + si.Name + ".Unwrap(fs, state, func(fs *fflib.FFLexer) error {\n"
+	out += "  return j.unmarshalJSONFFLexerPayload(fs, fflib.FFParse_want_key)\n"
+	out += "})\n"
+	out += "}\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
@@ -0,0 +1,83 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// prepareWrap checks that a struct declared with ffjson: wrap can have
+// the conversion methods of createWrapHelpers.
+func prepareWrap(ic *Inception, si *StructInfo) error {
+	if si.Typ.Kind() != reflect.Struct {
+		return fmt.Errorf("%s: wrap is only supported on structs, not %v", si.Name, si.Typ.Kind())
+	}
+	if _, ok := si.Typ.FieldByName("Unwrap"); ok {
+		return fmt.Errorf("%s: wrap can't be used on structs with an Unwrap field", si.Name)
+	}
+	return nil
+}
+
+// createWrapHelpers generates the conversions between a wrapper type and
+// the type of another package it is declared as.
+func createWrapHelpers(ic *Inception, si *StructInfo) {
+	ic.OutputImports[si.Options.WrapImport] = true
+	ic.OutputFuncs = append(ic.OutputFuncs, getWrapHelpers(si.Name, si.Options.Wrap))
+}
+
+// prepareInstantiation checks that a struct listed by ffjson:
+// instantiate can have the conversion methods of createInstantiation.
+func prepareInstantiation(ic *Inception, si *StructInfo) error {
+	if _, ok := si.Typ.FieldByName("Unwrap"); ok {
+		return fmt.Errorf("%s: instantiate can't be used on structs with an Unwrap field", si.Name)
+	}
+	return nil
+}
+
+// createInstantiation declares a struct listed by ffjson: instantiate as
+// the instantiation of the generic type, with the conversions of
+// createWrapHelpers.
+func createInstantiation(ic *Inception, si *StructInfo) {
+	for _, spec := range si.Options.InstantiateImports {
+		ic.OutputImports[spec] = true
+	}
+
+	out := ""
+	out += "// " + si.Name + " is " + si.Options.Instantiate + " with the generated methods, declared by ffjson: instantiate\n"
+	out += "type " + si.Name + " " + si.Options.Instantiate + "\n\n"
+	out += getWrapHelpers(si.Name, si.Options.Instantiate)
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
+
+// getWrapHelpers returns the conversions between the type name and the
+// type wrapped it is declared as.
+func getWrapHelpers(name string, wrapped string) string {
+	out := ""
+	out += "// Wrap" + name + " returns v as a *" + name + ", sharing its memory\n"
+	out += "func Wrap" + name + "(v *" + wrapped + ") *" + name + " {" + "\n"
+	out += "return (*" + name + ")(v)" + "\n"
+	out += "}" + "\n\n"
+
+	out += "// Unwrap returns j as the *" + wrapped + " it wraps, sharing its memory\n"
+	out += "func (j *" + name + ") Unwrap() *" + wrapped + " {" + "\n"
+	out += "return (*" + wrapped + ")(j)" + "\n"
+	out += "}" + "\n"
+	return out
+}
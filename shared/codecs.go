@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna, Klaus Post
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// Codec names the functions the generated code calls to encode and
+// decode a type, instead of its methods or encoding/json, as listed in
+// the file of -codecs.
+type Codec struct {
+	// Package is the import path of the functions, or empty for the
+	// package of the structs.
+	Package string `json:"package"`
+	// Marshal is called as Marshal(buf fflib.EncodingBuffer, v T) error
+	// to write the JSON of v.
+	Marshal string `json:"marshal"`
+	// Unmarshal is called as Unmarshal(data []byte, v *T) error with the
+	// JSON of a value other than null.
+	Unmarshal string `json:"unmarshal"`
+}
+
+// ParseCodecs parses the file of -codecs, a JSON object mapping types,
+// named by their package path and name like
+// "github.com/shopspring/decimal.Decimal", to their codecs.
+func ParseCodecs(data string) (map[string]Codec, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var codecs map[string]Codec
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&codecs)
+	if err != nil {
+		return nil, err
+	}
+	for name, c := range codecs {
+		dot := strings.LastIndex(name, ".")
+		if dot <= 0 || !token.IsIdentifier(name[dot+1:]) {
+			return nil, fmt.Errorf("codec of %q: types must be named by their package path and name, like \"example.com/pkg.Type\"", name)
+		}
+		if c.Marshal == "" && c.Unmarshal == "" {
+			return nil, fmt.Errorf("codec of %s: no marshal or unmarshal function", name)
+		}
+		for _, fn := range []string{c.Marshal, c.Unmarshal} {
+			if fn != "" && !token.IsIdentifier(fn) {
+				return nil, fmt.Errorf("codec of %s: invalid function name %q, the package is set with \"package\"", name, fn)
+			}
+		}
+	}
+	return codecs, nil
+}
@@ -20,11 +20,136 @@ package shared
 type StructOptions struct {
 	SkipDecoder bool
 	SkipEncoder bool
+	Canonical   bool
+	// Verbose generates encoders writing all fields, indented, when
+	// built with the ffjson_verbose tag.
+	Verbose bool
+	// RedactPattern redacts fields with a matching json name in
+	// MarshalJSONRedacted.
+	RedactPattern string
+	// Accessors generates GetField and SetField functions.
+	Accessors bool
+	// Schema generates JSONSchema functions.
+	Schema bool
+	// Patch generates JSONPatch functions.
+	Patch bool
+	// SSE generates MarshalSSE functions.
+	SSE bool
+	// JSONRPC generates MarshalJSONRPC and MarshalJSONRPCError functions
+	// writing JSON-RPC 2.0 responses.
+	JSONRPC bool
+	// Negotiate generates Marshal functions choosing the format from a
+	// content type. Unknown types are written as json with "json", and
+	// fail with "error"; empty means no function.
+	Negotiate string
+	// PoolMarshal makes MarshalJSON write into buffers reused from a
+	// sync.Pool, returning a copy of the json.
+	PoolMarshal bool
+	// Stream generates EncodeJSON and DecodeJSON methods writing to an
+	// io.Writer and reading from an io.Reader.
+	Stream bool
+	// NaNNull writes NaN and infinite floats as null, instead of failing
+	// the encoding with a *json.UnsupportedValueError.
+	NaNNull bool
+	// EncodeStats generates FooEncodeStats functions counting how often
+	// each field is written.
+	EncodeStats bool
+	// Gate generates MarshalJSON functions calling json.Marshal while
+	// fflib.MarshalGate selects encoding/json.
+	Gate bool
+	// Target restricts the generated code to what the named compiler
+	// supports. The only target is "tinygo"; empty means gc.
+	Target string
+	// Form generates UnmarshalForm functions decoding url.Values.
+	Form bool
+	// RootDispatch generates UnmarshalFooRoot functions decoding either
+	// an object or an array of objects.
+	RootDispatch bool
+	// ArrayPooled generates DecodeFooArrayPooled functions decoding the
+	// elements of an array into a single pooled struct.
+	ArrayPooled bool
+	// DisallowUnknownFields makes decoding fail on keys not matching a
+	// field, like json.Decoder.DisallowUnknownFields.
+	DisallowUnknownFields bool
+	// MarshalTo generates MarshalTo methods writing the json to an
+	// io.Writer from a pooled buffer.
+	MarshalTo bool
+	// GenTests writes fuzz targets and round-trip tests of the struct to
+	// the _test.go file of the output.
+	GenTests bool
+	// UseNumber makes decoding store numbers in interface{} values as
+	// json.Number instead of float64, like json.Decoder.UseNumber.
+	UseNumber bool
+	// ExactCase makes decoding match keys to the json names of fields only
+	// if they have the same case, instead of ignoring case like
+	// encoding/json.
+	ExactCase bool
+	// ResetFields makes decoding reset the fields missing in the JSON to
+	// their zero values.
+	ResetFields bool
+	// Refs writes pointers to structs with Refs shared by several
+	// fields once, and the other occurrences as JSON Pointer references.
+	Refs bool
+	// KeepOrder records the order of the keys decoded into the keyOrder
+	// field of the struct, and writes them in that order.
+	KeepOrder bool
+	// SortedMaps writes the keys of map fields in sorted order, as
+	// encoding/json does, instead of the random order of range.
+	SortedMaps bool
+	// Tuple writes the fields as the elements of a JSON array, in
+	// declaration order, and decodes them by position.
+	Tuple bool
+	// BufferSize is the typical size of the json of the struct, measured
+	// by -profile. MarshalJSON reserves room for it; 0 leaves the buffer
+	// to grow as needed.
+	BufferSize int
+	// UnwrapType decodes root objects wrapped in an object whose only
+	// member is named after the struct, as {"Foo":{...}} for a Foo.
+	UnwrapType bool
+	// NormalizeKeys makes the decoder match keys in Unicode NFC form.
+	NormalizeKeys bool
+	// Extractors generates a function per field decoding just its
+	// member of an object.
+	Extractors bool
+	// SkipJSON leaves out the JSON methods, when -formats doesn't list
+	// json, and Msgpack and CBOR generate the methods of these binary
+	// formats.
+	SkipJSON bool
+	Msgpack  bool
+	CBOR     bool
+	// EnvelopeKey is the member of the envelope object holding the
+	// struct's JSON, and EnvelopeFields a JSON object with the constant
+	// members emitted next to it. See README.md for the directive format.
+	EnvelopeKey    string
+	EnvelopeFields string
+	// Wrap is the type of another package the struct is declared as,
+	// like "other.Foo" for type FooJSON other.Foo, and WrapImport the
+	// import of its package.
+	Wrap       string
+	WrapImport string
+	// Instantiate is the instantiation of a generic struct, like
+	// "Page[User]", that ffjson: instantiate declares the struct as, and
+	// InstantiateImports the imports of the packages of its type
+	// arguments.
+	Instantiate        string
+	InstantiateImports []string
+	// Codecs is the JSON of the file of -codecs, listing the functions
+	// encoding and decoding types, see ParseCodecs.
+	Codecs string
+	// Implements names an interface of the package whose getters must
+	// be covered by fields of the struct.
+	Implements string
 }
 
 type InceptionType struct {
 	Obj     interface{}
 	Options StructOptions
+	// Name is the name of the struct when it isn't the name of the type
+	// of Obj, as for instantiations of generic types.
+	Name string
+	// Interface is a nil pointer to the interface named by
+	// Options.Implements, if any.
+	Interface interface{}
 }
 type Feature int
 
@@ -41,10 +166,10 @@ func (i InceptionType) HasFeature(f Feature) bool {
 
 func (s StructOptions) HasFeature(f Feature) bool {
 	hasNeeded := true
-	if f&MustDecoder != 0 && s.SkipDecoder {
+	if f&MustDecoder != 0 && (s.SkipDecoder || s.SkipJSON) {
 		hasNeeded = false
 	}
-	if f&MustEncoder != 0 && s.SkipEncoder {
+	if f&MustEncoder != 0 && (s.SkipEncoder || s.SkipJSON) {
 		hasNeeded = false
 	}
 	return hasNeeded
@@ -0,0 +1,55 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package shared
+
+// TriState records how a field tagged with `ffjson:"tristate"` appeared in
+// the JSON input: absent, explicitly null, or with a value. The state is
+// kept in a companion field named after the tagged field with a "State"
+// suffix, which must be excluded from JSON with `json:"-"`:
+//
+//	type Patch struct {
+//		Name      *string `json:"name" ffjson:"tristate"`
+//		NameState shared.TriState `json:"-"`
+//	}
+//
+// The generated decoder sets the state of every tristate field, and the
+// generated encoder uses it to omit the field, write null, or write the
+// value.
+type TriState uint8
+
+const (
+	// TriStateAbsent is the state of a field missing from the JSON object.
+	// It is the zero value, so fields of new structs are absent.
+	TriStateAbsent TriState = iota
+	// TriStateNull is the state of a field that was explicitly null.
+	TriStateNull
+	// TriStateSet is the state of a field with a value.
+	TriStateSet
+)
+
+func (s TriState) String() string {
+	switch s {
+	case TriStateAbsent:
+		return "absent"
+	case TriStateNull:
+		return "null"
+	case TriStateSet:
+		return "set"
+	}
+	return "invalid"
+}
@@ -0,0 +1,99 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/accessors/ff"
+)
+
+func TestGetField(t *testing.T) {
+	email := "a@example.com"
+	a := &ff.Account{ID: 7, Name: "a", Email: &email, Home: ff.Address{City: "Berlin"}, Untagged: 1.5}
+
+	v, ok := a.GetField("id")
+	if !ok || v.(int64) != 7 {
+		t.Fatalf("GetField(id): %v, %v", v, ok)
+	}
+	v, ok = a.GetField("email")
+	if !ok || v.(*string) != &email {
+		t.Fatalf("GetField(email): %v, %v", v, ok)
+	}
+	v, ok = a.GetField("home")
+	if !ok || v.(ff.Address).City != "Berlin" {
+		t.Fatalf("GetField(home): %v, %v", v, ok)
+	}
+	v, ok = a.GetField("Untagged")
+	if !ok || v.(float64) != 1.5 {
+		t.Fatalf("GetField(Untagged): %v, %v", v, ok)
+	}
+
+	for _, name := range []string{"Internal", "ID", "missing"} {
+		_, ok = a.GetField(name)
+		if ok {
+			t.Fatalf("GetField(%s): expected no field", name)
+		}
+	}
+}
+
+func TestSetField(t *testing.T) {
+	var a ff.Account
+	for name, value := range map[string]interface{}{
+		"id":      int64(7),
+		"name":    "a",
+		"roles":   []string{"admin"},
+		"limits":  map[string]int{"rps": 10},
+		"offices": []*ff.Address{{City: "Paris"}},
+		"extra":   3,
+	} {
+		err := a.SetField(name, value)
+		if err != nil {
+			t.Fatalf("SetField(%s): %v", name, err)
+		}
+	}
+	if a.ID != 7 || a.Name != "a" || a.Roles[0] != "admin" || a.Limits["rps"] != 10 || a.Offices[0].City != "Paris" || a.Extra != 3 {
+		t.Fatalf("Unexpected result: %+v", a)
+	}
+
+	pos := a.Pos
+	pos.Near = &ff.Address{City: "Rome"}
+	err := a.SetField("pos", pos)
+	if err != nil || a.Pos.Near.City != "Rome" {
+		t.Fatalf("SetField(pos): %v, %+v", err, a.Pos)
+	}
+
+	err = a.SetField("roles", nil)
+	if err != nil || a.Roles != nil {
+		t.Fatalf("SetField(roles, nil): %v, %v", err, a.Roles)
+	}
+
+	err = a.SetField("id", 7)
+	if err == nil || !strings.Contains(err.Error(), `of type int64 to int`) {
+		t.Fatalf("SetField(id, 7): expected type error, got %v", err)
+	}
+	err = a.SetField("name", nil)
+	if err == nil {
+		t.Fatalf("SetField(name, nil): expected type error")
+	}
+	err = a.SetField("missing", 1)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "missing"`) {
+		t.Fatalf("SetField(missing): expected unknown field error, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Address struct
+type Address struct {
+	City string `json:"city"`
+}
+
+// Account struct
+type Account struct {
+	ID      int64          `json:"id"`
+	Name    string         `json:"name"`
+	Email   *string        `json:"email,omitempty"`
+	Roles   []string       `json:"roles"`
+	Limits  map[string]int `json:"limits"`
+	Home    Address        `json:"home"`
+	Offices []*Address     `json:"offices"`
+	Extra   interface{}    `json:"extra"`
+	Pos     struct {
+		Lat  float64  `json:"lat"`
+		Near *Address `json:"near"`
+	} `json:"pos"`
+	Internal bool `json:"-"`
+	Untagged float64
+}
@@ -0,0 +1,269 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/binary/ff"
+)
+
+type binaryFormat struct {
+	name      string
+	marshal   func(r *ff.Record) ([]byte, error)
+	unmarshal func(r *ff.Record, data []byte) error
+	writer    func() interface {
+		fflib.BinaryWriter
+		Bytes() []byte
+	}
+}
+
+var binaryFormats = []binaryFormat{
+	{"msgpack", (*ff.Record).MarshalMsgpack, (*ff.Record).UnmarshalMsgpack, func() interface {
+		fflib.BinaryWriter
+		Bytes() []byte
+	} {
+		return fflib.NewMsgpackWriter(nil)
+	}},
+	{"cbor", (*ff.Record).MarshalCBOR, (*ff.Record).UnmarshalCBOR, func() interface {
+		fflib.BinaryWriter
+		Bytes() []byte
+	} {
+		return fflib.NewCBORWriter(nil)
+	}},
+}
+
+func newRecord() *ff.Record {
+	updated := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	r := &ff.Record{
+		Base:    &ff.Base{Version: 3},
+		ID:      -42,
+		Name:    "record",
+		Small:   -8,
+		Count:   65535,
+		Ratio:   0.5,
+		Score:   1.25,
+		Active:  true,
+		Tags:    []string{"a", "b"},
+		Data:    []byte{0, 1, 2},
+		Matrix:  [2][2]int{{1, 2}, {3, 4}},
+		Attrs:   map[string]int{"x": 1},
+		ByID:    map[int]string{-1: "minus", 7: "seven"},
+		Levels:  map[ff.Level]bool{2: true},
+		Level:   5,
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Updated: &updated,
+		Timeout: time.Second,
+		Child:   &ff.Item{Name: "child", Qty: 1},
+		Items:   []ff.Item{{Name: "i", Qty: 2}},
+		Lookup:  map[string]*ff.Item{"some": {Name: "s"}, "none": nil},
+		Any:     map[string]interface{}{"list": []interface{}{int64(1), "two", nil}},
+		Note:    "note",
+		Origin:  ff.Point{X: 1},
+		Extra:   map[string]string{"k": "v"},
+	}
+	r.Anon.X = 9
+	return r
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, f := range binaryFormats {
+		for _, in := range []*ff.Record{newRecord(), {}} {
+			data, err := f.marshal(in)
+			if err != nil {
+				t.Fatalf("%s: marshal: %v", f.name, err)
+			}
+			var out ff.Record
+			err = f.unmarshal(&out, data)
+			if err != nil {
+				t.Fatalf("%s: unmarshal: %v", f.name, err)
+			}
+			if !reflect.DeepEqual(&out, in) {
+				t.Errorf("%s:\nExpected: %+v\nGot: %+v", f.name, in, &out)
+			}
+		}
+	}
+}
+
+// The members are the ones of the JSON, with the values of the Go types.
+func TestBinaryKeys(t *testing.T) {
+	in := newRecord()
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON map[string]interface{}
+	err = json.Unmarshal(data, &fromJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = in.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := fflib.ReadBinaryValue(fflib.NewMsgpackReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromMsgpack := v.(map[string]interface{})
+	if len(fromMsgpack) != len(fromJSON) {
+		t.Errorf("got %d members, expected %d", len(fromMsgpack), len(fromJSON))
+	}
+	for k := range fromJSON {
+		if _, ok := fromMsgpack[k]; !ok {
+			t.Errorf("missing member %q", k)
+		}
+	}
+	if score := fromMsgpack["score"]; score != 1.25 {
+		t.Errorf("score: got %#v, expected a number", score)
+	}
+	if level := fromMsgpack["level"]; level != "L5" {
+		t.Errorf("level: got %#v, expected its text", level)
+	}
+}
+
+func TestBinaryEncoding(t *testing.T) {
+	item := &ff.Item{Name: "a", Qty: 1}
+	data, err := item.MarshalMsgpack()
+	if expected := "\x82\xa4name\xa1a\xa3qty\x01"; err != nil || string(data) != expected {
+		t.Errorf("msgpack: got %q, %v, expected %q", data, err, expected)
+	}
+	data, err = item.MarshalCBOR()
+	if expected := "\xa2\x64name\x61a\x63qty\x01"; err != nil || string(data) != expected {
+		t.Errorf("cbor: got %q, %v, expected %q", data, err, expected)
+	}
+}
+
+func TestBinaryUnmarshal(t *testing.T) {
+	for _, f := range binaryFormats {
+		w := f.writer()
+		w.WriteMapHeader(5)
+		// Unknown members are skipped.
+		w.WriteString("unknown")
+		w.WriteArrayHeader(1)
+		w.WriteMapHeader(0)
+		// nil leaves values unchanged, and sets pointers to nil.
+		w.WriteString("name")
+		w.WriteNil()
+		w.WriteString("child")
+		w.WriteNil()
+		// Integers are read into floats.
+		w.WriteString("score")
+		w.WriteInt(2)
+		// Extra elements of arrays are skipped.
+		w.WriteString("matrix")
+		w.WriteArrayHeader(3)
+		w.WriteArrayHeader(1)
+		w.WriteInt(5)
+		w.WriteNil()
+		w.WriteArrayHeader(0)
+
+		out := ff.Record{Name: "kept", Child: &ff.Item{}, Matrix: [2][2]int{{1, 1}, {1, 1}}}
+		err := f.unmarshal(&out, w.Bytes())
+		if err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		if out.Name != "kept" || out.Child != nil || out.Score != 2 || out.Matrix != [2][2]int{{5, 0}, {1, 1}} {
+			t.Errorf("%s: got %+v", f.name, out)
+		}
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	for _, f := range binaryFormats {
+		tests := []struct {
+			write    func(w fflib.BinaryWriter)
+			expected string
+		}{
+			{func(w fflib.BinaryWriter) {
+				w.WriteMapHeader(1)
+				w.WriteString("small")
+				w.WriteInt(300)
+			}, "integer 300 overflows int8"},
+			{func(w fflib.BinaryWriter) {
+				w.WriteMapHeader(1)
+				w.WriteString("count")
+				w.WriteInt(-1)
+			}, "negative integer"},
+			{func(w fflib.BinaryWriter) {
+				w.WriteMapHeader(1)
+				w.WriteString("name")
+				w.WriteInt(1)
+			}, "expected a string"},
+			{func(w fflib.BinaryWriter) {
+				w.WriteArrayHeader(0)
+			}, "expected a map"},
+			{func(w fflib.BinaryWriter) {
+				w.WriteMapHeader(0)
+				w.WriteMapHeader(0)
+			}, "unexpected data after the top-level value"},
+			{func(w fflib.BinaryWriter) {
+				w.WriteMapHeader(1)
+				w.WriteString("id")
+			}, "unexpected end of input"},
+		}
+
+		for _, test := range tests {
+			w := f.writer()
+			test.write(w)
+			var out ff.Record
+			err := f.unmarshal(&out, w.Bytes())
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("%s: got error %v, expected %q", f.name, err, test.expected)
+			}
+		}
+	}
+}
+
+func TestBinaryStrict(t *testing.T) {
+	defer fflib.SetDisallowUnknownFields(false)
+	fflib.SetDisallowUnknownFields(true)
+	var out ff.Item
+	err := out.UnmarshalMsgpack([]byte("\x81\xa3qtx\x01"))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "qtx"`) {
+		t.Errorf("got error %v, expected an unknown field", err)
+	}
+}
+
+func TestBinaryOnly(t *testing.T) {
+	var v interface{} = &ff.Vector{}
+	if _, ok := v.(json.Marshaler); ok {
+		t.Errorf("Vector has a MarshalJSON method without json in -formats")
+	}
+
+	in := ff.Vector{X: 1.5, Y: -2}
+	data, err := in.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out ff.Vector
+	err = out.UnmarshalMsgpack(data)
+	if err != nil || out != in {
+		t.Errorf("got %+v, %v, expected %+v", out, err, in)
+	}
+	if !bytes.HasPrefix(data, []byte{0x82, 0xa1, 'x', 0xcb}) {
+		t.Errorf("got %x", data)
+	}
+}
@@ -0,0 +1,80 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"fmt"
+	"time"
+)
+
+// Level is written as its text.
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("L%d", int(l))), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "L%d", (*int)(l))
+	return err
+}
+
+// Base is embedded through a pointer.
+type Base struct {
+	Version int `json:"version"`
+}
+
+type Item struct {
+	Name string `json:"name"`
+	Qty  int    `json:"qty"`
+}
+
+type Point struct {
+	X, Y int
+}
+
+// Record has fields of every kind the binary formats support.
+type Record struct {
+	*Base
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Small    int8              `json:"small"`
+	Count    uint16            `json:"count"`
+	Ratio    float32           `json:"ratio"`
+	Score    float64           `json:"score,string"`
+	Active   bool              `json:"active"`
+	Tags     []string          `json:"tags"`
+	Data     []byte            `json:"data"`
+	Matrix   [2][2]int         `json:"matrix"`
+	Attrs    map[string]int    `json:"attrs"`
+	ByID     map[int]string    `json:"by_id"`
+	Levels   map[Level]bool    `json:"levels"`
+	Level    Level             `json:"level"`
+	Created  time.Time         `json:"created"`
+	Updated  *time.Time        `json:"updated,omitempty"`
+	Timeout  time.Duration     `json:"timeout"`
+	Child    *Item             `json:"child"`
+	Items    []Item            `json:"items"`
+	Lookup   map[string]*Item  `json:"lookup"`
+	Any      interface{}       `json:"any"`
+	Anon     struct{ X int }   `json:"anon"`
+	Note     string            `json:"note,omitempty"`
+	Origin   Point             `json:"origin,omitzero"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Internal string            `json:"-"`
+}
@@ -0,0 +1,96 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/boolstring/ff"
+)
+
+func TestBoolStringUnmarshal(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected ff.Settings
+	}{
+		{`{"enabled":true,"strict":false,"quoted":true,"plain":true}`, ff.Settings{Enabled: true, Quoted: true, Plain: true}},
+		{`{"enabled":"true","strict":"true","quoted":"false"}`, ff.Settings{Enabled: true, Strict: true}},
+		{`{"enabled":"TRUE","quoted":"False"}`, ff.Settings{Enabled: true}},
+		{`{"enabled":"false","strict":true,"quoted":"tRuE"}`, ff.Settings{Strict: true, Quoted: true}},
+		{`{"enabled":null,"public":null}`, ff.Settings{}},
+	}
+	for _, test := range tests {
+		var s ff.Settings
+		err := s.UnmarshalJSON([]byte(test.in))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", test.in, err)
+		}
+		if s != test.expected {
+			t.Errorf("UnmarshalJSON(%s): Expected: %+v\nGot: %+v", test.in, test.expected, s)
+		}
+	}
+}
+
+func TestBoolStringPointer(t *testing.T) {
+	for _, in := range []string{`{"public":"True"}`, `{"public":true}`} {
+		var s ff.Settings
+		err := s.UnmarshalJSON([]byte(in))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", in, err)
+		}
+		if s.Public == nil || !*s.Public {
+			t.Errorf("UnmarshalJSON(%s): expected public to be true, got %v", in, s.Public)
+		}
+	}
+}
+
+func TestBoolStringInvalid(t *testing.T) {
+	for _, in := range []string{
+		`{"enabled":"yes"}`,
+		`{"enabled":""}`,
+		`{"enabled":1}`,
+		`{"strict":"True"}`,
+		`{"plain":"true"}`,
+	} {
+		var s ff.Settings
+		err := s.UnmarshalJSON([]byte(in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", in)
+		}
+	}
+
+	var s ff.Settings
+	err := s.UnmarshalJSON([]byte(`{"enabled":"yes"}`))
+	if err == nil || !strings.Contains(err.Error(), `cannot decode "yes" into a bool`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBoolStringMarshal(t *testing.T) {
+	public := true
+	s := &ff.Settings{Enabled: true, Public: &public, Quoted: true}
+	out, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"enabled":true,"public":true,"strict":false,"quoted":"true","plain":false}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
@@ -0,0 +1,30 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Toggle type
+type Toggle bool
+
+// Settings struct
+type Settings struct {
+	Enabled Toggle `json:"enabled" ffjson:"boolstring"`
+	Public  *bool  `json:"public" ffjson:"boolstring"`
+	Strict  bool   `json:"strict" ffjson:"boolstring=exact"`
+	Quoted  bool   `json:"quoted,string" ffjson:"boolstring"`
+	Plain   bool   `json:"plain"`
+}
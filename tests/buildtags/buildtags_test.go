@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/buildtags/ff"
+)
+
+// The package builds without the tag, as the generated code of
+// tagged.go has its build constraint.
+func TestUntagged(t *testing.T) {
+	out, err := json.Marshal(&ff.Plain{Name: "a"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"name":"a"}` {
+		t.Fatalf("Unexpected json: %s", out)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build ffjson_tagged
+
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/maxproc/ffjson/tests/buildtags/ff"
+	"github.com/maxproc/ffjson/tests/buildtags/gated"
+)
+
+func TestTagged(t *testing.T) {
+	out, err := (&ff.Tagged{ID: 1, Plain: ff.Plain{Name: "a"}}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `{"id":1,"plain":{"name":"a"}}` {
+		t.Fatalf("Unexpected json: %s", out)
+	}
+
+	var r gated.Record
+	err = r.UnmarshalJSON([]byte(`{"id":2}`))
+	if err != nil || r.ID != 2 {
+		t.Fatalf("Unexpected record %+v: %v", r, err)
+	}
+}
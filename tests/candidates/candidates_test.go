@@ -0,0 +1,96 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/candidates/ff"
+)
+
+func TestCandidates(t *testing.T) {
+	var d ff.Drawing
+	err := d.UnmarshalJSON([]byte(`{"main":{"width":2,"height":3},"shapes":["title",{"width":1},null,[1]]}`))
+	if err == nil {
+		t.Fatalf("Expected error for a shape no candidate accepts")
+	}
+	if !strings.Contains(err.Error(), "no candidate type accepted") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d = ff.Drawing{}
+	err = d.UnmarshalJSON([]byte(`{"main":"title","shapes":["a",{"width":1,"height":2},null]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if d.Main != ff.Label("title") {
+		t.Fatalf("Expected: title\nGot: %#v", d.Main)
+	}
+	expected := []ff.Shape{ff.Label("a"), &ff.Rect{Width: 1, Height: 2}, nil}
+	if !reflect.DeepEqual(d.Shapes, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, d.Shapes)
+	}
+
+	d = ff.Drawing{Main: ff.Label("x")}
+	err = d.UnmarshalJSON([]byte(`{"main":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if d.Main != nil {
+		t.Fatalf("Expected nil, got: %#v", d.Main)
+	}
+}
+
+func TestCandidatesOrder(t *testing.T) {
+	// Rect is listed before Circle, but doesn't know the radius key.
+	var d ff.Drawing
+	err := d.UnmarshalJSON([]byte(`{"shapes":[{"radius":2},{"width":1}]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := []ff.Shape{ff.Circle{Radius: 2}, &ff.Rect{Width: 1}}
+	if !reflect.DeepEqual(d.Shapes, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, d.Shapes)
+	}
+
+	// No candidate of main knows both keys.
+	d = ff.Drawing{}
+	err = d.UnmarshalJSON([]byte(`{"main":{"width":1,"radius":2}}`))
+	if err == nil {
+		t.Fatalf("Expected error for an object no candidate knows all keys of")
+	}
+}
+
+func TestCandidatesMarshal(t *testing.T) {
+	d := ff.Drawing{Main: &ff.Rect{Width: 1, Height: 2}, Shapes: []ff.Shape{ff.Circle{Radius: 1}, ff.Label("l")}}
+	out, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ff.Drawing
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+	}
+	if !reflect.DeepEqual(got.Main, d.Main) {
+		t.Fatalf("Expected: %#v\nGot: %#v", d.Main, got.Main)
+	}
+}
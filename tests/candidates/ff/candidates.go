@@ -0,0 +1,52 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Shape is implemented by all shapes.
+type Shape interface {
+	Area() float64
+}
+
+// Circle struct
+type Circle struct {
+	Radius float64 `json:"radius"`
+}
+
+// Area of the circle.
+func (c Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+// Rect struct
+type Rect struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Area of the rectangle.
+func (r *Rect) Area() float64 { return r.Width * r.Height }
+
+// Label is a shape given just by its name.
+type Label string
+
+// Area of a label.
+func (l Label) Area() float64 { return 0 }
+
+// Drawing struct
+type Drawing struct {
+	Main   Shape   `json:"main" ffjson:"candidates=*Rect|Label"`
+	Shapes []Shape `json:"shapes" ffjson:"candidates=Label|*Rect|Circle"`
+}
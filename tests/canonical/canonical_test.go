@@ -0,0 +1,79 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/canonical/ff"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	c := ff.Credential{
+		Subject: "alice",
+		Issuer:  "did:example",
+		Numbers: []float64{333333333.33333329, 1e30, 4.50, 2e-3, 0.000000000000000000000000001},
+		Claims:  map[string]string{"z": "\x1f", "a": "<b>", "\ufb33": "dalet", "\U0001F600": "grin"},
+		Nested:  &ff.Proof{Type: "Ed25519", Value: "€"},
+		Expires: 1500000000,
+		Umlaut:  "o",
+	}
+
+	buf, err := c.MarshalJSONCanonical()
+	if err != nil {
+		t.Fatalf("MarshalJSONCanonical: %v", err)
+	}
+	// Keys are sorted by UTF-16 code units, so U+1F600 (a surrogate pair)
+	// sorts before U+FB33 even though its UTF-8 encoding is larger.
+	if string(buf) != `{"b_expires":1500000000,`+
+		`"claims":{"a":"<b>","z":"\u001f","😀":"grin","דּ":"dalet"},"issuer":"did:example",`+
+		`"nested":{"type":"Ed25519","value":"€"},"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"subject":"alice","ö":"o"}` {
+		t.Fatalf("Expected canonical output, got: %s", string(buf))
+	}
+
+	// Canonical output must be stable across repeated calls.
+	for i := 0; i < 10; i++ {
+		again, err := c.MarshalJSONCanonical()
+		if err != nil {
+			t.Fatalf("MarshalJSONCanonical: %v", err)
+		}
+		if string(again) != string(buf) {
+			t.Fatalf("Expected: %s\n Got: %s", string(buf), string(again))
+		}
+	}
+}
+
+func TestMarshalCanonicalNil(t *testing.T) {
+	var c *ff.Credential
+	buf, err := c.MarshalJSONCanonical()
+	if err != nil {
+		t.Fatalf("MarshalJSONCanonical: %v", err)
+	}
+	if string(buf) != "null" {
+		t.Fatalf("Expected: null\n Got: %s", string(buf))
+	}
+}
+
+func TestMarshalCanonicalNaN(t *testing.T) {
+	c := ff.Credential{Numbers: []float64{math.Inf(1)}}
+	_, err := c.MarshalJSONCanonical()
+	if err == nil {
+		t.Fatalf("Expected error for non-finite number")
+	}
+}
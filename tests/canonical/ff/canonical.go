@@ -0,0 +1,35 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Credential struct
+type Credential struct {
+	Subject string            `json:"subject"`
+	Issuer  string            `json:"issuer"`
+	Numbers []float64         `json:"numbers"`
+	Claims  map[string]string `json:"claims"`
+	Nested  *Proof            `json:"nested,omitempty"`
+	Expires int64             `json:"b_expires"`
+	Umlaut  string            `json:"ö"`
+}
+
+// Proof struct
+type Proof struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,43 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package codec has the functions of codecs.json encoding and decoding
+// the types of money.
+package codec
+
+import (
+	"strconv"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	"github.com/maxproc/ffjson/tests/codecs/money"
+)
+
+// WriteAmount writes a as a JSON string, like "12.05".
+func WriteAmount(buf fflib.EncodingBuffer, a money.Amount) error {
+	fflib.WriteJsonString(buf, a.String())
+	return nil
+}
+
+// ReadAmount reads an amount written by WriteAmount.
+func ReadAmount(data []byte, a *money.Amount) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return err
+	}
+	*a, err = money.Parse(s)
+	return err
+}
@@ -0,0 +1,90 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/codecs/ff"
+	"github.com/maxproc/ffjson/tests/codecs/money"
+)
+
+func TestCodecsMarshal(t *testing.T) {
+	discount := money.Cents(-150)
+	inv := ff.Invoice{
+		Total:    money.Cents(1205),
+		Discount: &discount,
+		Lines:    []money.Amount{money.Cents(1000), money.Cents(355)},
+		Taxes:    map[string]money.Amount{"vat": money.Cents(201)},
+		Level:    3,
+	}
+	out, err := inv.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"total":"12.05","discount":"-1.50","lines":["10.00","3.55"],"taxes":{ "vat":"2.01"},"level":"L3"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestCodecsUnmarshal(t *testing.T) {
+	var inv ff.Invoice
+	err := inv.UnmarshalJSON([]byte(`{"total":"12.05","discount":"-1.50","lines":["10.00","3.55"],"taxes":{"vat":"2.01"},"level":"L3"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if inv.Total.Cents() != 1205 {
+		t.Fatalf("Expected total 1205, got %d", inv.Total.Cents())
+	}
+	if inv.Discount == nil || inv.Discount.Cents() != -150 {
+		t.Fatalf("Expected discount -150, got %v", inv.Discount)
+	}
+	if len(inv.Lines) != 2 || inv.Lines[0].Cents() != 1000 || inv.Lines[1].Cents() != 355 {
+		t.Fatalf("Unexpected lines %v", inv.Lines)
+	}
+	if inv.Taxes["vat"].Cents() != 201 {
+		t.Fatalf("Unexpected taxes %v", inv.Taxes)
+	}
+	if inv.Level != 3 {
+		t.Fatalf("Expected level 3, got %d", inv.Level)
+	}
+}
+
+func TestCodecsNull(t *testing.T) {
+	inv := ff.Invoice{Discount: new(money.Amount)}
+	err := inv.UnmarshalJSON([]byte(`{"total":"1.00","discount":null,"level":"L0"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if inv.Discount != nil {
+		t.Fatalf("Expected nil discount, got %v", inv.Discount)
+	}
+}
+
+func TestCodecsError(t *testing.T) {
+	var inv ff.Invoice
+	err := inv.UnmarshalJSON([]byte(`{"total":"12.5"}`))
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid amount")
+	}
+	err = inv.UnmarshalJSON([]byte(`{"level":3}`))
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid level")
+	}
+}
@@ -0,0 +1,53 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"strconv"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	"github.com/maxproc/ffjson/tests/codecs/money"
+)
+
+// Level is written as "L<n>" by the functions of this package.
+type Level int
+
+func writeLevel(buf fflib.EncodingBuffer, l Level) error {
+	buf.WriteString(`"L`)
+	fflib.WriteInt(buf, int64(l))
+	buf.WriteByte('"')
+	return nil
+}
+
+func readLevel(data []byte, l *Level) error {
+	if len(data) < 3 || data[0] != '"' || data[1] != 'L' || data[len(data)-1] != '"' {
+		return errors.New("invalid level " + string(data))
+	}
+	n, err := strconv.Atoi(string(data[2 : len(data)-1]))
+	*l = Level(n)
+	return err
+}
+
+type Invoice struct {
+	Total    money.Amount            `json:"total"`
+	Discount *money.Amount           `json:"discount,omitempty"`
+	Lines    []money.Amount          `json:"lines"`
+	Taxes    map[string]money.Amount `json:"taxes,omitempty"`
+	Level    Level                   `json:"level"`
+}
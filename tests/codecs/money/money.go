@@ -0,0 +1,74 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package money stands for a package of another module, with types the
+// generated code can't encode and which have no JSON methods.
+package money
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Amount is an amount of money in cents.
+type Amount struct {
+	cents int64
+}
+
+// Cents returns the amount of c cents.
+func Cents(c int64) Amount {
+	return Amount{cents: c}
+}
+
+// Cents returns the amount in cents.
+func (a Amount) Cents() int64 {
+	return a.cents
+}
+
+// String formats the amount like 12.05.
+func (a Amount) String() string {
+	c := a.cents
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return sign + strconv.FormatInt(c/100, 10) + "." + strconv.FormatInt(c%100/10, 10) + strconv.FormatInt(c%10, 10)
+}
+
+// Parse parses an amount formatted by String.
+func Parse(s string) (Amount, error) {
+	units, cents := s, "00"
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		units, cents = s[:dot], s[dot+1:]
+	}
+	if len(cents) != 2 {
+		return Amount{}, errors.New("money: invalid amount " + strconv.Quote(s))
+	}
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return Amount{}, errors.New("money: invalid amount " + strconv.Quote(s))
+	}
+	c, err := strconv.ParseUint(cents, 10, 8)
+	if err != nil {
+		return Amount{}, errors.New("money: invalid amount " + strconv.Quote(s))
+	}
+	if strings.HasPrefix(units, "-") {
+		return Amount{cents: u*100 - int64(c)}, nil
+	}
+	return Amount{cents: u*100 + int64(c)}, nil
+}
@@ -0,0 +1,225 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxproc/ffjson/ffjson"
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/decodeopts/ff"
+)
+
+const account = `{
+	"id": 12345678901234567,
+	"NAME": "ann",
+	"owner": {"Name": "bob", "email": "bob@example.com", "extra": 1},
+	"tags": ["a", "b"],
+	"meta": {"size": 12345678901234567, "deep": [[1]]},
+	"limits": {"x": 1},
+	"unknown": true
+}`
+
+var untrusted = &ffjson.DecodeOptions{
+	DisallowUnknownFields: true,
+	ExactCase:             true,
+	MaxDepth:              3,
+	MaxStringLength:       16,
+	UseNumber:             true,
+}
+
+func TestDefaults(t *testing.T) {
+	var a, b ff.Account
+	if err := a.UnmarshalJSON([]byte(account)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if err := b.UnmarshalJSONOpts([]byte(account), nil); err != nil {
+		t.Fatalf("UnmarshalJSONOpts: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("nil options: got %+v, expected %+v", b, a)
+	}
+	if err := b.UnmarshalJSONOpts([]byte(account), &ffjson.DecodeOptions{}); err != nil {
+		t.Fatalf("zero options: %v", err)
+	}
+	if a.Name != "ann" || a.Owner.Name != "bob" {
+		t.Fatalf("keys not folded: %+v %+v", a, a.Owner)
+	}
+	if _, ok := a.Meta.(map[string]interface{})["size"].(float64); !ok {
+		t.Fatalf("meta size is %T, expected float64", a.Meta.(map[string]interface{})["size"])
+	}
+}
+
+func TestExactCase(t *testing.T) {
+	var a ff.Account
+	err := a.UnmarshalJSONOpts([]byte(account), &ffjson.DecodeOptions{ExactCase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The owner is decoded with the options of the account.
+	if a.Name != "" || a.Owner.Name != "" || a.Owner.Email != "bob@example.com" {
+		t.Fatalf("folded keys matched: %+v %+v", a, a.Owner)
+	}
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	var a ff.Account
+	err := a.UnmarshalJSONOpts([]byte(`{"id":1,"owner":{"name":"bob","extra":1}}`), &ffjson.DecodeOptions{DisallowUnknownFields: true})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Fatalf("got %v, expected an unknown field error for the owner", err)
+	}
+	err = a.UnmarshalJSONOpts([]byte(`{"id":1,"unknown":true}`), &ffjson.DecodeOptions{DisallowUnknownFields: true})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "unknown"`) {
+		t.Fatalf("got %v, expected an unknown field error", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	check := func(input string, opts *ffjson.DecodeOptions, want error) {
+		t.Helper()
+		var a ff.Account
+		err := a.UnmarshalJSONOpts([]byte(input), opts)
+		if !errors.Is(err, want) {
+			t.Errorf("%s: got %v, expected %v", input, err, want)
+		}
+	}
+	depth := &ffjson.DecodeOptions{MaxDepth: 3}
+	check(`{"meta":[[1]]}`, depth, nil)
+	check(`{"meta":[[[1]]]}`, depth, fflib.ErrMaxDepth)
+	check(`{"tags":["a"],"owner":{"name":"x"}}`, depth, nil)
+	// Skipped values count too.
+	check(`{"unknown":[[[1]]]}`, depth, fflib.ErrMaxDepth)
+
+	length := &ffjson.DecodeOptions{MaxStringLength: 4}
+	check(`{"name":"abcd"}`, length, nil)
+	check(`{"name":"abcde"}`, length, fflib.ErrMaxStringLength)
+	check(`{"name":"éé"}`, length, nil)
+	check(`{"notes":{"abcde":"x"}}`, length, fflib.ErrMaxStringLength)
+	check(`{"meta":{"x":"abcde"}}`, length, fflib.ErrMaxStringLength)
+	check(`{"unknown":"abcde"}`, length, fflib.ErrMaxStringLength)
+	check(`{"id":12345678}`, length, nil)
+
+	var a ff.Account
+	err := a.UnmarshalJSONOpts([]byte(`{"name":"abcdefgh"}`), length)
+	if err == nil || !strings.Contains(err.Error(), "offset=") {
+		t.Fatalf("got %v, expected the offset of the string", err)
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	var a ff.Account
+	err := a.UnmarshalJSONOpts([]byte(account), &ffjson.DecodeOptions{UseNumber: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := a.Meta.(map[string]interface{})["size"]; n != json.Number("12345678901234567") {
+		t.Fatalf("meta size is %#v, expected a json.Number", n)
+	}
+
+	var p ff.Pair
+	err = p.UnmarshalJSONOpts([]byte(`["k", 12345678901234567]`), &ffjson.DecodeOptions{UseNumber: true})
+	if err != nil || p.Value != json.Number("12345678901234567") {
+		t.Fatalf("tuple: got %#v, %v", p.Value, err)
+	}
+}
+
+func TestInterfaceFields(t *testing.T) {
+	// Registered types and candidates are decoded from a copy of their
+	// value, with the options of the scene.
+	var sc ff.Scene
+	err := sc.UnmarshalJSONOpts([]byte(`{"event":{"type":"click","x":1,"bogus":2}}`), &ffjson.DecodeOptions{DisallowUnknownFields: true})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "bogus"`) {
+		t.Fatalf("got %v, expected an unknown field error for the event", err)
+	}
+
+	sc = ff.Scene{}
+	err = sc.UnmarshalJSONOpts([]byte(`{"event":{"type":"click","X":1,"extra":12345678901234567}}`), &ffjson.DecodeOptions{ExactCase: true, UseNumber: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := sc.Event.(*ff.Click)
+	if c.X != 0 || c.Extra != json.Number("12345678901234567") {
+		t.Fatalf("event decoded without the options: %+v", c)
+	}
+
+	sc = ff.Scene{}
+	err = sc.UnmarshalJSON([]byte(`{"shape":{"Radius":2}}`))
+	if err != nil || sc.Shape.(*ff.Circle).Radius != 2 {
+		t.Fatalf("got %#v, %v, expected a circle", sc.Shape, err)
+	}
+	sc = ff.Scene{}
+	err = sc.UnmarshalJSONOpts([]byte(`{"shape":{"Radius":2}}`), &ffjson.DecodeOptions{ExactCase: true})
+	if err == nil || !strings.Contains(err.Error(), "no candidate type accepted") {
+		t.Fatalf("got %#v, %v, expected no candidate to know Radius", sc.Shape, err)
+	}
+}
+
+func TestOptionsDontLoosen(t *testing.T) {
+	var p ff.Pinned
+	err := p.UnmarshalJSONOpts([]byte(`{"NAME":"x"}`), &ffjson.DecodeOptions{})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "NAME"`) {
+		t.Fatalf("got %v, expected the directives to apply", err)
+	}
+
+	fflib.SetDisallowUnknownFields(true)
+	defer fflib.SetDisallowUnknownFields(false)
+	var a ff.Account
+	if err := a.UnmarshalJSONOpts([]byte(`{"unknown":1}`), nil); err == nil {
+		t.Fatal("the global switch doesn't apply")
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+	dec := ffjson.NewDecoder()
+	dec.SetOptions(untrusted)
+	var a ff.Account
+	if err := dec.Decode([]byte(account), &a); err == nil {
+		t.Fatal("decoded untrusted input")
+	}
+	// The options apply to the reused lexer.
+	if err := dec.Decode([]byte(`{"id":1,"name":"ann"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode([]byte(`{"name":"abcdefghijklmnopq"}`), &a); !errors.Is(err, fflib.ErrMaxStringLength) {
+		t.Fatalf("got %v, expected %v", err, fflib.ErrMaxStringLength)
+	}
+	dec.SetOptions(nil)
+	if err := dec.Decode([]byte(account), &a); err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+
+	// The encoding/json fallback rejects unknown fields.
+	var plain struct{ ID int }
+	dec.SetOptions(untrusted)
+	if err := dec.Decode([]byte(`{"ID":1,"x":2}`), &plain); err == nil {
+		t.Fatal("fallback accepted an unknown field")
+	}
+
+	sd := ffjson.NewStreamDecoder(strings.NewReader(`{"id":1} {"id":2,"x":3}`))
+	sd.SetOptions(untrusted)
+	if err := sd.Decode(&a); err != nil || a.ID != 1 {
+		t.Fatalf("stream: got %d, %v", a.ID, err)
+	}
+	if err := sd.Decode(&a); err == nil {
+		t.Fatal("stream accepted an unknown field")
+	}
+}
@@ -0,0 +1,101 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/maxproc/ffjson/ffjson"
+)
+
+// Account is decoded with different options for trusted and untrusted
+// input.
+type Account struct {
+	ID     int64             `json:"id"`
+	Name   string            `json:"name"`
+	Owner  *Owner            `json:"owner"`
+	Tags   []string          `json:"tags"`
+	Meta   interface{}       `json:"meta"`
+	Limits map[string]int    `json:"limits"`
+	Notes  map[string]string `json:"notes,omitempty"`
+}
+
+// Owner is decoded by the decoder of Account with its options.
+type Owner struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Pinned is strict and exact case whatever the options.
+// ffjson: strict
+// ffjson: exactcase
+type Pinned struct {
+	Name string `json:"name"`
+}
+
+// Pair is a tuple, which has no keys to match.
+// ffjson: tuple
+type Pair struct {
+	Key   string
+	Value interface{}
+}
+
+// Shape is decoded from its candidate types.
+type Shape interface {
+	Area() float64
+}
+
+// Circle is a candidate of Shape.
+type Circle struct {
+	Radius float64 `json:"radius"`
+}
+
+// Area of the circle.
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+// Square is a candidate of Shape.
+type Square struct {
+	Side float64 `json:"side"`
+}
+
+// Area of the square.
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+// Event is decoded by the type registered for its type member.
+type Event interface {
+	Kind() string
+}
+
+// Click is registered as "click".
+type Click struct {
+	Type  string      `json:"type"`
+	X     int         `json:"x"`
+	Extra interface{} `json:"extra"`
+}
+
+// Kind of the event.
+func (c *Click) Kind() string { return c.Type }
+
+// Scene has interface fields decoded with their own lexers, which get
+// the options of the decoder of Scene.
+type Scene struct {
+	Shape Shape `json:"shape" ffjson:"candidates=*Circle|*Square"`
+	Event Event `json:"event" ffjson:"polymorphic=type"`
+}
+
+func init() {
+	ffjson.RegisterType("click", func() interface{} { return new(Click) })
+}
@@ -0,0 +1,62 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/dotimport/ff"
+	flags "github.com/maxproc/ffjson/tests/flags/ff"
+)
+
+func TestDotImportRoundTrip(t *testing.T) {
+	ends := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := &ff.Event{
+		At:       time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+		Duration: time.Hour,
+		Ends:     &ends,
+		File:     flags.File{Name: "a.sh", Mode: 5},
+		Perms:    []flags.Perm{1, 2},
+	}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"at":"2024-05-01T11:00:00Z","duration":3600000000000,"ends":"2024-05-01T12:00:00Z","file":{"name":"a.sh","mode":["read","exec"],"attrs":[]},"perms":"AQI="}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Event
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !got.At.Equal(e.At) || got.Duration != e.Duration || got.Ends == nil || !got.Ends.Equal(ends) ||
+		got.File != e.File || len(got.Perms) != 2 || got.Perms[1] != 2 {
+		t.Fatalf("Expected: %+v\nGot: %+v", e, &got)
+	}
+}
+
+func TestDotImportDerivedType(t *testing.T) {
+	var m interface{} = &ff.Moment{}
+	if _, ok := m.(interface{ MarshalJSON() ([]byte, error) }); ok {
+		t.Fatalf("expected no generated code for a type based on a dot-imported type")
+	}
+}
@@ -0,0 +1,38 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	. "time"
+
+	. "github.com/maxproc/ffjson/tests/flags/ff"
+)
+
+// Event struct
+type Event struct {
+	At       Time      `json:"at"`
+	Duration Duration  `json:"duration"`
+	Ends     *Time     `json:"ends,omitempty"`
+	File     File      `json:"file"`
+	Perms    []Perm    `json:"perms"`
+	Zone     *Location `json:"-"`
+}
+
+// Moment is a local type based on a dot-imported one, and has no
+// generated code.
+type Moment Time
@@ -0,0 +1,147 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/embedded/ff"
+)
+
+func newDocument() *ff.Document {
+	d := &ff.Document{
+		Title: "report",
+		Meta: ff.Meta{
+			ID:      7,
+			Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Note:    "hidden",
+		},
+		Owner: &ff.Owner{Owner: "ann", Team: &ff.Team{Team: "core"}},
+		Note:  "visible",
+		Extra: ff.Meta{ID: 8},
+	}
+	d.Labels.Label = "a"
+	d.Tags.Label = "b"
+	d.Labels.Kind = "untagged"
+	d.Tags.Kind = "tagged"
+	return d
+}
+
+// compact removes the spaces ffjson writes after the braces of objects
+// with conditional fields.
+func compact(t *testing.T, data []byte) string {
+	var buf bytes.Buffer
+	err := json.Compact(&buf, data)
+	if err != nil {
+		t.Fatalf("Compact %s: %v", data, err)
+	}
+	return buf.String()
+}
+
+func TestEmbeddedMarshal(t *testing.T) {
+	docs := []*ff.Document{
+		newDocument(),
+		{Owner: &ff.Owner{Owner: "bob", Email: "bob@example.com"}},
+		{Size: 3},
+	}
+	for _, d := range docs {
+		out, err := d.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected, err := json.Marshal((*ff.DocumentStd)(d))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if compact(t, out) != string(expected) {
+			t.Fatalf("Got %s, want %s", out, expected)
+		}
+	}
+}
+
+func TestEmbeddedMarshalOuter(t *testing.T) {
+	outers := []*ff.Outer{
+		{DocumentStd: (*ff.DocumentStd)(newDocument()), Title: "outer", Level: 2},
+		{Title: "empty"},
+	}
+	for _, o := range outers {
+		out, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected, err := json.Marshal((*ff.OuterStd)(o))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if compact(t, out) != string(expected) {
+			t.Fatalf("Got %s, want %s", out, expected)
+		}
+	}
+
+	for _, w := range []*ff.Wrapper{{}, {Inner: &ff.Inner{Value: 1}}} {
+		out, err := w.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected, err := json.Marshal((*ff.WrapperStd)(w))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if compact(t, out) != string(expected) {
+			t.Fatalf("Got %s, want %s", out, expected)
+		}
+	}
+}
+
+func TestEmbeddedUnmarshal(t *testing.T) {
+	data, err := json.Marshal((*ff.OuterStd)(&ff.Outer{DocumentStd: (*ff.DocumentStd)(newDocument()), Title: "outer", Level: 2}))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var got ff.Outer
+	err = got.UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %s: %v", data, err)
+	}
+	var expected ff.OuterStd
+	err = json.Unmarshal(data, &expected)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(&got, (*ff.Outer)(&expected)) {
+		t.Fatalf("Got %+v, want %+v", got, expected)
+	}
+	if got.DocumentStd == nil || got.Owner == nil || got.Team == nil || got.Team.Team != "core" {
+		t.Fatalf("Embedded pointers weren't allocated: %+v", got)
+	}
+}
+
+func TestEmbeddedUnmarshalAbsent(t *testing.T) {
+	var got ff.Outer
+	err := got.UnmarshalJSON([]byte(`{"title":"outer","level":1}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if got.DocumentStd != nil {
+		t.Fatalf("Expected a nil DocumentStd, got %+v", got.DocumentStd)
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Meta is embedded by value.
+type Meta struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// Owner is embedded by pointer, so its fields are left out when nil.
+type Owner struct {
+	Owner string `json:"owner"`
+	Email string `json:"email,omitempty"`
+	*Team
+}
+
+// Team is embedded by pointer in Owner, two levels down.
+type Team struct {
+	Team string `json:"team"`
+}
+
+// audit is unexported, but its exported fields are promoted.
+type audit struct {
+	Revision int `json:"revision"`
+}
+
+// Labels conflicts with Tags on the name Label at the same depth, so
+// Label isn't written at all, like encoding/json does.
+type Labels struct {
+	Label string
+	Kind  string
+}
+
+// Tags has the other Label and a tagged Kind, which wins over the
+// untagged Kind of Labels.
+type Tags struct {
+	Label string
+	Kind  string `json:"Kind"`
+}
+
+// Document has promoted fields of every kind.
+type Document struct {
+	Title string `json:"title"`
+	Meta
+	*Owner
+	audit
+	Labels
+	Tags
+	// Note hides Meta.Note, being less deep.
+	Note string `json:"note"`
+	// Named embeds with a json name aren't promoted.
+	Extra Meta `json:"extra"`
+	Size  int  `json:"size,omitempty"`
+}
+
+// Outer embeds the fields of Document by pointer, with a field hiding
+// its title. DocumentStd is embedded, as the methods of Document would
+// be promoted to OuterStd.
+type Outer struct {
+	*DocumentStd
+	Title string `json:"title"`
+	Level int    `json:"level"`
+}
+
+// Inner is only reachable through an untagged embedded pointer. It has
+// no methods, which WrapperStd would have promoted.
+// ffjson: skip
+type Inner struct {
+	Value int `json:"value"`
+}
+
+// Wrapper has nothing but an embedded pointer, so it is written as {}
+// when the pointer is nil.
+type Wrapper struct {
+	*Inner
+}
+
+// DocumentStd has the fields of Document, without its methods, so
+// encoding/json encodes it by reflection.
+// ffjson: skip
+type DocumentStd Document
+
+// OuterStd is Outer for encoding/json.
+// ffjson: skip
+type OuterStd Outer
+
+// WrapperStd is Wrapper for encoding/json.
+// ffjson: skip
+type WrapperStd Wrapper
@@ -0,0 +1,96 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/encodestats/ff"
+)
+
+func TestEncodeStats(t *testing.T) {
+	ff.ResetEventEncodeStats()
+	user := "ann"
+	events := []ff.Event{
+		{ID: 1},
+		{ID: 2, Note: "n", User: &user},
+		{ID: 3, Tags: []string{"a"}, City: "Oslo", At: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for i := range events {
+		_, err := events[i].MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+	}
+
+	expected := map[string]int64{
+		"":              3,
+		"id":            3,
+		"note":          1,
+		"user":          1,
+		"tags":          1,
+		"where.city":    1,
+		"where.country": 3,
+		"at_date":       1,
+		"at_time":       1,
+	}
+	got := ff.EventEncodeStats()
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected: %v\nGot: %v", expected, got)
+	}
+
+	ff.ResetEventEncodeStats()
+	for k, v := range ff.EventEncodeStats() {
+		if v != 0 {
+			t.Fatalf("%q is %d after a reset", k, v)
+		}
+	}
+
+	// A nil value isn't counted.
+	var nilEvent *ff.Event
+	nilEvent.MarshalJSON()
+	if n := ff.EventEncodeStats()[""]; n != 0 {
+		t.Fatalf("a nil value was counted: %d", n)
+	}
+}
+
+func TestEncodeStatsConcurrent(t *testing.T) {
+	ff.ResetEventEncodeStats()
+	const goroutines, encodes = 8, 1000
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := ff.Event{ID: 1, Note: "n"}
+			for j := 0; j < encodes; j++ {
+				e.MarshalJSON()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := ff.EventEncodeStats()
+	if stats[""] != goroutines*encodes || stats["note"] != goroutines*encodes || stats["user"] != 0 {
+		t.Fatalf("unexpected counts: %v", stats)
+	}
+}
@@ -0,0 +1,33 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Event has optional fields whose use is counted.
+type Event struct {
+	ID      int64     `json:"id"`
+	Note    string    `json:"note,omitempty"`
+	User    *string   `json:"user"`
+	Tags    []string  `json:"tags,omitempty"`
+	City    string    `json:"city,omitempty" ffjson:"group=where"`
+	Country string    `json:"country" ffjson:"group=where"`
+	At      time.Time `json:"at,omitempty" ffjson:"split=at_date|at_time"`
+}
@@ -0,0 +1,87 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/enum/ff"
+)
+
+func TestEnumKnown(t *testing.T) {
+	var o ff.Order
+	err := o.UnmarshalJSON([]byte(`{"status":"shipped","kept":"pending","carrier":"dhl","priority":"high"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.Order{Status: ff.StatusShipped, Kept: ff.StatusPending, Carrier: "dhl", Priority: "high"}
+	if o != expected {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, o)
+	}
+
+	out, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `{"status":"shipped","kept":"pending","carrier":"dhl","priority":"high"}` {
+		t.Fatalf("Got: %s", out)
+	}
+}
+
+func TestEnumUnknownFallback(t *testing.T) {
+	var o ff.Order
+	err := o.UnmarshalJSON([]byte(`{"status":"returned","kept":"lost","priority":"urgent"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.Order{Status: ff.StatusUnknown, Kept: ff.StatusUnknown, Priority: ff.PriorityDefault}
+	if o != expected {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, o)
+	}
+
+	err = o.UnmarshalJSON([]byte(`{"status":null}`))
+	if err != nil || o.Status != ff.StatusUnknown {
+		t.Fatalf("Expected null to leave the field unchanged, got %v %+v", err, o)
+	}
+}
+
+func TestEnumUnknownEncode(t *testing.T) {
+	o := ff.Order{Status: "returned", Kept: "lost", Carrier: "fedex", Priority: "urgent"}
+	out, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"status":"unknown","kept":"lost","carrier":"fedex","priority":"low"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestEnumInvalid(t *testing.T) {
+	var o ff.Order
+	err := o.UnmarshalJSON([]byte(`{"carrier":"fedex"}`))
+	if err == nil || !strings.Contains(err.Error(), `"fedex" is not a valid value for "carrier"`) {
+		t.Fatalf("Expected an invalid value error, got %v", err)
+	}
+
+	err = o.UnmarshalJSON([]byte(`{"status":1}`))
+	if err == nil {
+		t.Fatalf("Expected an error for a number")
+	}
+}
@@ -0,0 +1,39 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Status of an order.
+type Status string
+
+// Statuses of orders.
+const (
+	StatusPending Status = "pending"
+	StatusShipped Status = "shipped"
+	StatusUnknown Status = "unknown"
+)
+
+// Order struct
+type Order struct {
+	Status   Status `json:"status" ffjson:"enum=pending|shipped,unknown=StatusUnknown"`
+	Kept     Status `json:"kept" ffjson:"enum=pending|shipped,unknown=StatusUnknown,unknownencode=keep"`
+	Carrier  string `json:"carrier,omitempty" ffjson:"enum=ups|dhl"`
+	Priority string `json:"priority" ffjson:"enum=low|high,unknown=PriorityDefault"`
+}
+
+// PriorityDefault is the priority of orders with an unknown priority.
+const PriorityDefault = "low"
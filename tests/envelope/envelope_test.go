@@ -0,0 +1,123 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/envelope/ff"
+)
+
+func TestEnvelopeMarshal(t *testing.T) {
+	r := &ff.Response{ID: 7, Name: "seven", Inner: &ff.Inner{Values: []string{"a"}}}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"status":"ok","version":2,"data":{"id":7,"name":"seven","inner":{"values":["a"]}}}`
+	var compact bytes.Buffer
+	err = json.Compact(&compact, out)
+	if err != nil {
+		t.Fatalf("Compact(%s): %v", out, err)
+	}
+	if compact.String() != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, compact.String())
+	}
+
+	// encoding/json must see the same document.
+	out, err = json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var nilResponse *ff.Response
+	out, err = nilResponse.MarshalJSON()
+	if err != nil || string(out) != "null" {
+		t.Fatalf("Expected null, got %s (err: %v)", out, err)
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	r := ff.Response{ID: 1, Name: "one", Inner: &ff.Inner{Values: []string{"x", "y"}}}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ff.Response
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+	}
+	if got.ID != 1 || got.Name != "one" || got.Inner == nil || len(got.Inner.Values) != 2 {
+		t.Fatalf("Unexpected result: %+v", got)
+	}
+}
+
+func TestEnvelopeUnmarshal(t *testing.T) {
+	// Member order and formatting of the constants don't matter, and
+	// unknown members are skipped.
+	var r ff.Response
+	err := r.UnmarshalJSON([]byte(`{"data":{"id":3},"extra":[1,{"a":2}],"version":2.0,"status":"ok"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.ID != 3 {
+		t.Fatalf("Expected: 3\nGot: %v", r.ID)
+	}
+
+	r = ff.Response{ID: 5}
+	err = r.UnmarshalJSON([]byte(`{"status":"ok","version":2,"data":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.ID != 5 {
+		t.Fatalf("null payload modified destination: %+v", r)
+	}
+
+	err = r.UnmarshalJSON([]byte(`null`))
+	if err == nil {
+		t.Fatalf("Expected error for null envelope")
+	}
+}
+
+func TestEnvelopeUnmarshalInvalid(t *testing.T) {
+	for input, msg := range map[string]string{
+		`{"status":"error","version":2,"data":{}}`: `envelope field "status" must be "ok", but got "error"`,
+		`{"status":"ok","data":{}}`:                `envelope is missing fields: version`,
+		`{"status":"ok","version":2}`:              `envelope is missing fields: data`,
+		`{"status":"ok","version":2,"data":[]}`:    `wanted token`,
+		`{"status":"ok","version":2,"data":{},}`:   `wanted token`,
+		`{"id":1}`:                                 `envelope is missing fields: data, status, version`,
+	} {
+		var r ff.Response
+		err := r.UnmarshalJSON([]byte(input))
+		if err == nil {
+			t.Fatalf("UnmarshalJSON(%s): expected error", input)
+		}
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("UnmarshalJSON(%s): expected error containing %q, got: %v", input, msg, err)
+		}
+	}
+}
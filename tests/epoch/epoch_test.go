@@ -0,0 +1,111 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/epoch/ff"
+)
+
+func TestEpochMarshal(t *testing.T) {
+	ts := time.Date(2000, 1, 1, 0, 1, 40, 123456789, time.UTC)
+	r := ff.Reading{Taken: ts, Unix: ts, GPS: ts, Local: ts}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"taken":100,"unix":946684900123,"gps":630720100123456,"local":-631144699876543211,"received":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestEpochRoundTrip(t *testing.T) {
+	received := time.Date(1999, 12, 31, 23, 59, 59, 0, time.FixedZone("", -5*3600))
+	tests := []time.Time{
+		time.Date(2024, 2, 29, 12, 30, 45, 987654321, time.UTC),
+		time.Date(1975, 6, 1, 1, 2, 3, 4000, time.FixedZone("", 9*3600)),
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.FixedZone("", 2*3600)),
+	}
+
+	for _, ts := range tests {
+		r := ff.Reading{Taken: ts, Unix: ts, GPS: ts, Local: ts, Received: &received}
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON(%v): %v", ts, err)
+		}
+		var got ff.Reading
+		err = got.UnmarshalJSON(out)
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+		}
+
+		checks := []struct {
+			name string
+			got  time.Time
+			unit time.Duration
+		}{
+			{"taken", got.Taken, time.Second},
+			{"unix", got.Unix, time.Millisecond},
+			{"gps", got.GPS, time.Microsecond},
+			{"local", got.Local, time.Nanosecond},
+			{"received", *got.Received, time.Second},
+		}
+		for _, c := range checks {
+			want := ts.Truncate(c.unit)
+			if c.name == "received" {
+				want = received
+			}
+			if !c.got.Equal(want) || c.got.Location() != time.UTC {
+				t.Errorf("%s: expected %v, got %v", c.name, want, c.got)
+			}
+		}
+	}
+}
+
+func TestEpochDecode(t *testing.T) {
+	r := ff.Reading{Received: &time.Time{}}
+	err := r.UnmarshalJSON([]byte(`{"taken":-86400,"received":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if want := time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC); !r.Taken.Equal(want) {
+		t.Fatalf("Expected: %v\nGot: %v", want, r.Taken)
+	}
+	if r.Received != nil {
+		t.Fatalf("expected null to clear the pointer, got %v", r.Received)
+	}
+
+	for _, in := range []string{`{"taken":1.5}`, `{"taken":"1"}`, `{"taken":9223372036854775807}`} {
+		err = r.UnmarshalJSON([]byte(in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", in)
+		}
+	}
+}
+
+func TestEpochOutOfRange(t *testing.T) {
+	r := ff.Reading{Local: time.Date(2400, 1, 1, 0, 0, 0, 0, time.UTC)}
+	_, err := r.MarshalJSON()
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected an out of range error, got %v", err)
+	}
+}
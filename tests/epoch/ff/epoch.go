@@ -0,0 +1,31 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Reading holds timestamps relative to several epochs.
+type Reading struct {
+	Taken    time.Time  `json:"taken" ffjson:"epoch=2000-01-01,unit=s"`
+	Unix     time.Time  `json:"unix" ffjson:"epoch=1970-01-01,unit=ms"`
+	GPS      time.Time  `json:"gps" ffjson:"epoch=1980-01-06T00:00:00Z,unit=us"`
+	Local    time.Time  `json:"local" ffjson:"epoch=2020-01-01T00:00:00+02:00,unit=ns"`
+	Received *time.Time `json:"received" ffjson:"epoch=2000-01-01"`
+}
@@ -0,0 +1,56 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/errpath/ff"
+)
+
+func TestErrorPath(t *testing.T) {
+	tests := []struct {
+		input  string
+		path   string
+		line   int
+		column int
+	}{
+		{`{"id":1}`, "id", 1, 7},
+		{`{"items":[{"sku":"a"},{"sku":"b","owner":{"id":"x"}}]}`, "items[1].owner.id", 1, 50},
+		{"{\"byName\":{\n\"alice\":{\"owner\":{\"id\":true}}}}", "byName.alice.owner.id", 2, 27},
+		{`{"items":[{"sku":"a",]}`, "items[0]", 1, 22},
+		{`{"note":{"id":[]}}`, "note.id", 1, 17},
+		{`{"items":{}}`, "items", 1, 10},
+		{`[]`, "", 1, 1},
+		{"{\"items\":[{\"sku\":\"a\"},\n  {\"sku\":nul}]}", "items[1].sku", 2, 12},
+	}
+	for _, test := range tests {
+		var o ff.Order
+		err := o.UnmarshalJSON([]byte(test.input))
+		var lerr *fflib.LexerError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("UnmarshalJSON(%s): expected LexerError, got %v", test.input, err)
+		}
+		if lerr.Path() != test.path || lerr.Line() != test.line || lerr.Column() != test.column {
+			t.Errorf("UnmarshalJSON(%s): expected %s at %d:%d, got %s at %d:%d (%v)",
+				test.input, test.path, test.line, test.column, lerr.Path(), lerr.Line(), lerr.Column(), err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/maxproc/ffjson/ffjson"
+)
+
+// Order nests structs in slices, maps and pointers.
+type Order struct {
+	ID     string          `json:"id"`
+	Items  []Item          `json:"items"`
+	ByName map[string]Item `json:"byName"`
+	Note   Note            `json:"note"`
+}
+
+// Item is an element of Order.Items.
+type Item struct {
+	SKU   string `json:"sku"`
+	Owner *Owner `json:"owner"`
+}
+
+// Owner is pointed to by Item.
+type Owner struct {
+	ID int `json:"id"`
+}
+
+// Note decodes with its own UnmarshalJSON, from the captured value.
+//
+// ffjson: skip
+type Note struct {
+	Author Owner
+}
+
+func (n *Note) UnmarshalJSON(b []byte) error {
+	return ffjson.Unmarshal(b, &n.Author)
+}
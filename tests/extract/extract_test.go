@@ -0,0 +1,125 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/extract/ff"
+)
+
+const document = `{
+	"skipped": {"a": [1, 2, {"b": null}], "c": "}"},
+	"version": 3,
+	"id": 42,
+	"name": "report",
+	"tags": ["a", "b"],
+	"meta": {"owner": "ann", "size": 7},
+	"created": "2024-03-04T05:06:07Z",
+	"score": "1.5",
+	"count": 9,
+	"raw": {"any": [true]},
+	"attrs": {"x": 1, "y": 2},
+	"TITLE": "folded",
+	"title": "lower"
+}`
+
+func TestExtract(t *testing.T) {
+	var doc ff.Document
+	err := doc.UnmarshalJSON([]byte(document))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	data := []byte(document)
+	check := func(name string, got interface{}, err error, want interface{}) {
+		t.Helper()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", name, got, want)
+		}
+	}
+
+	version, err := ff.ExtractDocumentBaseVersion(data)
+	check("version", version, err, doc.Version)
+	id, err := ff.ExtractDocumentID(data)
+	check("id", id, err, doc.ID)
+	name, err := ff.ExtractDocumentName(data)
+	check("name", name, err, doc.Name)
+	tags, err := ff.ExtractDocumentTags(data)
+	check("tags", tags, err, doc.Tags)
+	meta, err := ff.ExtractDocumentMeta(data)
+	check("meta", meta, err, doc.Meta)
+	created, err := ff.ExtractDocumentCreated(data)
+	check("created", created, err, doc.Created)
+	score, err := ff.ExtractDocumentScore(data)
+	check("score", score, err, doc.Score)
+	count, err := ff.ExtractDocumentCount(data)
+	check("count", count, err, doc.Count)
+	raw, err := ff.ExtractDocumentRaw(data)
+	check("raw", raw, err, doc.Raw)
+	attrs, err := ff.ExtractDocumentAttrs(data)
+	check("attrs", attrs, err, doc.Attrs)
+	lower, err := ff.ExtractDocumentLower(data)
+	check("lower", lower, err, doc.Lower)
+	upper, err := ff.ExtractDocumentUpper(data)
+	check("upper", upper, err, doc.Upper)
+}
+
+func TestExtractMissing(t *testing.T) {
+	name, err := ff.ExtractDocumentName([]byte(`{"id": 1, "tags": null}`))
+	if err != nil || name != "" {
+		t.Errorf("got %q, %v, want the zero value", name, err)
+	}
+	meta, err := ff.ExtractDocumentMeta([]byte(`{}`))
+	if err != nil || meta != nil {
+		t.Errorf("got %v, %v, want nil", meta, err)
+	}
+	count, err := ff.ExtractDocumentCount([]byte(`{"count": null}`))
+	if err != nil || count != nil {
+		t.Errorf("got %v, %v, want nil", count, err)
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	for _, in := range []string{
+		`[]`,
+		`{"id": "x"}`,
+		`{"other": [1, }`,
+		`{"other" 1}`,
+		`{"other": 1 "id": 1}`,
+		`{"id"`,
+	} {
+		_, err := ff.ExtractDocumentID([]byte(in))
+		if err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestExtractFirstMember(t *testing.T) {
+	// Unlike the decoder, extractors stop at the first matching member.
+	id, err := ff.ExtractDocumentID([]byte(`{"id": 1, "id": 2}`))
+	if err != nil || id != 1 {
+		t.Errorf("got %d, %v, want 1", id, err)
+	}
+}
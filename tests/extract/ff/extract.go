@@ -0,0 +1,52 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Base is embedded in Document, its field is promoted.
+type Base struct {
+	Version int `json:"version"`
+}
+
+// Meta is decoded with its generated decoder.
+type Meta struct {
+	Owner string `json:"owner"`
+	Size  int    `json:"size"`
+}
+
+// Document gets an extractor for each of its fields.
+type Document struct {
+	Base
+	ID      int64           `json:"id"`
+	Name    string          `json:"name"`
+	Tags    []string        `json:"tags"`
+	Meta    *Meta           `json:"meta"`
+	Created time.Time       `json:"created"`
+	Score   float64         `json:"score,string"`
+	Count   *int            `json:"count"`
+	Raw     json.RawMessage `json:"raw"`
+	Attrs   map[string]int  `json:"attrs"`
+	// The keys only differ in case, an exact match wins, as in the decoder.
+	Lower string `json:"title"`
+	Upper string `json:"Title"`
+	Skip  string `json:"-"`
+}
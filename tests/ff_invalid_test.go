@@ -21,6 +21,7 @@ import (
 	fflib "github.com/maxproc/ffjson/fflib/v1"
 
 	_ "encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -188,3 +189,27 @@ func TestInvalidFloatGarbageAfterE(t *testing.T) {
 		`1ea`,
 		&Xfloat64{})
 }
+
+func TestInvalidPosition(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"{\n  \"X\": 12\n}", 2, 9},
+		{"{\n  \"X\": \"a\",\n  \"Y\" 1\n}", 3, 7},
+		{"{\n  \"X\": \"äö\" x\n}", 2, 13},
+		{"{\r\n\t\"X\":\r\n\ttru\r\n}", 3, 4},
+	} {
+		var x Xstring
+		err := x.UnmarshalJSON([]byte(tc.input))
+		var lerr *fflib.LexerError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("UnmarshalJSON(%q): expected LexerError, got %v", tc.input, err)
+		}
+		if lerr.Line() != tc.line || lerr.Column() != tc.column {
+			t.Fatalf("UnmarshalJSON(%q): expected error at %d:%d, got %d:%d (%v)",
+				tc.input, tc.line, tc.column, lerr.Line(), lerr.Column(), err)
+		}
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"testing"
@@ -205,6 +207,72 @@ func TestMarshalEncoderError(t *testing.T) {
 	require.Error(t, err, "excpected error from encoder on type that isn't fast")
 }
 
+// shortWriter accepts at most max bytes per call, reporting
+// io.ErrShortWrite for the rest, and fails once limit bytes are written.
+type shortWriter struct {
+	out      bytes.Buffer
+	max      int
+	limit    int
+	largest  int
+	failures int
+}
+
+var errWriterFull = errors.New("writer full")
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.largest {
+		w.largest = len(p)
+	}
+	if w.limit > 0 && w.out.Len() >= w.limit {
+		w.failures++
+		return 0, errWriterFull
+	}
+	if len(p) > w.max {
+		w.out.Write(p[:w.max])
+		return w.max, io.ErrShortWrite
+	}
+	return w.out.Write(p)
+}
+
+func TestMarshalEncoderFlushSize(t *testing.T) {
+	v := &Xslice{X: make([]int, 1000)}
+	for i := range v.X {
+		v.X[i] = i
+	}
+	expected, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	w := &shortWriter{max: 7}
+	enc := ffjson.NewEncoder(w)
+	enc.SetFlushSize(64)
+	err = enc.Encode(v)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), w.out.String())
+	require.True(t, w.largest < 128, "expected bounded writes, got one of %d bytes", w.largest)
+
+	w.out.Reset()
+	err = enc.Encode(v)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), w.out.String())
+}
+
+func TestMarshalEncoderFlushSizeError(t *testing.T) {
+	v := &Xslice{X: make([]int, 1000)}
+	w := &shortWriter{max: 1000, limit: 100}
+	enc := ffjson.NewEncoder(w)
+	enc.SetFlushSize(64)
+	err := enc.Encode(v)
+	require.Equal(t, errWriterFull, err)
+	require.Equal(t, 1, w.failures, "expected no writes after the first failure")
+	require.True(t, w.out.Len() < 200)
+
+	w.limit = 0
+	w.out.Reset()
+	err = enc.Encode(&Xslice{X: []int{1}})
+	require.NoError(t, err, "error did not clear as expected.")
+	require.Equal(t, `{"X":[1]}`, w.out.String())
+}
+
 func TestUnmarshalFaster(t *testing.T) {
 	buf := []byte(`{"id": 123213, "OriginID": 22, "meth": "GET"}`)
 	record := newLogFFRecord()
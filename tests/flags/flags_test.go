@@ -0,0 +1,67 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/flags/ff"
+)
+
+func TestFlagsMarshal(t *testing.T) {
+	f := &ff.File{Name: "a.sh", Mode: 5, Attrs: -1}
+	out, err := f.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"name":"a.sh","mode":["read","exec"],"attrs":["hidden","system"]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	f.Mode = 0x9
+	_, err = f.MarshalJSON()
+	if err == nil || !strings.Contains(err.Error(), "flag bit 3 has no name") {
+		t.Fatalf("MarshalJSON: expected unknown bit error, got %v", err)
+	}
+}
+
+func TestFlagsUnmarshal(t *testing.T) {
+	var f ff.File
+	err := f.UnmarshalJSON([]byte(`{"name":"a.sh","mode":["exec","write"],"attrs":["system","archive"]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if f.Mode != 6 || f.Attrs != 4 {
+		t.Fatalf("Unexpected result: %+v", f)
+	}
+
+	err = f.UnmarshalJSON([]byte(`{"mode":null}`))
+	if err != nil || f.Mode != 6 {
+		t.Fatalf("UnmarshalJSON(null): %v %+v", err, f)
+	}
+
+	for _, input := range []string{`{"mode":["archive"]}`, `{"mode":5}`, `{"mode":"read"}`} {
+		err = f.UnmarshalJSON([]byte(input))
+		if _, ok := err.(*fflib.LexerError); !ok {
+			t.Fatalf("UnmarshalJSON(%s): expected LexerError, got %v", input, err)
+		}
+	}
+}
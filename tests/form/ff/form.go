@@ -0,0 +1,40 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Level is a named numeric type.
+type Level uint8
+
+// Signup struct
+type Signup struct {
+	Name     string    `json:"name"`
+	Age      int       `json:"age"`
+	Level    Level     `json:"level"`
+	Score    *float64  `json:"score"`
+	Terms    bool      `json:"terms"`
+	Tags     []string  `json:"tag"`
+	Picks    []int16   `json:"pick"`
+	Birthday time.Time `json:"birthday"`
+	Nickname *string
+	Extra    map[string]string `json:"extra"`
+	Ignored  string            `json:"-"`
+}
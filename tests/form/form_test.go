@@ -0,0 +1,92 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/form/ff"
+)
+
+func TestUnmarshalForm(t *testing.T) {
+	values, err := url.ParseQuery("name=Ann&age=42&level=3&score=1.5&terms=on&tag=a&tag=b&pick=1&pick=&pick=-2" +
+		"&birthday=2020-01-02T03:04:05Z&Nickname=annie&extra=x&Ignored=x")
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+
+	var s ff.Signup
+	err = s.UnmarshalForm(values)
+	if err != nil {
+		t.Fatalf("UnmarshalForm: %v", err)
+	}
+
+	score := 1.5
+	nickname := "annie"
+	expected := ff.Signup{
+		Name:     "Ann",
+		Age:      42,
+		Level:    3,
+		Score:    &score,
+		Terms:    true,
+		Tags:     []string{"a", "b"},
+		Picks:    []int16{1, -2},
+		Birthday: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Nickname: &nickname,
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, s)
+	}
+}
+
+func TestUnmarshalFormMissing(t *testing.T) {
+	s := ff.Signup{Name: "keep", Age: 1, Tags: []string{"keep"}}
+	err := s.UnmarshalForm(url.Values{"age": {""}, "name": {""}, "terms": {"false"}})
+	if err != nil {
+		t.Fatalf("UnmarshalForm: %v", err)
+	}
+	// Empty non-string values are ignored, missing keys leave fields untouched.
+	if s.Name != "" || s.Age != 1 || s.Terms || len(s.Tags) != 1 || s.Score != nil {
+		t.Fatalf("Unexpected result: %+v", s)
+	}
+}
+
+func TestUnmarshalFormErrors(t *testing.T) {
+	for _, values := range []url.Values{
+		{"age": {"x"}},
+		{"level": {"256"}},
+		{"terms": {"maybe"}},
+		{"pick": {"1", "40000"}},
+		{"birthday": {"yesterday"}},
+	} {
+		var s ff.Signup
+		err := s.UnmarshalForm(values)
+		if err == nil {
+			t.Fatalf("UnmarshalForm(%v): expected error", values)
+		}
+		for k := range values {
+			if !strings.Contains(err.Error(), `form field "`+k+`"`) {
+				t.Fatalf("UnmarshalForm(%v): unexpected error: %v", values, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/gate/ff"
+)
+
+func TestGateMarshal(t *testing.T) {
+	o := &ff.Order{ID: 1234567, Items: []ff.Item{{SKU: "a", Count: 2}}, Secret: "x"}
+
+	out, err := json.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	expected := `{"id":"1,234,567","items":[{"sku":"a","count":2}]}`
+	if string(out) != expected {
+		t.Fatalf("ffjson\nExpected: %v\nGot: %v", expected, string(out))
+	}
+
+	fflib.MarshalGate.SetStdlib(true)
+	defer fflib.MarshalGate.SetStdlib(false)
+
+	out, err = json.Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	// encoding/json ignores the ffjson tag.
+	expected = `{"id":"1234567","items":[{"sku":"a","count":2}]}`
+	if string(out) != expected {
+		t.Fatalf("encoding/json\nExpected: %v\nGot: %v", expected, string(out))
+	}
+
+	var nilOrder *ff.Order
+	out, err = nilOrder.MarshalJSON()
+	if err != nil || string(out) != "null" {
+		t.Fatalf("nil MarshalJSON: %s, %v", out, err)
+	}
+}
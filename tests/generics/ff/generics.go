@@ -0,0 +1,68 @@
+//go:build go1.18
+
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import "time"
+
+// Page is generic, so ffjson skips it, and generates code for the types
+// instantiating it.
+type Page[T any] struct {
+	Items []T     `json:"items"`
+	Next  *string `json:"next,omitempty"`
+}
+
+// Pair has two type parameters.
+// ffjson: instantiate=Pair[int, time.Time]
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// Item isn't generic, and is generated as usual.
+type Item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type IntPage Page[int]
+
+type ItemPage Page[Item]
+
+type Count Pair[string, int]
+
+// Alias can't have methods, so ffjson skips it too.
+type Alias = Page[string]
+
+// Outer has fields of instantiations of generic types, which fall back
+// to encoding/json.
+type Outer struct {
+	P  Page[int]           `json:"p"`
+	PP *Pair[string, Item] `json:"pp"`
+}
+
+// Result gets the types ResultItem and ResultTime declared by ffjson,
+// which sort their maps as the directives apply to each of them.
+// ffjson: sortedmaps
+// ffjson: instantiate=Result[Item], Result[*time.Time]
+type Result[T any] struct {
+	Data  map[string]T `json:"data"`
+	Error string       `json:"error,omitempty"`
+	At    *time.Time   `json:"at,omitempty"`
+}
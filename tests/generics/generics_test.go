@@ -0,0 +1,147 @@
+//go:build go1.18
+
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/generics/ff"
+)
+
+func TestInstantiatedTypes(t *testing.T) {
+	next := "b"
+	tests := []struct {
+		v        interface{ MarshalJSON() ([]byte, error) }
+		expected string
+	}{
+		{&ff.IntPage{Items: []int{1, 2}, Next: &next}, `{ "items":[1,2],"next":"b"}`},
+		{&ff.ItemPage{Items: []ff.Item{{ID: 1, Name: "a"}}}, `{ "items":[{"id":1,"name":"a"}]}`},
+		{&ff.Count{Key: "a", Value: 3}, `{"key":"a","value":3}`},
+		{&ff.Item{ID: 2}, `{"id":2,"name":""}`},
+	}
+
+	for _, test := range tests {
+		out, err := test.v.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != test.expected {
+			t.Errorf("Expected: %s\nGot: %s", test.expected, out)
+		}
+	}
+}
+
+func TestGenericFields(t *testing.T) {
+	o := &ff.Outer{P: ff.Page[int]{Items: []int{1}}, PP: &ff.Pair[string, ff.Item]{Key: "a", Value: ff.Item{ID: 1}}}
+	out, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"p":{"items":[1]},"pp":{"key":"a","value":{"id":1,"name":""}}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	var o2 ff.Outer
+	err = o2.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if len(o2.P.Items) != 1 || o2.PP == nil || o2.PP.Value.ID != 1 {
+		t.Fatalf("Unexpected value: %+v", o2)
+	}
+}
+
+func TestInstantiatedTypesDecode(t *testing.T) {
+	var p ff.ItemPage
+	err := p.UnmarshalJSON([]byte(`{"items":[{"id":1,"name":"a"},{"id":2}],"next":"c"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	// The declared type converts to the instantiation.
+	page := ff.Page[ff.Item](p)
+	if len(page.Items) != 2 || page.Items[1].ID != 2 || page.Next == nil || *page.Next != "c" {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+
+	var c ff.Count
+	err = c.UnmarshalJSON([]byte(`{"key":"x","value":"y"}`))
+	if err == nil {
+		t.Fatalf("Expected an error for a string value, got %+v", c)
+	}
+}
+
+func TestInstantiateDirective(t *testing.T) {
+	when := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	p := ff.PairIntTime{Key: 7, Value: when}
+	out, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"key":7,"value":"2024-05-01T12:00:00Z"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	var pair ff.Pair[int, time.Time]
+	err = ff.WrapPairIntTime(&pair).UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if pair.Key != 7 || !pair.Value.Equal(when) {
+		t.Fatalf("Unexpected pair: %+v", pair)
+	}
+}
+
+func TestInstantiateDirectives(t *testing.T) {
+	r := ff.Result[ff.Item]{Data: map[string]ff.Item{"c": {ID: 3}, "a": {ID: 1}, "b": {ID: 2}}}
+	out, err := ff.WrapResultItem(&r).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	// Maps of structs are written sorted, like encoding/json does.
+	expected := `{ "data":{ "a":{"id":1,"name":""},"b":{"id":2,"name":""},"c":{"id":3,"name":""}}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	when := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rt := ff.ResultTime{Data: map[string]*time.Time{"z": &when, "y": nil, "x": &when}, Error: "partial"}
+	for i := 0; i < 10; i++ {
+		out, err = rt.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected = `{ "data":{ "x":"2024-05-01T00:00:00Z","y":null,"z":"2024-05-01T00:00:00Z"},"error":"partial"}`
+		if string(out) != expected {
+			t.Fatalf("Expected: %s\nGot: %s", expected, out)
+		}
+	}
+
+	var back ff.ResultTime
+	err = back.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if len(back.Data) != 3 || back.Data["y"] != nil || !back.Data["x"].Equal(when) || back.Unwrap().Error != "partial" {
+		t.Fatalf("Unexpected result: %+v", back)
+	}
+}
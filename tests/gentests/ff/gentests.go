@@ -0,0 +1,64 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Order gets random round-trip tests, and a check against encoding/json.
+type Order struct {
+	ID       int64             `json:"id"`
+	Customer string            `json:"customer,omitempty"`
+	Paid     bool              `json:"paid"`
+	Total    float64           `json:"total"`
+	Tags     []string          `json:"tags"`
+	Lines    []Line            `json:"lines,omitempty"`
+	Meta     map[string]string `json:"meta"`
+	Parent   *Line             `json:"parent"`
+	Skipped  int               `json:"-"`
+	Codes    [2]uint16         `json:"codes"`
+}
+
+// Line is a struct of the package nested in Order.
+type Line struct {
+	SKU   string  `json:"sku"`
+	Count int     `json:"count,string"`
+	Price float32 `json:"price"`
+}
+
+// Event has a time.Time, which testing/quick can't generate, so it is
+// only tested from its zero value and by fuzzing.
+type Event struct {
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+}
+
+// Reading has ffjson options, so it isn't compared with encoding/json.
+type Reading struct {
+	Taken time.Time `json:"taken" ffjson:"format=unix"`
+	Value float64   `json:"value" ffjson:"scale=2"`
+}
+
+// Point is written as a tuple.
+//
+// ffjson: tuple
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
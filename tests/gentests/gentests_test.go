@@ -0,0 +1,77 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedTests checks which tests -gen-tests wrote for each struct
+// of ff. The tests themselves run with the ff package.
+func TestGeneratedTests(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "ff/gentests_ffjson_test.go", nil, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	decls := map[string]string{}
+	for _, d := range f.Decls {
+		var b strings.Builder
+		printer.Fprint(&b, fset, d)
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			decls[d.Name.Name] = b.String()
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				if ts, ok := s.(*ast.TypeSpec); ok {
+					decls[ts.Name.Name] = b.String()
+				}
+			}
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		std    bool
+		random bool
+	}{
+		{"Order", true, true},
+		{"Line", true, true},
+		{"Event", true, false},
+		{"Reading", false, false},
+		{"Point", false, false},
+	} {
+		for _, fn := range []string{"Fuzz" + test.name, "Test" + test.name + "RoundTrip", "ffjTestCheck" + test.name} {
+			if _, ok := decls[fn]; !ok {
+				t.Errorf("%s: missing %s", test.name, fn)
+			}
+		}
+		if _, std := decls["ffjTestStd"+test.name]; std != test.std {
+			t.Errorf("%s: expected a comparison with encoding/json: %v", test.name, test.std)
+		}
+		random := strings.Contains(decls["Test"+test.name+"RoundTrip"], "quick.Value")
+		if random != test.random {
+			t.Errorf("%s: expected random values: %v", test.name, test.random)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Config struct
+type Config struct {
+	Name     string `json:"name"`
+	Host     string `json:"host" ffjson:"group=database"`
+	Port     int    `json:"port,omitempty" ffjson:"group=database"`
+	Debug    bool   `json:"debug"`
+	MaxConns int    `json:"max" ffjson:"group=database.pool"`
+	Idle     *int   `json:"idle,omitempty" ffjson:"group=database.pool"`
+	User     string `json:"user" ffjson:"group=database"`
+	CacheTTL int    `json:"ttl,omitempty" ffjson:"group=cache"`
+	Addr     string `json:"addr" ffjson:"group=cache"`
+}
+
+// Optional struct
+type Optional struct {
+	A string `json:"a,omitempty" ffjson:"group=g"`
+	B string `json:"b,omitempty" ffjson:"group=g.h"`
+}
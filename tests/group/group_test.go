@@ -0,0 +1,113 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/group/ff"
+)
+
+func TestGroupMarshal(t *testing.T) {
+	idle := 2
+	c := &ff.Config{
+		Name:     "app",
+		Host:     "db.local",
+		Port:     5432,
+		Debug:    true,
+		MaxConns: 10,
+		Idle:     &idle,
+		User:     "admin",
+		Addr:     "cache.local",
+	}
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"name":"app","database":{"host":"db.local","port":5432,"pool":{ "max":10,"idle":2},"user":"admin"},` +
+		`"debug":true,"cache":{"addr":"cache.local"}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Config
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(&got, c) {
+		t.Fatalf("Expected: %+v\nGot: %+v", c, &got)
+	}
+}
+
+func TestGroupEmpty(t *testing.T) {
+	out, err := (&ff.Optional{}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"g":{"h":{}}}`
+	if strings.Replace(string(out), " ", "", -1) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	out, err = (&ff.Optional{B: "b"}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected = `{"g":{"h":{"b":"b"}}}`
+	if strings.Replace(string(out), " ", "", -1) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestGroupUnmarshal(t *testing.T) {
+	var c ff.Config
+	err := c.UnmarshalJSON([]byte(`{"cache":{"ttl":30,"unknown":1},"database":null,"name":"x","host":"ignored",` +
+		`"database":{"pool":{"max":3},"user":"u"}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.Config{Name: "x", MaxConns: 3, User: "u", CacheTTL: 30}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, c)
+	}
+
+	err = c.UnmarshalJSON([]byte(`{"database":"db.local"}`))
+	if err == nil || !strings.Contains(err.Error(), "into Go value for database") {
+		t.Fatalf("Expected an error for a group that isn't an object, got %v", err)
+	}
+}
+
+func TestGroupEncodingJSON(t *testing.T) {
+	c := ff.Config{Name: "app", Host: "h", Port: 1, MaxConns: 2, CacheTTL: 3}
+	out, err := json.Marshal(&c)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var got ff.Config
+	err = json.Unmarshal(out, &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("Expected: %+v\nGot: %+v", c, got)
+	}
+}
@@ -0,0 +1,29 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Stats is displayed by a UI.
+type Stats struct {
+	Visits   int64    `json:"visits,string" ffjson:"grouped"`
+	Bytes    uint64   `json:"bytes,string" ffjson:"grouped=space"`
+	Revenue  float64  `json:"revenue,string" ffjson:"grouped=dot"`
+	Ratio    float32  `json:"ratio,string" ffjson:"grouped=apostrophe"`
+	Balance  *int32   `json:"balance,string" ffjson:"grouped"`
+	Plain    int      `json:"plain,string"`
+	Optional *float64 `json:"optional,string,omitempty" ffjson:"grouped=underscore"`
+}
@@ -0,0 +1,105 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/grouped/ff"
+)
+
+func TestGroupedMarshal(t *testing.T) {
+	balance := int32(-1234)
+	s := ff.Stats{
+		Visits:  1234567,
+		Bytes:   math.MaxUint64,
+		Revenue: 9876543.21,
+		Ratio:   1000.5,
+		Balance: &balance,
+		Plain:   1234,
+	}
+	out, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "visits":"1,234,567","bytes":"18 446 744 073 709 551 615","revenue":"9.876.543,21",` +
+		`"ratio":"1'000.5","balance":"-1,234","plain":"1234"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestGroupedRoundTrip(t *testing.T) {
+	balance := int32(math.MinInt32)
+	optional := 12345678.125
+	tests := []ff.Stats{
+		{},
+		{Visits: math.MaxInt64, Bytes: 1000, Revenue: -0.5, Ratio: 999, Balance: &balance, Optional: &optional},
+		{Visits: math.MinInt64, Revenue: 1e21, Ratio: -1234567},
+	}
+
+	for _, s := range tests {
+		out, err := s.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON(%+v): %v", s, err)
+		}
+		var got ff.Stats
+		err = got.UnmarshalJSON(out)
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+		}
+		if !reflect.DeepEqual(got, s) {
+			t.Fatalf("Expected: %+v\nGot: %+v\nFrom: %s", s, got, out)
+		}
+	}
+}
+
+func TestGroupedDecode(t *testing.T) {
+	var s ff.Stats
+	err := s.UnmarshalJSON([]byte(`{"visits":"12,34,5678","bytes":"123","revenue":"1.000","optional":"1_000.25","balance":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if s.Visits != 12345678 || s.Bytes != 123 || s.Revenue != 1000 || *s.Optional != 1000.25 || s.Balance != nil {
+		t.Fatalf("unexpected values: %+v", s)
+	}
+
+	for _, in := range []string{
+		`{"visits":1234}`,
+		`{"visits":"1,,234"}`,
+		`{"visits":"1.5"}`,
+		`{"bytes":"-1"}`,
+		`{"revenue":"1,000.5"}`,
+		`{"balance":"3,000,000,000"}`,
+	} {
+		err := s.UnmarshalJSON([]byte(in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", in)
+		}
+	}
+}
+
+func TestGroupedNaN(t *testing.T) {
+	s := ff.Stats{Revenue: math.NaN()}
+	_, err := s.MarshalJSON()
+	if err == nil {
+		t.Fatalf("expected an error for NaN")
+	}
+}
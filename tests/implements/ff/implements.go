@@ -0,0 +1,51 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Contract is the data contract of an account.
+type Contract interface {
+	GetID() int64
+	Name() string
+	Tags() []string
+	Summary(verbose bool) string
+}
+
+// Account implements Contract.
+// ffjson: implements Contract
+type Account struct {
+	ID       int64    `json:"id"`
+	FullName string   `json:"name"`
+	Labels   []string `json:"tags,omitempty"`
+	Note     string   `json:"note"`
+}
+
+func (a *Account) GetID() int64 {
+	return a.ID
+}
+
+func (a *Account) Name() string {
+	return a.FullName
+}
+
+func (a *Account) Tags() []string {
+	return a.Labels
+}
+
+func (a *Account) Summary(verbose bool) string {
+	return a.FullName
+}
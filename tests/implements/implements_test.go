@@ -0,0 +1,46 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/implements/ff"
+)
+
+func TestImplementsRoundTrip(t *testing.T) {
+	var c ff.Contract = &ff.Account{ID: 7, FullName: "Ann", Labels: []string{"admin"}}
+	out, err := c.(*ff.Account).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"id":7,"name":"Ann","tags":["admin"],"note":""}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Account
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	c = &got
+	if c.GetID() != 7 || c.Name() != "Ann" || len(c.Tags()) != 1 || c.Tags()[0] != "admin" {
+		t.Fatalf("unexpected getters: %v %v %v", c.GetID(), c.Name(), c.Tags())
+	}
+}
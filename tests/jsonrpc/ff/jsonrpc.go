@@ -0,0 +1,30 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Balance is the result of a method call.
+type Balance struct {
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+// Detail is the data of a method error.
+type Detail struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason,omitempty"`
+}
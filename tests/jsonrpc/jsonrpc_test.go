@@ -0,0 +1,110 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/jsonrpc/ff"
+)
+
+func TestMarshalJSONRPC(t *testing.T) {
+	// The id of a request decoded by encoding/json.
+	var req struct {
+		ID interface{} `json:"id"`
+	}
+	err := json.Unmarshal([]byte(`{"id":42}`), &req)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		b        *ff.Balance
+		id       interface{}
+		expected string
+	}{
+		{&ff.Balance{Account: "a", Amount: 5}, 1, `{"jsonrpc":"2.0","result":{"account":"a","amount":5},"id":1}`},
+		{&ff.Balance{Account: "b"}, "abc", `{"jsonrpc":"2.0","result":{"account":"b","amount":0},"id":"abc"}`},
+		{&ff.Balance{}, req.ID, `{"jsonrpc":"2.0","result":{"account":"","amount":0},"id":42}`},
+		{nil, json.RawMessage(`"r-1"`), `{"jsonrpc":"2.0","result":null,"id":"r-1"}`},
+	}
+
+	for _, test := range tests {
+		out, err := test.b.MarshalJSONRPC(test.id)
+		if err != nil {
+			t.Errorf("MarshalJSONRPC(%#v): %v", test.id, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("MarshalJSONRPC(%#v)\nExpected: %s\nGot: %s", test.id, test.expected, out)
+		}
+	}
+}
+
+func TestMarshalJSONRPCError(t *testing.T) {
+	d := &ff.Detail{Field: "amount", Reason: "negative"}
+	out, err := d.MarshalJSONRPCError(3, fflib.JSONRPCInvalidParams, "Invalid params")
+	if err != nil {
+		t.Fatalf("MarshalJSONRPCError: %v", err)
+	}
+	expected := `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":{ "field":"amount","reason":"negative"}},"id":3}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	d = nil
+	out, err = d.MarshalJSONRPCError(nil, fflib.JSONRPCInternalError, "Internal error")
+	if err != nil {
+		t.Fatalf("MarshalJSONRPCError: %v", err)
+	}
+	expected = `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	// The response is valid JSON, read back by encoding/json.
+	var resp struct {
+		Version string `json:"jsonrpc"`
+		Error   struct {
+			Code int `json:"code"`
+		} `json:"error"`
+		ID interface{} `json:"id"`
+	}
+	err = json.Unmarshal(out, &resp)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.Version != "2.0" || resp.Error.Code != fflib.JSONRPCInternalError || resp.ID != nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestMarshalJSONRPCInvalidID(t *testing.T) {
+	b := &ff.Balance{}
+	_, err := b.MarshalJSONRPC(true)
+	if err == nil {
+		t.Fatalf("expected an error for a bool id")
+	}
+	d := &ff.Detail{}
+	_, err = d.MarshalJSONRPCError([]string{"x"}, fflib.JSONRPCInternalError, "")
+	if err == nil {
+		t.Fatalf("expected an error for a slice id")
+	}
+}
@@ -0,0 +1,98 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/keeporder/ff"
+)
+
+func TestKeepOrderRoundTrip(t *testing.T) {
+	in := `{"servers":{"b":"x"},"debug":true,"name":"app","Port":80}`
+	var c ff.Config
+	err := c.UnmarshalJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if c.Name != "app" || c.Port != 80 || !c.Debug {
+		t.Fatalf("unexpected config: %+v", c)
+	}
+
+	c.Port = 8080
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "servers":{ "b":"x"},"debug":true,"name":"app","port":8080}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestKeepOrderNewFields(t *testing.T) {
+	var c ff.Config
+	err := c.UnmarshalJSON([]byte(`{"port":1,"name":"a","unknown":2,"port":3}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	limit := 5
+	c.Limit = &limit
+	c.Tags = []string{"t"}
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "port":3,"name":"a","tags":["t"],"servers":null,"limit":5}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestKeepOrderDecodeResets(t *testing.T) {
+	var c ff.Config
+	err := c.UnmarshalJSON([]byte(`{"port":1,"name":"a"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	err = c.UnmarshalJSON([]byte(`{"name":"b"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "name":"b","port":1,"servers":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestKeepOrderUnordered(t *testing.T) {
+	c := ff.Config{Name: "a", Port: 2}
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "name":"a","port":2,"servers":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
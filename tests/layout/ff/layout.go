@@ -0,0 +1,31 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Record holds times written with custom layouts, and one without.
+type Record struct {
+	Created  time.Time   `json:"created" ffjson:"layout=2006-01-02 15:04:05"`
+	Deleted  *time.Time  `json:"deleted" ffjson:"layout=2006-01-02 15:04:05"`
+	Days     []time.Time `json:"days" ffjson:"layout=02/01/2006"`
+	Zoned    time.Time   `json:"zoned" ffjson:"layout=Jan _2 15:04:05.000 -0700 \"MST\""`
+	Standard time.Time   `json:"standard"`
+}
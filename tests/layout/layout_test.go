@@ -0,0 +1,125 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/layout/ff"
+)
+
+func TestLayoutMarshal(t *testing.T) {
+	ts := time.Date(2024, 2, 29, 13, 4, 5, 678000000, time.FixedZone("CET", 3600))
+	r := ff.Record{
+		Created:  ts,
+		Deleted:  &ts,
+		Days:     []time.Time{ts, ts.AddDate(0, 0, 1)},
+		Zoned:    ts,
+		Standard: ts,
+	}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"created":"2024-02-29 13:04:05","deleted":"2024-02-29 13:04:05","days":["29/02/2024","01/03/2024"],` +
+		`"zoned":"Feb 29 13:04:05.678 +0100 \"CET\"","standard":"2024-02-29T13:04:05.678+01:00"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	out, err = (&ff.Record{}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected = `{"created":"0001-01-01 00:00:00","deleted":null,"days":null,` +
+		`"zoned":"Jan  1 00:00:00.000 +0000 \"UTC\"","standard":"0001-01-01T00:00:00Z"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestLayoutUnmarshal(t *testing.T) {
+	var r ff.Record
+	err := r.UnmarshalJSON([]byte(`{"created":"2024-02-29 13:04:05","deleted":"1999-12-31 23:59:59",` +
+		`"days":["29/02/2024",null],"standard":"2024-02-29T13:04:05+01:00"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	created := time.Date(2024, 2, 29, 13, 4, 5, 0, time.UTC)
+	deleted := time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC)
+	if !r.Created.Equal(created) || r.Deleted == nil || !r.Deleted.Equal(deleted) {
+		t.Fatalf("Unexpected times: %v, %v", r.Created, r.Deleted)
+	}
+	days := []time.Time{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), {}}
+	if !reflect.DeepEqual(r.Days, days) {
+		t.Fatalf("Expected: %v\nGot: %v", days, r.Days)
+	}
+	if !r.Standard.Equal(created.Add(-time.Hour)) {
+		t.Fatalf("Unexpected standard time: %v", r.Standard)
+	}
+
+	err = r.UnmarshalJSON([]byte(`{"deleted":null,"days":null,"created":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Deleted != nil || r.Days != nil || !r.Created.Equal(created) {
+		t.Fatalf("Unexpected values after null: %+v", r)
+	}
+}
+
+func TestLayoutRoundTrip(t *testing.T) {
+	ts := time.Date(2010, 7, 8, 9, 10, 11, 0, time.UTC)
+	r := ff.Record{Created: ts, Deleted: &ts, Days: []time.Time{ts.Truncate(24 * time.Hour)}, Standard: ts}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	var got ff.Record
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+	}
+	out2, err := json.Marshal(&got)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != string(out2) {
+		t.Fatalf("Expected: %s\nGot: %s", out, out2)
+	}
+}
+
+func TestLayoutInvalid(t *testing.T) {
+	for _, input := range []string{
+		`{"created":"2024-02-29T13:04:05Z"}`,
+		`{"created":1}`,
+		`{"deleted":"2024-02-30 00:00:00"}`,
+		`{"days":"29/02/2024"}`,
+		`{"days":["2024-02-29"]}`,
+		`{"days":[1]}`,
+	} {
+		var r ff.Record
+		err := r.UnmarshalJSON([]byte(input))
+		if err == nil || !strings.HasPrefix(err.Error(), "ffjson error:") {
+			t.Errorf("%s: expected an ffjson error, got %v", input, err)
+		}
+	}
+}
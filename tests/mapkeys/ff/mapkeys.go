@@ -0,0 +1,88 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Level is an integer map key.
+type Level int
+
+// ID is a map key written as text.
+type ID [4]byte
+
+// MarshalText writes the id as hex.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+// UnmarshalText reads the id from hex.
+func (id *ID) UnmarshalText(text []byte) error {
+	if hex.DecodedLen(len(text)) != len(id) {
+		return errors.New("invalid id " + string(text))
+	}
+	_, err := hex.Decode(id[:], text)
+	return err
+}
+
+// Point is a struct map key written as "x,y".
+type Point struct {
+	X, Y int
+}
+
+// MarshalText writes the point as x,y.
+func (p Point) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+// UnmarshalText reads the point from x,y.
+func (p *Point) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+// Inner is a map value of non-string keys.
+type Inner struct {
+	Name string `json:"name"`
+}
+
+// Keys has maps with keys that aren't strings.
+type Keys struct {
+	Ints    map[int]string      `json:"ints"`
+	Int8s   map[int8]int        `json:"int8s"`
+	Uints   map[uint64]bool     `json:"uints"`
+	Levels  map[Level]float64   `json:"levels"`
+	IDs     map[ID]int          `json:"ids"`
+	Points  map[Point]string    `json:"points"`
+	Inners  map[Level]Inner     `json:"inners"`
+	Ptrs    map[uint16]*Inner   `json:"ptrs"`
+	Strings map[string]string   `json:"strings,omitempty"`
+	Nested  map[int]map[ID]bool `json:"nested,omitempty"`
+	Lists   map[Level][]string  `json:"lists,omitempty"`
+	Anon    map[ID]struct {
+		N int `json:"n"`
+	} `json:"anon,omitempty"`
+}
+
+// KeysStd has the fields of Keys, without its methods, so encoding/json
+// encodes it by reflection.
+// ffjson: skip
+type KeysStd Keys
@@ -0,0 +1,119 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/mapkeys/ff"
+)
+
+func newKeys() *ff.Keys {
+	return &ff.Keys{
+		Ints:    map[int]string{10: "ten", 9: "nine", -1: "minus one", 0: "zero"},
+		Int8s:   map[int8]int{-128: 1, 127: 2},
+		Uints:   map[uint64]bool{18446744073709551615: true, 2: false},
+		Levels:  map[ff.Level]float64{3: 0.5, 1: 1.5},
+		IDs:     map[ff.ID]int{{0xde, 0xad, 0xbe, 0xef}: 1, {0, 0, 0, 1}: 2},
+		Points:  map[ff.Point]string{{X: 1, Y: -2}: "a", {X: 0, Y: 0}: "origin"},
+		Inners:  map[ff.Level]ff.Inner{2: {Name: "two"}},
+		Ptrs:    map[uint16]*ff.Inner{7: {Name: "seven"}, 8: nil},
+		Strings: map[string]string{"a": "b"},
+		Nested:  map[int]map[ff.ID]bool{5: {{1, 2, 3, 4}: true}},
+		Lists:   map[ff.Level][]string{1: {"a", "b"}, 2: nil},
+		Anon: map[ff.ID]struct {
+			N int `json:"n"`
+		}{{9, 9, 9, 9}: {N: 9}},
+	}
+}
+
+func TestMapKeysMarshal(t *testing.T) {
+	k := newKeys()
+	// Like encoding/json, keys are sorted by their text, so -1 < 0 < 10 < 9.
+	out, err := k.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, err := json.Marshal((*ff.KeysStd)(k))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var compact bytes.Buffer
+	err = json.Compact(&compact, out)
+	if err != nil {
+		t.Fatalf("Compact %s: %v", out, err)
+	}
+	if compact.String() != string(expected) {
+		t.Fatalf("Got %s, want %s", out, expected)
+	}
+	if !strings.Contains(string(out), `"ints":{ "-1":"minus one","0":"zero","10":"ten","9":"nine"}`) {
+		t.Fatalf("Unsorted integer keys in %s", out)
+	}
+}
+
+func TestMapKeysEmpty(t *testing.T) {
+	k := &ff.Keys{Ints: map[int]string{}, IDs: map[ff.ID]int{}}
+	out, err := k.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if !strings.Contains(string(out), `"ints":{}`) || !strings.Contains(string(out), `"ids":{}`) || !strings.Contains(string(out), `"int8s":null`) {
+		t.Fatalf("Unexpected output %s", out)
+	}
+}
+
+func TestMapKeysRoundTrip(t *testing.T) {
+	k := newKeys()
+	data, err := json.Marshal((*ff.KeysStd)(k))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var got ff.Keys
+	err = got.UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(&got, k) {
+		t.Fatalf("Got %+v, want %+v", got, *k)
+	}
+}
+
+func TestMapKeysInvalid(t *testing.T) {
+	tests := []string{
+		`{"ints":{"x":"a"}}`,
+		`{"int8s":{"128":1}}`,
+		`{"uints":{"-1":true}}`,
+		`{"ids":{"zz":1}}`,
+		`{"points":{"1":"a"}}`,
+	}
+	for _, input := range tests {
+		var k ff.Keys
+		err := k.UnmarshalJSON([]byte(input))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s) succeeded: %+v", input, k)
+		}
+		var std ff.KeysStd
+		if json.Unmarshal([]byte(input), &std) == nil {
+			t.Errorf("json.Unmarshal(%s) succeeded: %+v", input, std)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package types
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/maxproc/ffjson/ffjson"
+	ff "github.com/maxproc/ffjson/tests/marshalto/ff"
+)
+
+func event() *ff.Event {
+	return &ff.Event{ID: 42, Kind: "click", Score: 0.5, Tags: []string{"a", "b"}}
+}
+
+func TestMarshalTo(t *testing.T) {
+	for _, e := range []*ff.Event{event(), {}, nil} {
+		expected, err := e.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		var w bytes.Buffer
+		if err := e.MarshalTo(&w); err != nil {
+			t.Fatalf("MarshalTo: %v", err)
+		}
+		if w.String() != string(expected) {
+			t.Errorf("MarshalTo\nExpected: %s\nGot: %s", expected, w.String())
+		}
+	}
+}
+
+type errWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestMarshalToWriteError(t *testing.T) {
+	if err := event().MarshalTo(errWriter{}); err != errWrite {
+		t.Fatalf("Expected the error of the writer, got %v", err)
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	e := event()
+	expected, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	dst := []byte("prefix:")
+	dst, err = ffjson.MarshalAppend(dst, e)
+	if err != nil {
+		t.Fatalf("MarshalAppend: %v", err)
+	}
+	if string(dst) != "prefix:"+string(expected) {
+		t.Errorf("MarshalAppend = %s", dst)
+	}
+
+	// Types without ffjson code use encoding/json.
+	dst, err = ffjson.MarshalAppend(dst[:0], map[string]int{"a": 1})
+	if err != nil || string(dst) != `{"a":1}` {
+		t.Errorf("MarshalAppend = %s, %v", dst, err)
+	}
+}
+
+func TestMarshalAppendAllocs(t *testing.T) {
+	e := event()
+	dst := make([]byte, 0, 1024)
+	appended := testing.AllocsPerRun(100, func() {
+		dst, _ = ffjson.MarshalAppend(dst[:0], e)
+	})
+	if appended != 0 {
+		t.Errorf("MarshalAppend into a large enough dst: %v allocations, expected none", appended)
+	}
+	w := &nopWriter{}
+	written := testing.AllocsPerRun(100, func() {
+		e.MarshalTo(w)
+	})
+	if written != 0 {
+		t.Errorf("MarshalTo: %v allocations, expected none", written)
+	}
+}
+
+type nopWriter struct{}
+
+func (*nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	e := event()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.MarshalJSON()
+	}
+}
+
+func BenchmarkMarshalAppend(b *testing.B) {
+	e := event()
+	dst := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst, _ = ffjson.MarshalAppend(dst[:0], e)
+	}
+}
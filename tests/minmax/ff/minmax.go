@@ -0,0 +1,30 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Review has bounded numbers.
+type Review struct {
+	Stars   int      `json:"stars" ffjson:"min=1,max=5"`
+	Score   float64  `json:"score" ffjson:"min=0,max=100"`
+	Weight  float32  `json:"weight,omitempty" ffjson:"min=0.1"`
+	Votes   uint16   `json:"votes" ffjson:"min=1,max=1000"`
+	Offset  *int64   `json:"offset" ffjson:"min=-10,max=10"`
+	Percent int8     `json:"percent,string" ffjson:"min=-100,max=100"`
+	Ratios  []uint32 `json:"ratios"`
+	Count   int      `json:"count"`
+}
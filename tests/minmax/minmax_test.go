@@ -0,0 +1,116 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/minmax/ff"
+)
+
+func TestRangeValid(t *testing.T) {
+	tests := []string{
+		`{"stars":1,"score":0,"weight":0.1,"votes":1,"offset":-10,"percent":"-100"}`,
+		`{"stars":5,"score":100,"weight":1e9,"votes":1000,"offset":10,"percent":"100"}`,
+		`{"stars":3,"score":99.99,"offset":null,"percent":null,"count":-5}`,
+		`{"stars":null}`,
+		`{}`,
+	}
+
+	for _, in := range tests {
+		var r ff.Review
+		err := r.UnmarshalJSON([]byte(in))
+		if err != nil {
+			t.Errorf("UnmarshalJSON(%s): %v", in, err)
+		}
+	}
+}
+
+func TestRangeValues(t *testing.T) {
+	var r ff.Review
+	err := r.UnmarshalJSON([]byte(`{"stars":4,"score":12.5,"offset":-3,"percent":"7"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Stars != 4 || r.Score != 12.5 || r.Offset == nil || *r.Offset != -3 || r.Percent != 7 {
+		t.Fatalf("unexpected value: %+v", r)
+	}
+}
+
+func TestRangeInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{`{"stars":0}`, `ffjson: 0 is below the minimum 1 for "stars"`},
+		{`{"stars":6}`, `ffjson: 6 is above the maximum 5 for "stars"`},
+		{`{"score":-0.5}`, `ffjson: -0.5 is below the minimum 0 for "score"`},
+		{`{"score":100.01}`, `ffjson: 100.01 is above the maximum 100 for "score"`},
+		{`{"weight":0.09}`, `ffjson: 0.09 is below the minimum 0.1 for "weight"`},
+		{`{"votes":0}`, `ffjson: 0 is below the minimum 1 for "votes"`},
+		{`{"votes":1001}`, `ffjson: 1001 is above the maximum 1000 for "votes"`},
+		{`{"offset":-11}`, `ffjson: -11 is below the minimum -10 for "offset"`},
+		{`{"offset":11}`, `ffjson: 11 is above the maximum 10 for "offset"`},
+		{`{"percent":"-101"}`, ``},
+		{`{"percent":"101"}`, ``},
+	}
+
+	for _, test := range tests {
+		var r ff.Review
+		err := r.UnmarshalJSON([]byte(test.in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", test.in)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("UnmarshalJSON(%s): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+	}
+}
+
+func TestRangeSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	err := json.Unmarshal(ff.Review{}.JSONSchema(), &schema)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for name, bounds := range map[string][2]float64{"stars": {1, 5}, "score": {0, 100}, "votes": {1, 1000}} {
+		p := schema.Properties[name]
+		if p["minimum"] != bounds[0] || p["maximum"] != bounds[1] {
+			t.Errorf("unexpected schema for %s: %v", name, p)
+		}
+	}
+	if strings.Count(string(ff.Review{}.JSONSchema()), `"minimum":1,`) != 2 {
+		t.Errorf("minimum must be written once per field: %s", ff.Review{}.JSONSchema())
+	}
+}
+
+func TestRangeMarshal(t *testing.T) {
+	// Encoding doesn't check the bounds.
+	out, err := (&ff.Review{Stars: 9}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if !strings.Contains(string(out), `"stars":9`) {
+		t.Fatalf("unexpected json: %s", out)
+	}
+}
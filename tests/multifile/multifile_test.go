@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/multifile/ff"
+)
+
+func TestMultiFileRoundTrip(t *testing.T) {
+	in := ff.Order{
+		ID:       7,
+		Customer: ff.Customer{Name: "ann"},
+		Items:    []ff.LineItem{{SKU: "x1", Quantity: 2, Price: 1.5}},
+	}
+
+	var _ json.Marshaler = &in.Customer
+	var _ json.Marshaler = &in.Items[0]
+
+	data, err := in.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	std, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		t.Fatalf("json.Compact: %v", err)
+	}
+	if compact.String() != string(std) {
+		t.Errorf("MarshalJSON = %s, want %s", data, std)
+	}
+
+	var out ff.Order
+	if err := out.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestMultiFileNoExposeFiles(t *testing.T) {
+	left, err := filepath.Glob("ff/*_ffjson_expose.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("expose files left behind: %v", left)
+	}
+}
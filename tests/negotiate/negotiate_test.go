@@ -0,0 +1,93 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/negotiate/ff"
+	"github.com/maxproc/ffjson/tests/negotiate/strict"
+)
+
+func TestMarshalNegotiated(t *testing.T) {
+	o := &ff.Order{ID: 1, Status: "paid"}
+	json := `{"id":1,"status":"paid"}`
+	sse := "data: " + json + "\n\n"
+	msgpack, err := o.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cbor, err := o.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		contentType string
+		expected    string
+	}{
+		{"application/json", json},
+		{"application/json; charset=utf-8", json},
+		{"application/problem+json", json},
+		{"*/*", json},
+		{"", json},
+		{"text/event-stream", sse},
+		{"Text/Event-Stream; charset=utf-8", sse},
+		{"application/msgpack", string(msgpack)},
+		{"application/x-msgpack", string(msgpack)},
+		{"application/cbor", string(cbor)},
+		// Unknown types are written as json.
+		{"application/yaml", json},
+		{"text/csv", json},
+	}
+
+	for _, test := range tests {
+		out, err := o.Marshal(test.contentType)
+		if err != nil {
+			t.Errorf("Marshal(%q): %v", test.contentType, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("Marshal(%q)\nExpected: %q\nGot: %q", test.contentType, test.expected, out)
+		}
+	}
+}
+
+func TestMarshalNegotiatedStrict(t *testing.T) {
+	o := &strict.Order{ID: 2}
+	for _, contentType := range []string{"application/json", "application/vnd.api+json", "application/*"} {
+		out, err := o.Marshal(contentType)
+		if err != nil {
+			t.Errorf("Marshal(%q): %v", contentType, err)
+			continue
+		}
+		if string(out) != `{"id":2}` {
+			t.Errorf("Marshal(%q): unexpected json %s", contentType, out)
+		}
+	}
+
+	// Without -sse and -formats, event streams and binary formats are
+	// unknown too.
+	for _, contentType := range []string{"text/event-stream", "application/msgpack", "application/cbor", "application/yaml", "text/*"} {
+		_, err := o.Marshal(contentType)
+		if _, ok := err.(*fflib.UnsupportedMediaTypeError); !ok {
+			t.Errorf("Marshal(%q): expected an UnsupportedMediaTypeError, got %v", contentType, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Reading has the float fields whose NaN and infinite values fail the
+// encoding.
+type Reading struct {
+	F64    float64            `json:"f64"`
+	F32    float32            `json:"f32"`
+	Ptr    *float64           `json:"ptr"`
+	Slice  []float64          `json:"slice"`
+	Slice2 []float32          `json:"slice32"`
+	Map    map[string]float64 `json:"map"`
+	Scaled float64            `json:"scaled" ffjson:"scale=100"`
+	Str    float64            `json:"str,string"`
+}
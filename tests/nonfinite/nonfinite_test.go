@@ -0,0 +1,100 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/nonfinite/ff"
+	"github.com/maxproc/ffjson/tests/nonfinite/null"
+)
+
+var nonFinite = []float64{math.NaN(), math.Inf(1), math.Inf(-1)}
+
+func TestNonFiniteError(t *testing.T) {
+	for _, v := range nonFinite {
+		v32 := float32(v)
+		tests := []*ff.Reading{
+			{F64: v},
+			{F32: v32},
+			{Ptr: &v},
+			{Slice: []float64{1, v}},
+			{Slice2: []float32{v32}},
+			{Map: map[string]float64{"a": v}},
+			{Scaled: v},
+			{Str: v},
+		}
+
+		for _, r := range tests {
+			out, err := r.MarshalJSON()
+			var uerr *json.UnsupportedValueError
+			if !errors.As(err, &uerr) {
+				t.Errorf("%+v: expected a *json.UnsupportedValueError, got %v (%s)", r, err, out)
+				continue
+			}
+			// The message is the one of encoding/json.
+			_, expected := json.Marshal(v)
+			if r.F32 != 0 || r.Slice2 != nil {
+				_, expected = json.Marshal(v32)
+			}
+			if err.Error() != expected.Error() {
+				t.Errorf("%+v\nExpected: %v\nGot: %v", r, expected, err)
+			}
+		}
+	}
+}
+
+func TestFiniteValues(t *testing.T) {
+	r := &ff.Reading{F64: 1.5, F32: -2, Slice: []float64{0}, Scaled: 0.25, Str: 3}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"f64":1.5,"f32":-2,"ptr":null,"slice":[0],"slice32":null,"map":null,"scaled":25,"str":"3"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+}
+
+func TestNonFiniteNull(t *testing.T) {
+	for _, v := range nonFinite {
+		r := &null.Reading{
+			F64:    v,
+			F32:    float32(v),
+			Ptr:    &v,
+			Slice:  []float64{1, v},
+			Slice2: []float32{float32(v)},
+			Map:    map[string]float64{"a": v},
+			Scaled: v,
+		}
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		expected := `{"f64":null,"f32":null,"ptr":null,"slice":[1,null],"slice32":[null],"map":{ "a":null},"scaled":null}`
+		if string(out) != expected {
+			t.Fatalf("%v\nExpected: %s\nGot: %s", v, expected, out)
+		}
+		if !json.Valid(out) {
+			t.Fatalf("%v: invalid json %s", v, out)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/normkeys/ff"
+)
+
+func TestNormalizedKeys(t *testing.T) {
+	for _, input := range []string{
+		// Precomposed (NFC).
+		"{\"caf\u00e9\":\"x\",\"len\u00c5\":2,\"plain\":true,\"Nature\":\"n\"}",
+		// Decomposed (NFD), and the key as declared.
+		"{\"cafe\u0301\":\"x\",\"len\u212b\":2,\"plain\":true,\"Nature\":\"n\"}",
+		// JSON escapes, and keys matched case-insensitively.
+		`{"CAFE\u0301":"x","LENA\u030a":2,"PLAIN":true,"nature":"n"}`,
+	} {
+		var m ff.Menu
+		err := m.UnmarshalJSON([]byte(input))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", input, err)
+		}
+		expected := ff.Menu{Cafe: "x", Length: 2, Plain: true, Nature: "n"}
+		if m != expected {
+			t.Fatalf("UnmarshalJSON(%s): Expected: %+v\nGot: %+v", input, expected, m)
+		}
+	}
+}
+
+func TestNormalizedKeysMarshal(t *testing.T) {
+	// Only decoding is affected, keys are written as declared.
+	m := ff.Menu{Cafe: "x"}
+	out, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := "{\"caf\u00e9\":\"x\",\"len\u212b\":0,\"plain\":false,\"Nature\":\"\"}"
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestExactKeys(t *testing.T) {
+	var m ff.ExactMenu
+	err := m.UnmarshalJSON([]byte("{\"cafe\u0301\":\"x\"}"))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if m.Cafe != "" {
+		t.Fatalf("Expected no match without normalization, got: %v", m.Cafe)
+	}
+
+	err = m.UnmarshalJSON([]byte("{\"caf\u00e9\":\"x\"}"))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if m.Cafe != "x" {
+		t.Fatalf("Expected: x\nGot: %v", m.Cafe)
+	}
+}
@@ -0,0 +1,43 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Tags is a named slice type.
+type Tags []string
+
+// Doc decodes null collections as empty ones.
+type Doc struct {
+	Names   []string          `json:"names" ffjson:"nullasempty"`
+	Counts  map[string]int    `json:"counts" ffjson:"nullasempty"`
+	Tags    Tags              `json:"tags" ffjson:"nullasempty"`
+	Times   []time.Time       `json:"times" ffjson:"nullasempty,cap=4"`
+	Items   []*Item           `json:"items" ffjson:"nullasempty"`
+	ByID    map[string]*Item  `json:"by_id" ffjson:"nullasempty"`
+	Raw     []byte            `json:"raw" ffjson:"nullasempty"`
+	Plain   []string          `json:"plain"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Item is an element of Doc.
+type Item struct {
+	N int `json:"n"`
+}
@@ -0,0 +1,108 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/nullasempty/ff"
+)
+
+func TestNullAsEmptyNull(t *testing.T) {
+	doc := ff.Doc{Names: []string{"old"}, Plain: []string{"old"}}
+	err := doc.UnmarshalJSON([]byte(`{"names":null,"counts":null,"tags":null,"times":null,"items":null,"by_id":null,"raw":null,"plain":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	expected := ff.Doc{
+		Names:  []string{},
+		Counts: map[string]int{},
+		Tags:   ff.Tags{},
+		Times:  []time.Time{},
+		Items:  []*ff.Item{},
+		ByID:   map[string]*ff.Item{},
+		Raw:    []byte{},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, doc)
+	}
+	if doc.Names == nil || doc.Counts == nil || doc.Tags == nil || doc.Times == nil ||
+		doc.Items == nil || doc.ByID == nil || doc.Raw == nil {
+		t.Fatalf("null must decode to empty, non-nil values: %#v", doc)
+	}
+	if cap(doc.Times) != 4 {
+		t.Fatalf("the cap option must apply, got %d", cap(doc.Times))
+	}
+	if doc.Plain != nil {
+		t.Fatalf("fields without nullasempty must be nil for null: %#v", doc.Plain)
+	}
+}
+
+func TestNullAsEmptyValues(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected ff.Doc
+	}{
+		{`{}`, ff.Doc{}},
+		{`{"names":[],"counts":{},"items":[]}`, ff.Doc{Names: []string{}, Counts: map[string]int{}, Items: []*ff.Item{}}},
+		{`{"names":["a","b"],"counts":{"x":1},"tags":["t"],"items":[{"n":1},null],"by_id":{"2":{"n":2}},"raw":"AQI="}`, ff.Doc{
+			Names:  []string{"a", "b"},
+			Counts: map[string]int{"x": 1},
+			Tags:   ff.Tags{"t"},
+			Items:  []*ff.Item{{N: 1}, nil},
+			ByID:   map[string]*ff.Item{"2": {N: 2}},
+			Raw:    []byte{1, 2},
+		}},
+	}
+
+	for _, test := range tests {
+		var doc ff.Doc
+		err := doc.UnmarshalJSON([]byte(test.in))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", test.in, err)
+		}
+		if !reflect.DeepEqual(doc, test.expected) {
+			t.Errorf("UnmarshalJSON(%s)\nExpected: %#v\nGot: %#v", test.in, test.expected, doc)
+		}
+	}
+}
+
+func TestNullAsEmptyInvalid(t *testing.T) {
+	for _, in := range []string{`{"names":1}`, `{"counts":[]}`, `{"names":"a"}`} {
+		var doc ff.Doc
+		err := doc.UnmarshalJSON([]byte(in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", in)
+		}
+	}
+}
+
+func TestNullAsEmptyMarshal(t *testing.T) {
+	// Encoding is unchanged: nil is still written as null.
+	out, err := (&ff.Doc{Names: []string{}}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "names":[],"counts":null,"tags":null,"times":null,"items":null,"by_id":null,"raw":null,"plain":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+}
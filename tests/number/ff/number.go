@@ -32,3 +32,17 @@ func NewNumber(e *Number) {
 	e.Int = "1"
 	e.Float = "3.14"
 }
+
+// Numbers has json.Number fields of all forms.
+type Numbers struct {
+	Big    json.Number            `json:"big"`
+	Ptr    *json.Number           `json:"ptr"`
+	List   []json.Number          `json:"list"`
+	Map    map[string]json.Number `json:"map,omitempty"`
+	Quoted json.Number            `json:"quoted,string,omitempty"`
+}
+
+// NumbersStd has the fields of Numbers, without its methods, so
+// encoding/json encodes it by reflection.
+// ffjson: skip
+type NumbersStd Numbers
@@ -20,6 +20,7 @@ package types
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
 	ff "github.com/maxproc/ffjson/tests/number/ff"
@@ -68,3 +69,91 @@ func TestUnmarshalFull(t *testing.T) {
 		t.Fatalf("UnmarshalJSON: %v", err)
 	}
 }
+
+func TestNumbersRoundTrip(t *testing.T) {
+	// Integers beyond float64 precision and floats keep their exact text.
+	input := `{"big":123456789012345678901234567890,"ptr":-0.10000000000000000555,"list":[1,2.50,1e400,-0],"map":{"a":9007199254740993},"quoted":"12.0"}`
+	var n ff.Numbers
+	err := n.UnmarshalJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	var std ff.NumbersStd
+	err = json.Unmarshal([]byte(input), &std)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(n, ff.Numbers(std)) {
+		t.Fatalf("Got %+v, want %+v", n, std)
+	}
+	if n.Big != "123456789012345678901234567890" || *n.Ptr != "-0.10000000000000000555" || n.List[2] != "1e400" {
+		t.Fatalf("Unexpected value: %+v", n)
+	}
+
+	out, err := n.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, err := json.Marshal(&std)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if strings.Replace(string(out), " ", "", -1) != string(expected) {
+		t.Fatalf("Got %s, want %s", out, expected)
+	}
+}
+
+func TestNumbersNull(t *testing.T) {
+	ptr := json.Number("1")
+	n := ff.Numbers{Big: "2", Ptr: &ptr}
+	err := n.UnmarshalJSON([]byte(`{"big":null,"ptr":null,"list":[null]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	// Like encoding/json, null leaves a json.Number unchanged and clears
+	// a pointer.
+	if n.Big != "2" || n.Ptr != nil || len(n.List) != 1 || n.List[0] != "" {
+		t.Fatalf("Unexpected value: %+v", n)
+	}
+
+	// Empty numbers are written as 0, and nil pointers as null.
+	out, err := n.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `{ "big":2,"ptr":null,"list":[0]}` {
+		t.Fatalf("Got %s", out)
+	}
+}
+
+func TestNumbersInvalid(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset string
+	}{
+		{`{"big":true}`, "offset=11"},
+		{`{"big":"abc"}`, "offset=12"},
+		{`{"list":[1,{}]}`, "offset=12"},
+		{`{"ptr":[]}`, "offset=8"},
+	}
+	for _, test := range tests {
+		var n ff.Numbers
+		err := n.UnmarshalJSON([]byte(test.input))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s) succeeded: %+v", test.input, n)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.offset) {
+			t.Errorf("UnmarshalJSON(%s): %v, want %s", test.input, err, test.offset)
+		}
+		var std ff.NumbersStd
+		if json.Unmarshal([]byte(test.input), &std) == nil {
+			t.Errorf("json.Unmarshal(%s) succeeded", test.input)
+		}
+	}
+
+	_, err := (&ff.Numbers{Big: "1.2.3"}).MarshalJSON()
+	if err == nil {
+		t.Fatalf("MarshalJSON of an invalid number succeeded")
+	}
+}
@@ -0,0 +1,33 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Metrics struct
+type Metrics struct {
+	Count   int64
+	Small   int8
+	Delta   int
+	Total   uint64
+	Port    uint16
+	Ratio   float64
+	Load    float32
+	Latency *float64
+	Samples []int32
+	Weights []float64
+	Limits  map[string]uint32
+}
@@ -0,0 +1,97 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/numfmt/ff"
+)
+
+func newMetrics() *ff.Metrics {
+	latency := 0.000123
+	return &ff.Metrics{
+		Count:   math.MinInt64,
+		Small:   -128,
+		Delta:   -42,
+		Total:   math.MaxUint64,
+		Port:    65535,
+		Ratio:   1e21,
+		Load:    0.1,
+		Latency: &latency,
+		Samples: []int32{0, 10, -2147483648, 2147483647},
+		Weights: []float64{math.MaxFloat64, math.SmallestNonzeroFloat64, -0.5, 1e-7},
+		Limits:  map[string]uint32{"max": 4294967295},
+	}
+}
+
+func TestNumberFormatting(t *testing.T) {
+	m := newMetrics()
+	out, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"Count":-9223372036854775808,"Small":-128,"Delta":-42,"Total":18446744073709551615,"Port":65535,` +
+		`"Ratio":1e+21,"Load":0.1,"Latency":0.000123,"Samples":[0,10,-2147483648,2147483647],` +
+		`"Weights":[1.7976931348623157e+308,5e-324,-0.5,1e-07],"Limits":{ "max":4294967295}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Metrics
+	err = json.Unmarshal(out, &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", out, err)
+	}
+	if got.Count != m.Count || got.Total != m.Total || got.Load != m.Load || got.Weights[1] != m.Weights[1] {
+		t.Fatalf("Round trip mismatch: %+v", got)
+	}
+}
+
+func TestNumberFormattingAllocs(t *testing.T) {
+	m := newMetrics()
+	m.Limits = nil // Map iteration allocates on its own.
+	var buf fflib.Buffer
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		err := m.MarshalJSONBuf(&buf)
+		if err != nil {
+			t.Fatalf("MarshalJSONBuf: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkMarshalNumbers(b *testing.B) {
+	m := newMetrics()
+	m.Limits = nil
+	var buf fflib.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		err := m.MarshalJSONBuf(&buf)
+		if err != nil {
+			b.Fatalf("MarshalJSONBuf: %v", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Money is zero when its currency is empty, whatever the amount.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// IsZero reports whether m has no currency.
+func (m *Money) IsZero() bool {
+	return m.Currency == ""
+}
+
+// Point is compared with its zero value.
+type Point struct {
+	X, Y int
+}
+
+// Path can't be compared with ==, so it is checked by reflection.
+type Path struct {
+	Points []Point
+}
+
+// Record has omitzero fields of every kind.
+type Record struct {
+	Created  time.Time         `json:"created,omitzero"`
+	Updated  *time.Time        `json:"updated,omitzero"`
+	Price    Money             `json:"price,omitzero"`
+	Cost     *Money            `json:"cost,omitzero"`
+	Origin   Point             `json:"origin,omitzero"`
+	Route    Path              `json:"route,omitzero"`
+	Tags     []string          `json:"tags,omitzero"`
+	Attrs    map[string]string `json:"attrs,omitzero"`
+	Grid     [2]int            `json:"grid,omitzero"`
+	Count    int               `json:"count,omitzero"`
+	Name     string            `json:"name,omitzero"`
+	Enabled  bool              `json:"enabled,omitzero"`
+	Inline   struct{ A int }   `json:"inline,omitzero"`
+	Either   []int             `json:"either,omitempty,omitzero"`
+	Required time.Time         `json:"required"`
+}
+
+// RecordStd has the fields of Record, without its methods, so
+// encoding/json encodes it by reflection.
+// ffjson: skip
+type RecordStd Record
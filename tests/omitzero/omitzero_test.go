@@ -0,0 +1,90 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/omitzero/ff"
+)
+
+func TestOmitZeroMarshal(t *testing.T) {
+	now := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+	records := []ff.Record{
+		{},
+		{
+			Updated: &time.Time{},
+			Price:   ff.Money{Amount: 5},
+			Cost:    &ff.Money{},
+			Route:   ff.Path{Points: []ff.Point{}},
+			Tags:    []string{},
+			Attrs:   map[string]string{},
+			Either:  []int{},
+		},
+		{
+			Created: now,
+			Updated: &now,
+			Price:   ff.Money{Amount: 5, Currency: "EUR"},
+			Cost:    &ff.Money{Currency: "USD"},
+			Origin:  ff.Point{X: 1},
+			Route:   ff.Path{Points: []ff.Point{{}}},
+			Tags:    []string{"a"},
+			Attrs:   map[string]string{"k": "v"},
+			Grid:    [2]int{0, 1},
+			Count:   -1,
+			Name:    "n",
+			Enabled: true,
+			Inline:  struct{ A int }{A: 2},
+			Either:  []int{3},
+		},
+	}
+	for _, r := range records {
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		std := ff.RecordStd(r)
+		expected, err := json.Marshal(&std)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var compact bytes.Buffer
+		err = json.Compact(&compact, out)
+		if err != nil {
+			t.Fatalf("Compact %s: %v", out, err)
+		}
+		if compact.String() != string(expected) {
+			t.Errorf("Expected: %s\nGot: %s", expected, out)
+		}
+	}
+}
+
+func TestOmitZeroEmpty(t *testing.T) {
+	var r ff.Record
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"required":"0001-01-01T00:00:00Z"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+}
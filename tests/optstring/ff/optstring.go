@@ -0,0 +1,32 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Profile tells "" apart from null with *string fields.
+type Profile struct {
+	Nick     *string            `json:"nick"`
+	Bio      *string            `json:"bio,omitempty"`
+	Quoted   *string            `json:"quoted,string"`
+	Aliases  []*string          `json:"aliases"`
+	ByLocale map[string]*string `json:"by_locale"`
+}
+
+// ProfileStd has the fields of Profile, without its methods, so
+// encoding/json encodes it by reflection.
+// ffjson: skip
+type ProfileStd Profile
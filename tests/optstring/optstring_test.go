@@ -0,0 +1,131 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/optstring/ff"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// profiles returns a Profile with all fields set to each of nil, "" and
+// non-empty strings.
+func profiles() []ff.Profile {
+	values := []*string{nil, strPtr(""), strPtr("x"), strPtr(" \"é\"\n<>")}
+	rv := make([]ff.Profile, 0, len(values))
+	for _, v := range values {
+		rv = append(rv, ff.Profile{
+			Nick:     v,
+			Bio:      v,
+			Quoted:   v,
+			Aliases:  []*string{v},
+			ByLocale: map[string]*string{"en": v},
+		})
+	}
+	return rv
+}
+
+func TestOptionalStringMarshal(t *testing.T) {
+	for _, p := range profiles() {
+		out, err := p.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		std := ff.ProfileStd(p)
+		expected, err := json.Marshal(&std)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		// The generated code writes a space after the brace of the map.
+		var compact bytes.Buffer
+		err = json.Compact(&compact, out)
+		if err != nil {
+			t.Fatalf("Compact %s: %v", out, err)
+		}
+		if compact.String() != string(expected) {
+			t.Errorf("Expected: %s\nGot: %s", expected, out)
+		}
+	}
+}
+
+func TestOptionalStringRoundTrip(t *testing.T) {
+	for _, p := range profiles() {
+		std := ff.ProfileStd(p)
+		in, err := json.Marshal(&std)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+
+		var got ff.Profile
+		err = got.UnmarshalJSON(in)
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", in, err)
+		}
+		var expected ff.ProfileStd
+		err = json.Unmarshal(in, &expected)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		if !reflect.DeepEqual(ff.ProfileStd(got), expected) {
+			t.Errorf("UnmarshalJSON(%s)\nExpected: %+v\nGot: %+v", in, expected, got)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("UnmarshalJSON(%s) differs from the encoded value", in)
+		}
+	}
+}
+
+func TestOptionalStringDecodeSet(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected *string
+	}{
+		{`{"nick":null,"bio":null,"quoted":null}`, nil},
+		{`{"nick":"","bio":"","quoted":"\"\""}`, strPtr("")},
+		{`{"nick":"y","bio":"y","quoted":"\"y\""}`, strPtr("y")},
+	}
+
+	for _, test := range tests {
+		// The fields are set beforehand, so null must reset them.
+		got := ff.Profile{Nick: strPtr("old"), Bio: strPtr("old"), Quoted: strPtr("old")}
+		err := got.UnmarshalJSON([]byte(test.in))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", test.in, err)
+		}
+		expected := ff.ProfileStd{Nick: strPtr("old"), Bio: strPtr("old"), Quoted: strPtr("old")}
+		err = json.Unmarshal([]byte(test.in), &expected)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s): %v", test.in, err)
+		}
+		if !reflect.DeepEqual(ff.ProfileStd(got), expected) {
+			t.Errorf("UnmarshalJSON(%s)\nExpected: %+v\nGot: %+v", test.in, expected, got)
+		}
+		for _, f := range []*string{got.Nick, got.Bio, got.Quoted} {
+			if !reflect.DeepEqual(f, test.expected) {
+				t.Errorf("UnmarshalJSON(%s): expected %v, got %v", test.in, test.expected, f)
+			}
+		}
+	}
+}
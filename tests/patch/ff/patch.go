@@ -0,0 +1,41 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Address struct
+type Address struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+// Item struct
+type Item struct {
+	SKU   string `json:"sku"`
+	Count int    `json:"count"`
+}
+
+// Order struct
+type Order struct {
+	ID      int               `json:"id"`
+	Note    string            `json:"note,omitempty"`
+	Ship    Address           `json:"ship"`
+	Bill    *Address          `json:"bill,omitempty"`
+	Items   []Item            `json:"items"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Comment string            `json:"a/b"`
+}
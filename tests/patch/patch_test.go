@@ -0,0 +1,107 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/patch/ff"
+)
+
+func TestJSONPatchEqual(t *testing.T) {
+	o := &ff.Order{ID: 1, Items: []ff.Item{{SKU: "a", Count: 1}}}
+	out, err := o.JSONPatch(&ff.Order{ID: 1, Items: []ff.Item{{SKU: "a", Count: 1}}})
+	if err != nil {
+		t.Fatalf("JSONPatch: %v", err)
+	}
+	if string(out) != `[]` {
+		t.Fatalf("Expected an empty patch, got %s", out)
+	}
+}
+
+func TestJSONPatchFields(t *testing.T) {
+	base := &ff.Order{
+		ID:     1,
+		Note:   "gift",
+		Ship:   ff.Address{Street: "Main St", City: "Springfield"},
+		Items:  []ff.Item{{SKU: "a", Count: 1}, {SKU: "b", Count: 2}, {SKU: "c", Count: 3}},
+		Labels: map[string]string{"prio": "low"},
+	}
+	target := &ff.Order{
+		ID:      1,
+		Ship:    ff.Address{Street: "Main St", City: "Shelbyville"},
+		Bill:    &ff.Address{Street: "Elm St", City: "Springfield"},
+		Items:   []ff.Item{{SKU: "a", Count: 5}},
+		Labels:  map[string]string{"prio": "high"},
+		Comment: "x",
+	}
+
+	out, err := target.JSONPatch(base)
+	if err != nil {
+		t.Fatalf("JSONPatch: %v", err)
+	}
+	expected := `[{"op":"remove","path":"/note"},` +
+		`{"op":"replace","path":"/ship/city","value":"Shelbyville"},` +
+		`{"op":"add","path":"/bill","value":{"street":"Elm St","city":"Springfield"}},` +
+		`{"op":"replace","path":"/items/0/count","value":5},` +
+		`{"op":"remove","path":"/items/2"},{"op":"remove","path":"/items/1"},` +
+		`{"op":"replace","path":"/labels/prio","value":"high"},` +
+		`{"op":"replace","path":"/a~1b","value":"x"}]`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestJSONPatchGrow(t *testing.T) {
+	base := &ff.Order{Items: []ff.Item{{SKU: "a"}}}
+	target := &ff.Order{Items: []ff.Item{{SKU: "a"}, {SKU: "b", Count: 1}}}
+	out, err := target.JSONPatch(base)
+	if err != nil {
+		t.Fatalf("JSONPatch: %v", err)
+	}
+	expected := `[{"op":"add","path":"/items/-","value":{"sku":"b","count":1}}]`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestJSONPatchNilBase(t *testing.T) {
+	target := &ff.Order{ID: 7}
+	out, err := target.JSONPatch(nil)
+	if err != nil {
+		t.Fatalf("JSONPatch: %v", err)
+	}
+	var ops []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	err = json.Unmarshal(out, &ops)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Op != "replace" || ops[0].Path != "" {
+		t.Fatalf("Expected a replace of the whole document, got %s", out)
+	}
+	value, _ := target.MarshalJSON()
+	if !reflect.DeepEqual([]byte(ops[0].Value), value) {
+		t.Fatalf("Expected: %s\nGot: %s", value, ops[0].Value)
+	}
+}
@@ -0,0 +1,30 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package ff
+
+// Order is generated into ff_ffjson.go with the structs of the other
+// files of the package.
+type Order struct {
+	ID       int        `json:"id"`
+	Customer Customer   `json:"customer"`
+	Items    []LineItem `json:"items"`
+}
+
+// DraftOrder is left out with -exclude Draft.
+type DraftOrder struct {
+	ID int `json:"id"`
+}
@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/pkggen/ff"
+	"github.com/maxproc/ffjson/tests/pkggen/ff/nested"
+)
+
+func TestPackageRoundTrip(t *testing.T) {
+	in := ff.Order{
+		ID:       3,
+		Customer: ff.Customer{Name: "bo"},
+		Items:    []ff.LineItem{{SKU: "a", Quantity: 1}},
+	}
+	data, err := in.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	var out ff.Order
+	if err := out.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestPackageExclude(t *testing.T) {
+	var v interface{} = &ff.DraftOrder{}
+	if _, ok := v.(json.Marshaler); ok {
+		t.Error("DraftOrder has a MarshalJSON, but -exclude Draft should leave it out")
+	}
+}
+
+func TestPackageRecursive(t *testing.T) {
+	var v interface{} = &nested.Leaf{}
+	if _, ok := v.(json.Marshaler); !ok {
+		t.Error("Leaf has no MarshalJSON, the nested package wasn't generated")
+	}
+}
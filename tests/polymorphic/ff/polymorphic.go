@@ -0,0 +1,57 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/maxproc/ffjson/ffjson"
+)
+
+// Event is implemented by the event types, registered by their type
+// member.
+type Event interface {
+	Kind() string
+}
+
+// Click is registered as "click".
+type Click struct {
+	Type string `json:"type"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+func (c *Click) Kind() string { return c.Type }
+
+// Key is registered as "key".
+type Key struct {
+	Type string `json:"type"`
+	Code string `json:"code"`
+}
+
+func (k *Key) Kind() string { return k.Type }
+
+// Message wraps events decoded by their type member.
+type Message struct {
+	ID      string  `json:"id"`
+	Event   Event   `json:"event" ffjson:"polymorphic=type"`
+	History []Event `json:"history" ffjson:"polymorphic=type"`
+}
+
+func init() {
+	ffjson.RegisterType("click", func() interface{} { return new(Click) })
+	ffjson.RegisterType("key", func() interface{} { return new(Key) })
+}
@@ -0,0 +1,107 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxproc/ffjson/ffjson"
+	ff "github.com/maxproc/ffjson/tests/polymorphic/ff"
+)
+
+// Note doesn't implement ff.Event.
+type Note struct {
+	Text string `json:"text"`
+}
+
+func init() {
+	ffjson.RegisterType("note", func() interface{} { return new(Note) })
+}
+
+func TestPolymorphicUnmarshal(t *testing.T) {
+	var m ff.Message
+	err := m.UnmarshalJSON([]byte(`{"id":"m1","event":{"x":3,"type":"click","y":4},` +
+		`"history":[{"type":"key","code":"Enter"},null,{"type":"click"}]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.Message{
+		ID:      "m1",
+		Event:   &ff.Click{Type: "click", X: 3, Y: 4},
+		History: []ff.Event{&ff.Key{Type: "key", Code: "Enter"}, nil, &ff.Click{Type: "click"}},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, m)
+	}
+
+	err = m.UnmarshalJSON([]byte(`{"event":null,"history":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if m.Event != nil || m.History != nil {
+		t.Fatalf("Expected null to clear the fields, got: %#v", m)
+	}
+}
+
+func TestPolymorphicMarshal(t *testing.T) {
+	m := ff.Message{
+		ID:      "m1",
+		Event:   &ff.Key{Type: "key", Code: "Esc"},
+		History: []ff.Event{&ff.Click{Type: "click", X: 1, Y: 2}},
+	}
+	out, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"id":"m1","event":{"type":"key","code":"Esc"},"history":[{"type":"click","x":1,"y":2}]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var back ff.Message
+	err = back.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(back, m) {
+		t.Fatalf("Expected: %#v\nGot: %#v", m, back)
+	}
+}
+
+func TestPolymorphicErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"event":{"x":1}}`, "object has no type member"},
+		{`{"event":{"type":1}}`, "type member must be a string"},
+		{`{"event":{"type":"scroll"}}`, `no type registered for type "scroll"`},
+		{`{"event":{"type":"note"}}`, "registered type *types.Note doesn't implement Event"},
+		{`{"event":"click"}`, "wanted an object with a type member"},
+		{`{"history":[{"type":"click","x":"1"}]}`, "cannot unmarshal"},
+	}
+	for _, test := range tests {
+		var m ff.Message
+		err := m.UnmarshalJSON([]byte(test.input))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error containing %q, got: %v", test.input, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/pooled/ff"
+)
+
+func recordsJSON(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id":%d,"amount":%d.25,"paid":%v}`, i, i, i%2 == 0)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+func TestDecodeArrayPooled(t *testing.T) {
+	input := `[{"id":1,"name":"a","tags":[1,2],"parent":{"id":9}}, {"id":2,"amount":1.5}, {}]`
+	var got []ff.Record
+	var ptrs []*ff.Record
+	err := ff.DecodeRecordArrayPooled([]byte(input), func(r *ff.Record) {
+		got = append(got, *r)
+		ptrs = append(ptrs, r)
+	})
+	if err != nil {
+		t.Fatalf("DecodeRecordArrayPooled: %v", err)
+	}
+
+	// Fields of an element don't leak into the next one.
+	expected := []ff.Record{
+		{ID: 1, Name: "a", Tags: []int{1, 2}, Parent: &ff.Record{ID: 9}},
+		{ID: 2, Amount: 1.5},
+		{},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, got)
+	}
+	if ptrs[0] != ptrs[1] || ptrs[1] != ptrs[2] {
+		t.Fatalf("Expected the same struct for each element, got %p %p %p", ptrs[0], ptrs[1], ptrs[2])
+	}
+	// The struct is reset when returned to the pool.
+	if !reflect.DeepEqual(*ptrs[0], ff.Record{}) {
+		t.Fatalf("Expected a reset struct, got %+v", *ptrs[0])
+	}
+}
+
+func TestDecodeArrayPooledEmpty(t *testing.T) {
+	n := 0
+	err := ff.DecodeRecordArrayPooled([]byte(` [ ] `), func(r *ff.Record) { n++ })
+	if err != nil || n != 0 {
+		t.Fatalf("Expected no elements, got %d, %v", n, err)
+	}
+}
+
+func TestDecodeArrayPooledInvalid(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"id":1}`, "wanted an array at the root of Record"},
+		{`null`, "wanted an array at the root of Record"},
+		{`[{"id":1},null]`, "wanted an object in the array"},
+		{`[{"id":1},2]`, "wanted an object in the array"},
+		{`[{"id":"x"}]`, "cannot unmarshal"},
+		{`[{"id":1}`, ""},
+		{`[{"id":1}] []`, ""},
+	}
+
+	for _, test := range tests {
+		n := 0
+		err := ff.DecodeRecordArrayPooled([]byte(test.input), func(r *ff.Record) { n++ })
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.input, test.err, err)
+		}
+	}
+}
+
+func TestDecodeArrayPooledAllocs(t *testing.T) {
+	decode := func(buf []byte) float64 {
+		return testing.AllocsPerRun(100, func() {
+			ff.DecodeRecordArrayPooled(buf, func(r *ff.Record) {})
+		})
+	}
+	// Records without strings or slices are decoded without allocating
+	// for each element.
+	small, large := decode(recordsJSON(10)), decode(recordsJSON(1000))
+	if small != large {
+		t.Fatalf("Expected the same allocations for 10 and 1000 elements, got %v and %v", small, large)
+	}
+
+	buf := recordsJSON(1000)
+	naive := testing.AllocsPerRun(100, func() {
+		ff.UnmarshalRecordRoot(buf, nil, func(vs []*ff.Record) error { return nil })
+	})
+	if large >= naive {
+		t.Fatalf("Expected fewer allocations than decoding a slice, got %v pooled, %v with a slice", large, naive)
+	}
+}
+
+func BenchmarkDecodeArrayPooled(b *testing.B) {
+	buf := recordsJSON(1000)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ff.DecodeRecordArrayPooled(buf, func(r *ff.Record) {})
+		if err != nil {
+			b.Fatalf("DecodeRecordArrayPooled: %v", err)
+		}
+	}
+}
+
+func BenchmarkDecodeArraySlice(b *testing.B) {
+	buf := recordsJSON(1000)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ff.UnmarshalRecordRoot(buf, nil, func(vs []*ff.Record) error { return nil })
+		if err != nil {
+			b.Fatalf("UnmarshalRecordRoot: %v", err)
+		}
+	}
+}
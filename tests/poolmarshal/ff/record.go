@@ -0,0 +1,34 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Record is generated with -pool-marshal.
+type Record struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Age      int    `json:"age"`
+	City     string `json:"city"`
+	Country  string `json:"country"`
+	Zip      int    `json:"zip"`
+	Phone    string `json:"phone"`
+	Score    int    `json:"score"`
+	Company  string `json:"company"`
+	Visits   int    `json:"visits"`
+	Referrer string `json:"referrer,omitempty"`
+}
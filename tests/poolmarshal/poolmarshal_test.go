@@ -0,0 +1,123 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/maxproc/ffjson/tests/poolmarshal/base"
+	ff "github.com/maxproc/ffjson/tests/poolmarshal/ff"
+)
+
+func newRecord() *ff.Record {
+	return &ff.Record{
+		ID:       42,
+		Name:     "Ada Lovelace",
+		Email:    "ada@example.com",
+		Age:      36,
+		City:     "London",
+		Country:  "UK",
+		Zip:      12345,
+		Phone:    "+44 20 7946 0000",
+		Score:    9001,
+		Company:  "Analytical Engines",
+		Visits:   17,
+		Referrer: "https://example.com/",
+	}
+}
+
+func TestPoolMarshalOutput(t *testing.T) {
+	r := newRecord()
+	got, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	// base.Record has the same fields, generated without pooling.
+	want, err := (*base.Record)(r).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("Got %s, want %s", got, want)
+	}
+
+	var nilRecord *ff.Record
+	got, err = nilRecord.MarshalJSON()
+	if err != nil || string(got) != "null" {
+		t.Fatalf("Got %q, %v, want null", got, err)
+	}
+}
+
+func TestPoolMarshalOwnsResult(t *testing.T) {
+	// The buffers are reused, so earlier results must not change when
+	// later values are marshaled.
+	first := newRecord()
+	a, err := first.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	kept := string(a)
+	for i := 0; i < 100; i++ {
+		r := newRecord()
+		r.Name = "Charles Babbage"
+		r.ID = i
+		_, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+	}
+	if string(a) != kept {
+		t.Fatalf("Result changed from %s to %s", kept, a)
+	}
+}
+
+func TestPoolMarshalAllocs(t *testing.T) {
+	r := newRecord()
+	pooled := testing.AllocsPerRun(100, func() {
+		_, _ = r.MarshalJSON()
+	})
+	b := (*base.Record)(r)
+	plain := testing.AllocsPerRun(100, func() {
+		_, _ = b.MarshalJSON()
+	})
+	if pooled >= plain {
+		t.Fatalf("Pooled MarshalJSON allocates %v times, not less than %v", pooled, plain)
+	}
+}
+
+func BenchmarkMarshalJSONPooled(b *testing.B) {
+	r := newRecord()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := r.MarshalJSON()
+		if err != nil {
+			b.Fatalf("MarshalJSON: %v", err)
+		}
+	}
+}
+
+func BenchmarkMarshalJSONUnpooled(b *testing.B) {
+	r := (*base.Record)(newRecord())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := r.MarshalJSON()
+		if err != nil {
+			b.Fatalf("MarshalJSON: %v", err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/profile/ff"
+)
+
+var growRe = regexp.MustCompile(`func \(j \*(\w+)\) MarshalJSON\(\)[^}]*}\n\s*buf\.Grow\((\d+)\)`)
+
+func TestProfileBufferSize(t *testing.T) {
+	src, err := ioutil.ReadFile("ff/profile_ffjson.go")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	grows := map[string]string{}
+	for _, m := range growRe.FindAllStringSubmatch(string(src), -1) {
+		grows[m[1]] = m[2]
+	}
+
+	// 90% of the samples in ff/samples/Event.json fit into 221 bytes.
+	if grows["Event"] != "256" {
+		t.Errorf("Expected Event to reserve 256 bytes, got %q", grows["Event"])
+	}
+	if _, ok := grows["Plain"]; ok {
+		t.Errorf("Expected Plain without samples to keep the default buffer")
+	}
+}
+
+func TestProfileMarshal(t *testing.T) {
+	e := &ff.Event{ID: 1, Message: "hello", Tags: []string{"a"}}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "id":1,"message":"hello","tags":["a"]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
@@ -0,0 +1,62 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Quoted has numbers and booleans written as json strings with the
+// string option.
+type Quoted struct {
+	Int     int     `json:"int,string"`
+	Int8    int8    `json:"int8,string"`
+	Int16   int16   `json:"int16,string"`
+	Int32   int32   `json:"int32,string"`
+	Int64   int64   `json:"int64,string"`
+	Uint    uint    `json:"uint,string"`
+	Uint8   uint8   `json:"uint8,string"`
+	Uint16  uint16  `json:"uint16,string"`
+	Uint32  uint32  `json:"uint32,string"`
+	Uint64  uint64  `json:"uint64,string"`
+	Uintptr uintptr `json:"uintptr,string"`
+	Float32 float32 `json:"float32,string"`
+	Float64 float64 `json:"float64,string"`
+	Bool    bool    `json:"bool,string"`
+	Omitted int64   `json:"omitted,string,omitempty"`
+
+	PInt     *int     `json:"pint,string"`
+	PUint64  *uint64  `json:"puint64,string"`
+	PFloat64 *float64 `json:"pfloat64,string"`
+	PBool    *bool    `json:"pbool,string"`
+
+	PFloat32   *float32 `json:"pfloat32,string"`
+	OmitBool   bool     `json:"omitbool,string,omitempty"`
+	OmitFloat  float64  `json:"omitfloat,string,omitempty"`
+	POmitFloat *float64 `json:"pomitfloat,string,omitempty"`
+	Ratio      Ratio    `json:"ratio,string"`
+	Enabled    Enabled  `json:"enabled,string"`
+	PEnabled   *Enabled `json:"penabled,string"`
+}
+
+// Ratio is a named float type.
+type Ratio float64
+
+// Enabled is a named bool type.
+type Enabled bool
+
+// QuotedStd has the fields of Quoted, without its methods, so
+// encoding/json encodes it by reflection.
+// ffjson: skip
+type QuotedStd Quoted
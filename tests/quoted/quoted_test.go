@@ -0,0 +1,131 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/quoted/ff"
+)
+
+func quotedValues() []*ff.Quoted {
+	i, u, f, b := -7, uint64(math.MaxUint64), 0.1, true
+	f32, zero, e := float32(1e-7), 0.0, ff.Enabled(true)
+	return []*ff.Quoted{
+		{},
+		{
+			Int: math.MinInt64, Int8: math.MinInt8, Int16: math.MinInt16, Int32: math.MinInt32, Int64: math.MinInt64,
+			Uint: math.MaxUint64, Uint8: math.MaxUint8, Uint16: math.MaxUint16, Uint32: math.MaxUint32, Uint64: math.MaxUint64,
+			Uintptr: 42, Float32: 3.25, Float64: -1e21, Bool: true,
+			PInt: &i, PUint64: &u, PFloat64: &f, PBool: &b,
+			Omitted: 1 << 53,
+		},
+		{
+			Float32: math.MaxFloat32, Float64: 1e-7, PFloat32: &f32,
+			OmitBool: true, OmitFloat: 123456789.125, POmitFloat: &zero,
+			Ratio: 0.5, Enabled: true, PEnabled: &e,
+		},
+		{
+			Float32: math.SmallestNonzeroFloat32, Float64: math.MaxFloat64,
+			OmitFloat: -5e-324, Ratio: 1e21,
+		},
+	}
+}
+
+func TestQuotedMarshal(t *testing.T) {
+	for _, q := range quotedValues() {
+		out, err := q.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected, err := json.Marshal((*ff.QuotedStd)(q))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if string(out) != string(expected) {
+			t.Errorf("Expected: %s\nGot: %s", expected, out)
+		}
+	}
+}
+
+func TestQuotedUnmarshal(t *testing.T) {
+	for _, q := range quotedValues() {
+		data, err := json.Marshal((*ff.QuotedStd)(q))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var got ff.Quoted
+		err = got.UnmarshalJSON(data)
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", data, err)
+		}
+		if !reflect.DeepEqual(&got, q) {
+			t.Errorf("UnmarshalJSON(%s)\nExpected: %+v\nGot: %+v", data, q, &got)
+		}
+	}
+}
+
+func TestQuotedNull(t *testing.T) {
+	i, b := 1, true
+	q := ff.Quoted{PInt: &i, PBool: &b, Int: 3}
+	err := q.UnmarshalJSON([]byte(`{"pint":null,"pbool":null,"pfloat64":null,"int":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	// null sets pointers to nil, and leaves other fields unchanged, as
+	// with encoding/json.
+	var std ff.QuotedStd
+	std.PInt, std.PBool, std.Int = &i, &b, 3
+	json.Unmarshal([]byte(`{"pint":null,"pbool":null,"pfloat64":null,"int":null}`), &std)
+	if q.PInt != nil || q.PBool != nil || q.PFloat64 != nil || q.Int != 3 || std.PInt != nil || std.Int != 3 {
+		t.Fatalf("Unexpected values: %+v, encoding/json: %+v", q, std)
+	}
+}
+
+func TestQuotedInvalid(t *testing.T) {
+	for _, input := range []string{
+		`{"int":"x"}`,
+		`{"int8":"128"}`,
+		`{"uint":"-1"}`,
+		`{"bool":"yes"}`,
+		`{"float64":"1e400"}`,
+		`{"pint":"1.5"}`,
+		`{"enabled":"1"}`,
+		`{"penabled":"on"}`,
+		`{"ratio":"x"}`,
+		`{"pfloat32":"1e39"}`,
+	} {
+		var q ff.Quoted
+		err := q.UnmarshalJSON([]byte(input))
+		if err == nil {
+			t.Errorf("%s: expected an error, got %+v", input, q)
+			continue
+		}
+		var std ff.QuotedStd
+		if json.Unmarshal([]byte(input), &std) == nil {
+			t.Errorf("%s: encoding/json accepts it", input)
+		}
+		if strings.Contains(err.Error(), "panic") {
+			t.Errorf("%s: %v", input, err)
+		}
+	}
+}
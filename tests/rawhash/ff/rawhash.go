@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Document records the hash of the json it was decoded from.
+type Document struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	ETag  string `json:"-" ffjson:"rawhash"`
+}
+
+// Blob records a raw SHA-1 hash.
+type Blob struct {
+	Data string `json:"data"`
+	Sum  []byte `json:"-" ffjson:"rawhash=sha1"`
+}
+
+// Page holds documents.
+type Page struct {
+	Docs []Document `json:"docs"`
+	Hash string     `json:"-" ffjson:"rawhash=md5"`
+}
@@ -0,0 +1,99 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/rawhash/ff"
+)
+
+func TestRawHashHex(t *testing.T) {
+	in := []byte(`{ "title": "a",  "id": 1 }`)
+	var d ff.Document
+	err := d.UnmarshalJSON(in)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	sum := sha256.Sum256(in)
+	if d.ETag != hex.EncodeToString(sum[:]) {
+		t.Fatalf("Expected: %x\nGot: %s", sum, d.ETag)
+	}
+
+	// The hash is of the input, not of the re-encoded output.
+	out, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `{"id":1,"title":"a"}` {
+		t.Fatalf("unexpected output %s", out)
+	}
+	var again ff.Document
+	err = again.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if again.ETag == d.ETag {
+		t.Fatalf("expected the hash of different input to differ")
+	}
+}
+
+func TestRawHashBytes(t *testing.T) {
+	in := []byte(`{"data":"x"}`)
+	var b ff.Blob
+	err := b.UnmarshalJSON(in)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	sum := sha1.Sum(in)
+	if !bytes.Equal(b.Sum, sum[:]) {
+		t.Fatalf("Expected: %x\nGot: %x", sum, b.Sum)
+	}
+}
+
+func TestRawHashNested(t *testing.T) {
+	in := []byte(`{"docs":[{"id":1,"title":"a"}]}`)
+	var p ff.Page
+	err := p.UnmarshalJSON(in)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	sum := md5.Sum(in)
+	if p.Hash != hex.EncodeToString(sum[:]) {
+		t.Fatalf("Expected: %x\nGot: %s", sum, p.Hash)
+	}
+	if p.Docs[0].ETag != "" {
+		t.Fatalf("expected nested documents to be left without a hash, got %s", p.Docs[0].ETag)
+	}
+}
+
+func TestRawHashError(t *testing.T) {
+	d := ff.Document{ETag: "old"}
+	err := d.UnmarshalJSON([]byte(`{"id":"x"}`))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if d.ETag != "old" {
+		t.Fatalf("expected a failed decode to leave the hash unchanged, got %s", d.ETag)
+	}
+}
@@ -0,0 +1,39 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Credentials struct
+type Credentials struct {
+	User         string  `json:"user"`
+	Password     string  `json:"password"`
+	APIToken     *string `json:"api_token,omitempty"`
+	SSN          string  `json:"ssn" ffjson:"redact"`
+	TokenCount   int     `json:"token_count" ffjson:"noredact"`
+	ClientSecret string  `json:"clientSecret"`
+}
+
+// Login struct
+type Login struct {
+	Host    string                  `json:"host"`
+	Creds   Credentials             `json:"creds"`
+	Backup  *Credentials            `json:"backup"`
+	History []Credentials           `json:"history,omitempty"`
+	ByHost  map[string]*Credentials `json:"by_host,omitempty"`
+	Vault   *Vault                  `json:"vault,omitempty"`
+	Extra   interface{}             `json:"extra,omitempty" ffjson:"noredact"`
+}
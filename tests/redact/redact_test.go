@@ -0,0 +1,100 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/redact/ff"
+)
+
+func TestRedacted(t *testing.T) {
+	token := "t0k3n"
+	c := &ff.Credentials{
+		User:         "admin",
+		Password:     "hunter2",
+		APIToken:     &token,
+		SSN:          "078-05-1120",
+		TokenCount:   3,
+		ClientSecret: "s3cr3t",
+	}
+
+	out, err := c.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	expected := `{"user":"admin","password":"[REDACTED]","api_token":"[REDACTED]","ssn":"[REDACTED]","token_count":3,"clientSecret":"[REDACTED]"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	c.APIToken = nil
+	out, err = c.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	expected = `{"user":"admin","password":"[REDACTED]","ssn":"[REDACTED]","token_count":3,"clientSecret":"[REDACTED]"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestRedactedRegularMarshal(t *testing.T) {
+	c := &ff.Credentials{User: "admin", Password: "hunter2", SSN: "078-05-1120"}
+	out, err := c.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"user":"admin","password":"hunter2","ssn":"078-05-1120","token_count":0,"clientSecret":""}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestRedactedNested(t *testing.T) {
+	l := &ff.Login{Host: "db", Creds: ff.Credentials{Password: "hunter2"}, Backup: &ff.Credentials{User: "backup"}}
+	out, err := l.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	expected := `{ "host":"db","creds":{"user":"","password":"[REDACTED]","ssn":"[REDACTED]","token_count":0,"clientSecret":"[REDACTED]"},` +
+		`"backup":{"user":"backup","password":"[REDACTED]","ssn":"[REDACTED]","token_count":0,"clientSecret":"[REDACTED]"}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	// Structs in slices and maps, and structs of other files generated
+	// with redaction, are redacted too.
+	l = &ff.Login{
+		History: []ff.Credentials{{Password: "hunter2"}},
+		ByHost:  map[string]*ff.Credentials{"a": {ClientSecret: "s"}, "b": nil},
+		Vault:   &ff.Vault{Name: "v", MasterKey: "k"},
+	}
+	out, err = l.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	expected = `{ "host":"",` +
+		`"creds":{"user":"","password":"[REDACTED]","ssn":"[REDACTED]","token_count":0,"clientSecret":"[REDACTED]"},"backup":null,` +
+		`"history":[{"user":"","password":"[REDACTED]","ssn":"[REDACTED]","token_count":0,"clientSecret":"[REDACTED]"}],` +
+		`"by_host":{ "a":{"user":"","password":"[REDACTED]","ssn":"[REDACTED]","token_count":0,"clientSecret":"[REDACTED]"},"b":null},` +
+		`"vault":{"name":"v","master_token":"[REDACTED]"}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
@@ -0,0 +1,42 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Node is a node of a graph, which may be shared and contain cycles.
+// ffjson: refs
+type Node struct {
+	Name     string  `json:"name"`
+	Parent   *Node   `json:"parent,omitempty"`
+	Next     *Node   `json:"next"`
+	Children []*Node `json:"children,omitempty"`
+	Owner    *Owner  `json:"owner,omitempty"`
+}
+
+// Owner struct
+// ffjson: refs
+type Owner struct {
+	ID    int     `json:"id"`
+	Nodes []*Node `json:"nodes"`
+}
+
+// Graph struct
+// ffjson: refs
+type Graph struct {
+	Root  *Node   `json:"root"`
+	Nodes []*Node `json:"a/b~c"`
+}
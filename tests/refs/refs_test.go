@@ -0,0 +1,152 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/refs/ff"
+)
+
+func TestRefsShared(t *testing.T) {
+	a := &ff.Node{Name: "a"}
+	b := &ff.Node{Name: "b", Parent: a, Next: a}
+	a.Next = b
+	a.Children = []*ff.Node{b, nil, b}
+	b.Owner = &ff.Owner{ID: 1, Nodes: []*ff.Node{a}}
+	g := &ff.Graph{Root: a, Nodes: []*ff.Node{b, a}}
+
+	out, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"root":{ "name":"a","next":{ "name":"b","parent":{"$ref":"#/root"},"next":{"$ref":"#/root"},` +
+		`"owner":{"id":1,"nodes":[{"$ref":"#/root"}]}},"children":[{"$ref":"#/root/next"},null,{"$ref":"#/root/next"}]},` +
+		`"a/b~c":[{"$ref":"#/root/next"},{"$ref":"#/root"}]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Graph
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	ga := got.Root
+	gb := ga.Next
+	if ga.Name != "a" || gb.Name != "b" || gb.Parent != ga || gb.Next != ga {
+		t.Fatalf("shared pointers were not restored: %+v %+v", ga, gb)
+	}
+	if len(ga.Children) != 3 || ga.Children[0] != gb || ga.Children[1] != nil || ga.Children[2] != gb {
+		t.Fatalf("shared slice elements were not restored: %+v", ga.Children)
+	}
+	if gb.Owner.ID != 1 || gb.Owner.Nodes[0] != ga || got.Nodes[0] != gb || got.Nodes[1] != ga {
+		t.Fatalf("shared pointers in other types were not restored: %+v %+v", gb.Owner, got.Nodes)
+	}
+
+	again, err := got.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(again) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(again))
+	}
+}
+
+func TestRefsEscaping(t *testing.T) {
+	c := &ff.Node{Name: "c"}
+	g := &ff.Graph{Nodes: []*ff.Node{c, c}}
+	out, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"root":null,"a/b~c":[{ "name":"c","next":null},{"$ref":"#/a~1b~0c/0"}]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Graph
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if len(got.Nodes) != 2 || got.Nodes[0] != got.Nodes[1] || got.Nodes[0].Name != "c" {
+		t.Fatalf("Expected the same node twice, got %+v", got.Nodes)
+	}
+}
+
+func TestRefsCycleToRoot(t *testing.T) {
+	o := &ff.Owner{ID: 2}
+	o.Nodes = []*ff.Node{{Name: "n", Owner: o}}
+	out, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"id":2,"nodes":[{ "name":"n","next":null,"owner":{"$ref":"#"}}]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Owner
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if got.Nodes[0].Owner != &got {
+		t.Fatalf("Expected a reference to the outermost value")
+	}
+}
+
+func TestRefsToRefs(t *testing.T) {
+	// A reference to a reference shares the value the first one refers to.
+	var n ff.Node
+	err := n.UnmarshalJSON([]byte(`{"name":"r","children":[{"name":"s"},{"$ref":"#/children/0"},{"$ref":"#/children/1"}]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if len(n.Children) != 3 || n.Children[0].Name != "s" || n.Children[1] != n.Children[0] || n.Children[2] != n.Children[0] {
+		t.Fatalf("Expected three pointers to the same node, got %+v", n.Children)
+	}
+}
+
+func TestRefsInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{`{"root":{"name":"a","next":{"$ref":"#/nodes/0"}}}`, `reference "#/nodes/0" does not refer to a value read before`},
+		{`{"root":{"name":"a","next":{"$ref":"root"}}}`, `reference "root" is not a JSON Pointer fragment`},
+		{`{"root":{"name":"a","next":{"$ref":1}}}`, `wanted token`},
+		{`{"$ref":"#"}`, `cannot resolve the reference "#" of the outermost value`},
+		{`{"root":{"name":"r","children":[{"$ref":"#/root/children/0"}]}}`, `reference "#/root/children/0" refers to itself`},
+	}
+	for _, test := range tests {
+		var g ff.Graph
+		err := g.UnmarshalJSON([]byte(test.in))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("UnmarshalJSON(%s): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+	}
+
+	var o ff.Owner
+	err := o.UnmarshalJSON([]byte(`{"id":1,"nodes":[{"name":"x","next":{"$ref":"#"}}]}`))
+	if err == nil || !strings.Contains(err.Error(), `reference "#" is a *ff.Owner, not a *ff.Node`) {
+		t.Errorf("expected a type mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Reset zeroes the fields missing in the JSON.
+//
+// ffjson: resetFields
+type Reset struct {
+	Name  string            `json:"name"`
+	Ptr   *int              `json:"ptr"`
+	List  []string          `json:"list"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+// Keep leaves them unchanged, as by default.
+type Keep struct {
+	Name string   `json:"name"`
+	Ptr  *int     `json:"ptr"`
+	List []string `json:"list"`
+}
@@ -0,0 +1,80 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/resetfields/ff"
+	"github.com/maxproc/ffjson/tests/resetfields/global"
+)
+
+const (
+	fullJSON    = `{"name":"a","ptr":1,"list":["x"],"attrs":{"k":"v"}}`
+	partialJSON = `{"name":"b"}`
+)
+
+func TestResetFieldsDirective(t *testing.T) {
+	var r ff.Reset
+	if err := r.UnmarshalJSON([]byte(fullJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if err := r.UnmarshalJSON([]byte(partialJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Name != "b" || r.Ptr != nil || r.List != nil || r.Attrs != nil {
+		t.Fatalf("Missing fields not reset: %+v", r)
+	}
+
+	// Without the directive nor -reset-fields, the fields are kept.
+	var k ff.Keep
+	if err := k.UnmarshalJSON([]byte(fullJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if err := k.UnmarshalJSON([]byte(partialJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if k.Name != "b" || k.Ptr == nil || *k.Ptr != 1 || len(k.List) != 1 {
+		t.Fatalf("Missing fields reset: %+v", k)
+	}
+}
+
+func TestResetFieldsDefault(t *testing.T) {
+	// The types of global are generated with -reset-fields.
+	var d global.Default
+	if err := d.UnmarshalJSON([]byte(fullJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if err := d.UnmarshalJSON([]byte(partialJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if d.Name != "b" || d.Ptr != nil || d.List != nil {
+		t.Fatalf("Missing fields not reset: %+v", d)
+	}
+
+	var n global.NoReset
+	if err := n.UnmarshalJSON([]byte(fullJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if err := n.UnmarshalJSON([]byte(partialJSON)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if n.Name != "b" || n.Ptr == nil || *n.Ptr != 1 || len(n.List) != 1 {
+		t.Fatalf("Missing fields reset: %+v", n)
+	}
+}
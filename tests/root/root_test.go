@@ -0,0 +1,133 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/root/ff"
+)
+
+type handled struct {
+	object *ff.Result
+	array  []*ff.Result
+	calls  []string
+}
+
+func (h *handled) unmarshal(input string) error {
+	return ff.UnmarshalResultRoot([]byte(input), func(r *ff.Result) error {
+		h.calls = append(h.calls, "object")
+		h.object = r
+		return nil
+	}, func(rs []*ff.Result) error {
+		h.calls = append(h.calls, "array")
+		h.array = rs
+		return nil
+	})
+}
+
+func TestUnmarshalRootObject(t *testing.T) {
+	var h handled
+	err := h.unmarshal(" \n{\"id\":1,\"name\":\"a\"}\n")
+	if err != nil {
+		t.Fatalf("UnmarshalResultRoot: %v", err)
+	}
+	if len(h.calls) != 1 || h.calls[0] != "object" || h.object.ID != 1 || h.object.Name != "a" {
+		t.Fatalf("unexpected result: %+v %+v", h, h.object)
+	}
+}
+
+func TestUnmarshalRootArray(t *testing.T) {
+	var h handled
+	err := h.unmarshal(`[{"id":1},null, {"id":2,"name":"b"}]`)
+	if err != nil {
+		t.Fatalf("UnmarshalResultRoot: %v", err)
+	}
+	if len(h.calls) != 1 || h.calls[0] != "array" || len(h.array) != 3 {
+		t.Fatalf("unexpected result: %+v", h)
+	}
+	if h.array[0].ID != 1 || h.array[1] != nil || h.array[2].Name != "b" {
+		t.Fatalf("unexpected elements: %+v %+v %+v", h.array[0], h.array[1], h.array[2])
+	}
+
+	h = handled{}
+	err = h.unmarshal(`[]`)
+	if err != nil {
+		t.Fatalf("UnmarshalResultRoot: %v", err)
+	}
+	if h.array == nil || len(h.array) != 0 {
+		t.Fatalf("expected an empty slice, got %#v", h.array)
+	}
+}
+
+func TestUnmarshalRootNull(t *testing.T) {
+	var h handled
+	err := h.unmarshal(` null `)
+	if err != nil {
+		t.Fatalf("UnmarshalResultRoot: %v", err)
+	}
+	if len(h.calls) != 1 || h.calls[0] != "object" || h.object != nil {
+		t.Fatalf("unexpected result: %+v", h)
+	}
+}
+
+func TestUnmarshalRootHandlerError(t *testing.T) {
+	errStop := errors.New("stop")
+	err := ff.UnmarshalResultRoot([]byte(`{}`), func(*ff.Result) error { return errStop }, nil)
+	if err != errStop {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+}
+
+func TestUnmarshalRootInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{``, "EOF"},
+		{`"x"`, "wanted an object or an array"},
+		{`{"id":1} {}`, "after the root value"},
+		{`[{"id":1},2]`, "wanted an object or null in the array"},
+		{`[{"id":"x"}]`, "cannot unmarshal"},
+		{`[{}`, ""},
+	}
+
+	for _, test := range tests {
+		var h handled
+		err := h.unmarshal(test.in)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("UnmarshalResultRoot(%s): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+		if len(h.calls) != 0 {
+			t.Errorf("UnmarshalResultRoot(%s): unexpected calls %v", test.in, h.calls)
+		}
+	}
+}
+
+func TestUnmarshalRootMissingHandler(t *testing.T) {
+	err := ff.UnmarshalResultRoot([]byte(`[]`), func(*ff.Result) error { return nil }, nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected array") {
+		t.Fatalf("expected an error for the missing array handler, got %v", err)
+	}
+	err = ff.UnmarshalResultRoot([]byte(`null`), nil, func([]*ff.Result) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "unexpected object") {
+		t.Fatalf("expected an error for the missing object handler, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Reading struct
+type Reading struct {
+	Meters      int64    `json:"mm" ffjson:"scale=1000"`
+	Kilograms   *int32   `json:"g,omitempty" ffjson:"scale=1000,round=trunc"`
+	Seconds     uint16   `json:"ms" ffjson:"scale=1000,round=exact"`
+	Celsius     float64  `json:"milli_c" ffjson:"scale=1000"`
+	Volts       *float32 `json:"mv" ffjson:"scale=1e3"`
+	Temperature int8     `json:"deci_c" ffjson:"scale=10,round=floor"`
+}
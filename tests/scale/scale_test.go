@@ -0,0 +1,93 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/scale/ff"
+)
+
+func TestScaleMarshal(t *testing.T) {
+	kg := int32(-3)
+	v := float32(1.5)
+	r := ff.Reading{Meters: 2, Kilograms: &kg, Seconds: 60, Celsius: 21.5, Volts: &v, Temperature: -4}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"mm":2000,"g":-3000,"ms":60000,"milli_c":21500,"mv":1500,"deci_c":-40}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Reading
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON(%s): %v", out, err)
+	}
+	if got.Meters != 2 || *got.Kilograms != -3 || got.Seconds != 60 || got.Celsius != 21.5 || *got.Volts != 1.5 || got.Temperature != -4 {
+		t.Fatalf("Unexpected result: %+v", got)
+	}
+}
+
+func TestScaleRounding(t *testing.T) {
+	var r ff.Reading
+	err := r.UnmarshalJSON([]byte(`{"mm":1500,"g":-1999,"ms":1000,"milli_c":1,"mv":null,"deci_c":-41}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Meters != 2 {
+		t.Fatalf("Expected: 2\nGot: %v", r.Meters)
+	}
+	if *r.Kilograms != -1 {
+		t.Fatalf("Expected: -1\nGot: %v", *r.Kilograms)
+	}
+	if r.Celsius != 0.001 {
+		t.Fatalf("Expected: 0.001\nGot: %v", r.Celsius)
+	}
+	if r.Volts != nil {
+		t.Fatalf("Expected nil, got: %v", *r.Volts)
+	}
+	if r.Temperature != -5 {
+		t.Fatalf("Expected: -5\nGot: %v", r.Temperature)
+	}
+}
+
+func TestScaleErrors(t *testing.T) {
+	r := ff.Reading{Meters: math.MaxInt64 / 100}
+	_, err := r.MarshalJSON()
+	if err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("Expected overflow error, got: %v", err)
+	}
+
+	for input, msg := range map[string]string{
+		`{"ms":1001}`:     "not a multiple",
+		`{"ms":65536000}`: "overflows uint16",
+		`{"deci_c":1290}`: "overflows int8",
+		`{"mm":1.5}`:      "cannot unmarshal tok:double",
+	} {
+		var r ff.Reading
+		err := r.UnmarshalJSON([]byte(input))
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("UnmarshalJSON(%s): expected error containing %q, got: %v", input, msg, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Tag struct
+type Tag struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// Node struct
+type Node struct {
+	ID       uint32            `json:"id"`
+	Label    string            `json:"label"`
+	Weight   float64           `json:"weight,string"`
+	Price    int64             `json:"price" ffjson:"scale=100"`
+	Active   bool              `json:"active"`
+	Parent   *Node             `json:"parent,omitempty"`
+	Children []*Node           `json:"children"`
+	Tags     []Tag             `json:"tags,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Point    [2]int            `json:"point"`
+	Created  time.Time         `json:"created"`
+	Note     *string           `json:"note,omitempty"`
+	Extra    interface{}       `json:"extra,omitempty"`
+	Owner    struct {
+		Name string `json:"name"`
+	} `json:"owner"`
+}
+
+// ffjson: envelope node {"version":1}
+type Wrapped struct {
+	ID int `json:"id"`
+}
+
+// Settings struct
+type Settings struct {
+	Host string `json:"host" ffjson:"group=db"`
+	Port int    `json:"port,omitempty" ffjson:"group=db"`
+}
@@ -0,0 +1,95 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/schema/ff"
+)
+
+func TestSchemaFlat(t *testing.T) {
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Tag","type":"object",` +
+		`"properties":{"name":{"type":"string"},"color":{"type":"string"}},"required":["name"]}`
+	out := ff.Tag{}.JSONSchema()
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestSchemaEnvelope(t *testing.T) {
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Wrapped","type":"object",` +
+		`"properties":{"version":{"const":1},"node":{"$ref":"#/$defs/Wrapped"}},"required":["version","node"],` +
+		`"$defs":{"Wrapped":{"type":"object","properties":{"id":{"type":"integer"}},"required":["id"]}}}`
+	out := ff.Wrapped{}.JSONSchema()
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestSchemaNested(t *testing.T) {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	err := json.Unmarshal(ff.Node{}.JSONSchema(), &schema)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"id":       `{"type":"integer","minimum":0}`,
+		"weight":   `{"type":"string"}`,
+		"price":    `{"type":"number"}`,
+		"parent":   `{"anyOf":[{"$ref":"#"},{"type":"null"}]}`,
+		"children": `{"type":"array","items":{"anyOf":[{"$ref":"#"},{"type":"null"}]}}`,
+		"tags":     `{"type":"array","items":{"$ref":"#/$defs/Tag"}}`,
+		"attrs":    `{"type":"object","additionalProperties":{"type":"string"}}`,
+		"data":     `{"type":"string","contentEncoding":"base64"}`,
+		"point":    `{"type":"array","items":{"type":"integer"},"minItems":2,"maxItems":2}`,
+		"created":  `{"type":"string","format":"date-time"}`,
+		"note":     `{"type":["string","null"]}`,
+		"extra":    `{}`,
+		"owner":    `{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`,
+	} {
+		if string(schema.Properties[name]) != expected {
+			t.Fatalf("%s: Expected: %v\nGot: %v", name, expected, string(schema.Properties[name]))
+		}
+	}
+
+	required := []string{"id", "label", "weight", "price", "active", "children", "point", "created", "owner"}
+	if !reflect.DeepEqual(schema.Required, required) {
+		t.Fatalf("Expected required: %v\nGot: %v", required, schema.Required)
+	}
+	if _, ok := schema.Defs["Tag"]; !ok || len(schema.Defs) != 1 {
+		t.Fatalf("Expected $defs with Tag, got %v", schema.Defs)
+	}
+}
+
+func TestSchemaGroup(t *testing.T) {
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Settings","type":"object",` +
+		`"properties":{"db":{"type":"object","properties":{"host":{"type":"string"},"port":{"type":"integer"}},` +
+		`"required":["host"]}},"required":["db"]}`
+	out := ff.Settings{}.JSONSchema()
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
@@ -0,0 +1,32 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Samples struct
+type Samples struct {
+	Values []int64    `ffjson:"cap=1024"`
+	Labels *[]string  `ffjson:"cap=16"`
+	Points []*float64 `json:"points" ffjson:"cap=8"`
+}
+
+// SamplesNoCap struct
+type SamplesNoCap struct {
+	Values []int64
+	Labels *[]string
+	Points []*float64 `json:"points"`
+}
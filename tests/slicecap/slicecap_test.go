@@ -0,0 +1,113 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/slicecap/ff"
+)
+
+func samplesJSON(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"Values":[`)
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%d", i)
+	}
+	buf.WriteString(`],"Labels":["a","b"],"points":[1.5,null]}`)
+	return buf.Bytes()
+}
+
+func TestSliceCap(t *testing.T) {
+	var s ff.Samples
+	err := s.UnmarshalJSON(samplesJSON(1000))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if len(s.Values) != 1000 || cap(s.Values) != 1024 {
+		t.Fatalf("Expected len 1000, cap 1024, got len %d, cap %d", len(s.Values), cap(s.Values))
+	}
+	if s.Values[999] != 999 {
+		t.Fatalf("Expected: 999\n Got: %v", s.Values[999])
+	}
+	if s.Labels == nil || len(*s.Labels) != 2 || cap(*s.Labels) != 16 {
+		t.Fatalf("Unexpected labels: %v", s.Labels)
+	}
+	if len(s.Points) != 2 || cap(s.Points) != 8 || *s.Points[0] != 1.5 || s.Points[1] != nil {
+		t.Fatalf("Unexpected points: %v", s.Points)
+	}
+}
+
+func TestSliceCapNull(t *testing.T) {
+	s := ff.Samples{Values: []int64{1}}
+	err := s.UnmarshalJSON([]byte(`{"Values":null,"Labels":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if s.Values != nil || s.Labels != nil {
+		t.Fatalf("Expected nil slices, got: %v %v", s.Values, s.Labels)
+	}
+}
+
+func TestSliceCapAllocs(t *testing.T) {
+	buf := samplesJSON(1000)
+	withCap := testing.AllocsPerRun(100, func() {
+		var s ff.Samples
+		s.UnmarshalJSON(buf)
+	})
+	withoutCap := testing.AllocsPerRun(100, func() {
+		var s ff.SamplesNoCap
+		s.UnmarshalJSON(buf)
+	})
+	if withCap >= withoutCap {
+		t.Fatalf("Expected fewer allocations with cap, got %v with cap, %v without", withCap, withoutCap)
+	}
+}
+
+func BenchmarkUnmarshalSliceCap(b *testing.B) {
+	buf := samplesJSON(1000)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s ff.Samples
+		err := s.UnmarshalJSON(buf)
+		if err != nil {
+			b.Fatalf("UnmarshalJSON: %v", err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalSliceNoCap(b *testing.B) {
+	buf := samplesJSON(1000)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s ff.SamplesNoCap
+		err := s.UnmarshalJSON(buf)
+		if err != nil {
+			b.Fatalf("UnmarshalJSON: %v", err)
+		}
+	}
+}
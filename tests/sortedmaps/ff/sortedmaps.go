@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Label names a metric.
+type Label string
+
+// Index is written into golden files, so its maps are always sorted.
+// ffjson: sortedmaps
+type Index struct {
+	Counts map[string]int     `json:"counts"`
+	Names  map[Label]string   `json:"names"`
+	Flags  *map[string]bool   `json:"flags,omitempty"`
+	Levels map[int]string     `json:"levels"`
+	Scores map[string]float64 `json:"scores,omitempty"`
+}
+
+// Report sorts one of its maps.
+type Report struct {
+	Totals map[string]int `json:"totals" ffjson:"sorted"`
+	Extra  map[string]int `json:"extra"`
+}
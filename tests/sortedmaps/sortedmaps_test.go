@@ -0,0 +1,92 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/sortedmaps/ff"
+)
+
+func TestSortedMapsStruct(t *testing.T) {
+	flags := map[string]bool{"z": true, "a": false}
+	idx := ff.Index{
+		Counts: map[string]int{"c": 3, "a": 1, "b": 2, "d": 4},
+		Names:  map[ff.Label]string{"y": "Y", "x": "X"},
+		Flags:  &flags,
+		Levels: map[int]string{10: "ten", 9: "nine"},
+	}
+	expected := `{ "counts":{ "a":1,"b":2,"c":3,"d":4},"names":{ "x":"X","y":"Y"},"flags":{ "a":false,"z":true},"levels":{ "10":"ten","9":"nine"}}`
+	for i := 0; i < 20; i++ {
+		out, err := idx.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != expected {
+			t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+		}
+	}
+}
+
+func TestSortedMapsField(t *testing.T) {
+	r := ff.Report{
+		Totals: map[string]int{"c": 3, "a": 1, "b": 2, "d": 4, "e": 5},
+	}
+	expected := `{"totals":{ "a":1,"b":2,"c":3,"d":4,"e":5},"extra":null}`
+	for i := 0; i < 20; i++ {
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != expected {
+			t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+		}
+	}
+}
+
+func TestSortedMapsRuntime(t *testing.T) {
+	fflib.SetSortMapKeys(true)
+	defer fflib.SetSortMapKeys(false)
+
+	r := ff.Report{
+		Extra: map[string]int{"c": 3, "a": 1, "b": 2, "d": 4, "e": 5},
+	}
+	expected := `{"totals":null,"extra":{ "a":1,"b":2,"c":3,"d":4,"e":5}}`
+	for i := 0; i < 20; i++ {
+		out, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != expected {
+			t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+		}
+	}
+}
+
+func TestSortedMapsEmpty(t *testing.T) {
+	idx := ff.Index{Counts: map[string]int{}}
+	out, err := idx.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "counts":{},"names":null,"levels":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
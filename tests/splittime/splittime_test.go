@@ -0,0 +1,106 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/splittime/ff"
+)
+
+func TestSplitTimeMarshal(t *testing.T) {
+	r := ff.Record{
+		ID:      1,
+		Created: time.Date(2024, 2, 29, 13, 4, 5, 0, time.UTC),
+	}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "id":1,"date":"2024-02-29","time":"13:04:05Z"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	r.Modified = time.Date(2024, 3, 1, 8, 0, 0, 500000000, time.FixedZone("", -7*3600))
+	out, err = r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected = `{ "id":1,"date":"2024-02-29","time":"13:04:05Z","mdate":"2024-03-01","mtime":"08:00:00.5-07:00"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+
+	var got ff.Record
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !got.Created.Equal(r.Created) || !got.Modified.Equal(r.Modified) {
+		t.Fatalf("Expected: %v %v\nGot: %v %v", r.Created, r.Modified, got.Created, got.Modified)
+	}
+	if _, offset := got.Modified.Zone(); offset != -7*3600 {
+		t.Fatalf("expected the offset of the time part to be kept, got %v", got.Modified)
+	}
+}
+
+func TestSplitTimeMissingParts(t *testing.T) {
+	var r ff.Record
+	err := r.UnmarshalJSON([]byte(`{"time":"10:11:12.125","date":"2020-01-02","mdate":"2021-05-06"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if want := time.Date(2020, 1, 2, 10, 11, 12, 125000000, time.UTC); !r.Created.Equal(want) || r.Created.Location() != time.UTC {
+		t.Fatalf("Expected: %v\nGot: %v", want, r.Created)
+	}
+	if want := time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC); !r.Modified.Equal(want) {
+		t.Fatalf("Expected: %v\nGot: %v", want, r.Modified)
+	}
+
+	created := r.Created
+	err = r.UnmarshalJSON([]byte(`{"id":2,"date":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !r.Created.Equal(created) {
+		t.Fatalf("expected missing parts to leave the time unchanged, got %v", r.Created)
+	}
+}
+
+func TestSplitTimeInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{`{"time":"10:11:12"}`, "time part without a date part"},
+		{`{"date":"02/01/2020"}`, `invalid date "02/01/2020"`},
+		{`{"date":"2020-01-02","time":"10h"}`, `invalid time "10h"`},
+		{`{"date":1}`, "Created"},
+	}
+
+	for _, test := range tests {
+		var r ff.Record
+		err := r.UnmarshalJSON([]byte(test.in))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("UnmarshalJSON(%s): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+	}
+}
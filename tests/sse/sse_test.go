@@ -0,0 +1,55 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/sse/ff"
+)
+
+func TestMarshalSSE(t *testing.T) {
+	tests := []struct {
+		u        *ff.Update
+		event    string
+		expected string
+	}{
+		{&ff.Update{ID: 1, Status: "shipped"}, "update", "event: update\ndata: {\"id\":1,\"status\":\"shipped\"}\n\n"},
+		{&ff.Update{ID: 2, Status: "line\nbreak"}, "", "data: {\"id\":2,\"status\":\"line\\nbreak\"}\n\n"},
+		{nil, "gone", "event: gone\ndata: null\n\n"},
+	}
+
+	for _, test := range tests {
+		out, err := test.u.MarshalSSE(test.event)
+		if err != nil {
+			t.Errorf("MarshalSSE(%q): %v", test.event, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("MarshalSSE(%q)\nExpected: %q\nGot: %q", test.event, test.expected, out)
+		}
+	}
+}
+
+func TestMarshalSSEInvalidEvent(t *testing.T) {
+	u := &ff.Update{ID: 1}
+	_, err := u.MarshalSSE("a\nb")
+	if err == nil {
+		t.Fatalf("expected an error for an event name with a line break")
+	}
+}
@@ -0,0 +1,46 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package ff
+
+// Level is a named int of the package.
+type Level int
+
+const (
+	LevelLow Level = iota
+	LevelHigh
+)
+
+// Owner is a struct of the package used as a field.
+type Owner struct {
+	Name string `json:"name"`
+}
+
+// Audit is embedded in Document.
+type Audit struct {
+	Revision int    `json:"revision"`
+	Editor   string `json:"editor"`
+}
+
+// Document has fields -static can't describe, so its file falls back to
+// the inception program.
+type Document struct {
+	Audit
+	Title  string   `json:"title"`
+	Level  Level    `json:"level"`
+	Owner  Owner    `json:"owner"`
+	Owners []*Owner `json:"owners"`
+}
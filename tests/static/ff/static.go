@@ -0,0 +1,56 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package ff
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Record is generated with -static, from the source of the package only.
+type Record struct {
+	ID       int64          `json:"id"`
+	Name     string         `json:"name"`
+	Score    float64        `json:"score,omitempty"`
+	Active   bool           `json:"active"`
+	Tags     []string       `json:"tags"`
+	Counts   map[string]int `json:"counts"`
+	Parent   *int           `json:"parent"`
+	Created  time.Time      `json:"created"`
+	Amount   json.Number    `json:"amount"`
+	Extra    interface{}    `json:"extra"`
+	Location struct {
+		Lat float64 `json:"lat"`
+		Lng float64 `json:"lng"`
+	} `json:"location"`
+
+	// cache isn't encoded, so its type doesn't have to be known.
+	cache *index
+}
+
+// Custom has its own MarshalJSON, so only its decoder is generated.
+type Custom struct {
+	Value string `json:"value"`
+}
+
+func (c Custom) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"custom": c.Value})
+}
+
+type index struct {
+	byName map[string]int
+}
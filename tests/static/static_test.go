@@ -0,0 +1,129 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/static/ff"
+)
+
+func TestStaticRoundTrip(t *testing.T) {
+	parent := 3
+	in := ff.Record{
+		ID:      1,
+		Name:    "a",
+		Score:   2.5,
+		Active:  true,
+		Tags:    []string{"x", "y"},
+		Counts:  map[string]int{"k": 4},
+		Parent:  &parent,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Amount:  json.Number("12.50"),
+		Extra:   "e",
+	}
+	in.Location.Lat = 1.5
+	in.Location.Lng = -2
+
+	data, err := in.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	std, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		t.Fatalf("json.Compact: %v", err)
+	}
+	if compact.String() != string(std) {
+		t.Errorf("MarshalJSON = %s, want %s", data, std)
+	}
+
+	var out ff.Record
+	if err := out.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	again, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(again) != string(std) {
+		t.Errorf("round trip = %s, want %s", again, std)
+	}
+}
+
+func TestStaticKeepsCustomMarshalJSON(t *testing.T) {
+	c := ff.Custom{Value: "v"}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(data) != `{"custom":"v"}` {
+		t.Errorf("json.Marshal = %s, want the custom encoding", data)
+	}
+
+	var out ff.Custom
+	if err := out.UnmarshalJSON([]byte(`{"value":"w"}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if out.Value != "w" {
+		t.Errorf("Value = %q, want w", out.Value)
+	}
+}
+
+func TestStaticFallback(t *testing.T) {
+	in := ff.Document{
+		Audit:  ff.Audit{Revision: 2, Editor: "e"},
+		Title:  "t",
+		Level:  ff.LevelHigh,
+		Owner:  ff.Owner{Name: "o"},
+		Owners: []*ff.Owner{{Name: "p"}, nil},
+	}
+
+	data, err := in.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	std, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		t.Fatalf("json.Compact: %v", err)
+	}
+	if compact.String() != string(std) {
+		t.Errorf("MarshalJSON = %s, want %s", data, std)
+	}
+
+	var out ff.Document
+	if err := out.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	again, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(again) != string(std) {
+		t.Errorf("round trip = %s, want %s", again, std)
+	}
+}
@@ -0,0 +1,240 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/maxproc/ffjson/ffjson"
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/stream/ff"
+)
+
+func largeRecord(n int) *ff.Record {
+	r := &ff.Record{ID: 1, Name: "<large>", Tags: []string{"a", "b"}, Attrs: map[string]string{"k": "v"}}
+	for i := 0; i < n; i++ {
+		r.Items = append(r.Items, &ff.Item{SKU: fmt.Sprintf("sku-%d", i), Price: float64(i) / 4})
+	}
+	return r
+}
+
+// countingWriter records the size of each write.
+type countingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestEncodeJSON(t *testing.T) {
+	for _, r := range []*ff.Record{{}, largeRecord(1), largeRecord(1000), nil} {
+		expected, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		var w countingWriter
+		err = r.EncodeJSON(&w)
+		if err != nil {
+			t.Fatalf("EncodeJSON: %v", err)
+		}
+		if !bytes.Equal(w.Bytes(), expected) {
+			t.Fatalf("Expected: %s\nGot: %s", expected, w.Bytes())
+		}
+
+		// Large values are written in chunks, instead of buffering the
+		// whole output.
+		chunks := (len(expected) + fflib.StreamChunkSize - 1) / fflib.StreamChunkSize
+		if len(w.writes) < chunks {
+			t.Fatalf("Expected at least %d writes for %d bytes, got %v", chunks, len(expected), w.writes)
+		}
+		for _, n := range w.writes {
+			if n > 2*fflib.StreamChunkSize {
+				t.Fatalf("Expected writes of about %d bytes, got %v", fflib.StreamChunkSize, w.writes)
+			}
+		}
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestEncodeJSONWriteError(t *testing.T) {
+	err := largeRecord(1000).EncodeJSON(errWriter{})
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Expected the error of the writer, got %v", err)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	expected := largeRecord(100)
+	data, err := expected.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	// The value spans many reads of a byte.
+	var r ff.Record
+	err = r.DecodeJSON(iotest.OneByteReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("DecodeJSON: %v", err)
+	}
+	if !reflect.DeepEqual(&r, expected) {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, &r)
+	}
+}
+
+func TestDecodeJSONStream(t *testing.T) {
+	input := `{"id":1,"name":"a","items":null}` + "\n" + ` {"id":2,"name":"b","items":[{"sku":"x","price":1.5}]}` + "\n"
+	br := bufio.NewReader(iotest.HalfReader(strings.NewReader(input)))
+
+	var records []ff.Record
+	for {
+		var r ff.Record
+		err := r.DecodeJSON(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("DecodeJSON: %v", err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 || records[0].ID != 1 || records[1].Name != "b" || records[1].Items[0].Price != 1.5 {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+}
+
+func TestDecodeJSONInvalid(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{`{"id":1,"name":"a"`, io.ErrUnexpectedEOF},
+		{``, io.EOF},
+		{`{"id":"x"}`, nil},
+		{`[1]`, nil},
+	}
+
+	for _, test := range tests {
+		var r ff.Record
+		err := r.DecodeJSON(strings.NewReader(test.input))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+			continue
+		}
+		if test.err != nil && err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.input, test.err, err)
+		}
+	}
+}
+
+func TestStreamDecoderElements(t *testing.T) {
+	var w bytes.Buffer
+	items := make([]ff.Item, 1000)
+	for i := range items {
+		items[i] = ff.Item{SKU: fmt.Sprintf("sku-%d", i), Price: float64(i) / 4}
+	}
+	err := ffjson.NewEncoder(&w).EncodeArray(items)
+	if err != nil {
+		t.Fatalf("EncodeArray: %v", err)
+	}
+	w.WriteString(` {"id":9}`)
+
+	dec := ffjson.NewStreamDecoder(iotest.HalfReader(&w))
+	var got []ff.Item
+	for {
+		var item ff.Item
+		err := dec.DecodeElement(&item)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("DecodeElement: %v", err)
+		}
+		got = append(got, item)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Fatalf("Decoded %d items, different from the %d encoded", len(got), len(items))
+	}
+
+	var r ff.Record
+	if err := dec.Decode(&r); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if r.ID != 9 {
+		t.Errorf("Expected the value after the array, got %+v", r)
+	}
+	if err := dec.Decode(&r); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+func TestEncodeArray(t *testing.T) {
+	items := []*ff.Item{{SKU: "a", Price: 1}, nil, {SKU: "<b>"}}
+	for _, v := range []interface{}{items, &items, [1]int{7}, []map[string]int{{"k": 1}}, []int(nil)} {
+		var w countingWriter
+		err := ffjson.NewEncoder(&w).EncodeArray(v)
+		if err != nil {
+			t.Fatalf("EncodeArray(%T): %v", v, err)
+		}
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if w.String() != string(expected) {
+			t.Errorf("EncodeArray(%T)\nExpected: %s\nGot: %s", v, expected, w.String())
+		}
+	}
+
+	err := ffjson.NewEncoder(io.Discard).EncodeArray(ff.Item{})
+	if err == nil {
+		t.Fatal("Expected an error encoding a struct as an array")
+	}
+}
+
+func TestEncodeArrayChunks(t *testing.T) {
+	items := make([]ff.Item, 2000)
+	var w countingWriter
+	err := ffjson.NewEncoder(&w).EncodeArray(items)
+	if err != nil {
+		t.Fatalf("EncodeArray: %v", err)
+	}
+	if len(w.writes) < 2 {
+		t.Fatalf("Expected the array to be written in chunks, got %d writes of %d bytes", len(w.writes), w.Len())
+	}
+	for _, n := range w.writes[:len(w.writes)-1] {
+		if n > 2*fflib.StreamChunkSize {
+			t.Errorf("Expected chunks of about %d bytes, got a write of %d", fflib.StreamChunkSize, n)
+		}
+	}
+}
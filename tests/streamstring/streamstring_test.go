@@ -0,0 +1,163 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/maxproc/ffjson/ffjson"
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/streamstring/ff"
+)
+
+func TestStreamStringMarshal(t *testing.T) {
+	tests := []struct {
+		doc      ff.Document
+		expected string
+	}{
+		{ff.Document{Name: "a"}, `{ "name":"a","body":null}`},
+		{ff.Document{Body: strings.NewReader("")}, `{ "name":"","body":""}`},
+		{ff.Document{Body: strings.NewReader("line\n\"quoted\" <b>"), Attachment: strings.NewReader("日本")},
+			`{ "name":"","body":"line\n\"quoted\" \u003cb\u003e","attachment":"日本"}`},
+	}
+
+	for _, test := range tests {
+		out, err := test.doc.MarshalJSON()
+		if err != nil {
+			t.Errorf("MarshalJSON: %v", err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("Expected: %v\nGot: %v", test.expected, string(out))
+		}
+	}
+}
+
+// largeText is several MB of text with multi-byte characters and
+// characters needing escapes, so reads split them.
+func largeText() string {
+	return strings.Repeat("Grüße, 世界! \"quoted\"\t<tag> \U0001F600\n", 100000)
+}
+
+func TestStreamStringLarge(t *testing.T) {
+	text := largeText()
+	var expected fflib.Buffer
+	fflib.WriteJsonString(&expected, text)
+
+	for name, r := range map[string]func() io.Reader{
+		"whole":    func() io.Reader { return strings.NewReader(text) },
+		"one byte": func() io.Reader { return iotest.OneByteReader(strings.NewReader(text)) },
+		"half":     func() io.Reader { return iotest.HalfReader(strings.NewReader(text)) },
+	} {
+		doc := ff.Document{Name: "large", Body: r()}
+		out, err := doc.MarshalJSON()
+		if err != nil {
+			t.Fatalf("%s: MarshalJSON: %v", name, err)
+		}
+		want := `{ "name":"large","body":` + expected.String() + `}`
+		if string(out) != want {
+			t.Fatalf("%s: the streamed body differs from a string field", name)
+		}
+
+		var std struct {
+			Body string `json:"body"`
+		}
+		err = json.Unmarshal(out, &std)
+		if err != nil || std.Body != text {
+			t.Fatalf("%s: encoding/json decodes another body: %v", name, err)
+		}
+
+		var got ff.Document
+		err = got.UnmarshalJSON(out)
+		if err != nil {
+			t.Fatalf("%s: UnmarshalJSON: %v", name, err)
+		}
+		body, err := ioutil.ReadAll(got.Body)
+		if err != nil {
+			t.Fatalf("%s: ReadAll: %v", name, err)
+		}
+		if string(body) != text {
+			t.Fatalf("%s: the decoded body differs", name)
+		}
+	}
+}
+
+// maxWriter records the size of the largest write.
+type maxWriter struct {
+	n   int
+	max int
+}
+
+func (w *maxWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	if len(p) > w.max {
+		w.max = len(p)
+	}
+	return len(p), nil
+}
+
+func TestStreamStringEncoder(t *testing.T) {
+	text := largeText()
+	w := &maxWriter{}
+	enc := ffjson.NewEncoder(w)
+	enc.SetFlushSize(64 * 1024)
+
+	err := enc.Encode(&ff.Document{Body: strings.NewReader(text)})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if w.n < len(text) {
+		t.Fatalf("only %d bytes were written", w.n)
+	}
+	if w.max > 256*1024 {
+		t.Fatalf("the body was buffered: largest write of %d bytes", w.max)
+	}
+}
+
+func TestStreamStringUnmarshal(t *testing.T) {
+	var doc ff.Document
+	err := doc.UnmarshalJSON([]byte(`{"name":"a","body":"xé\n","attachment":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	body, _ := ioutil.ReadAll(doc.Body)
+	if string(body) != "xé\n" || doc.Attachment != nil {
+		t.Fatalf("unexpected values: %q, %v", body, doc.Attachment)
+	}
+
+	err = doc.UnmarshalJSON([]byte(`{"body":1}`))
+	if err == nil {
+		t.Fatalf("expected an error for a number")
+	}
+}
+
+func TestStreamStringReadError(t *testing.T) {
+	fail := errors.New("fail")
+	doc := ff.Document{Body: io.MultiReader(bytes.NewReader([]byte("partial")), iotest.ErrReader(fail))}
+	_, err := doc.MarshalJSON()
+	if err != fail {
+		t.Fatalf("expected %v, got %v", fail, err)
+	}
+}
@@ -0,0 +1,65 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Base is embedded in Payload, so its fields are known keys of Payload.
+type Base struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// Payload rejects keys that aren't its fields.
+//
+// ffjson: strict
+type Payload struct {
+	Base
+	Name   string  `json:"name"`
+	Secret string  `json:"-"`
+	Child  *Child  `json:"child,omitempty"`
+	Loose  *Loose  `json:"loose,omitempty"`
+	Where  string  `json:"city,omitempty" ffjson:"group=where"`
+	Amount float64 `json:"amount,omitempty"`
+}
+
+// Child is strict too.
+//
+// ffjson: strict
+type Child struct {
+	Name string `json:"name"`
+}
+
+// Loose ignores unknown keys, as by default.
+type Loose struct {
+	Name string `json:"name"`
+}
+
+// Exact matches keys only if they have the case of the json names.
+//
+// ffjson: exactcase
+type Exact struct {
+	Name   string `json:"name"`
+	UserID int    `json:"userId"`
+}
+
+// ExactStrict rejects keys of a different case as unknown.
+//
+// ffjson: strict
+// ffjson: exactcase
+type ExactStrict struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,125 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/strict/ff"
+)
+
+func TestStrictKnownFields(t *testing.T) {
+	var p ff.Payload
+	// Embedded fields, groups and keys matching case-insensitively are
+	// known, and unknown keys of structs without strict are ignored.
+	input := `{"id":1,"KIND":"a","name":"n","child":{"name":"c"},"loose":{"name":"l","extra":1},"where":{"city":"x"}}`
+	err := p.UnmarshalJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if p.ID != 1 || p.Kind != "a" || p.Name != "n" || p.Child.Name != "c" || p.Loose.Name != "l" || p.Where != "x" {
+		t.Fatalf("Unexpected value: %+v", p)
+	}
+}
+
+func TestStrictUnknownFields(t *testing.T) {
+	tests := []struct {
+		input string
+		key   string
+	}{
+		{`{"id":1,"foo":2}`, `"foo"`},
+		// Fields tagged json:"-" aren't known keys.
+		{`{"Secret":"s"}`, `"Secret"`},
+		{`{"-":"s"}`, `"-"`},
+		{`{"":1}`, `""`},
+		{`{"Base":{"id":1}}`, `"Base"`},
+		{`{"child":{"name":"c","age":3}}`, `"age"`},
+		{`{"where":{"city":"x","zip":"1"}}`, `"zip"`},
+	}
+
+	for _, test := range tests {
+		var p ff.Payload
+		err := p.UnmarshalJSON([]byte(test.input))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), "json: unknown field "+test.key) {
+			t.Errorf("%s: expected an unknown field %s, got %v", test.input, test.key, err)
+		}
+		if p.Secret != "" {
+			t.Errorf("%s: expected Secret to be left unset, got %q", test.input, p.Secret)
+		}
+	}
+}
+
+func TestStrictOffset(t *testing.T) {
+	var p ff.Payload
+	err := p.UnmarshalJSON([]byte(`{"id":1,` + "\n" + `"foo":2}`))
+	if err == nil || !strings.Contains(err.Error(), "offset=14 line=2") {
+		t.Fatalf("Expected an error at offset 14, line 2, got %v", err)
+	}
+}
+
+func TestExactCase(t *testing.T) {
+	var e ff.Exact
+	err := e.UnmarshalJSON([]byte(`{"name":"a","NAME":"b","userid":1,"userId":2}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if e.Name != "a" || e.UserID != 2 {
+		t.Fatalf("Expected keys of a different case to be ignored, got %+v", e)
+	}
+
+	var s ff.ExactStrict
+	err = s.UnmarshalJSON([]byte(`{"Name":"a"}`))
+	if err == nil || !strings.Contains(err.Error(), `json: unknown field "Name"`) {
+		t.Fatalf("Expected an unknown field \"Name\", got %v", err)
+	}
+	if err := s.UnmarshalJSON([]byte(`{"name":"a"}`)); err != nil || s.Name != "a" {
+		t.Fatalf("UnmarshalJSON: %v, %+v", err, s)
+	}
+}
+
+func TestDisallowUnknownFieldsToggle(t *testing.T) {
+	input := []byte(`{"name":"l","extra":1}`)
+
+	var l ff.Loose
+	if err := l.UnmarshalJSON(input); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	fflib.SetDisallowUnknownFields(true)
+	defer fflib.SetDisallowUnknownFields(false)
+	err := l.UnmarshalJSON(input)
+	if err == nil || !strings.Contains(err.Error(), `json: unknown field "extra"`) {
+		t.Fatalf("Expected an unknown field \"extra\", got %v", err)
+	}
+	var p ff.Payload
+	err = p.UnmarshalJSON([]byte(`{"loose":{"extra":1}}`))
+	if err == nil || !strings.Contains(err.Error(), `json: unknown field "extra"`) {
+		t.Fatalf("Expected an unknown field \"extra\" in the nested struct, got %v", err)
+	}
+
+	fflib.SetDisallowUnknownFields(false)
+	if err := l.UnmarshalJSON(input); err != nil {
+		t.Fatalf("UnmarshalJSON after switching back: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+// Event holds times written with each kind of format option.
+type Event struct {
+	Seen    time.Time   `json:"seen" ffjson:"format=unix"`
+	Sent    *time.Time  `json:"sent,omitempty" ffjson:"format=unixmilli"`
+	Stored  time.Time   `json:"stored" ffjson:"format=unixnano"`
+	Day     time.Time   `json:"day" ffjson:"format=2006-01-02"`
+	Logged  time.Time   `json:"logged" ffjson:"format=rfc3339nano"`
+	Retries []time.Time `json:"retries" ffjson:"format=rfc3339"`
+}
@@ -0,0 +1,103 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/timeformat/ff"
+)
+
+func TestTimeFormatMarshal(t *testing.T) {
+	ts := time.Date(2024, 2, 29, 13, 4, 5, 678000000, time.FixedZone("CET", 3600))
+	e := ff.Event{
+		Seen:    ts,
+		Sent:    &ts,
+		Stored:  ts,
+		Day:     ts,
+		Logged:  ts,
+		Retries: []time.Time{ts.Add(time.Minute)},
+	}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"seen":1709208245,"sent":1709208245678,"stored":1709208245678000000,"day":"2024-02-29",` +
+		`"logged":"2024-02-29T13:04:05.678+01:00","retries":["2024-02-29T13:05:05+01:00"]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestTimeFormatUnmarshal(t *testing.T) {
+	var e ff.Event
+	err := e.UnmarshalJSON([]byte(`{"seen":1709208245,"sent":1709208245678,"stored":1709208245678000001,` +
+		`"day":"2024-02-29","logged":"2024-02-29T13:04:05.678+01:00","retries":["2024-02-29T13:05:05Z"]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	ts := time.Date(2024, 2, 29, 12, 4, 5, 0, time.UTC)
+	if !e.Seen.Equal(ts) || e.Seen.Location() != time.UTC {
+		t.Fatalf("Unexpected seen time: %v", e.Seen)
+	}
+	if e.Sent == nil || !e.Sent.Equal(ts.Add(678*time.Millisecond)) {
+		t.Fatalf("Unexpected sent time: %v", e.Sent)
+	}
+	if !e.Stored.Equal(ts.Add(678000001)) {
+		t.Fatalf("Unexpected stored time: %v", e.Stored)
+	}
+	if !e.Day.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected day: %v", e.Day)
+	}
+	if !e.Logged.Equal(ts.Add(678 * time.Millisecond)) {
+		t.Fatalf("Unexpected logged time: %v", e.Logged)
+	}
+	retries := []time.Time{time.Date(2024, 2, 29, 13, 5, 5, 0, time.UTC)}
+	if !reflect.DeepEqual(e.Retries, retries) {
+		t.Fatalf("Expected: %v\nGot: %v", retries, e.Retries)
+	}
+
+	err = e.UnmarshalJSON([]byte(`{"sent":null,"day":"29/02/2024"}`))
+	if err == nil {
+		t.Fatalf("Expected an error for a day in another format")
+	}
+	err = e.UnmarshalJSON([]byte(`{"seen":"1709208245"}`))
+	if err == nil {
+		t.Fatalf("Expected an error for a quoted timestamp")
+	}
+}
+
+func TestTimeFormatZero(t *testing.T) {
+	// The zero time is too far from 1970 for a count of nanoseconds.
+	_, err := (&ff.Event{}).MarshalJSON()
+	if err == nil {
+		t.Fatalf("Expected an error for the zero time with unixnano")
+	}
+
+	out, err := (&ff.Event{Stored: time.Unix(0, 0)}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"seen":-62135596800,"stored":0,"day":"0001-01-01",` +
+		`"logged":"0001-01-01T00:00:00Z","retries":null}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
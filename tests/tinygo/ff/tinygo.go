@@ -0,0 +1,37 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Sensor struct
+type Sensor struct {
+	ID       uint32            `json:"id"`
+	Name     string            `json:"name"`
+	Enabled  bool              `json:"enabled"`
+	Readings []float64         `json:"readings"`
+	Offset   *int16            `json:"offset,omitempty"`
+	Raw      []byte            `json:"raw"`
+	Labels   map[string]string `json:"labels"`
+	Location Location          `json:"location"`
+	History  [2]*Location      `json:"history"`
+}
+
+// Location struct
+type Location struct {
+	Lat float32 `json:"lat"`
+	Lon float32 `json:"lon"`
+}
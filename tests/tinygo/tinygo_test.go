@@ -0,0 +1,67 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/tinygo/ff"
+)
+
+func TestTinyGoRoundTrip(t *testing.T) {
+	offset := int16(-3)
+	s := &ff.Sensor{
+		ID:       7,
+		Name:     "probe",
+		Enabled:  true,
+		Readings: []float64{1.5, 2.25},
+		Offset:   &offset,
+		Raw:      []byte("raw"),
+		Labels:   map[string]string{"room": "lab"},
+		Location: ff.Location{Lat: 1, Lon: 2},
+		History:  [2]*ff.Location{{Lat: 3, Lon: 4}, nil},
+	}
+	out, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ff.Sensor
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(s, &got) {
+		t.Fatalf("Expected: %+v\nGot: %+v", s, &got)
+	}
+}
+
+func TestTinyGoNoReflection(t *testing.T) {
+	src, err := ioutil.ReadFile("ff/tinygo_ffjson.go")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, s := range []string{`"reflect"`, `"encoding/json"`, "json.Unmarshal(", "buf.Encode("} {
+		if strings.Contains(string(src), s) {
+			t.Errorf("generated code contains %s", s)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/maxproc/ffjson/shared"
+)
+
+// Patch struct
+type Patch struct {
+	Name      *string         `json:"name" ffjson:"tristate"`
+	NameState shared.TriState `json:"-"`
+	Age       *int            `json:"age" ffjson:"tristate"`
+	AgeState  shared.TriState `json:"-"`
+	Tags      *[]string       `json:"tags" ffjson:"tristate"`
+	TagsState shared.TriState `json:"-"`
+	ID        string          `json:"id"`
+}
+
+// TrailingPatch struct
+type TrailingPatch struct {
+	ID        string          `json:"id"`
+	Note      *string         `json:"note" ffjson:"tristate"`
+	NoteState shared.TriState `json:"-"`
+}
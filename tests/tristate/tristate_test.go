@@ -0,0 +1,111 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/maxproc/ffjson/shared"
+	ff "github.com/maxproc/ffjson/tests/tristate/ff"
+)
+
+func TestTriStateUnmarshal(t *testing.T) {
+	var p ff.Patch
+	err := p.UnmarshalJSON([]byte(`{"name":"x","age":null,"id":"1"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if p.NameState != shared.TriStateSet || p.Name == nil || *p.Name != "x" {
+		t.Fatalf("Unexpected name: %v %v", p.NameState, p.Name)
+	}
+	if p.AgeState != shared.TriStateNull || p.Age != nil {
+		t.Fatalf("Unexpected age: %v %v", p.AgeState, p.Age)
+	}
+	if p.TagsState != shared.TriStateAbsent || p.Tags != nil {
+		t.Fatalf("Unexpected tags: %v %v", p.TagsState, p.Tags)
+	}
+
+	// Decoding again resets fields missing from the input to absent.
+	err = p.UnmarshalJSON([]byte(`{"tags":[]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if p.NameState != shared.TriStateAbsent || p.AgeState != shared.TriStateAbsent || p.TagsState != shared.TriStateSet {
+		t.Fatalf("Unexpected states: %v %v %v", p.NameState, p.AgeState, p.TagsState)
+	}
+}
+
+func TestTriStateMarshal(t *testing.T) {
+	name := "x"
+	for _, tc := range []struct {
+		p        ff.Patch
+		expected string
+	}{
+		{ff.Patch{ID: "1"}, `{"id":"1"}`},
+		{ff.Patch{NameState: shared.TriStateNull, Name: &name}, `{"name":null,"id":""}`},
+		{ff.Patch{NameState: shared.TriStateSet, Name: &name, AgeState: shared.TriStateSet}, `{"name":"x","age":null,"id":""}`},
+		{ff.Patch{TagsState: shared.TriStateSet, Tags: &[]string{"a"}}, `{"tags":["a"],"id":""}`},
+	} {
+		out, err := tc.p.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != tc.expected {
+			t.Fatalf("Expected: %v\nGot: %v", tc.expected, string(out))
+		}
+	}
+
+	for _, tc := range []struct {
+		p        ff.TrailingPatch
+		expected string
+	}{
+		// As with omitempty, a conditional last field leaves a space.
+		{ff.TrailingPatch{ID: "1"}, `{ "id":"1"}`},
+		{ff.TrailingPatch{ID: "1", NoteState: shared.TriStateNull}, `{ "id":"1","note":null}`},
+		{ff.TrailingPatch{}, `{ "id":""}`},
+	} {
+		out, err := tc.p.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != tc.expected {
+			t.Fatalf("Expected: %v\nGot: %v", tc.expected, string(out))
+		}
+	}
+}
+
+func TestTriStateRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		`{"id":"1"}`,
+		`{"name":null,"age":3,"id":"1"}`,
+		`{"name":"a","age":null,"tags":null,"id":"1"}`,
+	} {
+		var p ff.Patch
+		err := p.UnmarshalJSON([]byte(input))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", input, err)
+		}
+		out, err := p.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != input {
+			t.Fatalf("Expected: %v\nGot: %v", input, string(out))
+		}
+	}
+}
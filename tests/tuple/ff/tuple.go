@@ -0,0 +1,58 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Point is written as [x,y].
+// ffjson: tuple
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// User is written as [id,name,age,tags,pos,score].
+// ffjson: tuple
+type User struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Age    *int     `json:"age"`
+	Tags   []string `json:"tags"`
+	Pos    Point    `json:"pos"`
+	Secret string   `json:"-"`
+	Score  float64  `json:"score,string"`
+}
+
+// Team is an object holding tuples.
+type Team struct {
+	Name    string  `json:"name"`
+	Members []User  `json:"members"`
+	Lead    *User   `json:"lead"`
+	Points  []Point `json:"points,omitempty"`
+}
+
+// Stamp is embedded in Event.
+type Stamp struct {
+	At int64 `json:"at"`
+}
+
+// Event is written as [kind,at]: the fields of embedded structs follow
+// the fields of the struct.
+// ffjson: tuple
+type Event struct {
+	Stamp
+	Kind string `json:"kind"`
+}
@@ -0,0 +1,182 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/tuple/ff"
+)
+
+func TestTupleMarshal(t *testing.T) {
+	age := 30
+	tests := []struct {
+		u        *ff.User
+		expected string
+	}{
+		{&ff.User{ID: 1, Name: "ann", Age: &age, Tags: []string{"a"}, Pos: ff.Point{X: 1.5, Y: -2}, Secret: "x", Score: 9.5},
+			`[1,"ann",30,["a"],[1.5,-2],"9.5"]`},
+		{&ff.User{}, `[0,"",null,null,[0,0],"0"]`},
+		{nil, `null`},
+	}
+
+	for _, test := range tests {
+		out, err := test.u.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if string(out) != test.expected {
+			t.Errorf("Expected: %s\nGot: %s", test.expected, out)
+		}
+		if !json.Valid(out) {
+			t.Errorf("invalid json: %s", out)
+		}
+	}
+}
+
+func TestTupleRoundTrip(t *testing.T) {
+	age := 7
+	team := ff.Team{
+		Name: "red",
+		Members: []ff.User{
+			{ID: 1, Name: "ann", Age: &age, Tags: []string{}, Score: 1},
+			{ID: 2, Name: "bob", Pos: ff.Point{X: 3, Y: 4}},
+		},
+		Lead:   &ff.User{ID: 3, Name: "cy"},
+		Points: []ff.Point{{X: 1, Y: 2}},
+	}
+	out, err := team.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "name":"red","members":[[1,"ann",7,[],[0,0],"1"],[2,"bob",null,null,[3,4],"0"]],"lead":[3,"cy",null,null,[0,0],"0"],"points":[[1,2]]}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	var got ff.Team
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, team) {
+		t.Fatalf("Expected: %#v\nGot: %#v", team, got)
+	}
+}
+
+func TestTupleUnmarshalMissing(t *testing.T) {
+	// Missing trailing elements leave their fields unchanged.
+	u := ff.User{ID: 9, Name: "old", Score: 2.5}
+	err := u.UnmarshalJSON([]byte(`[1, "new"]`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.User{ID: 1, Name: "new", Score: 2.5}
+	if !reflect.DeepEqual(u, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, u)
+	}
+
+	u = ff.User{ID: 9}
+	err = u.UnmarshalJSON([]byte(`[]`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if u.ID != 9 {
+		t.Fatalf("an empty array must leave the struct unchanged: %#v", u)
+	}
+
+	var p ff.Point
+	err = p.UnmarshalJSON([]byte(`[null, 2]`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if p != (ff.Point{Y: 2}) {
+		t.Fatalf("unexpected point: %#v", p)
+	}
+}
+
+func TestTupleUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		v   json.Unmarshaler
+		in  string
+		err string
+	}{
+		{&ff.User{}, `[1,"a",null,null,[0,0],"0",true]`, "User is a tuple of 6 elements"},
+		{&ff.Point{}, `[1,2,3]`, "Point is a tuple of 2 elements"},
+		{&ff.Point{}, `{"x":1,"y":2}`, "wanted token"},
+		{&ff.Point{}, `[1 2]`, "wanted token"},
+		{&ff.Point{}, `[1,]`, ""},
+		{&ff.Point{}, `[1,2`, ""},
+		{&ff.Point{}, `["a",2]`, ""},
+		{&ff.Team{}, `{"lead":{"id":1}}`, ""},
+	}
+
+	for _, test := range tests {
+		err := test.v.UnmarshalJSON([]byte(test.in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", test.in)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("UnmarshalJSON(%s): expected an error containing %q, got %v", test.in, test.err, err)
+		}
+	}
+}
+
+func TestTupleSchema(t *testing.T) {
+	var schema struct {
+		Type        string            `json:"type"`
+		PrefixItems []json.RawMessage `json:"prefixItems"`
+		Items       *bool             `json:"items"`
+		MinItems    int               `json:"minItems"`
+	}
+	err := json.Unmarshal(ff.User{}.JSONSchema(), &schema)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if schema.Type != "array" || len(schema.PrefixItems) != 6 || schema.Items == nil || *schema.Items || schema.MinItems != 6 {
+		t.Fatalf("unexpected schema: %s", ff.User{}.JSONSchema())
+	}
+	if !bytes.Contains(ff.Team{}.JSONSchema(), []byte(`"prefixItems"`)) {
+		t.Fatalf("the schema of Team must describe its tuples: %s", ff.Team{}.JSONSchema())
+	}
+}
+
+func TestTupleEmbedded(t *testing.T) {
+	e := ff.Event{Stamp: ff.Stamp{At: 5}, Kind: "k"}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `["k",5]` {
+		t.Fatalf("Expected: %s\nGot: %s", `["k",5]`, out)
+	}
+
+	var got ff.Event
+	err = got.UnmarshalJSON(out)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if got != e {
+		t.Fatalf("Expected: %#v\nGot: %#v", e, got)
+	}
+}
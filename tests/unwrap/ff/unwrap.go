@@ -0,0 +1,33 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Point struct
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Record struct
+type Record struct {
+	Name    string   `json:"name" ffjson:"unwrap"`
+	Count   int      `json:"count" ffjson:"unwrap"`
+	Ratio   *float64 `json:"ratio,omitempty" ffjson:"unwrap"`
+	Primary string   `json:"primary" ffjson:"unwrap=first"`
+	Origin  Point    `json:"origin" ffjson:"unwrap"`
+}
@@ -0,0 +1,74 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	ff "github.com/maxproc/ffjson/tests/unwrap/ff"
+)
+
+func TestUnwrapScalar(t *testing.T) {
+	var r ff.Record
+	err := r.UnmarshalJSON([]byte(`{"name":"a","count":1,"ratio":0.5,"primary":"p","origin":{"x":1,"y":2}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Name != "a" || r.Count != 1 || r.Ratio == nil || *r.Ratio != 0.5 || r.Primary != "p" || r.Origin.X != 1 {
+		t.Fatalf("Unexpected result: %+v", r)
+	}
+}
+
+func TestUnwrapArray(t *testing.T) {
+	var r ff.Record
+	err := r.UnmarshalJSON([]byte(`{"name":["a"],"count":[ 1 ],"ratio":[null],"primary":["p","q",["r"]],"origin":[{"x":1,"y":2}]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Name != "a" || r.Count != 1 || r.Ratio != nil || r.Primary != "p" || r.Origin.Y != 2 {
+		t.Fatalf("Unexpected result: %+v", r)
+	}
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	for input, msg := range map[string]string{
+		`{"name":["a","b"]}`: `more than one element into "name"`,
+		`{"count":[]}`:       `empty array into "count"`,
+		`{"name":[["a"]]}`:   `cannot unmarshal`,
+		`{"name":["a"}`:      `wanted token`,
+	} {
+		var r ff.Record
+		err := r.UnmarshalJSON([]byte(input))
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("UnmarshalJSON(%s): expected error containing %q, got %v", input, msg, err)
+		}
+	}
+}
+
+func TestUnwrapMarshal(t *testing.T) {
+	r := ff.Record{Name: "a", Count: 1, Primary: "p"}
+	out, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"name":"a","count":1,"primary":"p","origin":{"x":0,"y":0}}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
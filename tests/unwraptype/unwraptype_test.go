@@ -0,0 +1,103 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/maxproc/ffjson/ffjson"
+	ff "github.com/maxproc/ffjson/tests/unwraptype/ff"
+)
+
+func TestUnwrapType(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected ff.User
+	}{
+		{`{"id":1,"name":"a"}`, ff.User{ID: 1, Name: "a"}},
+		{`{"User":{"id":1,"name":"a"}}`, ff.User{ID: 1, Name: "a"}},
+		{` { "User" : { "id" : 1 } } `, ff.User{ID: 1}},
+		{`{}`, ff.User{}},
+		{`{"User":{}}`, ff.User{}},
+		// Not unwrapped: the key is matched exactly.
+		{`{"user":{"id":1}}`, ff.User{}},
+		// Not unwrapped: the wrapper has other members.
+		{`{"User":{"id":1},"name":"b"}`, ff.User{Name: "b"}},
+		{`{"name":"b","User":{"id":1}}`, ff.User{Name: "b"}},
+		// Not unwrapped: the member doesn't hold an object.
+		{`{"User":null}`, ff.User{}},
+		{`{"User":[{"id":1}]}`, ff.User{}},
+		// Only one level is unwrapped.
+		{`{"User":{"User":{"id":1}}}`, ff.User{}},
+	}
+
+	for _, test := range tests {
+		var u ff.User
+		err := u.UnmarshalJSON([]byte(test.in))
+		if err != nil {
+			t.Errorf("UnmarshalJSON(%s): %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, test.expected) {
+			t.Errorf("UnmarshalJSON(%s)\nExpected: %+v\nGot: %+v", test.in, test.expected, u)
+		}
+
+		var f ff.User
+		err = ffjson.Unmarshal([]byte(test.in), &f)
+		if err != nil {
+			t.Errorf("ffjson.Unmarshal(%s): %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(f, test.expected) {
+			t.Errorf("ffjson.Unmarshal(%s)\nExpected: %+v\nGot: %+v", test.in, test.expected, f)
+		}
+	}
+}
+
+func TestUnwrapTypeNested(t *testing.T) {
+	var team ff.Team
+	err := json.Unmarshal([]byte(`{"Team":{"lead":{"User":{"id":1}},"Label":"x"}}`), &team)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if team.Label != "x" || team.Lead == nil || team.Lead.ID != 0 {
+		t.Fatalf("the nested User must not be unwrapped: %+v, %+v", team, team.Lead)
+	}
+}
+
+func TestUnwrapTypeInvalid(t *testing.T) {
+	for _, in := range []string{`{"User":{"id":"x"}}`, `{"User":{"id":1}`, `[]`, `null`} {
+		var u ff.User
+		err := u.UnmarshalJSON([]byte(in))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected an error", in)
+		}
+	}
+}
+
+func TestUnwrapTypeMarshal(t *testing.T) {
+	out, err := json.Marshal(&ff.User{ID: 1, Name: "a"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"id":1,"name":"a"}` {
+		t.Fatalf("encoding is not wrapped: %s", out)
+	}
+}
@@ -0,0 +1,27 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Doc has interface{} values, decoding numbers as float64 unless the
+// decoder or fflib.SetUseNumber uses numbers.
+type Doc struct {
+	ID    int64                  `json:"id"`
+	Any   interface{}            `json:"any"`
+	Attrs map[string]interface{} `json:"attrs"`
+	List  []interface{}          `json:"list"`
+}
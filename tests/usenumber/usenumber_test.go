@@ -0,0 +1,136 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/maxproc/ffjson/ffjson"
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/usenumber/ff"
+)
+
+const input = `{"id":9007199254740993,"any":9007199254740993,` +
+	`"attrs":{"n":1.50,"s":"x","o":{"big":12345678901234567890}},"list":[1,[2]]}`
+
+var numbers = ff.Doc{
+	ID:  9007199254740993,
+	Any: json.Number("9007199254740993"),
+	Attrs: map[string]interface{}{
+		"n": json.Number("1.50"),
+		"s": "x",
+		"o": map[string]interface{}{"big": json.Number("12345678901234567890")},
+	},
+	List: []interface{}{json.Number("1"), []interface{}{json.Number("2")}},
+}
+
+func TestFloatsByDefault(t *testing.T) {
+	var d ff.Doc
+	err := d.UnmarshalJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if d.ID != 9007199254740993 {
+		t.Fatalf("Unexpected id: %d", d.ID)
+	}
+	if _, ok := d.Any.(float64); !ok {
+		t.Fatalf("Expected a float64, got %T", d.Any)
+	}
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	dec := ffjson.NewDecoder()
+	dec.UseNumber()
+	var d ff.Doc
+	err := dec.Decode([]byte(input), &d)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(d, numbers) {
+		t.Fatalf("Expected: %#v\nGot: %#v", numbers, d)
+	}
+
+	// Types without generated code use encoding/json with UseNumber.
+	var m map[string]interface{}
+	err = dec.Decode([]byte(`{"n":9007199254740993}`), &m)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if m["n"] != json.Number("9007199254740993") {
+		t.Fatalf("Expected a json.Number, got %#v", m["n"])
+	}
+
+	sd := ffjson.NewStreamDecoder(bytes.NewReader([]byte(input)))
+	sd.UseNumber()
+	d = ff.Doc{}
+	err = sd.Decode(&d)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(d, numbers) {
+		t.Fatalf("Expected: %#v\nGot: %#v", numbers, d)
+	}
+}
+
+func TestSetUseNumber(t *testing.T) {
+	fflib.SetUseNumber(true)
+	defer fflib.SetUseNumber(false)
+	var d ff.Doc
+	err := d.UnmarshalJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if !reflect.DeepEqual(d, numbers) {
+		t.Fatalf("Expected: %#v\nGot: %#v", numbers, d)
+	}
+
+	fflib.SetUseNumber(false)
+	err = d.UnmarshalJSON([]byte(`{"any":1}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if d.Any != 1.0 {
+		t.Fatalf("Expected a float64 after switching back, got %#v", d.Any)
+	}
+}
+
+func TestGeneratedUseNumber(t *testing.T) {
+	var d ff.NumberDoc
+	err := d.UnmarshalJSON([]byte(`{"any":-0.1e3,"attrs":{"n":[1]}}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	expected := ff.NumberDoc{
+		Any:   json.Number("-0.1e3"),
+		Attrs: map[string]interface{}{"n": []interface{}{json.Number("1")}},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Fatalf("Expected: %#v\nGot: %#v", expected, d)
+	}
+
+	out, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(out) != `{"any":-0.1e3,"attrs":{ "n":[1]}}` {
+		t.Fatalf("Unexpected json: %s", out)
+	}
+}
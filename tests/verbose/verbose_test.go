@@ -0,0 +1,80 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/maxproc/ffjson/fflib/v1"
+	ff "github.com/maxproc/ffjson/tests/verbose/ff"
+)
+
+func TestVerboseRoundTrip(t *testing.T) {
+	e := &ff.Event{ID: 1, Name: "start", Tags: []string{"a"}, Parent: &ff.Event{ID: 0}}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ff.Event
+	err = json.Unmarshal(out, &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", out, err)
+	}
+	if got.ID != e.ID || got.Name != e.Name || len(got.Tags) != 1 || got.Parent == nil {
+		t.Fatalf("Round trip mismatch: %+v", got)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	if fflib.Verbose {
+		t.Skip("built with ffjson_verbose")
+	}
+	e := &ff.Event{ID: 1, Name: "start"}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{ "id":1,"name":"start"}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	if !fflib.Verbose {
+		t.Skip("requires -tags ffjson_verbose")
+	}
+	e := &ff.Event{ID: 1, Name: "start"}
+	out, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{
+  "id": 1,
+  "name": "start",
+  "debug": "",
+  "tags": null,
+  "labels": null,
+  "parent": null
+}`
+	if string(out) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(out))
+	}
+}
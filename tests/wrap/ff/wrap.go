@@ -0,0 +1,31 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/maxproc/ffjson/tests/wrap/other"
+	lim "github.com/maxproc/ffjson/tests/wrap/other"
+)
+
+// AccountJSON encodes other.Account with ffjson.
+// ffjson: wrap
+type AccountJSON other.Account
+
+// LimitsJSON encodes other.Limits with ffjson.
+// ffjson: wrap
+type LimitsJSON lim.Limits
@@ -0,0 +1,47 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package other stands for a package whose types can't have methods
+// added, like one of another module.
+package other
+
+import (
+	"time"
+)
+
+type Account struct {
+	ID      int64     `json:"id"`
+	Owner   string    `json:"owner"`
+	Tags    []string  `json:"tags,omitempty"`
+	Created time.Time `json:"created"`
+	Limits  Limits    `json:"limits"`
+	secret  string
+}
+
+type Limits struct {
+	Daily int `json:"daily"`
+}
+
+// SetSecret sets a field that json doesn't see.
+func (a *Account) SetSecret(s string) {
+	a.secret = s
+}
+
+// Secret returns the field set by SetSecret.
+func (a *Account) Secret() string {
+	return a.secret
+}
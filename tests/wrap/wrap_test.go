@@ -0,0 +1,110 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	ff "github.com/maxproc/ffjson/tests/wrap/ff"
+	"github.com/maxproc/ffjson/tests/wrap/other"
+)
+
+func TestWrapMarshal(t *testing.T) {
+	a := other.Account{
+		ID:      7,
+		Owner:   "ann",
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Limits:  other.Limits{Daily: 100},
+	}
+	a.SetSecret("s")
+
+	expected, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	out, err := json.Marshal(ff.WrapAccountJSON(&a))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+
+	// The wrapper has the generated methods; the wrapped type doesn't.
+	var m json.Marshaler = ff.WrapAccountJSON(&a)
+	_ = m
+	if _, ok := interface{}(&a).(json.Marshaler); ok {
+		t.Fatalf("other.Account must not implement json.Marshaler")
+	}
+}
+
+func TestWrapUnmarshal(t *testing.T) {
+	in := `{"id":7,"owner":"ann","tags":["a"],"created":"2020-01-02T03:04:05Z","limits":{"daily":100}}`
+
+	var a other.Account
+	a.SetSecret("s")
+	err := json.Unmarshal([]byte(in), ff.WrapAccountJSON(&a))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	var expected other.Account
+	expected.SetSecret("s")
+	err = json.Unmarshal([]byte(in), &expected)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(a, expected) {
+		t.Fatalf("Expected: %+v\nGot: %+v", expected, a)
+	}
+}
+
+func TestWrapConversions(t *testing.T) {
+	a := &other.Account{ID: 1}
+	w := ff.WrapAccountJSON(a)
+	w.ID = 2
+	if a.ID != 2 || w.Unwrap() != a {
+		t.Fatalf("the wrapper must share the memory of the wrapped value")
+	}
+
+	var l ff.LimitsJSON
+	err := l.UnmarshalJSON([]byte(`{"daily":5}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if *l.Unwrap() != (other.Limits{Daily: 5}) {
+		t.Fatalf("unexpected value: %+v", l)
+	}
+
+	// Slices are converted element by element.
+	accounts := []other.Account{{ID: 1}, {ID: 2}}
+	wrapped := make([]*ff.AccountJSON, len(accounts))
+	for i := range accounts {
+		wrapped[i] = ff.WrapAccountJSON(&accounts[i])
+	}
+	out, err := json.Marshal(wrapped)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	expected, _ := json.Marshal(accounts)
+	if string(out) != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, out)
+	}
+}